@@ -0,0 +1,77 @@
+// Package annotations prints the problems found by the commands as CI annotations.
+package annotations
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Formats of the annotations.
+const (
+	FormatNone   = ""
+	FormatGitHub = "github"
+)
+
+// Levels of the annotations.
+const (
+	LevelWarning = "warning"
+	LevelError   = "error"
+)
+
+// Annotation is a problem attached to a file.
+type Annotation struct {
+	Level   string
+	File    string
+	Line    int
+	Message string
+}
+
+// Validate checks that a format is supported.
+func Validate(format string) error {
+	switch format {
+	case FormatNone, FormatGitHub:
+		return nil
+	default:
+		return fmt.Errorf("unsupported annotations format %q: supported formats are: %s", format, FormatGitHub)
+	}
+}
+
+// Format returns the annotation in the given format, an empty string without format.
+func (a Annotation) Format(format string) string {
+	if format != FormatGitHub {
+		return ""
+	}
+
+	properties := map[string]string{}
+	if a.File != "" {
+		properties["file"] = filepath.ToSlash(a.File)
+	}
+	if a.Line > 0 {
+		properties["line"] = fmt.Sprint(a.Line)
+	}
+
+	var keys []string
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		parts = append(parts, key+"="+escapeProperty(properties[key]))
+	}
+
+	return fmt.Sprintf("::%s %s::%s", a.Level, strings.Join(parts, ","), escapeData(a.Message))
+}
+
+// escapeData escapes the message of a GitHub workflow command.
+func escapeData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+// escapeProperty escapes a property of a GitHub workflow command.
+func escapeProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
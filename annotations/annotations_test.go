@@ -0,0 +1,53 @@
+package annotations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotation_Format(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		annotation Annotation
+		format     string
+		expected   string
+	}{
+		{
+			desc:       "without format",
+			annotation: Annotation{Level: LevelError, File: "ingress.yml", Line: 3, Message: "invalid"},
+			expected:   "",
+		},
+		{
+			desc:       "github",
+			annotation: Annotation{Level: LevelError, File: "ingress.yml", Line: 3, Message: "invalid"},
+			format:     FormatGitHub,
+			expected:   "::error file=ingress.yml,line=3::invalid",
+		},
+		{
+			desc:       "github without line",
+			annotation: Annotation{Level: LevelWarning, File: "app/ingress.yml", Message: "manual"},
+			format:     FormatGitHub,
+			expected:   "::warning file=app/ingress.yml::manual",
+		},
+		{
+			desc:       "github escaping",
+			annotation: Annotation{Level: LevelWarning, File: "a,b:c.yml", Message: "100%\nconverted"},
+			format:     FormatGitHub,
+			expected:   "::warning file=a%2Cb%3Ac.yml::100%25%0Aconverted",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.annotation.Format(test.format))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(""))
+	assert.NoError(t, Validate("github"))
+	assert.Error(t, Validate("gitlab"))
+}
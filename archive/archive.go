@@ -0,0 +1,105 @@
+// Package archive bundles the results of a migration in a compressed archive.
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Write writes a tar.gz archive with the files of the directory dir, under the dirName directory of the archive,
+// and with the extra files, by path in the archive.
+// The archive itself is skipped when written in dir.
+func Write(filename, dir, dirName string, extra map[string][]byte) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	err = write(file, dir, dirName, extra)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func write(file *os.File, dir, dirName string, extra map[string][]byte) error {
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		archiveInfo, err := file.Stat()
+		if err != nil {
+			return err
+		}
+
+		if os.SameFile(info, archiveInfo) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		return addFile(tw, path.Join(dirName, filepath.ToSlash(rel)), content, info.ModTime())
+	})
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		err = addFile(tw, name, extra[name], now)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+func addFile(tw *tar.Writer, name string, content []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tw.Write(content)
+	return err
+}
@@ -0,0 +1,56 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "apps"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "apps", "ingress.yml"), []byte("kind: IngressRoute\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ingress.yml"), []byte("kind: Middleware\n"), 0644))
+
+	// The archive is written in the bundled directory.
+	filename := filepath.Join(dir, "migration.tar.gz")
+
+	err := Write(filename, dir, "output", map[string][]byte{"report.json": []byte(`{"ingresses":[]}`)})
+	require.NoError(t, err)
+
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	files := map[string]string{}
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+
+		files[header.Name] = string(content)
+	}
+
+	expected := map[string]string{
+		"output/apps/ingress.yml": "kind: IngressRoute\n",
+		"output/ingress.yml":      "kind: Middleware\n",
+		"report.json":             `{"ingresses":[]}`,
+	}
+	assert.Equal(t, expected, files)
+}
@@ -0,0 +1,68 @@
+// Package cluster loads the configuration of the Kubernetes clients.
+package cluster
+
+import (
+	"context"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Config returns the client configuration of a kubeconfig context.
+// The default loading rules (KUBECONFIG, ~/.kube/config) are used when kubeconfig is empty, and the current context when context is empty.
+func Config(kubeconfig, context string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{CurrentContext: context}).ClientConfig()
+}
+
+// Contexts returns the sorted names of the contexts of the kubeconfig, loaded as in Config.
+func Contexts(kubeconfig string) ([]string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+
+	config, err := loadingRules.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// ServiceAccounts returns the sorted service accounts (<namespace>/<name>) of the cluster of a kubeconfig context.
+func ServiceAccounts(ctx context.Context, kubeconfig, context string) ([]string, error) {
+	config, err := Config(kubeconfig, context)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := client.CoreV1().ServiceAccounts(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, sa := range list.Items {
+		names = append(names, sa.Namespace+"/"+sa.Name)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
@@ -0,0 +1,49 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com
+- name: staging
+  cluster:
+    server: https://staging.example.com
+contexts:
+- name: staging
+  context:
+    cluster: staging
+- name: prod
+  context:
+    cluster: prod
+current-context: prod
+`
+
+func TestContexts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(path, []byte(kubeconfig), 0600))
+
+	contexts, err := Contexts(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"prod", "staging"}, contexts)
+}
+
+func TestConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(path, []byte(kubeconfig), 0600))
+
+	config, err := Config(path, "staging")
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://staging.example.com", config.Host)
+}
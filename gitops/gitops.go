@@ -0,0 +1,206 @@
+// Package gitops wraps the output of a migration in the manifests of the GitOps tools (Flux, Argo CD) deploying it.
+package gitops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats.
+const (
+	FormatFlux   = "flux"
+	FormatArgoCD = "argocd"
+)
+
+// AnnotationSyncWave is the Argo CD annotation ordering the synchronization of the objects, by ascending wave.
+const AnnotationSyncWave = "argocd.argoproj.io/sync-wave"
+
+// SyncWave returns the sync wave of a kind: the CRDs, then the middlewares and the other Traefik objects referenced by the routes, then the routes.
+// It returns an empty string for the other kinds, synchronized in the default wave.
+func SyncWave(kind string) string {
+	switch kind {
+	case "CustomResourceDefinition":
+		return "-2"
+	case "Middleware", "MiddlewareTCP", "TLSOption", "TLSStore", "TraefikService", "ServersTransport":
+		return "-1"
+	case "Ingress", "IngressRoute", "IngressRouteTCP", "IngressRouteUDP":
+		return "1"
+	default:
+		return ""
+	}
+}
+
+// Options holds the options of the GitOps manifest.
+type Options struct {
+	// Format is the GitOps tool: flux or argocd.
+	Format string
+	// Name is the name of the Flux Kustomization or of the Argo CD Application.
+	Name string
+	// Repository is the Flux GitRepository source (default to flux-system, the source created by the Flux bootstrap),
+	// or the URL of the Git repository of the Argo CD Application.
+	Repository string
+	// Path is the path of the output directory in the Git repository.
+	Path string
+}
+
+// Validate checks the options.
+func (o Options) Validate() error {
+	switch o.Format {
+	case FormatFlux, FormatArgoCD:
+	default:
+		return fmt.Errorf("unsupported output format %q: %s, %s", o.Format, FormatFlux, FormatArgoCD)
+	}
+
+	if o.Name == "" {
+		return fmt.Errorf("the name of the %s manifest is required", o.Format)
+	}
+
+	if o.Repository == "" && o.Format == FormatArgoCD {
+		return errors.New("the repository URL of the argocd manifest is required")
+	}
+
+	if o.Path == "" || filepath.IsAbs(o.Path) || strings.HasPrefix(filepath.ToSlash(filepath.Clean(o.Path)), "../") {
+		return fmt.Errorf("the path %q must be relative to the root of the repository", o.Path)
+	}
+
+	return nil
+}
+
+// Write writes the GitOps manifest to filename.
+func Write(filename string, opts Options) error {
+	content, err := Manifest(opts)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, content, 0o644)
+}
+
+// Manifest returns the Flux Kustomization, or the Argo CD Application, deploying the output directory.
+func Manifest(opts Options) ([]byte, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.ToSlash(filepath.Clean(opts.Path))
+
+	if opts.Format == FormatFlux {
+		repository := opts.Repository
+		if repository == "" {
+			repository = "flux-system"
+		}
+
+		return encode(fluxKustomization{
+			APIVersion: "kustomize.toolkit.fluxcd.io/v1beta1",
+			Kind:       "Kustomization",
+			Metadata:   metadata{Name: opts.Name, Namespace: "flux-system"},
+			Spec: fluxKustomizationSpec{
+				Interval:  "10m",
+				Path:      "./" + strings.TrimPrefix(path, "./"),
+				Prune:     true,
+				SourceRef: fluxSourceRef{Kind: "GitRepository", Name: repository},
+				// The routes are not reported ready before their middlewares are applied.
+				Wait: true,
+			},
+		})
+	}
+
+	return encode(argoCDApplication{
+		APIVersion: "argoproj.io/v1alpha1",
+		Kind:       "Application",
+		Metadata:   metadata{Name: opts.Name, Namespace: "argocd"},
+		Spec: argoCDApplicationSpec{
+			Project: "default",
+			Source: argoCDSource{
+				RepoURL:        opts.Repository,
+				Path:           path,
+				TargetRevision: "HEAD",
+			},
+			Destination: argoCDDestination{Server: "https://kubernetes.default.svc"},
+			SyncPolicy:  argoCDSyncPolicy{Automated: argoCDAutomated{Prune: true}},
+		},
+	})
+}
+
+func encode(manifest interface{}) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+
+	err := encoder.Encode(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+type metadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+type fluxKustomization struct {
+	APIVersion string                `yaml:"apiVersion"`
+	Kind       string                `yaml:"kind"`
+	Metadata   metadata              `yaml:"metadata"`
+	Spec       fluxKustomizationSpec `yaml:"spec"`
+}
+
+type fluxKustomizationSpec struct {
+	Interval  string        `yaml:"interval"`
+	Path      string        `yaml:"path"`
+	Prune     bool          `yaml:"prune"`
+	SourceRef fluxSourceRef `yaml:"sourceRef"`
+	Wait      bool          `yaml:"wait"`
+}
+
+type fluxSourceRef struct {
+	Kind string `yaml:"kind"`
+	Name string `yaml:"name"`
+}
+
+type argoCDApplication struct {
+	APIVersion string                `yaml:"apiVersion"`
+	Kind       string                `yaml:"kind"`
+	Metadata   metadata              `yaml:"metadata"`
+	Spec       argoCDApplicationSpec `yaml:"spec"`
+}
+
+type argoCDApplicationSpec struct {
+	Project     string            `yaml:"project"`
+	Source      argoCDSource      `yaml:"source"`
+	Destination argoCDDestination `yaml:"destination"`
+	SyncPolicy  argoCDSyncPolicy  `yaml:"syncPolicy"`
+}
+
+type argoCDSource struct {
+	RepoURL        string `yaml:"repoURL"`
+	Path           string `yaml:"path"`
+	TargetRevision string `yaml:"targetRevision"`
+}
+
+type argoCDDestination struct {
+	Server string `yaml:"server"`
+}
+
+type argoCDSyncPolicy struct {
+	Automated argoCDAutomated `yaml:"automated"`
+}
+
+type argoCDAutomated struct {
+	Prune bool `yaml:"prune"`
+}
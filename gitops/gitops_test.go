@@ -0,0 +1,134 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		opts     Options
+		expected string
+	}{
+		{
+			desc: "flux",
+			opts: Options{Format: FormatFlux, Name: "traefik-migration", Path: "clusters/prod/output"},
+			expected: `apiVersion: kustomize.toolkit.fluxcd.io/v1beta1
+kind: Kustomization
+metadata:
+  name: traefik-migration
+  namespace: flux-system
+spec:
+  interval: 10m
+  path: ./clusters/prod/output
+  prune: true
+  sourceRef:
+    kind: GitRepository
+    name: flux-system
+  wait: true
+`,
+		},
+		{
+			desc: "argocd",
+			opts: Options{Format: FormatArgoCD, Name: "traefik-migration", Repository: "https://git.example.com/infra.git", Path: "./output/"},
+			expected: `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: traefik-migration
+  namespace: argocd
+spec:
+  project: default
+  source:
+    repoURL: https://git.example.com/infra.git
+    path: output
+    targetRevision: HEAD
+  destination:
+    server: https://kubernetes.default.svc
+  syncPolicy:
+    automated:
+      prune: true
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := Manifest(test.opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, string(content))
+		})
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		opts     Options
+		expected string
+	}{
+		{
+			desc:     "unsupported format",
+			opts:     Options{Format: "helm", Name: "migration", Path: "output"},
+			expected: `unsupported output format "helm": flux, argocd`,
+		},
+		{
+			desc:     "missing name",
+			opts:     Options{Format: FormatFlux, Path: "output"},
+			expected: "the name of the flux manifest is required",
+		},
+		{
+			desc:     "missing argocd repository",
+			opts:     Options{Format: FormatArgoCD, Name: "migration", Path: "output"},
+			expected: "the repository URL of the argocd manifest is required",
+		},
+		{
+			desc:     "path outside of the repository",
+			opts:     Options{Format: FormatFlux, Name: "migration", Path: "../output"},
+			expected: `the path "../output" must be relative to the root of the repository`,
+		},
+		{
+			desc:     "absolute path",
+			opts:     Options{Format: FormatFlux, Name: "migration", Path: "/output"},
+			expected: `the path "/output" must be relative to the root of the repository`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.opts.Validate()
+			require.Error(t, err)
+			assert.Equal(t, test.expected, err.Error())
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "output-flux.yml")
+
+	err := Write(filename, Options{Format: FormatFlux, Name: "migration", Repository: "infra", Path: "output"})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(content), "name: infra\n")
+}
+
+func TestSyncWave(t *testing.T) {
+	assert.Equal(t, "-2", SyncWave("CustomResourceDefinition"))
+	assert.Equal(t, "-1", SyncWave("Middleware"))
+	assert.Equal(t, "1", SyncWave("IngressRoute"))
+	assert.Equal(t, "", SyncWave("Service"))
+}
@@ -4,18 +4,29 @@ go 1.14
 
 require (
 	github.com/BurntSushi/toml v0.3.1
+	github.com/abronan/valkeyrie v0.0.0-20200127174252-ef4277a138cd
 	github.com/containous/flaeg v1.4.1
 	github.com/go-acme/lego/v4 v4.1.3
 	github.com/gogo/protobuf v1.3.1
+	github.com/golang/protobuf v1.4.2
 	github.com/mitchellh/hashstructure v1.0.0
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/prometheus/client_golang v1.3.0
+	github.com/samuel/go-zookeeper v0.0.0-20190923202752-2cc03de413da
 	github.com/spf13/cobra v1.0.0
 	github.com/stretchr/testify v1.6.1
 	github.com/traefik/paerser v0.1.1
 	github.com/traefik/traefik/v2 v2.4.0
+	github.com/traefik/yaegi v0.9.8
+	google.golang.org/grpc v1.27.1
+	google.golang.org/protobuf v1.24.0
+	gopkg.in/redis.v5 v5.2.9
 	gopkg.in/yaml.v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.0-20200615113413-eeeca48fe776
 	k8s.io/api v0.19.2
 	k8s.io/apimachinery v0.19.2
 	k8s.io/client-go v0.19.2
+	sigs.k8s.io/service-apis v0.1.0
 	sigs.k8s.io/yaml v1.2.0
 )
 
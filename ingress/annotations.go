@@ -1,9 +1,12 @@
 package ingress
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/traefik/traefik-migration-tool/label"
+	networking "k8s.io/api/networking/v1beta1"
 )
 
 const (
@@ -37,10 +40,14 @@ const (
 	annotationKubernetesPublicKey               = "ingress.kubernetes.io/public-key"
 	annotationKubernetesReferrerPolicy          = "ingress.kubernetes.io/referrer-policy"
 	annotationKubernetesIsDevelopment           = "ingress.kubernetes.io/is-development"
+	annotationKubernetesFeaturePolicy           = "ingress.kubernetes.io/feature-policy"
+	annotationKubernetesPermissionsPolicy       = "ingress.kubernetes.io/permissions-policy"
 
 	// WhitelistMiddleware.
-	annotationKubernetesWhiteListSourceRange      = "ingress.kubernetes.io/whitelist-source-range"
-	annotationKubernetesWhiteListUseXForwardedFor = "ingress.kubernetes.io/whitelist-x-forwarded-for"
+	annotationKubernetesWhiteListSourceRange           = "ingress.kubernetes.io/whitelist-source-range"
+	annotationKubernetesWhiteListUseXForwardedFor      = "ingress.kubernetes.io/whitelist-x-forwarded-for"
+	annotationKubernetesWhiteListIPStrategyDepth       = "ingress.kubernetes.io/whitelist-ipstrategy-depth"
+	annotationKubernetesWhiteListIPStrategyExcludedIPs = "ingress.kubernetes.io/whitelist-ipstrategy-excluded-ips"
 
 	// AuthMiddleware.
 	annotationKubernetesAuthType                   = "ingress.kubernetes.io/auth-type"
@@ -70,7 +77,7 @@ const (
 	// Modifiers Middlewares.
 	annotationKubernetesRequestModifier = "ingress.kubernetes.io/request-modifier"
 
-	// TODO ErrorPagesMiddleware.
+	// Errors Middleware.
 	annotationKubernetesErrorPages = "ingress.kubernetes.io/error-pages"
 
 	// TODO service annotation.
@@ -78,28 +85,38 @@ const (
 	// TODO BufferingMiddleware.
 	annotationKubernetesBuffering = "ingress.kubernetes.io/buffering"
 
-	// TODO CircuitBreakMiddleware.
+	// CircuitBreaker Middleware.
 	annotationKubernetesCircuitBreakerExpression = "ingress.kubernetes.io/circuit-breaker-expression"
 
-	// TODO InFlightReqMiddleware.
+	// InFlightReq Middleware.
 	annotationKubernetesMaxConnAmount        = "ingress.kubernetes.io/max-conn-amount"
 	annotationKubernetesMaxConnExtractorFunc = "ingress.kubernetes.io/max-conn-extractor-func"
 
+	// Services.
 	annotationKubernetesResponseForwardingFlushInterval = "ingress.kubernetes.io/responseforwarding-flushinterval"
-	annotationKubernetesLoadBalancerMethod              = "ingress.kubernetes.io/load-balancer-method"
+	annotationKubernetesPreserveHost                    = "ingress.kubernetes.io/preserve-host"
+	annotationKubernetesSessionCookieName               = "ingress.kubernetes.io/session-cookie-name"
+	annotationKubernetesAffinity                        = "ingress.kubernetes.io/affinity"
 
-	// FIXME Not possible yet.
-	annotationKubernetesPreserveHost      = "ingress.kubernetes.io/preserve-host"
-	annotationKubernetesSessionCookieName = "ingress.kubernetes.io/session-cookie-name"
-	annotationKubernetesAffinity          = "ingress.kubernetes.io/affinity"
+	annotationKubernetesLoadBalancerMethod = "ingress.kubernetes.io/load-balancer-method"
 
-	// TODO ??
-	annotationKubernetesAuthRealm      = "ingress.kubernetes.io/auth-realm"
+	// TraefikService.
 	annotationKubernetesServiceWeights = "ingress.kubernetes.io/service-weights"
 
+	// TODO ??
+	annotationKubernetesAuthRealm = "ingress.kubernetes.io/auth-realm"
+
 	// FIXME global backend.
 )
 
+// v1AnnotationsPrefix is the prefix of the Traefik v1 annotations of the ingresses.
+const v1AnnotationsPrefix = "ingress.kubernetes.io/"
+
+// Traefik v2 annotations of the ingresses, read by the Kubernetes Ingress provider.
+const (
+	annotationRouterPriority = "traefik.ingress.kubernetes.io/router.priority"
+)
+
 var compatibilityMapping = map[string]string{
 	annotationKubernetesPreserveHost:             "traefik.frontend.passHostHeader",
 	annotationKubernetesPassTLSCert:              "traefik.frontend.passTLSCert",
@@ -113,8 +130,11 @@ var compatibilityMapping = map[string]string{
 	annotationKubernetesRedirectEntryPoint:       "traefik.frontend.redirect.entrypoint",
 	annotationKubernetesRedirectRegex:            "traefik.frontend.redirect.regex",
 	annotationKubernetesRedirectReplacement:      "traefik.frontend.redirect.replacement",
+	annotationKubernetesRedirectPermanent:        "traefik.frontend.redirect.permanent",
 }
 
+// getAnnotationName returns the name of an annotation set on an Ingress, in one of the forms read by Traefik v1:
+// the unprefixed ingress.kubernetes.io/ form, the traefik.ingress.kubernetes.io/ form, or the Traefik v1 label.
 func getAnnotationName(annotations map[string]string, name string) string {
 	if _, ok := annotations[name]; ok {
 		return name
@@ -150,11 +170,6 @@ func getBoolValue(annotations map[string]string, annotation string, defaultValue
 	return label.GetBoolValue(annotations, annotationName, defaultValue)
 }
 
-func getIntValue(annotations map[string]string, annotation string, defaultValue int) int {
-	annotationName := getAnnotationName(annotations, annotation)
-	return label.GetIntValue(annotations, annotationName, defaultValue)
-}
-
 func getInt64Value(annotations map[string]string, annotation string, defaultValue int64) int64 {
 	annotationName := getAnnotationName(annotations, annotation)
 	return label.GetInt64Value(annotations, annotationName, defaultValue)
@@ -169,3 +184,86 @@ func getMapValue(annotations map[string]string, annotation string) map[string]st
 	annotationName := getAnnotationName(annotations, annotation)
 	return label.GetMapValue(annotations, annotationName)
 }
+
+// setAnnotationPrefix copies the annotations of an Ingress with a custom prefix (ex: example.com/rule-type) to their ingress.kubernetes.io/ form,
+// the annotations already set in the ingress.kubernetes.io/ form are kept.
+func setAnnotationPrefix(ingress *networking.Ingress, prefix string) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return
+	}
+
+	annotations := ingress.GetAnnotations()
+	for key, value := range annotations {
+		name := strings.TrimPrefix(key, prefix+"/")
+		if name == key {
+			continue
+		}
+
+		if _, ok := annotations[v1AnnotationsPrefix+name]; !ok {
+			annotations[v1AnnotationsPrefix+name] = value
+		}
+	}
+}
+
+// getPriority returns the priority of the routes: the Traefik v1 priority annotation,
+// or the Traefik v2 router.priority annotation of the ingresses already annotated for Traefik v2.
+func getPriority(annotations map[string]string) (int, error) {
+	name := getAnnotationName(annotations, annotationKubernetesPriority)
+	raw, ok := annotations[name]
+	if !ok {
+		name = annotationRouterPriority
+		raw, ok = annotations[name]
+	}
+
+	if !ok || raw == "" {
+		return 0, nil
+	}
+
+	priority, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || priority < 0 {
+		return 0, fmt.Errorf("invalid %s %q: a positive integer is expected", name, raw)
+	}
+
+	return priority, nil
+}
+
+// priorityNotes returns the note about the priority annotation which cannot be converted: the routes have the default priority, the length of their rule.
+func priorityNotes(i *networking.Ingress) []string {
+	_, err := getPriority(i.GetAnnotations())
+	if err != nil {
+		return []string{fmt.Sprintf("The priority is not converted, the routes have the default priority: %v.", err)}
+	}
+
+	return nil
+}
+
+// hasV1Annotations returns whether an Ingress has Traefik v1 annotations.
+// The Traefik v2 annotations (traefik.ingress.kubernetes.io/router.* and service.*) are ignored.
+func hasV1Annotations(ingress *networking.Ingress) bool {
+	for key := range ingress.GetAnnotations() {
+		if _, ok := compatibilityLabels[key]; ok {
+			return true
+		}
+
+		if strings.HasPrefix(key, "ingress.kubernetes.io/") {
+			return true
+		}
+
+		if name := strings.TrimPrefix(key, label.Prefix+"ingress.kubernetes.io/"); name != key &&
+			!strings.HasPrefix(name, "router.") && !strings.HasPrefix(name, "service.") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compatibilityLabels are the Traefik v1 labels supported as annotations.
+var compatibilityLabels = func() map[string]struct{} {
+	labels := map[string]struct{}{}
+	for _, lbl := range compatibilityMapping {
+		labels[lbl] = struct{}{}
+	}
+	return labels
+}()
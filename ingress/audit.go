@@ -0,0 +1,232 @@
+package ingress
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	networking "k8s.io/api/networking/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// annotationAudit is the annotation holding the transformations applied to produce a converted object.
+const annotationAudit = "migration.traefik.io/audit"
+
+// Transformation is a step of the conversion of an Ingress: a Traefik v1 setting and the Traefik v2 construct it produced.
+type Transformation struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// field is the path of the produced field in the converted object, empty for the whole object.
+	field string
+}
+
+// middlewareAnnotations returns the annotations producing a middleware, by type of middleware.
+func middlewareAnnotations(spec v1alpha1.MiddlewareSpec) []string {
+	switch {
+	case spec.Headers != nil:
+		return []string{
+			annotationKubernetesCustomRequestHeaders, annotationKubernetesCustomResponseHeaders,
+			annotationKubernetesAllowedHosts, annotationKubernetesProxyHeaders,
+			annotationKubernetesSSLForceHost, annotationKubernetesSSLRedirect, annotationKubernetesSSLTemporaryRedirect,
+			annotationKubernetesSSLHost, annotationKubernetesSSLProxyHeaders,
+			annotationKubernetesHSTSMaxAge, annotationKubernetesHSTSIncludeSubdomains, annotationKubernetesHSTSPreload,
+			annotationKubernetesForceHSTSHeader, annotationKubernetesFrameDeny, annotationKubernetesCustomFrameOptionsValue,
+			annotationKubernetesContentTypeNosniff, annotationKubernetesBrowserXSSFilter, annotationKubernetesCustomBrowserXSSValue,
+			annotationKubernetesContentSecurityPolicy, annotationKubernetesPublicKey, annotationKubernetesReferrerPolicy,
+			annotationKubernetesIsDevelopment, annotationKubernetesFeaturePolicy, annotationKubernetesPermissionsPolicy,
+		}
+	case spec.BasicAuth != nil, spec.DigestAuth != nil, spec.ForwardAuth != nil:
+		return []string{
+			annotationKubernetesAuthType, annotationKubernetesAuthSecret, annotationKubernetesAuthRemoveHeader,
+			annotationKubernetesAuthHeaderField, annotationKubernetesAuthForwardURL, annotationKubernetesAuthForwardTrustHeaders,
+			annotationKubernetesAuthForwardResponseHeaders, annotationKubernetesAuthForwardTLSSecret, annotationKubernetesAuthForwardTLSInsecure,
+		}
+	case spec.IPWhiteList != nil:
+		return []string{
+			annotationKubernetesWhiteListSourceRange, annotationKubernetesWhiteListUseXForwardedFor,
+			annotationKubernetesWhiteListIPStrategyDepth, annotationKubernetesWhiteListIPStrategyExcludedIPs,
+		}
+	case spec.PassTLSClientCert != nil:
+		return []string{annotationKubernetesPassTLSClientCert, annotationKubernetesPassTLSCert}
+	case spec.RateLimit != nil:
+		return []string{annotationKubernetesRateLimit}
+	case spec.StripPrefix != nil:
+		return []string{annotationKubernetesRuleType}
+	case spec.ReplacePathRegex != nil:
+		return []string{annotationKubernetesRewriteTarget, annotationKubernetesRequestModifier, annotationKubernetesRuleType}
+	case spec.ReplacePath != nil:
+		return []string{annotationKubernetesRequestModifier, annotationKubernetesRuleType}
+	case spec.AddPrefix != nil:
+		return []string{annotationKubernetesRequestModifier}
+	case spec.RedirectScheme != nil:
+		return []string{
+			annotationKubernetesRedirectEntryPoint, annotationKubernetesRedirectPermanent,
+			annotationKubernetesSSLRedirect, annotationKubernetesSSLTemporaryRedirect,
+		}
+	case spec.RedirectRegex != nil:
+		return []string{
+			annotationKubernetesAppRoot, annotationKubernetesRedirectEntryPoint, annotationKubernetesRedirectPermanent,
+			annotationKubernetesRedirectRegex, annotationKubernetesRedirectReplacement,
+		}
+	default:
+		return nil
+	}
+}
+
+// auditIngress returns the transformations applied to an Ingress to produce each of the converted objects.
+func auditIngress(ingress *networking.Ingress, objects []runtime.Object, hostless string) map[runtime.Object][]Transformation {
+	audit := map[runtime.Object][]Transformation{}
+
+	annotations := ingress.GetAnnotations()
+	source := fmt.Sprintf("Ingress %s/%s", ingress.GetNamespace(), ingress.GetName())
+
+	for _, object := range objects {
+		switch obj := object.(type) {
+		case *v1alpha1.IngressRoute:
+			target := fmt.Sprintf("IngressRoute %s/%s", obj.GetNamespace(), obj.GetName())
+			steps := []Transformation{{From: source, To: target}}
+
+			for _, annotation := range presentAnnotations(annotations, annotationKubernetesFrontendEntryPoints) {
+				steps = append(steps, Transformation{From: "annotation " + annotation, To: "spec.entryPoints", field: "spec.entryPoints"})
+			}
+
+			for _, annotation := range presentAnnotations(annotations, annotationKubernetesRuleType, annotationKubernetesPriority, annotationRouterPriority, annotationKubernetesProtocol,
+				annotationKubernetesPreserveHost, annotationKubernetesResponseForwardingFlushInterval, annotationKubernetesAffinity, annotationKubernetesSessionCookieName) {
+				steps = append(steps, Transformation{From: "annotation " + annotation, To: "spec.routes", field: "spec.routes"})
+			}
+
+			steps = append(steps, auditRules(ingress.Spec.Rules, obj.Spec.Routes, hostless)...)
+
+			if ingress.Spec.Backend != nil {
+				for i, route := range obj.Spec.Routes {
+					if route.Match == defaultBackendMatch && route.Priority == defaultBackendPriority {
+						steps = append(steps, Transformation{
+							From:  fmt.Sprintf("default backend %s", ingress.Spec.Backend.ServiceName),
+							To:    fmt.Sprintf("route %s", route.Match),
+							field: fmt.Sprintf("spec.routes.%d", i),
+						})
+					}
+				}
+			}
+
+			if obj.Spec.TLS != nil {
+				steps = append(steps, Transformation{From: "spec.tls", To: "spec.tls", field: "spec.tls"})
+			}
+
+			audit[object] = steps
+
+		case *v1alpha1.Middleware:
+			target := fmt.Sprintf("Middleware %s/%s", obj.GetNamespace(), obj.GetName())
+
+			var steps []Transformation
+			for _, annotation := range presentAnnotations(annotations, middlewareAnnotations(obj.Spec)...) {
+				steps = append(steps, Transformation{From: "annotation " + annotation, To: target})
+			}
+
+			audit[object] = steps
+
+		case *v1alpha1.TraefikService:
+			target := fmt.Sprintf("TraefikService %s/%s", obj.GetNamespace(), obj.GetName())
+
+			var steps []Transformation
+			for _, annotation := range presentAnnotations(annotations, annotationKubernetesServiceWeights, annotationKubernetesAffinity, annotationKubernetesSessionCookieName) {
+				steps = append(steps, Transformation{From: "annotation " + annotation, To: target})
+			}
+
+			audit[object] = steps
+
+		case *v1alpha1.TLSOption:
+			target := fmt.Sprintf("TLSOption %s/%s", obj.GetNamespace(), obj.GetName())
+
+			steps := []Transformation{{From: "spec.tls", To: target}}
+			for _, annotation := range presentAnnotations(annotations, annotationKubernetesFrontendEntryPoints) {
+				steps = append(steps, Transformation{From: "annotation " + annotation, To: target})
+			}
+
+			audit[object] = steps
+		}
+	}
+
+	return audit
+}
+
+// auditRules returns the transformations of the Ingress rules into routes.
+// The routes are created in the order of the rules paths, the paths of the rules without host skipped with HostlessSkip,
+// and the paths of the resource backends which are not a TraefikService, don't produce a route.
+func auditRules(rules []networking.IngressRule, routes []v1alpha1.Route, hostless string) []Transformation {
+	var steps []Transformation
+
+	i := 0
+	for _, rule := range rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for _, path := range rule.HTTP.Paths {
+			if len(rule.Host) == 0 && hostless == HostlessSkip {
+				continue
+			}
+
+			if path.Backend.Resource != nil && !isTraefikServiceResource(path.Backend.Resource) {
+				continue
+			}
+
+			if i >= len(routes) {
+				return steps
+			}
+
+			steps = append(steps, Transformation{
+				From:  fmt.Sprintf("rule host %q path %q", rule.Host, path.Path),
+				To:    fmt.Sprintf("route %s", routes[i].Match),
+				field: fmt.Sprintf("spec.routes.%d", i),
+			})
+			i++
+		}
+	}
+
+	return steps
+}
+
+// presentAnnotations returns the names of the annotations set on an object, among the given annotations.
+func presentAnnotations(annotations map[string]string, names ...string) []string {
+	var present []string
+	for _, name := range names {
+		annotationName := getAnnotationName(annotations, name)
+		if _, ok := annotations[annotationName]; ok {
+			present = append(present, annotationName)
+		}
+	}
+
+	return present
+}
+
+// setAuditAnnotations sets the audit annotation on each of the converted objects.
+func setAuditAnnotations(audit map[runtime.Object][]Transformation) error {
+	for object, steps := range audit {
+		meta, ok := object.(v1.Object)
+		if !ok || len(steps) == 0 {
+			continue
+		}
+
+		// The rules are kept readable: && must not be escaped.
+		value := &bytes.Buffer{}
+		encoder := json.NewEncoder(value)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(steps); err != nil {
+			return err
+		}
+
+		annotations := meta.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[annotationAudit] = strings.TrimSpace(value.String())
+		meta.SetAnnotations(annotations)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package ingress
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	networking "k8s.io/api/networking/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Canary configures a canary migration: the ingresses are kept, next to their conversion.
+type Canary struct {
+	// EntryPoint, when set, binds the IngressRoutes to this entry point only, to shadow-test the Traefik v2 routing.
+	EntryPoint string
+	// LegacyService, when set, is the Traefik v1 service receiving the traffic not sent to the services of the converted routes.
+	LegacyService *v1alpha1.LoadBalancerSpec
+	// Weight is the percentage of the traffic sent to the services of the converted routes, with LegacyService.
+	Weight int
+}
+
+// Validate checks the canary configuration.
+func (c *Canary) Validate() error {
+	if c.EntryPoint == "" && c.LegacyService == nil {
+		return errors.New("the canary needs an entry point or a legacy service")
+	}
+
+	if c.LegacyService != nil && (c.Weight < 1 || c.Weight > 99) {
+		return fmt.Errorf("the canary weight must be a percentage between 1 and 99: %d", c.Weight)
+	}
+
+	return nil
+}
+
+// ParseService parses a service reference: <namespace>/<name>:<port>.
+func ParseService(value string) (*v1alpha1.LoadBalancerSpec, error) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, fmt.Errorf("invalid service %q: <namespace>/<name>:<port> expected", value)
+	}
+
+	nameAndPort := strings.SplitN(parts[1], ":", 2)
+	if len(nameAndPort) != 2 || nameAndPort[0] == "" {
+		return nil, fmt.Errorf("invalid service %q: <namespace>/<name>:<port> expected", value)
+	}
+
+	port, err := strconv.ParseInt(nameAndPort[1], 10, 32)
+	if err != nil || port <= 0 {
+		return nil, fmt.Errorf("invalid service %q: invalid port %q", value, nameAndPort[1])
+	}
+
+	return &v1alpha1.LoadBalancerSpec{
+		Name:      nameAndPort[0],
+		Namespace: parts[0],
+		Kind:      "Service",
+		Port:      int32(port),
+	}, nil
+}
+
+// applyCanary changes the objects produced by the conversion of an Ingress for a canary migration.
+// With a legacy service, the services of each route are replaced by a weighted TraefikService,
+// splitting the traffic between the services of the route and the legacy service.
+func applyCanary(canary *Canary, ingress *networking.Ingress, objects []runtime.Object) []runtime.Object {
+	if canary == nil || len(objects) == 0 {
+		return objects
+	}
+
+	ingressRoute, ok := objects[0].(*v1alpha1.IngressRoute)
+	if !ok {
+		return objects
+	}
+
+	if canary.EntryPoint != "" {
+		ingressRoute.Spec.EntryPoints = []string{canary.EntryPoint}
+	}
+
+	if canary.LegacyService == nil {
+		return objects
+	}
+
+	prefix := "canary"
+	if ingressRoute.GetName() != "" {
+		prefix = ingressRoute.GetName() + "-canary"
+	}
+
+	for i, route := range ingressRoute.Spec.Routes {
+		weight := canary.Weight
+		// The weights are relative: the legacy service receives as much as all the services of the route.
+		legacyWeight := (100 - canary.Weight) * len(route.Services)
+
+		var services []v1alpha1.Service
+		for _, service := range route.Services {
+			service.Weight = &weight
+			services = append(services, service)
+		}
+
+		legacy := *canary.LegacyService
+		legacy.Weight = &legacyWeight
+		services = append(services, v1alpha1.Service{LoadBalancerSpec: legacy})
+
+		traefikService := &v1alpha1.TraefikService{
+			ObjectMeta: v1.ObjectMeta{Name: indexedName(prefix, i), Namespace: ingress.GetNamespace()},
+			Spec: v1alpha1.ServiceSpec{
+				Weighted: &v1alpha1.WeightedRoundRobin{Services: services},
+			},
+		}
+
+		ingressRoute.Spec.Routes[i].Services = []v1alpha1.Service{{
+			LoadBalancerSpec: v1alpha1.LoadBalancerSpec{
+				Name:      traefikService.GetName(),
+				Namespace: traefikService.GetNamespace(),
+				Kind:      "TraefikService",
+			},
+		}}
+
+		objects = append(objects, traefikService)
+	}
+
+	return objects
+}
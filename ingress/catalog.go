@@ -0,0 +1,69 @@
+package ingress
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+)
+
+// LoadMiddlewareCatalog returns the middlewares of the manifests of a file, or of the files of a directory:
+// the middlewares already deployed, referenced instead of the converted middlewares with the same spec (see Options.MiddlewareCatalog).
+func LoadMiddlewareCatalog(src string) ([]*v1alpha1.Middleware, error) {
+	var middlewares []*v1alpha1.Middleware
+
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		for _, document := range splitDocuments(content) {
+			object, err := parseYaml([]byte(document))
+			if err != nil {
+				continue
+			}
+
+			if middleware, ok := object.(*v1alpha1.Middleware); ok {
+				middlewares = append(middlewares, middleware)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return middlewares, nil
+}
+
+// catalogMiddlewares returns the names of the middlewares of a catalog by <namespace>/<spec key>, the first middleware of a spec is kept.
+func catalogMiddlewares(catalog []*v1alpha1.Middleware) map[string]string {
+	names := map[string]string{}
+	for _, middleware := range catalog {
+		key := middleware.GetNamespace() + "/" + middlewareSpecKey(middleware.Spec)
+		if _, ok := names[key]; !ok {
+			names[key] = middleware.GetName()
+		}
+	}
+
+	return names
+}
+
+// middlewareSpecKey returns the key of a middleware spec: the same for the specs with the same options,
+// whether they are converted or read from a manifest.
+func middlewareSpecKey(spec v1alpha1.MiddlewareSpec) string {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(data)
+}
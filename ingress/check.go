@@ -0,0 +1,86 @@
+package ingress
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Check converts the ingresses of src in a temporary directory, and returns the differences with the files of dstDir.
+// The differences are the paths, relative to dstDir, of the files modified, missing, or not produced by the conversion.
+func Check(src, dstDir string, opts Options) ([]string, error) {
+	tmpDir, err := os.MkdirTemp("", "traefik-migration-check-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	opts.Checkpoint = ""
+
+	_, err = Convert(src, tmpDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	expected, err := readTree(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, err := readTree(dstDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var diffs []string
+	for name, content := range expected {
+		current, ok := actual[name]
+		switch {
+		case !ok:
+			diffs = append(diffs, name+": missing")
+		case !bytes.Equal(content, current):
+			diffs = append(diffs, name+": modified")
+		}
+	}
+
+	for name := range actual {
+		if _, ok := expected[name]; !ok {
+			diffs = append(diffs, name+": not produced by the conversion")
+		}
+	}
+
+	sort.Strings(diffs)
+
+	return diffs, nil
+}
+
+// readTree returns the content of the files of a directory tree, by path relative to the directory.
+func readTree(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(rel)] = content
+		return nil
+	})
+
+	return files, err
+}
@@ -0,0 +1,98 @@
+package ingress
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// checkpoint records the files already converted by a migration, to resume it after an interruption.
+type checkpoint struct {
+	Source string   `json:"source"`
+	Output string   `json:"output"`
+	Done   []string `json:"done"`
+
+	path string
+	done map[string]bool
+}
+
+// loadCheckpoint returns the checkpoint of the conversion of src into dstDir.
+// An existing checkpoint is resumed only when resume is set, and must be the checkpoint of the same source and output.
+func loadCheckpoint(path, src, dstDir string, resume bool) (*checkpoint, error) {
+	source, err := filepath.Abs(src)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := filepath.Abs(dstDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &checkpoint{Source: source, Output: output, path: path, done: map[string]bool{}}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !resume {
+		return nil, fmt.Errorf("the checkpoint %s of a previous migration exists: resume or restart the migration", path)
+	}
+
+	previous := &checkpoint{}
+	err = json.Unmarshal(content, previous)
+	if err != nil {
+		return nil, fmt.Errorf("reading the checkpoint %s: %w", path, err)
+	}
+
+	if previous.Source != source || previous.Output != output {
+		return nil, fmt.Errorf("the checkpoint %s is the migration of %s into %s", path, previous.Source, previous.Output)
+	}
+
+	for _, file := range previous.Done {
+		cp.Done = append(cp.Done, file)
+		cp.done[file] = true
+	}
+
+	return cp, nil
+}
+
+func (c *checkpoint) isDone(file string) bool {
+	return c.done[file]
+}
+
+// markDone records a converted file, the checkpoint is replaced atomically.
+func (c *checkpoint) markDone(file string) error {
+	c.Done = append(c.Done, file)
+	c.done[file] = true
+
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+
+	err = os.WriteFile(tmp, content, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, c.path)
+}
+
+// remove removes the checkpoint of a completed migration.
+func (c *checkpoint) remove() error {
+	err := os.Remove(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,188 @@
+package ingress
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fieldComment is a comment of a source document.
+type fieldComment struct {
+	// path is the path of the commented field, empty for the comment above the document.
+	path []string
+	// lines are the lines of the comment, without the # marker.
+	lines []string
+}
+
+// documentComments returns the comments of a YAML document: the comment above the document, and the comments of the fields of its mappings and sequences.
+// The comments at the end of the line of a field are returned as comments of the field.
+func documentComments(part string) []fieldComment {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(part), &document); err != nil || len(document.Content) == 0 {
+		return nil
+	}
+
+	root := document.Content[0]
+
+	// The comment above the document is the comment of the first field when it is not followed by a blank line.
+	head := document.HeadComment
+	if root.Kind == yaml.MappingNode && len(root.Content) > 0 {
+		head = joinComments(head, root.Content[0].HeadComment)
+	}
+
+	var comments []fieldComment
+	if lines := commentLines(head); len(lines) > 0 {
+		comments = append(comments, fieldComment{lines: lines})
+	}
+
+	return append(comments, nodeComments(root, nil)...)
+}
+
+// nodeComments returns the comments of the fields of a node, the fields are under the path of the node.
+func nodeComments(node *yaml.Node, path []string) []fieldComment {
+	var comments []fieldComment
+
+	add := func(fieldPath []string, nodes ...*yaml.Node) {
+		var comment string
+		for i, n := range nodes {
+			// The comment above the first field of the document is the comment above the document.
+			if len(path) > 0 || i > 0 || n != node.Content[0] {
+				comment = joinComments(comment, n.HeadComment)
+			}
+			comment = joinComments(comment, n.LineComment)
+		}
+
+		if lines := commentLines(comment); len(lines) > 0 {
+			comments = append(comments, fieldComment{path: fieldPath, lines: lines})
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldPath := append(path[:len(path):len(path)], key.Value)
+
+			add(fieldPath, key, value)
+			comments = append(comments, nodeComments(value, fieldPath)...)
+		}
+
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			fieldPath := append(path[:len(path):len(path)], strconv.Itoa(i))
+
+			add(fieldPath, item)
+			comments = append(comments, nodeComments(item, fieldPath)...)
+		}
+	}
+
+	return comments
+}
+
+// joinComments joins the comments of a node.
+func joinComments(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+
+	return a + "\n" + b
+}
+
+// commentLines returns the lines of a comment, without the # markers and the blank lines.
+func commentLines(comment string) []string {
+	var lines []string
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		lines = append(lines, strings.TrimPrefix(strings.TrimPrefix(line, "#"), " "))
+	}
+
+	return lines
+}
+
+// restoreComments inserts, in the YAML of an object, comments of its source document above the same fields.
+// The comments of the fields which are not in the YAML are dropped.
+func restoreComments(yml string, comments []fieldComment) (string, error) {
+	if len(comments) == 0 {
+		return yml, nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(yml), &document); err != nil {
+		return "", err
+	}
+
+	if len(document.Content) == 0 {
+		return yml, nil
+	}
+
+	lines := map[int][]string{}
+	for _, comment := range comments {
+		line := 1
+		if len(comment.path) > 0 {
+			node := lookupField(document.Content[0], comment.path)
+			if node == nil {
+				continue
+			}
+			line = node.Line
+		}
+
+		lines[line] = append(lines[line], comment.lines...)
+	}
+
+	return insertComments(yml, lines), nil
+}
+
+// convertedComments returns the comments of the source Ingress carried to a converted object:
+// the comment above the source document for the first converted object, and the comments of the annotations above the fields produced from them.
+func convertedComments(comments []fieldComment, steps []Transformation, first bool) []fieldComment {
+	var converted []fieldComment
+	for _, comment := range comments {
+		if len(comment.path) == 0 {
+			if first {
+				converted = append(converted, comment)
+			}
+			continue
+		}
+
+		if len(comment.path) != 3 || comment.path[0] != "metadata" || comment.path[1] != "annotations" {
+			continue
+		}
+
+		for _, step := range steps {
+			if step.From != "annotation "+comment.path[2] {
+				continue
+			}
+
+			var path []string
+			if step.field != "" {
+				path = strings.Split(step.field, ".")
+			}
+
+			converted = append(converted, fieldComment{path: path, lines: comment.lines})
+		}
+	}
+
+	return converted
+}
+
+// insertComments inserts comments above the lines of a YAML, by line number (starting at 1), with the indentation of the lines.
+func insertComments(yml string, comments map[int][]string) string {
+	lines := strings.Split(yml, "\n")
+
+	var result []string
+	for i, line := range lines {
+		indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+		for _, comment := range comments[i+1] {
+			result = append(result, indent+"# "+comment)
+		}
+
+		result = append(result, line)
+	}
+
+	return strings.Join(result, "\n")
+}
@@ -0,0 +1,80 @@
+package ingress
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// explain adds to the YAML of a converted object a "# migrated from" comment above each field produced by a transformation.
+// The comments are inserted in the encoded YAML, which keeps the formatting of the converted objects.
+func explain(yml string, steps []Transformation) (string, error) {
+	if len(steps) == 0 {
+		return yml, nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(yml), &document); err != nil {
+		return "", err
+	}
+
+	if len(document.Content) == 0 {
+		return yml, nil
+	}
+
+	// comments holds the comments to insert above each line, by line number (starting at 1).
+	comments := map[int][]string{}
+	for _, step := range steps {
+		line := 1
+		if step.field != "" {
+			node := lookupField(document.Content[0], strings.Split(step.field, "."))
+			if node == nil {
+				return "", fmt.Errorf("field %s not found", step.field)
+			}
+			line = node.Line
+		}
+
+		comments[line] = append(comments[line], "migrated from "+strings.TrimPrefix(step.From, "annotation "))
+	}
+
+	return insertComments(yml, comments), nil
+}
+
+// lookupField returns the node of a field (the key of a mapping, or the item of a sequence) from its path.
+func lookupField(node *yaml.Node, path []string) *yaml.Node {
+	var field *yaml.Node
+
+	for _, elt := range path {
+		switch node.Kind {
+		case yaml.MappingNode:
+			field = nil
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == elt {
+					field = node.Content[i]
+					node = node.Content[i+1]
+					break
+				}
+			}
+
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(elt)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return nil
+			}
+
+			field = node.Content[index]
+			node = field
+
+		default:
+			return nil
+		}
+
+		if field == nil {
+			return nil
+		}
+	}
+
+	return field
+}
@@ -0,0 +1,141 @@
+package ingress
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// fileFilter selects the files of a source directory with the Include and Exclude glob patterns.
+// The patterns match the paths relative to the source directory, with / separators: * and ? don't match /, and ** matches any number of directories.
+// The patterns without / match the names of the files and the directories.
+type fileFilter struct {
+	root    string
+	include []glob
+	exclude []glob
+}
+
+// glob is a compiled glob pattern.
+type glob struct {
+	exp *regexp.Regexp
+	// base tells whether the pattern matches the base names, the patterns without /.
+	base bool
+}
+
+// newFileFilter returns the filter of the files of the src of a conversion.
+func newFileFilter(src string, include, exclude []string) (*fileFilter, error) {
+	filter := &fileFilter{root: src}
+
+	for _, pattern := range include {
+		g, err := compileGlob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+		filter.include = append(filter.include, g)
+	}
+
+	for _, pattern := range exclude {
+		g, err := compileGlob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+		filter.exclude = append(filter.exclude, g)
+	}
+
+	return filter, nil
+}
+
+// match returns whether a file, or a directory, of the source is converted.
+// The directories are only excluded by the Exclude patterns, the files are also excluded when they match none of the Include patterns.
+// The src of the conversion is always converted when it is a directory.
+func (f *fileFilter) match(path string, dir bool) bool {
+	if f == nil || len(f.include) == 0 && len(f.exclude) == 0 {
+		return true
+	}
+
+	rel, err := filepath.Rel(f.root, path)
+	if err != nil {
+		return true
+	}
+
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		if dir {
+			return true
+		}
+		rel = filepath.Base(path)
+	}
+
+	if dir {
+		// The directories match the patterns of their files (ex: **/charts/**).
+		return !matchAny(f.exclude, rel+"/")
+	}
+
+	if matchAny(f.exclude, rel) {
+		return false
+	}
+
+	return len(f.include) == 0 || matchAny(f.include, rel)
+}
+
+// matchAny returns whether a relative path matches one of the patterns, the patterns without / match its base name.
+func matchAny(globs []glob, rel string) bool {
+	base := strings.TrimSuffix(rel, "/")
+	base = base[strings.LastIndex(base, "/")+1:]
+
+	for _, g := range globs {
+		if g.base && g.exp.MatchString(base) || !g.base && g.exp.MatchString(rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compileGlob compiles a glob pattern into a regular expression.
+func compileGlob(pattern string) (glob, error) {
+	var exp strings.Builder
+	exp.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			switch {
+			case strings.HasPrefix(pattern[i:], "**/"):
+				exp.WriteString("(.*/)?")
+				i += 2
+			case strings.HasPrefix(pattern[i:], "**"):
+				exp.WriteString(".*")
+				i++
+			default:
+				exp.WriteString("[^/]*")
+			}
+		case '?':
+			exp.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return glob{}, fmt.Errorf("unterminated character class at %d", i)
+			}
+
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			exp.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			exp.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	exp.WriteString("$")
+
+	compiled, err := regexp.Compile(exp.String())
+	if err != nil {
+		return glob{}, err
+	}
+
+	return glob{exp: compiled, base: !strings.Contains(pattern, "/")}, nil
+}
@@ -0,0 +1,366 @@
+package ingress
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	networking "k8s.io/api/networking/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayapi "sigs.k8s.io/service-apis/apis/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// OutputKindGateway converts each Ingress to the HTTPRoutes of the Gateway API (networking.x-k8s.io/v1alpha1), served by the experimental Traefik provider.
+// The routes are bound to Options.Gateway, the generated middlewares are kept and referenced by ExtensionRef filters.
+const OutputKindGateway = "gateway"
+
+// gatewayFile is the file of the Gateway, in the output directory.
+const gatewayFile = "gateway.yml"
+
+// Gateway is the Gateway of the HTTPRoutes converted with OutputKindGateway.
+type Gateway struct {
+	Namespace string
+	Name      string
+	// ClassName is the GatewayClass of the Traefik provider.
+	ClassName string
+	// Port is the port of the HTTP listener, the port of a Traefik entry point.
+	Port int32
+}
+
+// DefaultGateway returns the Gateway used when Options.Gateway is not set.
+func DefaultGateway() *Gateway {
+	return &Gateway{Namespace: "default", Name: "traefik-gateway", ClassName: "traefik", Port: 80}
+}
+
+// Validate checks the Gateway.
+func (g *Gateway) Validate() error {
+	if g.Namespace == "" || g.Name == "" || g.ClassName == "" {
+		return errors.New("the namespace, name, and class of the Gateway are required")
+	}
+
+	if g.Port <= 0 || g.Port > 65535 {
+		return fmt.Errorf("invalid Gateway port %d", g.Port)
+	}
+
+	return nil
+}
+
+// object returns the Gateway, with an HTTP listener accepting the HTTPRoutes of all the namespaces.
+func (g *Gateway) object() *gatewayapi.Gateway {
+	return &gatewayapi.Gateway{
+		TypeMeta:   v1.TypeMeta{APIVersion: gatewayapi.SchemeGroupVersion.String(), Kind: "Gateway"},
+		ObjectMeta: v1.ObjectMeta{Name: g.Name, Namespace: g.Namespace},
+		Spec: gatewayapi.GatewaySpec{
+			GatewayClassName: g.ClassName,
+			Listeners: []gatewayapi.Listener{{
+				Port:     gatewayapi.PortNumber(g.Port),
+				Protocol: gatewayapi.HTTPProtocolType,
+				Routes: gatewayapi.RouteBindingSelector{
+					Kind:       "HTTPRoute",
+					Namespaces: &gatewayapi.RouteNamespaces{From: gatewayapi.RouteSelectAll},
+				},
+			}},
+		},
+	}
+}
+
+// gateway returns the Gateway of the converted routes.
+func (c *converter) gateway() *Gateway {
+	if c.opts.Gateway != nil {
+		return c.opts.Gateway
+	}
+
+	return DefaultGateway()
+}
+
+// gatewayFragment returns the YAML document of the Gateway of the converted routes, empty when nothing was converted to the Gateway API.
+func (c *converter) gatewayFragment() (string, error) {
+	if c.opts.OutputKind != OutputKindGateway || c.gatewayRoutes == 0 {
+		return "", nil
+	}
+
+	return encodeGatewayObject(c.gateway().object())
+}
+
+// matcherExp matches the matchers of the routes created by the conversion.
+var matcherExp = regexp.MustCompile("^(Host|HostRegexp|Path|PathPrefix)\\(`([^`]*)`\\)$")
+
+// toGatewayAPI replaces the IngressRoute of the conversion of an Ingress by HTTPRoutes, one per host.
+// The headers middlewares only setting the request headers are replaced by RequestHeaderModifier filters,
+// the other middlewares are kept and referenced by ExtensionRef filters.
+// It returns the settings which cannot be converted.
+func toGatewayAPI(ingress *networking.Ingress, objects []runtime.Object, gateway *Gateway) ([]runtime.Object, []string) {
+	var notes []string
+
+	if len(ingress.Spec.TLS) > 0 {
+		notes = append(notes, "The TLS configuration must be set on the listeners of the Gateway.")
+	}
+
+	headerFilters := map[string]*gatewayapi.HTTPRequestHeaderFilter{}
+	for _, object := range objects {
+		if middleware, ok := object.(*v1alpha1.Middleware); ok {
+			if filter := requestHeaderFilter(middleware); filter != nil {
+				headerFilters[middleware.Name] = filter
+			}
+		}
+	}
+
+	var converted []runtime.Object
+	for _, object := range objects {
+		switch obj := object.(type) {
+		case *v1alpha1.IngressRoute:
+			routes, routeNotes := toHTTPRoutes(obj, headerFilters, gateway)
+			notes = append(notes, routeNotes...)
+
+			for _, route := range routes {
+				converted = append(converted, route)
+			}
+		case *v1alpha1.Middleware:
+			if _, ok := headerFilters[obj.Name]; !ok {
+				converted = append(converted, obj)
+			}
+		case *v1alpha1.TLSOption:
+			// The TLS options are set on the listeners of the Gateway.
+		default:
+			converted = append(converted, object)
+		}
+	}
+
+	return converted, notes
+}
+
+func toHTTPRoutes(ingressRoute *v1alpha1.IngressRoute, headerFilters map[string]*gatewayapi.HTTPRequestHeaderFilter, gateway *Gateway) ([]*gatewayapi.HTTPRoute, []string) {
+	var notes []string
+
+	if len(ingressRoute.Spec.EntryPoints) > 0 {
+		notes = append(notes, fmt.Sprintf("The entry points %s are replaced by the listener of the Gateway %s/%s.",
+			strings.Join(ingressRoute.Spec.EntryPoints, ", "), gateway.Namespace, gateway.Name))
+	}
+
+	var hosts []string
+	rules := map[string][]gatewayapi.HTTPRouteRule{}
+
+	for _, route := range ingressRoute.Spec.Routes {
+		host, match, err := parseMatch(route.Match)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("The route %q is not converted: %v.", route.Match, err))
+			continue
+		}
+
+		if route.Priority != 0 {
+			notes = append(notes, fmt.Sprintf("The priority %d of the route %q is not supported by the HTTPRoutes.", route.Priority, route.Match))
+		}
+
+		rule := gatewayapi.HTTPRouteRule{Matches: []gatewayapi.HTTPRouteMatch{match}}
+
+		for _, ref := range route.Middlewares {
+			if strings.Contains(ref.Name, "@") {
+				notes = append(notes, fmt.Sprintf("The middleware %s of the route %q is a middleware of another provider.", ref.Name, route.Match))
+				continue
+			}
+
+			if ref.Namespace != "" && ref.Namespace != ingressRoute.Namespace {
+				notes = append(notes, fmt.Sprintf("The middleware %s/%s of the route %q is in another namespace.", ref.Namespace, ref.Name, route.Match))
+				continue
+			}
+
+			if filter, ok := headerFilters[ref.Name]; ok {
+				rule.Filters = append(rule.Filters, gatewayapi.HTTPRouteFilter{
+					Type:                  gatewayapi.HTTPRouteFilterRequestHeaderModifier,
+					RequestHeaderModifier: filter,
+				})
+				continue
+			}
+
+			rule.Filters = append(rule.Filters, gatewayapi.HTTPRouteFilter{
+				Type:         gatewayapi.HTTPRouteFilterExtensionRef,
+				ExtensionRef: &gatewayapi.LocalObjectReference{Group: v1alpha1.GroupName, Kind: "Middleware", Name: ref.Name},
+			})
+		}
+
+		for _, service := range route.Services {
+			forwardTo, err := toForwardTo(service, ingressRoute.Namespace)
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("The service %s of the route %q is not converted: %v.", service.Name, route.Match, err))
+				continue
+			}
+
+			rule.ForwardTo = append(rule.ForwardTo, forwardTo)
+		}
+
+		if _, ok := rules[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		rules[host] = append(rules[host], rule)
+	}
+
+	var routes []*gatewayapi.HTTPRoute
+	for i, host := range hosts {
+		name := ingressRoute.Name
+		if len(hosts) > 1 {
+			name = indexedName(ingressRoute.Name, i)
+		}
+
+		route := &gatewayapi.HTTPRoute{
+			TypeMeta: v1.TypeMeta{APIVersion: gatewayapi.SchemeGroupVersion.String(), Kind: "HTTPRoute"},
+			ObjectMeta: v1.ObjectMeta{
+				Name:        name,
+				Namespace:   ingressRoute.Namespace,
+				Annotations: ingressRoute.Annotations,
+			},
+			Spec: gatewayapi.HTTPRouteSpec{
+				Gateways: gatewayapi.RouteGateways{
+					Allow:       gatewayapi.GatewayAllowFromList,
+					GatewayRefs: []gatewayapi.GatewayReference{{Name: gateway.Name, Namespace: gateway.Namespace}},
+				},
+				Rules: rules[host],
+			},
+		}
+
+		if host != "" {
+			route.Spec.Hostnames = []gatewayapi.Hostname{gatewayapi.Hostname(host)}
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes, notes
+}
+
+// parseMatch returns the host and the path match of a rule created by the conversion (ex: Host(`example.com`) && PathPrefix(`/api`)).
+func parseMatch(rule string) (string, gatewayapi.HTTPRouteMatch, error) {
+	var host string
+	match := gatewayapi.HTTPRouteMatch{Path: gatewayapi.HTTPPathMatch{Type: gatewayapi.PathMatchPrefix, Value: "/"}}
+
+	for _, matcher := range strings.Split(rule, " && ") {
+		parts := matcherExp.FindStringSubmatch(strings.TrimSpace(matcher))
+		if parts == nil {
+			return "", match, fmt.Errorf("unsupported matcher %s", matcher)
+		}
+
+		switch parts[1] {
+		case "Host":
+			host = parts[2]
+		case "HostRegexp":
+			if parts[2] != anyHostRegexp {
+				host = strings.Replace(parts[2], wildcardHostRegexp, "*", 1)
+			}
+		case ruleTypePath:
+			match.Path = gatewayapi.HTTPPathMatch{Type: gatewayapi.PathMatchExact, Value: parts[2]}
+		case ruleTypePathPrefix:
+			match.Path = gatewayapi.HTTPPathMatch{Type: gatewayapi.PathMatchPrefix, Value: parts[2]}
+		}
+	}
+
+	return host, match, nil
+}
+
+func toForwardTo(service v1alpha1.Service, namespace string) (gatewayapi.HTTPRouteForwardTo, error) {
+	switch {
+	case service.Kind != "" && service.Kind != "Service":
+		return gatewayapi.HTTPRouteForwardTo{}, fmt.Errorf("the kind %s is not supported", service.Kind)
+	case service.Namespace != "" && service.Namespace != namespace:
+		return gatewayapi.HTTPRouteForwardTo{}, errors.New("the service is in another namespace")
+	case service.Port == 0:
+		return gatewayapi.HTTPRouteForwardTo{}, errors.New("the named ports are not supported")
+	case service.Scheme != "" && service.Scheme != "http":
+		return gatewayapi.HTTPRouteForwardTo{}, fmt.Errorf("the scheme %s is not supported", service.Scheme)
+	}
+
+	name := service.Name
+	forwardTo := gatewayapi.HTTPRouteForwardTo{ServiceName: &name, Port: gatewayapi.PortNumber(service.Port)}
+	if service.Weight != nil {
+		forwardTo.Weight = int32(*service.Weight)
+	}
+
+	return forwardTo, nil
+}
+
+// requestHeaderFilter returns the filter of a headers middleware only setting the request headers, nil for the other middlewares.
+// The headers with an empty value are removed, as with the middleware.
+func requestHeaderFilter(middleware *v1alpha1.Middleware) *gatewayapi.HTTPRequestHeaderFilter {
+	headers := middleware.Spec.Headers
+	if headers == nil || len(headers.CustomRequestHeaders) == 0 {
+		return nil
+	}
+
+	spec := middleware.Spec
+	spec.Headers = nil
+
+	if !reflect.DeepEqual(spec, v1alpha1.MiddlewareSpec{}) ||
+		len(headers.CustomResponseHeaders) > 0 || headers.HasCorsHeadersDefined() || headers.HasSecureHeadersDefined() {
+		return nil
+	}
+
+	filter := &gatewayapi.HTTPRequestHeaderFilter{}
+	for name, value := range headers.CustomRequestHeaders {
+		if value == "" {
+			filter.Remove = append(filter.Remove, name)
+			continue
+		}
+
+		if filter.Add == nil {
+			filter.Add = map[string]string{}
+		}
+		filter.Add[name] = value
+	}
+
+	sort.Strings(filter.Remove)
+
+	return filter
+}
+
+// isGatewayObject tells whether an object is a Gateway API object.
+func isGatewayObject(object runtime.Object) bool {
+	switch object.(type) {
+	case *gatewayapi.HTTPRoute, *gatewayapi.Gateway:
+		return true
+	default:
+		return false
+	}
+}
+
+// encodeGatewayObject encodes a Gateway API object without its status, and without the null and empty fields of the API types.
+func encodeGatewayObject(object runtime.Object) (string, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return "", err
+	}
+
+	delete(content, "status")
+	pruneNulls(content)
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// pruneNulls removes the null values, and the empty objects, of an unstructured object.
+func pruneNulls(content map[string]interface{}) {
+	for key, value := range content {
+		switch v := value.(type) {
+		case nil:
+			delete(content, key)
+		case map[string]interface{}:
+			pruneNulls(v)
+			if len(v) == 0 {
+				delete(content, key)
+			}
+		case []interface{}:
+			for _, item := range v {
+				if m, ok := item.(map[string]interface{}); ok {
+					pruneNulls(m)
+				}
+			}
+		}
+	}
+}
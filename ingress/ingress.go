@@ -2,20 +2,29 @@
 package ingress
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
-	"unicode"
+	"text/template"
+	"time"
 
+	"github.com/traefik/traefik-migration-tool/gitops"
+	"github.com/traefik/traefik-migration-tool/metrics"
+	"github.com/traefik/traefik-migration-tool/v2tov3"
 	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
 	extensions "k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
 	networking "k8s.io/api/networking/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	gatewayapi "sigs.k8s.io/service-apis/apis/v1alpha1"
 	"sigs.k8s.io/yaml"
 )
 
@@ -23,6 +32,16 @@ const separator = "---"
 
 const groupSuffix = "/v1alpha1"
 
+// metricsKind is the kind of conversion of the metrics.
+const metricsKind = "ingress"
+
+const (
+	// defaultBackendMatch is the rule of the route of the default backend of an Ingress, matching all the requests.
+	defaultBackendMatch = "PathPrefix(`/`)"
+	// defaultBackendPriority is the priority of the route of the default backend, the lowest priority of the routes.
+	defaultBackendPriority = 1
+)
+
 const (
 	ruleTypePath             = "Path"
 	ruleTypePathPrefix       = "PathPrefix"
@@ -33,119 +52,945 @@ const (
 	ruleTypeReplacePathRegex = "ReplacePathRegex"
 )
 
-// Convert converts all ingress in a src into a dstDir.
-func Convert(src, dstDir string) error {
-	info, err := os.Stat(src)
-	if err != nil {
-		return err
+// Options configures the conversion of the ingresses.
+type Options struct {
+	// Audit records, on each converted object, the transformations applied to the Ingress.
+	Audit bool
+	// Explain adds above each converted field a comment with the Traefik v1 setting which produced it.
+	Explain bool
+	// AnnotatedOnly converts only the ingresses with Traefik v1 annotations, the others are kept.
+	AnnotatedOnly bool
+	// Notes is where the annotations to convert manually are reported, default to the standard output.
+	Notes io.Writer
+	// Checkpoint is the file recording the converted files, removed when the conversion completes.
+	Checkpoint string
+	// Resume skips the files recorded by an existing checkpoint, instead of failing.
+	Resume bool
+	// NameTemplate, when set, names the output file of each object (see ParseNameTemplate), instead of the name of its source file.
+	// The objects named with the same file are written in the same file.
+	NameTemplate *template.Template
+	// MiddlewareNameTemplate, when set, names the converted middlewares (see ParseMiddlewareNameTemplate).
+	MiddlewareNameTemplate *template.Template
+	// DedupMiddlewares writes a single middleware per spec and namespace: the routes of the ingresses converted after reference it.
+	DedupMiddlewares bool
+	// MiddlewareCatalog are the middlewares already deployed (see LoadMiddlewareCatalog):
+	// the routes reference them instead of the converted middlewares with the same spec, in the same namespace.
+	MiddlewareCatalog []*v1alpha1.Middleware
+	// KeepMetadata keeps the fields set by the API server (status, creationTimestamp, resourceVersion, uid, managedFields, ...)
+	// in the converted objects and in the ingresses kept in the output, which are removed by default.
+	KeepMetadata bool
+	// MinimalDiff keeps the text of the documents of the source: the changes of the objects kept in the output are patched in their text,
+	// and the converted objects are written after the other documents, instead of in place of their Ingress.
+	MinimalDiff bool
+	// WrapLists writes the objects converted from the ingresses of a List, and the other items of the List, in a List.
+	WrapLists bool
+	// MirrorTree writes the files of a source directory at the same paths, relative to the output directory,
+	// instead of under a directory named after the source directory.
+	MirrorTree bool
+	// Include and Exclude are glob patterns of the files of the source directory to convert (see fileFilter),
+	// ** matches any number of directories (ex: **/charts/**). The other files are not converted nor written.
+	Include []string
+	Exclude []string
+	// ManifestFormat is the format of the written manifests, ManifestFormatYAML or ManifestFormatJSON.
+	// By default, the manifests converted from a JSON source are written as JSON, the others as YAML.
+	ManifestFormat string
+	// Marker, when set, is stamped in the migration.traefik.io/converted annotation of the converted objects, and of the ingresses kept next to their conversion
+	// (ex: the version of the tool). Whatever the Marker, the ingresses stamped by a previous conversion are kept as is, and the routes reference the stamped middlewares
+	// of the source instead of the converted middlewares with the same spec, which allows to convert again a partially migrated source.
+	Marker string
+	// Progress, when set, is called with the progress events of the conversion.
+	Progress func(ProgressEvent)
+	// DualAPIVersion adds, after the converted objects, their conversion to the traefik.io API group of Traefik v3,
+	// for the clusters running Traefik v2 and Traefik v3 side by side.
+	DualAPIVersion bool
+	// Canary, when set, keeps the ingresses next to their conversion (see Canary.Validate).
+	Canary *Canary
+	// OutputKind is the kind of the resources the ingresses are converted to, default to OutputKindIngressRoute.
+	OutputKind string
+	// Gateway is the Gateway of the routes converted with OutputKindGateway, default to DefaultGateway.
+	Gateway *Gateway
+	// SyncWaves sets on the converted objects the Argo CD sync wave ordering the middlewares before the routes (see gitops.SyncWave).
+	SyncWaves bool
+	// Plugins convert the annotations unknown to the converter.
+	Plugins []Plugin
+	// Review, when set, is called with the conversion of each Ingress before it is written, to accept, skip, or rename it.
+	Review func(IngressReport) (ReviewDecision, error)
+	// ServicePatches writes, in the service-patches.yml file of the output directory, the strategic merge patches
+	// adding to the backend services the Traefik v2 annotations of the Ingress annotations moved to the Service (protocol, preserve-host, affinity).
+	ServicePatches bool
+	// EntryPoints maps the names of the Traefik v1 entry points to the names of the Traefik v2 entry points,
+	// the entry points of the IngressRoutes and of the redirections are renamed.
+	EntryPoints map[string]string
+	// TLSOptions holds the TLS options of the Traefik v1 entry points, by entry point name,
+	// converted to a TLSOption referenced by the IngressRoutes of the ingresses with a TLS section.
+	TLSOptions map[string]v1alpha1.TLSOptionSpec
+	// IngressClass, when set, is the name of the IngressClass of Traefik written with the conversion (see ingressClassFragment):
+	// the ingresses kept in the output with this kubernetes.io/ingress.class annotation reference it with spec.ingressClassName instead.
+	IngressClass string
+	// ResolveNamedPorts resolves the named ports of the backends with the Service manifests of the input,
+	// the ports which are not resolved are referenced by name.
+	ResolveNamedPorts bool
+	// AnnotationPrefix is a custom prefix of the Traefik v1 annotations (ex: example.com/ for example.com/rule-type),
+	// read as the ingress.kubernetes.io/ annotations.
+	AnnotationPrefix string
+	// Hostless is the conversion of the rules without host, default to HostlessCatchAll.
+	Hostless string
+	// SSLRedirect is the conversion of the ssl-redirect annotations, default to SSLRedirectHeaders.
+	SSLRedirect string
+	// SSLRedirectRef, when set, is the middleware referenced by the routes of the ingresses with ssl-redirect annotations, instead of their conversion:
+	// a middleware of another provider (ex: https-redirect@file), <namespace>/<name>, or the name of a middleware of the namespace of the Ingress.
+	SSLRedirectRef string
+
+	// services holds the services of the manifests of the input.
+	services inputServices
+}
+
+// Output kinds.
+const (
+	// OutputKindIngressRoute converts each Ingress to an IngressRoute of the Traefik CRD provider, with the middlewares of its annotations:
+	// the Ingress is not kept.
+	OutputKindIngressRoute = "ingressroute"
+)
+
+// OutputKinds are the supported output kinds.
+var OutputKinds = []string{OutputKindIngressRoute, OutputKindGateway}
+
+// Conversions of the rules without host.
+const (
+	// HostlessCatchAll converts the rules without host into routes matching their path on all the hosts, PathPrefix(`/`) without path.
+	HostlessCatchAll = "catchall"
+	// HostlessHostRegexp converts the rules without host into routes matching all the hosts with HostRegexp, and their path.
+	HostlessHostRegexp = "hostregexp"
+	// HostlessSkip skips the rules without host, they are reported.
+	HostlessSkip = "skip"
+)
+
+// HostlessModes are the supported conversions of the rules without host.
+var HostlessModes = []string{HostlessCatchAll, HostlessHostRegexp, HostlessSkip}
+
+// Conversions of the ssl-redirect annotations.
+const (
+	// SSLRedirectHeaders converts the ssl-redirect annotations into the SSL redirect options of the headers middleware, deprecated in Traefik v2.
+	SSLRedirectHeaders = "headers"
+	// SSLRedirectScheme converts the ssl-redirect annotations into a RedirectScheme middleware to https, in the namespace of the Ingress.
+	SSLRedirectScheme = "redirectscheme"
+)
+
+// SSLRedirectModes are the supported conversions of the ssl-redirect annotations.
+var SSLRedirectModes = []string{SSLRedirectHeaders, SSLRedirectScheme}
+
+func validateOutputKind(opts Options) error {
+	if opts.OutputKind != "" && !contains(OutputKinds, opts.OutputKind) {
+		return fmt.Errorf("unsupported output kind %q: %s", opts.OutputKind, strings.Join(OutputKinds, ", "))
+	}
+
+	if opts.Hostless != "" && !contains(HostlessModes, opts.Hostless) {
+		return fmt.Errorf("unsupported hostless conversion %q: %s", opts.Hostless, strings.Join(HostlessModes, ", "))
+	}
+
+	if opts.SSLRedirect != "" && !contains(SSLRedirectModes, opts.SSLRedirect) {
+		return fmt.Errorf("unsupported ssl-redirect conversion %q: %s", opts.SSLRedirect, strings.Join(SSLRedirectModes, ", "))
+	}
+
+	if opts.ManifestFormat != "" && !contains(ManifestFormats, opts.ManifestFormat) {
+		return fmt.Errorf("unsupported manifest format %q: %s", opts.ManifestFormat, strings.Join(ManifestFormats, ", "))
+	}
+
+	if opts.OutputKind != OutputKindGateway {
+		return nil
+	}
+
+	if opts.Canary != nil || opts.DualAPIVersion {
+		return errors.New("the canary and dual API version conversions require the ingressroute output kind")
+	}
+
+	if opts.DedupMiddlewares {
+		return errors.New("the middleware deduplication requires the ingressroute output kind")
+	}
+
+	if opts.Gateway != nil {
+		return opts.Gateway.Validate()
+	}
+
+	return nil
+}
+
+// ReviewDecision is the decision of the review of the conversion of an Ingress.
+type ReviewDecision struct {
+	// Skip keeps the Ingress instead of its conversion.
+	Skip bool
+	// Name, when set, is the name of the IngressRoute, instead of the name of the Ingress.
+	Name string
+}
+
+// ConvertStream converts the ingresses of the manifests read from r, and writes all the manifests to w.
+func ConvertStream(r io.Reader, w io.Writer, opts Options) (*Report, error) {
+	c := &converter{opts: opts, report: &Report{}, middlewares: catalogMiddlewares(opts.MiddlewareCatalog)}
+
+	err := validateOutputKind(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ServicePatches {
+		return nil, errors.New("the service patches require an output directory")
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c.opts.services = inputServices{}
+	c.opts.services.add(content)
+
+	c.addConverted(convertedMiddlewares(content))
+
+	fragments, err := c.convertContent("-", content)
+	if err != nil {
+		return nil, err
+	}
+
+	gateway, err := c.gatewayFragment()
+	if err != nil {
+		return nil, err
+	}
+
+	if gateway != "" {
+		fragments = append(fragments, gateway)
+	}
+
+	if opts.IngressClass != "" {
+		ingressClass, err := ingressClassFragment(opts.IngressClass)
+		if err != nil {
+			return nil, err
+		}
+
+		fragments = append(fragments, ingressClass)
+	}
+
+	data, err := encodeFragments(fragments, c.manifestFormat(content))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.result()
+}
+
+type converter struct {
+	opts       Options
+	report     *Report
+	checkpoint *checkpoint
+
+	// gatewayRoutes is the number of objects converted with OutputKindGateway, the Gateway is written when there are some.
+	gatewayRoutes int
+
+	// outputDir is the root of the files named with the name template.
+	outputDir string
+	// named holds the fragments of the files named with the name template, by path relative to outputDir.
+	named map[string][]string
+
+	// servicePatches holds the annotations to add to the services, by <namespace>/<name>.
+	servicePatches map[string]map[string]string
+
+	// middlewares holds the names of the middlewares of the catalog, and of the middlewares written with DedupMiddlewares, by <namespace>/<spec key>.
+	middlewares map[string]string
+	// written holds the spec keys of the written middlewares, by <namespace>/<name>.
+	written middlewareNames
+	// filter selects the files of the source directory.
+	filter *fileFilter
+	// failed holds the errors of the ingresses kept because their conversion failed.
+	failed []string
+	// notes holds the notes of the Ingress being converted, reported with its conversion.
+	notes []string
+}
+
+// FailedError is returned with the report when the conversion of some ingresses failed:
+// these ingresses are kept unchanged in the output, and the other ingresses are converted.
+type FailedError struct {
+	// Ingresses are the errors of the ingresses, prefixed by their <namespace>/<name>.
+	Ingresses []string
+}
+
+func (e *FailedError) Error() string {
+	return fmt.Sprintf("the conversion of %d ingresses failed, they are kept unchanged: %s", len(e.Ingresses), strings.Join(e.Ingresses, "; "))
+}
+
+// result returns the report of the conversion, and the FailedError of the ingresses not converted.
+func (c *converter) result() (*Report, error) {
+	if len(c.failed) > 0 {
+		return c.report, &FailedError{Ingresses: c.failed}
+	}
+
+	return c.report, nil
+}
+
+// Convert converts all ingress in a src into a dstDir.
+// The returned report describes the conversion of each Ingress, except the ones of the files skipped when resuming from a checkpoint.
+func Convert(src, dstDir string, opts Options) (*Report, error) {
+	c := &converter{
+		opts: opts, report: &Report{}, outputDir: dstDir, named: map[string][]string{}, servicePatches: map[string]map[string]string{},
+		middlewares: catalogMiddlewares(opts.MiddlewareCatalog),
+	}
+
+	err := validateOutputKind(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Checkpoint != "" && opts.NameTemplate != nil {
+		// The files named with the template are written at the end of the conversion.
+		return nil, errors.New("a checkpoint cannot be used with a name template")
+	}
+
+	if opts.Checkpoint != "" && opts.ServicePatches {
+		// The service patches are written at the end of the conversion.
+		return nil, errors.New("a checkpoint cannot be used with the service patches")
+	}
+
+	c.filter, err = newFileFilter(src, opts.Include, opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	c.opts.services, err = loadServices(src, c.filter)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := loadConvertedMiddlewares(src, c.filter)
+	if err != nil {
+		return nil, err
+	}
+	c.addConverted(converted)
+
+	if opts.Checkpoint != "" {
+		cp, err := loadCheckpoint(opts.Checkpoint, src, dstDir, opts.Resume)
+		if err != nil {
+			return nil, err
+		}
+		c.checkpoint = cp
+	}
+
+	err = c.convertRoot(src, dstDir)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.writeNamed()
+	if err != nil {
+		return nil, err
+	}
+
+	gateway, err := c.gatewayFragment()
+	if err != nil {
+		return nil, err
+	}
+
+	if gateway != "" {
+		err = c.writeManifest(filepath.Join(dstDir, gatewayFile), gateway)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.IngressClass != "" {
+		ingressClass, err := ingressClassFragment(opts.IngressClass)
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.writeManifest(filepath.Join(dstDir, ingressClassFile), ingressClass)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.ServicePatches && len(c.servicePatches) > 0 {
+		patches, err := c.servicePatchesFragment()
+		if err != nil {
+			return nil, err
+		}
+
+		err = os.WriteFile(filepath.Join(dstDir, servicePatchesFile), []byte(patches), 0o666)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.checkpoint != nil {
+		err = c.checkpoint.remove()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c.result()
+}
+
+// convertRoot converts the src of the conversion, a file or a directory, into dstDir.
+// The files of a directory are converted into a sub-directory of dstDir of the same name, or into dstDir with MirrorTree.
+func (c *converter) convertRoot(src, dstDir string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if c.opts.MirrorTree && info.IsDir() {
+		return c.convertDir(src, dstDir)
+	}
+
+	return c.convert(src, dstDir)
+}
+
+func (c *converter) convert(src, dstDir string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if !c.filter.match(src, info.IsDir()) {
+		return nil
+	}
+
+	if !info.IsDir() {
+		filename := info.Name()
+		srcPath := filepath.Dir(src)
+		return c.convertFile(srcPath, dstDir, filename)
+	}
+
+	return c.convertDir(src, filepath.Join(dstDir, info.Name()))
+}
+
+// convertDir converts the files of a src directory into a dstDir directory, the sub-directories are converted into sub-directories of the same name.
+func (c *converter) convertDir(src, dstDir string) error {
+	infos, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		err := c.convert(filepath.Join(src, info.Name()), dstDir)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *converter) convertFile(srcDir, dstDir, filename string) error {
+	source := filepath.Join(srcDir, filename)
+	if c.checkpoint != nil && c.checkpoint.isDone(source) {
+		return nil
+	}
+
+	c.progress(ProgressEvent{Type: EventFileStarted, File: source})
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+
+	fragments, err := c.convertContent(source, content)
+	if err != nil {
+		return err
+	}
+
+	err = c.writeFragments(source, dstDir, filename, c.manifestFormat(content), fragments)
+	if err != nil {
+		return err
+	}
+
+	c.progress(ProgressEvent{Type: EventFileFinished, File: source, Objects: len(fragments)})
+
+	if c.checkpoint != nil {
+		return c.checkpoint.markDone(source)
+	}
+
+	return nil
+}
+
+// writeFragments writes the converted objects of a source file, in the dstDir directory in a format, or in the files named with the name template.
+func (c *converter) writeFragments(source, dstDir, filename, format string, fragments []string) error {
+	if c.opts.NameTemplate != nil {
+		for _, fragment := range fragments {
+			name, err := outputName(c.opts.NameTemplate, source, fragment)
+			if err != nil {
+				return err
+			}
+
+			c.named[name] = append(c.named[name], fragment)
+		}
+
+		return nil
+	}
+
+	err := os.MkdirAll(dstDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	data, err := encodeFragments(fragments, format)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dstDir, manifestFilename(filename, format)), data, 0666)
+}
+
+// writeNamed writes the files named with the name template, in the ManifestFormat, or in the format of their extension.
+func (c *converter) writeNamed() error {
+	for name, fragments := range c.named {
+		filename := filepath.Join(c.outputDir, name)
+
+		err := os.MkdirAll(filepath.Dir(filename), 0755)
+		if err != nil {
+			return err
+		}
+
+		format := c.opts.ManifestFormat
+		if format == "" && strings.EqualFold(filepath.Ext(name), ".json") {
+			format = ManifestFormatJSON
+		}
+
+		data, err := encodeFragments(fragments, format)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filename, data, 0666)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeManifest writes the file of an object added to the output directory (ex: the Gateway), in the ManifestFormat.
+func (c *converter) writeManifest(filename, fragment string) error {
+	data, err := encodeFragments([]string{fragment}, c.opts.ManifestFormat)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(manifestFilename(filename, c.opts.ManifestFormat), data, 0o666)
+}
+
+func joinFragments(fragments []string) []byte {
+	return []byte(strings.Join(fragments, separator+"\n"))
+}
+
+// convertContent converts the ingresses of the manifests of a source, the other objects are kept.
+// It returns the YAML documents of the objects.
+func (c *converter) convertContent(source string, content []byte) ([]string, error) {
+	defer metrics.ObserveConversion(metricsKind, time.Now())
+
+	documents, err := expandContent(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var fragments []string
+	// converted holds the converted objects written after the other documents, with MinimalDiff.
+	var converted []string
+	// starts holds the index of the first fragment of each document.
+	starts := make([]int, 0, len(documents))
+	for _, document := range documents {
+		starts = append(starts, len(fragments))
+
+		part := document.content
+		if document.list {
+			fragments = append(fragments, part)
+			continue
+		}
+
+		object, err := parseYaml([]byte(part))
+		if err != nil {
+			log.Printf("err while reading yaml: %v", err)
+			metrics.ObserveObject(metricsKind, metrics.StatusFailed)
+			fragments = append(fragments, part)
+			continue
+		}
+
+		var ingress *networking.Ingress
+		switch obj := object.(type) {
+		case *extensions.Ingress:
+			ingress, err = extensionsToNetworking(obj)
+			if err != nil {
+				metrics.ObserveObject(metricsKind, metrics.StatusFailed)
+				return nil, err
+			}
+		case *networking.Ingress:
+			ingress = obj
+		case *networkingv1.Ingress:
+			ingress = networkingV1ToV1beta1(obj)
+		default:
+			log.Printf("the object is skipped because is not an Ingress: %T", object)
+			metrics.ObserveObject(metricsKind, metrics.StatusSkipped)
+			fragments = append(fragments, c.keptFragment(part))
+			continue
+		}
+
+		if isConverted(ingress) {
+			log.Printf("the ingress %s/%s is kept because it is stamped by a previous conversion", ingress.GetNamespace(), ingress.GetName())
+			metrics.ObserveObject(metricsKind, metrics.StatusSkipped)
+			fragments = append(fragments, c.keptFragment(part))
+			continue
+		}
+
+		setAnnotationPrefix(ingress, c.opts.AnnotationPrefix)
+
+		if c.opts.AnnotatedOnly && !hasV1Annotations(ingress) {
+			metrics.ObserveObject(metricsKind, metrics.StatusSkipped)
+			fragments = append(fragments, c.keptFragment(part))
+			continue
+		}
+
+		c.notes = nil
+		c.logUnsupported(source, ingress)
+		c.logNotes(source, ingress, rateLimitNotes(ingress))
+		c.logNotes(source, ingress, authNotes(ingress))
+		c.logNotes(source, ingress, priorityNotes(ingress))
+		c.logNotes(source, ingress, redirectEntryPointNotes(ingress, c.opts.EntryPoints))
+		c.logNotes(source, ingress, tlsNotes(ingress, c.opts.TLSOptions))
+		c.logNotes(source, ingress, resourceBackendNotes(ingress))
+		c.logNotes(source, ingress, namedPortNotes(ingress, c.opts))
+		c.logNotes(source, ingress, errorPagesNotes(ingress, c.opts.services))
+		c.logNotes(source, ingress, externalNameNotes(ingress, c.opts.services))
+		c.logNotes(source, ingress, hostlessNotes(ingress, c.opts.Hostless))
+		c.logNotes(source, ingress, sslRedirectNotes(ingress, c.opts))
+
+		objects, err := convertIngress(ingress, c.opts)
+		if err != nil {
+			log.Printf("%s: the ingress %s/%s is kept because its conversion failed: %v", source, ingress.GetNamespace(), ingress.GetName(), err)
+			metrics.ObserveObject(metricsKind, metrics.StatusFailed)
+			c.failed = append(c.failed, fmt.Sprintf("%s/%s: %v", ingress.GetNamespace(), ingress.GetName(), err))
+			fragments = append(fragments, c.keptFragment(part))
+			continue
+		}
+
+		objects, err = applyPlugins(c.opts.Plugins, ingress, objects)
+		if err != nil {
+			return nil, err
+		}
+
+		objects = applyCanary(c.opts.Canary, ingress, objects)
+
+		err = renameMiddlewares(c.opts.MiddlewareNameTemplate, ingress.GetName(), objects)
+		if err != nil {
+			return nil, err
+		}
+
+		var middlewares map[string]string
+		if c.opts.DedupMiddlewares || len(c.middlewares) > 0 {
+			objects, middlewares = dedupMiddlewares(c.middlewares, objects, c.opts.DedupMiddlewares)
+		}
+
+		collisionNotes, written := resolveCollisions(c.written, objects)
+		c.logNotes(source, ingress, collisionNotes)
+
+		if c.opts.OutputKind == OutputKindGateway {
+			var notes []string
+			objects, notes = toGatewayAPI(ingress, objects, c.gateway())
+			c.logNotes(source, ingress, notes)
+			c.gatewayRoutes += len(objects)
+		}
+
+		comments := documentComments(part)
+		ymls, err := c.encodeObjects(ingress, comments, objects)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.opts.Review != nil {
+			decision, err := c.opts.Review(newIngressReport(source, ingress, objects, part, strings.Join(ymls, separator+"\n"), c.notes))
+			if err != nil {
+				return nil, err
+			}
+
+			if decision.Skip {
+				metrics.ObserveObject(metricsKind, metrics.StatusSkipped)
+				fragments = append(fragments, c.keptFragment(part))
+				continue
+			}
+
+			if decision.Name != "" {
+				renameIngressRoutes(objects, decision.Name)
+
+				ymls, err = c.encodeObjects(ingress, comments, objects)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if len(objects) == 0 {
+			metrics.ObserveObject(metricsKind, metrics.StatusFailed)
+		} else {
+			metrics.ObserveObject(metricsKind, metrics.StatusConverted)
+		}
+
+		if c.opts.Canary != nil {
+			fragments = append(fragments, c.markedFragment(c.keptFragment(part)))
+		}
+
+		if c.opts.MinimalDiff {
+			converted = append(converted, ymls...)
+		} else {
+			fragments = append(fragments, ymls...)
+		}
+
+		// The middlewares of the ingresses skipped by the review are not written, they are not shared.
+		for key, name := range middlewares {
+			c.middlewares[key] = name
+		}
+
+		if c.written == nil {
+			c.written = middlewareNames{}
+		}
+		for key, spec := range written {
+			c.written[key] = spec
+		}
+
+		if c.opts.ServicePatches {
+			c.logNotes(source, ingress, c.addServicePatches(ingress))
+		}
+
+		c.report.addIngress(source, ingress, objects, part, strings.Join(ymls, separator+"\n"), c.notes)
+
+		c.progress(ProgressEvent{
+			Type:      EventIngressConverted,
+			File:      source,
+			Namespace: ingress.GetNamespace(),
+			Name:      ingress.GetName(),
+			Objects:   len(objects),
+		})
 	}
 
-	if !info.IsDir() {
-		filename := info.Name()
-		srcPath := filepath.Dir(src)
-		return convertFile(srcPath, dstDir, filename)
+	if c.opts.WrapLists {
+		fragments, err = wrapLists(documents, starts, fragments)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	dir := info.Name()
-	infos, err := os.ReadDir(src)
-	if err != nil {
-		return err
+	return append(fragments, converted...), nil
+}
+
+// keptFragment returns the YAML of an object kept in the output, the server fields of the ingresses are removed without KeepMetadata,
+// the Ingress of the IngressClass option references the IngressClass with spec.ingressClassName, and the comments of the source are kept.
+// With MinimalDiff, the changes are patched in the source text when possible.
+func (c *converter) keptFragment(part string) string {
+	if c.opts.MinimalDiff {
+		if fragment, ok := c.patchedFragment(part); ok {
+			return fragment
+		}
 	}
 
-	for _, info := range infos {
-		newSrc := filepath.Join(src, info.Name())
-		newDst := filepath.Join(dstDir, dir)
-		err := Convert(newSrc, newDst)
+	fragment := part
+
+	if !c.opts.KeepMetadata {
+		cleaned, err := cleanMetadata(fragment, "Ingress")
 		if err != nil {
-			return err
+			log.Printf("The server fields of the object are not removed: %v", err)
+		} else {
+			fragment = cleaned
 		}
 	}
-	return nil
+
+	if c.opts.IngressClass != "" {
+		named, err := setIngressClassName(fragment, c.opts.IngressClass)
+		if err != nil {
+			log.Printf("The ingress class of the object is not replaced: %v", err)
+		} else {
+			fragment = named
+		}
+	}
+
+	return restoredFragment(part, fragment)
 }
 
-func convertFile(srcDir, dstDir, filename string) error {
-	content, err := expandFileContent(filepath.Join(srcDir, filename))
-	if err != nil {
-		return err
+// restoredFragment returns the YAML of an object kept in the output, with the comments of its source document when it is encoded again.
+func restoredFragment(part, fragment string) string {
+	if fragment == part {
+		return part
 	}
 
-	err = os.MkdirAll(dstDir, 0755)
+	restored, err := restoreComments(fragment, documentComments(part))
 	if err != nil {
-		return err
+		log.Printf("The comments of the object are not restored: %v", err)
+		return fragment
 	}
 
-	parts := strings.Split(string(content), separator)
-	var fragments []string
-	for _, part := range parts {
-		if part == "\n" || part == "" {
-			continue
+	return restored
+}
+
+// encodeObjects encodes the objects produced by the conversion of an Ingress, with the comments of its source document (see convertedComments).
+func (c *converter) encodeObjects(ingress *networking.Ingress, comments []fieldComment, objects []runtime.Object) ([]string, error) {
+	if err := validateObjectNames(ingress, objects); err != nil {
+		return nil, err
+	}
+
+	audit := auditIngress(ingress, objects, c.opts.Hostless)
+
+	if c.opts.Audit {
+		err := setAuditAnnotations(audit)
+		if err != nil {
+			return nil, err
 		}
+	}
+
+	if c.opts.SyncWaves {
+		setSyncWaves(objects)
+	}
+
+	if c.opts.Marker != "" {
+		setConvertedMarker(objects, c.opts.Marker)
+	}
 
-		unstruct, err := createUnstructured([]byte(part))
+	var ymls []string
+	for i, object := range objects {
+		yml, err := encodeObject(object)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		if unstruct.IsList() {
-			fragments = append(fragments, part)
-			continue
+		if !c.opts.KeepMetadata {
+			yml, err = cleanMetadata(yml, "")
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		object, err := parseYaml([]byte(part))
+		if ingressRoute, ok := object.(*v1alpha1.IngressRoute); ok {
+			yml, err = setPortNames(yml, portNames(ingress, ingressRoute))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		yml, err = restoreComments(yml, convertedComments(comments, audit[object], i == 0))
 		if err != nil {
-			log.Printf("err while reading yaml: %v", err)
-			fragments = append(fragments, part)
-			continue
+			return nil, err
 		}
 
-		var ingress *networking.Ingress
-		switch obj := object.(type) {
-		case *extensions.Ingress:
-			ingress, err = extensionsToNetworking(obj)
+		if c.opts.Explain {
+			yml, err = explain(yml, audit[object])
 			if err != nil {
-				return err
+				return nil, err
 			}
-		case *networking.Ingress:
-			ingress = obj
-		default:
-			log.Printf("the object is skipped because is not an Ingress: %T", object)
-			fragments = append(fragments, part)
-			continue
 		}
 
-		objects := convertIngress(ingress)
-		for _, object := range objects {
-			yml, err := encodeYaml(object, v1alpha1.GroupName+groupSuffix)
+		ymls = append(ymls, yml)
+	}
+
+	if c.opts.DualAPIVersion {
+		var v3 []string
+		for _, yml := range ymls {
+			converted, err := v2tov3.ConvertResources([]byte(yml))
 			if err != nil {
-				return err
+				return nil, err
 			}
-			fragments = append(fragments, yml)
+
+			v3 = append(v3, string(converted))
 		}
+
+		ymls = append(ymls, v3...)
 	}
 
-	return os.WriteFile(filepath.Join(dstDir, filename), []byte(strings.Join(fragments, separator+"\n")), 0666)
+	return ymls, nil
 }
 
-func expandFileContent(filePath string) ([]byte, error) {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, err
+// encodeObject encodes an object produced by the conversion.
+func encodeObject(object runtime.Object) (string, error) {
+	if isGatewayObject(object) {
+		return encodeGatewayObject(object)
 	}
 
-	parts := strings.Split(string(content), separator)
-	var fragments []string
-	for _, part := range parts {
-		if part == "\n" || part == "" {
+	return encodeYaml(object, v1alpha1.GroupName+groupSuffix)
+}
+
+// setSyncWaves sets the sync wave annotation of the objects produced by the conversion of an Ingress.
+func setSyncWaves(objects []runtime.Object) {
+	for _, object := range objects {
+		var kind string
+		switch object.(type) {
+		case *v1alpha1.Middleware:
+			kind = "Middleware"
+		case *v1alpha1.TraefikService:
+			kind = "TraefikService"
+		case *v1alpha1.TLSOption:
+			kind = "TLSOption"
+		case *v1alpha1.IngressRoute:
+			kind = "IngressRoute"
+		}
+
+		accessor, ok := object.(v1.Object)
+		if !ok || gitops.SyncWave(kind) == "" {
+			continue
+		}
+
+		annotations := accessor.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[gitops.AnnotationSyncWave] = gitops.SyncWave(kind)
+		accessor.SetAnnotations(annotations)
+	}
+}
+
+// renameIngressRoutes renames the IngressRoutes, or the HTTPRoutes, of the objects produced by the conversion of an Ingress.
+// The HTTPRoutes of the ingresses with several hosts are suffixed with their index.
+func renameIngressRoutes(objects []runtime.Object, name string) {
+	var httpRoutes []*gatewayapi.HTTPRoute
+	for _, object := range objects {
+		switch route := object.(type) {
+		case *v1alpha1.IngressRoute:
+			route.SetName(name)
+		case *gatewayapi.HTTPRoute:
+			httpRoutes = append(httpRoutes, route)
+		}
+	}
+
+	for i, route := range httpRoutes {
+		if len(httpRoutes) == 1 {
+			route.SetName(name)
 			continue
 		}
 
-		listObj, err := createUnstructured(content)
+		route.SetName(indexedName(name, i))
+	}
+}
+
+// document is a YAML document of the manifests.
+type document struct {
+	content string
+	// list tells whether the document is a list, the lists left by expandContent hold no ingress.
+	list bool
+	// source is the index, starting at 1, of the list the document is extracted from with its ingresses (0 for the other documents).
+	source int
+}
+
+// expandContent extracts the ingresses of the lists of the manifests.
+// Each document is decoded once, the documents returned tell whether they are lists, to not decode them again.
+func expandContent(content []byte) ([]document, error) {
+	parts := splitDocuments(content)
+
+	var documents []document
+	for i, part := range parts {
+		listObj, err := createUnstructured([]byte(part))
 		if err != nil {
 			return nil, err
 		}
 
 		if !listObj.IsList() {
-			fragments = append(fragments, part)
+			documents = append(documents, document{content: part})
 			continue
 		}
 
@@ -157,7 +1002,7 @@ func expandFileContent(filePath string) ([]byte, error) {
 		toKeep, toConvert := extractItems(items)
 
 		if len(items) == len(toKeep) {
-			fragments = append(fragments, part)
+			documents = append(documents, document{content: part, list: true})
 			continue
 		}
 
@@ -174,7 +1019,7 @@ func expandFileContent(filePath string) ([]byte, error) {
 				return nil, err
 			}
 
-			fragments = append(fragments, string(m))
+			documents = append(documents, document{content: string(m), list: true, source: i + 1})
 		}
 
 		for _, elt := range toConvert {
@@ -182,11 +1027,11 @@ func expandFileContent(filePath string) ([]byte, error) {
 			if err != nil {
 				return nil, err
 			}
-			fragments = append(fragments, string(m))
+			documents = append(documents, document{content: string(m), source: i + 1})
 		}
 	}
 
-	return []byte(strings.Join(fragments, separator+"\n")), nil
+	return documents, nil
 }
 
 func createUnstructured(content []byte) (*unstructured.Unstructured, error) {
@@ -205,7 +1050,8 @@ func extractItems(items []interface{}) ([]interface{}, []unstructured.Unstructur
 
 	for _, elt := range items {
 		obj := unstructured.Unstructured{Object: elt.(map[string]interface{})}
-		if (obj.GetAPIVersion() == "extensions/v1beta1" || obj.GetAPIVersion() == "networking.k8s.io/v1beta1") && obj.GetKind() == "Ingress" {
+		if (obj.GetAPIVersion() == "extensions/v1beta1" || obj.GetAPIVersion() == "networking.k8s.io/v1beta1" || obj.GetAPIVersion() == "networking.k8s.io/v1") &&
+			obj.GetKind() == "Ingress" {
 			toConvert = append(toConvert, obj)
 		} else {
 			toKeep = append(toKeep, elt)
@@ -216,13 +1062,12 @@ func extractItems(items []interface{}) ([]interface{}, []unstructured.Unstructur
 }
 
 // convertIngress converts an *networking.Ingress to a slice of runtime.Object (IngressRoute and Middlewares).
-func convertIngress(ingress *networking.Ingress) []runtime.Object {
-	logUnsupported(ingress)
-
+// It returns an error when the Ingress cannot be converted as is (ex: an invalid authentication, invalid service weights).
+func convertIngress(ingress *networking.Ingress, opts Options) ([]runtime.Object, error) {
 	ingressRoute := &v1alpha1.IngressRoute{
 		ObjectMeta: v1.ObjectMeta{Name: ingress.GetName(), Namespace: ingress.GetNamespace(), Annotations: map[string]string{}},
 		Spec: v1alpha1.IngressRouteSpec{
-			EntryPoints: getSliceStringValue(ingress.GetAnnotations(), annotationKubernetesFrontendEntryPoints),
+			EntryPoints: mapEntryPoints(getSliceStringValue(ingress.GetAnnotations(), annotationKubernetesFrontendEntryPoints), opts.EntryPoints),
 		},
 	}
 
@@ -231,16 +1076,32 @@ func convertIngress(ingress *networking.Ingress) []runtime.Object {
 		ingressRoute.GetAnnotations()[annotationKubernetesIngressClass] = ingressClass
 	}
 
+	ingressRoute.Spec.TLS = getTLS(ingress)
+
+	tlsOption := getTLSOption(ingress, opts.TLSOptions)
+	if tlsOption != nil {
+		ingressRoute.Spec.TLS.Options = &v1alpha1.TLSOptionRef{Name: tlsOption.GetName(), Namespace: tlsOption.GetNamespace()}
+	}
+
 	var middlewares []*v1alpha1.Middleware
 
 	// Headers middleware
-	headers := getHeadersMiddleware(ingress)
+	headers := getHeadersMiddleware(ingress, opts.SSLRedirect != SSLRedirectScheme && opts.SSLRedirectRef == "")
 	if headers != nil {
 		middlewares = append(middlewares, headers)
 	}
 
+	// SSL redirect middleware
+	sslRedirect := getSSLRedirect(ingress, opts)
+	if sslRedirect != nil {
+		middlewares = append(middlewares, sslRedirect)
+	}
+
 	// Auth middleware
-	auth := getAuthMiddleware(ingress)
+	auth, err := getAuthMiddleware(ingress)
+	if err != nil {
+		return nil, err
+	}
 	if auth != nil {
 		middlewares = append(middlewares, auth)
 	}
@@ -251,6 +1112,18 @@ func convertIngress(ingress *networking.Ingress) []runtime.Object {
 		middlewares = append(middlewares, whiteList)
 	}
 
+	// CircuitBreaker middleware
+	circuitBreaker := getCircuitBreaker(ingress)
+	if circuitBreaker != nil {
+		middlewares = append(middlewares, circuitBreaker)
+	}
+
+	// InFlightReq middleware
+	inFlightReq := getInFlightReq(ingress)
+	if inFlightReq != nil {
+		middlewares = append(middlewares, inFlightReq)
+	}
+
 	// PassTLSCert middleware
 	passTLSCert := getPassTLSClientCert(ingress)
 	if passTLSCert != nil {
@@ -260,6 +1133,9 @@ func convertIngress(ingress *networking.Ingress) []runtime.Object {
 	// rateLimit middleware
 	middlewares = append(middlewares, getRateLimit(ingress)...)
 
+	// errors middleware
+	middlewares = append(middlewares, getErrorPages(ingress, opts.services)...)
+
 	requestModifier := getStringValue(ingress.GetAnnotations(), annotationKubernetesRequestModifier, "")
 	if requestModifier != "" {
 		middleware, err := parseRequestModifier(ingress.GetNamespace(), requestModifier)
@@ -275,102 +1151,228 @@ func convertIngress(ingress *networking.Ingress) []runtime.Object {
 		miRefs = append(miRefs, toRef(mi))
 	}
 
-	routes, mi, err := createRoutes(ingress.GetNamespace(), ingress.Spec.Rules, ingress.GetAnnotations(), miRefs)
+	if ref := getSSLRedirectRef(ingress, opts.SSLRedirectRef); ref != nil {
+		miRefs = append(miRefs, *ref)
+	}
+
+	routes, mi, err := createRoutes(ingress.GetNamespace(), ingress.Spec.Rules, ingress.GetAnnotations(), miRefs, opts)
 	if err != nil {
-		log.Println(err)
-		return nil
+		return nil, err
+	}
+
+	if route, redirect := createDefaultRoute(ingress.GetNamespace(), ingress.Spec.Backend, ingress.GetAnnotations(), miRefs, opts); route != nil {
+		routes = append(routes, *route)
+
+		if redirect != nil {
+			mi = append(mi, redirect)
+		}
+	}
+
+	weights, err := getServiceWeights(ingress)
+	if err != nil {
+		return nil, err
+	}
+
+	routes, traefikServices, err := applyServiceWeights(ingress.GetNamespace(), routes, weights, getSticky(ingress.GetAnnotations()) != nil)
+	if err != nil {
+		return nil, err
 	}
+
 	ingressRoute.Spec.Routes = routes
 
 	middlewares = append(middlewares, mi...)
 
-	sort.Slice(middlewares, func(i, j int) bool { return middlewares[i].Name < middlewares[j].Name })
+	sort.SliceStable(middlewares, func(i, j int) bool { return middlewares[i].Name < middlewares[j].Name })
 
 	objects := []runtime.Object{ingressRoute}
 	for _, middleware := range middlewares {
 		objects = append(objects, middleware)
 	}
 
-	return objects
+	for _, traefikService := range traefikServices {
+		objects = append(objects, traefikService)
+	}
+
+	if tlsOption != nil {
+		objects = append(objects, tlsOption)
+	}
+
+	return objects, nil
 }
 
-func createRoutes(namespace string, rules []networking.IngressRule, annotations map[string]string, middlewareRefs []v1alpha1.MiddlewareRef) ([]v1alpha1.Route, []*v1alpha1.Middleware, error) {
+func createRoutes(namespace string, rules []networking.IngressRule, annotations map[string]string, middlewareRefs []v1alpha1.MiddlewareRef, opts Options) ([]v1alpha1.Route, []*v1alpha1.Middleware, error) {
 	ruleType, stripPrefix, err := extractRuleType(annotations)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	// The invalid priorities are reported by priorityNotes.
+	priority, _ := getPriority(annotations)
+
 	var mis []*v1alpha1.Middleware
 
+	// The middlewares of the paths are named after their host and path, which may collide.
+	names := middlewareNames{}
+
+	modifier := ruleTypeModifier(annotations)
+	if modifier != "" {
+		mi, err := parseRequestModifier(namespace, modifier)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s: %w", annotationKubernetesRuleType, err)
+		}
+
+		mis = append(mis, mi)
+		middlewareRefs = append(append([]v1alpha1.MiddlewareRef{}, middlewareRefs...), toRef(mi))
+	}
+
 	var routes []v1alpha1.Route
 
 	for _, rule := range rules {
+		if rule.HTTP == nil {
+			// The requests of the hosts without paths are served by the default backend.
+			continue
+		}
+
+		if rule.Host == "" && opts.Hostless == HostlessSkip {
+			// The rules without host are reported by hostlessNotes.
+			continue
+		}
+
 		for _, path := range rule.HTTP.Paths {
+			// The paths of the unsupported resource backends are reported by resourceBackendNotes.
+			service, ok := getService(namespace, path.Backend, annotations, opts)
+			if !ok {
+				continue
+			}
+
 			miRefs := make([]v1alpha1.MiddlewareRef, 0, 1)
 			miRefs = append(miRefs, middlewareRefs...)
 
 			var rules []string
 
 			if len(rule.Host) > 0 {
-				rules = append(rules, fmt.Sprintf("Host(`%s`)", rule.Host))
+				rules = append(rules, hostMatcher(rule.Host))
+			} else if opts.Hostless == HostlessHostRegexp {
+				rules = append(rules, fmt.Sprintf("HostRegexp(`%s`)", anyHostRegexp))
 			}
 
 			if len(path.Path) > 0 {
-				rules = append(rules, fmt.Sprintf("%s(`%s`)", ruleType, path.Path))
+				rewriteTarget := getStringValue(annotations, annotationKubernetesRewriteTarget, "")
+
+				rules = append(rules, fmt.Sprintf("%s(`%s`)", pathMatcher(annotations, ruleType, path.PathType), rewriteTargetPath(path.Path, rewriteTarget)))
 
 				if stripPrefix {
-					mi := getStripPrefix(path, rule.Host+path.Path, namespace)
+					mi := names.unique(getStripPrefix(path, objectBaseName(rule.Host, path.Path), namespace))
 					mis = append(mis, mi)
 					miRefs = append(miRefs, toRef(mi))
 				}
 
-				rewriteTarget := getStringValue(annotations, annotationKubernetesRewriteTarget, "")
 				if rewriteTarget != "" {
-					if ruleType == ruleTypeReplacePath {
+					if ruleType == ruleTypeReplacePath || modifier != "" {
 						return nil, nil, fmt.Errorf("rewrite-target must not be used together with annotation %q", annotationKubernetesRuleType)
 					}
 
-					mi := getReplacePathRegex(rule, path, namespace, rewriteTarget)
+					mi := names.unique(getReplacePathRegex(rule, path, namespace, rewriteTarget))
 					mis = append(mis, mi)
 					miRefs = append(miRefs, toRef(mi))
 				}
 			}
 
-			redirect := getFrontendRedirect(namespace, annotations, rule.Host+path.Path, path.Path)
+			redirect := getFrontendRedirect(namespace, annotations, rule.Host+path.Path, path.Path, opts.EntryPoints)
 			if redirect != nil {
 				mis = append(mis, redirect)
 				miRefs = append(miRefs, toRef(redirect))
 			}
 
-			if len(rules) > 0 {
-				sort.Slice(miRefs, func(i, j int) bool { return miRefs[i].Name < miRefs[j].Name })
-
-				routes = append(routes, v1alpha1.Route{
-					Match:    strings.Join(rules, " && "),
-					Kind:     "Rule",
-					Priority: getIntValue(annotations, annotationKubernetesPriority, 0),
-					Services: []v1alpha1.Service{
-						{
-							LoadBalancerSpec: v1alpha1.LoadBalancerSpec{
-								Name:      path.Backend.ServiceName,
-								Namespace: namespace,
-								Kind:      "Service",
-								// TODO pas de port en string dans ingressRoute ?
-								Port:   path.Backend.ServicePort.IntVal,
-								Scheme: getStringValue(annotations, annotationKubernetesProtocol, ""),
-							},
-						},
-					},
-					Middlewares: miRefs,
-				})
+			if len(rules) == 0 {
+				// A rule without host and path matches all the requests.
+				rules = append(rules, defaultBackendMatch)
 			}
+
+			sort.Slice(miRefs, func(i, j int) bool { return miRefs[i].Name < miRefs[j].Name })
+
+			routes = append(routes, v1alpha1.Route{
+				Match:       strings.Join(rules, " && "),
+				Kind:        "Rule",
+				Priority:    priority,
+				Services:    []v1alpha1.Service{service},
+				Middlewares: miRefs,
+			})
 		}
 	}
 
 	return routes, mis, nil
 }
 
+// createDefaultRoute returns the route of the default backend of an Ingress, and its app-root redirect middleware, nil without default backend.
+// As with Traefik v1, the route matches all the requests with the lowest priority: the requests not matched by the other routes.
+func createDefaultRoute(namespace string, backend *networking.IngressBackend, annotations map[string]string, middlewareRefs []v1alpha1.MiddlewareRef,
+	opts Options) (*v1alpha1.Route, *v1alpha1.Middleware) {
+	if backend == nil {
+		return nil, nil
+	}
+
+	service, ok := getService(namespace, *backend, annotations, opts)
+	if !ok {
+		return nil, nil
+	}
+
+	miRefs := make([]v1alpha1.MiddlewareRef, 0, 1)
+	miRefs = append(miRefs, middlewareRefs...)
+
+	redirect := getDefaultBackendAppRoot(namespace, annotations)
+	if redirect != nil {
+		miRefs = append(miRefs, toRef(redirect))
+	}
+
+	sort.Slice(miRefs, func(i, j int) bool { return miRefs[i].Name < miRefs[j].Name })
+
+	return &v1alpha1.Route{
+		Match:       defaultBackendMatch,
+		Kind:        "Rule",
+		Priority:    defaultBackendPriority,
+		Services:    []v1alpha1.Service{service},
+		Middlewares: miRefs,
+	}, redirect
+}
+
+// getService returns the service of a route to an Ingress backend, false for the resource backends which are not a TraefikService.
+// The named ports which are not resolved are set by setPortNames.
+func getService(namespace string, backend networking.IngressBackend, annotations map[string]string, opts Options) (v1alpha1.Service, bool) {
+	if backend.Resource != nil {
+		if !isTraefikServiceResource(backend.Resource) {
+			return v1alpha1.Service{}, false
+		}
+
+		return v1alpha1.Service{
+			LoadBalancerSpec: v1alpha1.LoadBalancerSpec{
+				Name:      backend.Resource.Name,
+				Namespace: namespace,
+				Kind:      "TraefikService",
+			},
+		}, true
+	}
+
+	return v1alpha1.Service{
+		LoadBalancerSpec: v1alpha1.LoadBalancerSpec{
+			Name:               backend.ServiceName,
+			Namespace:          namespace,
+			Kind:               "Service",
+			Port:               backendPort(namespace, backend, opts),
+			Scheme:             getStringValue(annotations, annotationKubernetesProtocol, ""),
+			PassHostHeader:     getPassHostHeader(annotations),
+			ResponseForwarding: getResponseForwarding(annotations),
+			Sticky:             getSticky(annotations),
+		},
+	}, true
+}
+
 func extractRuleType(annotations map[string]string) (string, bool, error) {
+	// The paths of the modifier rule types with a value are prefixes, modified by the middleware of the rule type.
+	if ruleTypeModifier(annotations) != "" {
+		return ruleTypePathPrefix, false, nil
+	}
+
 	var stripPrefix bool
 	ruleType := getStringValue(annotations, annotationKubernetesRuleType, ruleTypePathPrefix)
 
@@ -384,6 +1386,8 @@ func extractRuleType(annotations map[string]string) (string, bool, error) {
 		stripPrefix = true
 	case ruleTypeReplacePath:
 		log.Printf("Using %s as %s will be deprecated in the future. Please use the %s annotation instead", ruleType, annotationKubernetesRuleType, annotationKubernetesRequestModifier)
+	case ruleTypeReplacePathRegex:
+		return "", false, fmt.Errorf("rule type %q is missing the regex and the replacement (ex: \"ReplacePathRegex: ^/api/(.*) /$1\")", ruleType)
 	default:
 		return "", false, fmt.Errorf("cannot use non-matcher rule: %q", ruleType)
 	}
@@ -391,6 +1395,41 @@ func extractRuleType(annotations map[string]string) (string, bool, error) {
 	return ruleType, stripPrefix, nil
 }
 
+// ruleTypeModifier returns the rule-type annotation of a modifier rule type with a value (ex: "ReplacePathRegex: ^/api/(.*) /$1", "ReplacePath: /api"),
+// empty for the other rule types.
+func ruleTypeModifier(annotations map[string]string) string {
+	ruleType := getStringValue(annotations, annotationKubernetesRuleType, "")
+
+	parts := strings.SplitN(ruleType, ":", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+		return ""
+	}
+
+	switch strings.TrimSpace(parts[0]) {
+	case ruleTypeReplacePath, ruleTypeReplacePathRegex:
+		return ruleType
+	default:
+		return ""
+	}
+}
+
+// pathMatcher returns the matcher of a path: the matcher of the rule-type annotation when it is set,
+// the matcher of the Exact and Prefix path types otherwise.
+func pathMatcher(annotations map[string]string, ruleType string, pathType *networking.PathType) string {
+	if _, ok := annotations[getAnnotationName(annotations, annotationKubernetesRuleType)]; ok || pathType == nil {
+		return ruleType
+	}
+
+	switch *pathType {
+	case networking.PathTypeExact:
+		return ruleTypePath
+	case networking.PathTypePrefix:
+		return ruleTypePathPrefix
+	default:
+		return ruleType
+	}
+}
+
 func toRef(mi *v1alpha1.Middleware) v1alpha1.MiddlewareRef {
 	return v1alpha1.MiddlewareRef{
 		Name:      mi.Name,
@@ -398,34 +1437,149 @@ func toRef(mi *v1alpha1.Middleware) v1alpha1.MiddlewareRef {
 	}
 }
 
-func logUnsupported(ingress *networking.Ingress) {
+// logNotes writes the notes of the conversion of an Ingress, and records them for its report.
+func (c *converter) logNotes(source string, ingress *networking.Ingress, notes []string) {
+	out := c.opts.Notes
+	if out == nil {
+		out = os.Stdout
+	}
+
+	for _, note := range notes {
+		fmt.Fprintf(out, "%s/%s: %s\n", ingress.GetNamespace(), ingress.GetName(), note)
+		c.notes = append(c.notes, note)
+
+		c.progress(ProgressEvent{
+			Type:      EventWarning,
+			File:      source,
+			Namespace: ingress.GetNamespace(),
+			Name:      ingress.GetName(),
+			Message:   note,
+		})
+	}
+}
+
+func (c *converter) logUnsupported(source string, ingress *networking.Ingress) {
+	notes := c.opts.Notes
+	if notes == nil {
+		notes = os.Stdout
+	}
+
+	for _, action := range manualActions(ingress) {
+		fmt.Fprintf(notes, "%s/%s: The annotation %s must be converted manually. %s", ingress.GetNamespace(), ingress.GetName(), action.Annotation, action.Message)
+
+		c.progress(ProgressEvent{
+			Type:      EventWarning,
+			File:      source,
+			Namespace: ingress.GetNamespace(),
+			Name:      ingress.GetName(),
+			Message:   fmt.Sprintf("The annotation %s must be converted manually. %s", action.Annotation, action.Message),
+		})
+	}
+}
+
+// manualActions returns the annotations of an Ingress which must be converted manually.
+func manualActions(ingress *networking.Ingress) []ManualAction {
+	// The annotations without documentation URL are not supported yet.
 	unsupportedAnnotations := map[string]string{
-		annotationKubernetesErrorPages:                      "See https://docs.traefik.io/middlewares/errorpages/",
-		annotationKubernetesBuffering:                       "See https://docs.traefik.io/middlewares/buffering/",
-		annotationKubernetesCircuitBreakerExpression:        "See https://docs.traefik.io/middlewares/circuitbreaker/",
-		annotationKubernetesMaxConnAmount:                   "See https://docs.traefik.io/middlewares/inflightreq/",
-		annotationKubernetesMaxConnExtractorFunc:            "See https://docs.traefik.io/middlewares/inflightreq/",
-		annotationKubernetesResponseForwardingFlushInterval: "See https://docs.traefik.io/providers/kubernetes-crd/",
-		annotationKubernetesLoadBalancerMethod:              "See https://docs.traefik.io/providers/kubernetes-crd/",
-		annotationKubernetesPreserveHost:                    "See https://docs.traefik.io/providers/kubernetes-crd/",
-		annotationKubernetesSessionCookieName:               "Not supported yet.",
-		annotationKubernetesAffinity:                        "Not supported yet.",
-		annotationKubernetesAuthRealm:                       "See https://docs.traefik.io/middlewares/basicauth/",
-		annotationKubernetesServiceWeights:                  "See https://docs.traefik.io/providers/kubernetes-crd/",
+		annotationKubernetesBuffering:          "https://docs.traefik.io/middlewares/buffering/",
+		annotationKubernetesLoadBalancerMethod: "https://docs.traefik.io/providers/kubernetes-crd/",
+		annotationKubernetesAuthRealm:          "https://docs.traefik.io/middlewares/basicauth/",
+	}
+
+	var actions []ManualAction
+	for annot, docURL := range unsupportedAnnotations {
+		if getStringValue(ingress.GetAnnotations(), annot, "") == "" {
+			continue
+		}
+
+		action := ManualAction{Annotation: annot, DocURL: docURL, Message: "Not supported yet."}
+		if docURL != "" {
+			action.Message = "See " + docURL
+		}
+
+		actions = append(actions, action)
 	}
 
-	for annot, msg := range unsupportedAnnotations {
-		if getStringValue(ingress.GetAnnotations(), annot, "") != "" {
-			fmt.Printf("%s/%s: The annotation %s must be converted manually. %s", ingress.GetNamespace(), ingress.GetName(), annot, msg)
+	sort.Slice(actions, func(i, j int) bool { return actions[i].Annotation < actions[j].Annotation })
+
+	return actions
+}
+
+// hostlessNotes returns the note about the rules without host skipped with HostlessSkip.
+func hostlessNotes(i *networking.Ingress, hostless string) []string {
+	if hostless != HostlessSkip {
+		return nil
+	}
+
+	var paths []string
+	for _, rule := range i.Spec.Rules {
+		if rule.Host != "" || rule.HTTP == nil {
+			continue
+		}
+
+		for _, path := range rule.HTTP.Paths {
+			paths = append(paths, fmt.Sprintf("%q", path.Path))
 		}
 	}
+
+	if len(paths) == 0 {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("The rules without host are skipped, the paths %s are not converted.", strings.Join(paths, ", "))}
+}
+
+// anyHostRegexp is the HostRegexp of the routes of the rules without host, converted with HostlessHostRegexp.
+const anyHostRegexp = "{host:.+}"
+
+// wildcardHostRegexp replaces the wildcard of the wildcard hosts in the HostRegexp matchers, as with Traefik v1.
+const wildcardHostRegexp = "{subdomain:[A-Za-z0-9-_]+}"
+
+// hostMatcher returns the matcher of the host of a rule, HostRegexp for the wildcard hosts (ex: *.example.com).
+func hostMatcher(host string) string {
+	if isWildcardHost(host) {
+		return fmt.Sprintf("HostRegexp(`%s`)", strings.Replace(host, "*", wildcardHostRegexp, 1))
+	}
+
+	return fmt.Sprintf("Host(`%s`)", host)
+}
+
+// isWildcardHost returns whether a host is a wildcard host (ex: *.example.com).
+func isWildcardHost(host string) bool {
+	return strings.HasPrefix(host, "*")
+}
+
+// objectBaseName returns the base of the name of the objects of a path of a rule (ex: example.com/api),
+// the wildcard of the wildcard hosts is named (ex: wildcard.example.com/api).
+func objectBaseName(host, path string) string {
+	if isWildcardHost(host) {
+		host = "wildcard" + strings.TrimPrefix(host, "*")
+	}
+
+	return host + path
 }
 
+// normalizeObjectName returns a name derived from a host and a path which is a DNS subdomain (RFC 1123) of at most maxBaseNameLength characters:
+// it is lowercased, the other characters than the letters, the digits and the dots are replaced by dashes, and the longer names are shortened.
 // https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names
 func normalizeObjectName(name string) string {
 	fn := func(c rune) bool {
-		return !unicode.IsLetter(c) && !unicode.IsNumber(c) && c != '.' && c != '-'
+		return (c < 'a' || c > 'z') && (c < '0' || c > '9')
+	}
+
+	var labels []string
+	for _, label := range strings.Split(strings.ToLower(name), ".") {
+		if label = strings.Join(strings.FieldsFunc(label, fn), "-"); label != "" {
+			labels = append(labels, label)
+		}
 	}
 
-	return strings.Join(strings.FieldsFunc(name, fn), "-")
+	return shortenName(strings.Join(labels, "."), maxBaseNameLength)
+}
+
+// indexedName returns a name suffixed with an index, shortened to remain a DNS subdomain (RFC 1123).
+func indexedName(name string, i int) string {
+	suffix := fmt.Sprintf("-%d", i)
+
+	return shortenName(name, validation.DNS1123SubdomainMaxLength-len(suffix)) + suffix
 }
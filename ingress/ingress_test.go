@@ -1,18 +1,24 @@
 package ingress
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
 	networking "k8s.io/api/networking/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 var updateExpected = flag.Bool("update_expected", false, "Update expected files in testdata")
@@ -42,10 +48,38 @@ func Test_convertIngress(t *testing.T) {
 			ingressFile: "ingress_with_headers_annotations.yml",
 			objectCount: 2,
 		},
+		{
+			ingressFile: "ingress_with_policy_headers.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_tls.yml",
+			objectCount: 1,
+		},
+		{
+			ingressFile: "ingress_with_default_backend.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_default_backend_app_root.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_rule_type_replace_path_regex.yml",
+			objectCount: 2,
+		},
 		{
 			ingressFile: "ingress_rewrite_target.yml",
 			objectCount: 2,
 		},
+		{
+			ingressFile: "ingress_rewrite_target_capture_groups.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_wildcard_host.yml",
+			objectCount: 4,
+		},
 		{
 			ingressFile: "ingress_with_whitelist.yml",
 			objectCount: 2,
@@ -54,6 +88,10 @@ func Test_convertIngress(t *testing.T) {
 			ingressFile: "ingress_with_whitelist_xforwarded.yml",
 			objectCount: 2,
 		},
+		{
+			ingressFile: "ingress_with_whitelist_ipstrategy.yml",
+			objectCount: 2,
+		},
 		{
 			ingressFile: "ingress_with_passtlscert.yml",
 			objectCount: 2,
@@ -66,15 +104,42 @@ func Test_convertIngress(t *testing.T) {
 			ingressFile: "ingress_redirect_regex.yml",
 			objectCount: 3,
 		},
-		// FIXME errorPages middleware
-		// {
-		// 	ingressFile: "ingress_with_errorpage.yml",
-		// 	objectCount: 3,
-		// },
+		{
+			ingressFile: "ingress_with_errorpage.yml",
+			objectCount: 3,
+		},
+		{
+			ingressFile: "ingress_with_passtlscert_subject.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_passtlscert_deprecated.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_inflightreq.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_circuitbreaker.yml",
+			objectCount: 2,
+		},
 		{
 			ingressFile: "ingress_with_ratelimit.yml",
 			objectCount: 3,
 		},
+		{
+			ingressFile: "ingress_with_service_weights.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_affinity.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_forwardauth.yml",
+			objectCount: 2,
+		},
 		{
 			ingressFile: "ingress_with_request_modifier.yml",
 			objectCount: 2,
@@ -95,7 +160,8 @@ func Test_convertIngress(t *testing.T) {
 			objectIngress, err := parseYaml(bytes)
 			require.NoError(t, err)
 
-			objects := convertIngress(objectIngress.(*networking.Ingress))
+			objects, err := convertIngress(objectIngress.(*networking.Ingress), Options{})
+			require.NoError(t, err)
 
 			if !*updateExpected {
 				require.Len(t, objects, test.objectCount)
@@ -160,10 +226,46 @@ func Test_convertFile(t *testing.T) {
 			ingressFile: "ingress_with_headers_annotations.yml",
 			objectCount: 2,
 		},
+		{
+			ingressFile: "ingress_with_policy_headers.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_tls.yml",
+			objectCount: 1,
+		},
+		{
+			ingressFile: "ingress_with_default_backend.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_default_backend_app_root.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_rule_type_replace_path_regex.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_networking_v1.yml",
+			objectCount: 1,
+		},
+		{
+			ingressFile: "ingress_with_named_port.yml",
+			objectCount: 1,
+		},
 		{
 			ingressFile: "ingress_rewrite_target.yml",
 			objectCount: 2,
 		},
+		{
+			ingressFile: "ingress_rewrite_target_capture_groups.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_wildcard_host.yml",
+			objectCount: 4,
+		},
 		{
 			ingressFile: "ingress_with_whitelist.yml",
 			objectCount: 2,
@@ -172,6 +274,10 @@ func Test_convertFile(t *testing.T) {
 			ingressFile: "ingress_with_whitelist_xforwarded.yml",
 			objectCount: 2,
 		},
+		{
+			ingressFile: "ingress_with_whitelist_ipstrategy.yml",
+			objectCount: 2,
+		},
 		{
 			ingressFile: "ingress_with_passtlscert.yml",
 			objectCount: 2,
@@ -184,15 +290,42 @@ func Test_convertFile(t *testing.T) {
 			ingressFile: "ingress_redirect_regex.yml",
 			objectCount: 3,
 		},
-		// FIXME errorPages middleware
-		// {
-		// 	ingressFile: "ingress_with_errorpage.yml",
-		// 	objectCount: 3,
-		// },
+		{
+			ingressFile: "ingress_with_errorpage.yml",
+			objectCount: 3,
+		},
+		{
+			ingressFile: "ingress_with_passtlscert_subject.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_passtlscert_deprecated.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_inflightreq.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_circuitbreaker.yml",
+			objectCount: 2,
+		},
 		{
 			ingressFile: "ingress_with_ratelimit.yml",
 			objectCount: 3,
 		},
+		{
+			ingressFile: "ingress_with_service_weights.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_affinity.yml",
+			objectCount: 2,
+		},
+		{
+			ingressFile: "ingress_with_forwardauth.yml",
+			objectCount: 2,
+		},
 		{
 			ingressFile: "ingress_with_request_modifier.yml",
 			objectCount: 2,
@@ -211,7 +344,7 @@ func Test_convertFile(t *testing.T) {
 
 	for _, test := range testCases {
 		t.Run(test.ingressFile, func(t *testing.T) {
-			err := convertFile(filepath.Join("fixtures", "input"), tempDir, test.ingressFile)
+			err := (&converter{report: &Report{}}).convertFile(filepath.Join("fixtures", "input"), tempDir, test.ingressFile)
 			require.NoError(t, err)
 
 			require.FileExists(t, filepath.Join(tempDir, test.ingressFile))
@@ -237,3 +370,2911 @@ func Test_convertFile(t *testing.T) {
 		})
 	}
 }
+
+func TestReport_SaveHTML(t *testing.T) {
+	c := &converter{report: &Report{}}
+
+	for _, ingressFile := range []string{"ingress_with_buffering.yml", "ingress_with_headers_annotations.yml", "ingress_with_ratelimit.yml"} {
+		err := c.convertFile(filepath.Join("fixtures", "input"), t.TempDir(), ingressFile)
+		require.NoError(t, err)
+	}
+
+	report := c.report
+
+	require.Len(t, report.Ingresses, 3)
+	assert.Equal(t, []ManualAction{{
+		Annotation: annotationKubernetesBuffering,
+		Message:    "See https://docs.traefik.io/middlewares/buffering/",
+		DocURL:     "https://docs.traefik.io/middlewares/buffering/",
+	}}, report.Ingresses[0].ManualActions)
+
+	output := filepath.Join(t.TempDir(), "report.html")
+	require.NoError(t, report.SaveHTML(output))
+
+	fixtureFile := filepath.Join("fixtures", "output_report", "report.html")
+
+	if *updateExpected {
+		require.NoError(t, os.MkdirAll(filepath.Dir(fixtureFile), 0755))
+		content, err := os.ReadFile(output)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(fixtureFile, content, 0666))
+	}
+
+	fixture, err := os.ReadFile(fixtureFile)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(fixture), string(content))
+}
+
+func Test_diffLines(t *testing.T) {
+	lines := diffLines([]string{"a", "b", "c"}, []string{"a", "c", "d"})
+
+	expected := []DiffLine{
+		{Op: " ", Text: "a"},
+		{Op: "-", Text: "b"},
+		{Op: " ", Text: "c"},
+		{Op: "+", Text: "d"},
+	}
+	assert.Equal(t, expected, lines)
+}
+
+func Test_convertFile_explain(t *testing.T) {
+	testCases := []string{
+		"ingress_redirect_regex.yml",
+		"ingress_with_request_modifier.yml",
+	}
+
+	fixturesDir := filepath.Join("fixtures", "output_explain")
+
+	for _, test := range testCases {
+		t.Run(test, func(t *testing.T) {
+			tempDir := t.TempDir()
+
+			c := &converter{opts: Options{Explain: true}, report: &Report{}}
+			err := c.convertFile(filepath.Join("fixtures", "input"), tempDir, test)
+			require.NoError(t, err)
+
+			output, err := os.ReadFile(filepath.Join(tempDir, test))
+			require.NoError(t, err)
+
+			if *updateExpected {
+				require.NoError(t, os.MkdirAll(fixturesDir, 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(fixturesDir, test), output, 0666))
+			}
+
+			fixture, err := os.ReadFile(filepath.Join(fixturesDir, test))
+			require.NoError(t, err)
+
+			// The comments are compared too.
+			assert.Equal(t, string(fixture), string(output))
+		})
+	}
+}
+
+func Test_convertFile_audit(t *testing.T) {
+	testCases := []string{
+		"ingress_redirect_regex.yml",
+		"ingress_with_request_modifier.yml",
+	}
+
+	fixturesDir := filepath.Join("fixtures", "output_audit")
+
+	for _, test := range testCases {
+		t.Run(test, func(t *testing.T) {
+			tempDir := t.TempDir()
+
+			c := &converter{opts: Options{Audit: true}, report: &Report{}}
+			err := c.convertFile(filepath.Join("fixtures", "input"), tempDir, test)
+			require.NoError(t, err)
+
+			output, err := os.ReadFile(filepath.Join(tempDir, test))
+			require.NoError(t, err)
+
+			if *updateExpected {
+				require.NoError(t, os.MkdirAll(fixturesDir, 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(fixturesDir, test), output, 0666))
+			}
+
+			fixture, err := os.ReadFile(filepath.Join(fixturesDir, test))
+			require.NoError(t, err)
+
+			assert.YAMLEq(t, string(fixture), string(output))
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	src := filepath.Join("fixtures", "input", "ingress_with_request_modifier.yml")
+	dstDir := t.TempDir()
+
+	_, err := Convert(src, dstDir, Options{})
+	require.NoError(t, err)
+
+	diffs, err := Check(src, dstDir, Options{})
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	// The output of another option is a drift.
+	diffs, err = Check(src, dstDir, Options{Explain: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ingress_with_request_modifier.yml: modified"}, diffs)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dstDir, "stale.yml"), []byte("kind: IngressRoute"), 0666))
+	require.NoError(t, os.Remove(filepath.Join(dstDir, "ingress_with_request_modifier.yml")))
+
+	diffs, err = Check(src, dstDir, Options{})
+	require.NoError(t, err)
+
+	expected := []string{
+		"ingress_with_request_modifier.yml: missing",
+		"stale.yml: not produced by the conversion",
+	}
+	assert.Equal(t, expected, diffs)
+}
+
+func TestConvert_mirrorTree(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("fixtures", "input", "ingress_with_request_modifier.yml"))
+	require.NoError(t, err)
+
+	src := filepath.Join(t.TempDir(), "apps")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "whoami", "overlays"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "ingress.yml"), content, 0666))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "whoami", "overlays", "ingress.yml"), content, 0666))
+
+	testCases := []struct {
+		desc       string
+		mirrorTree bool
+		expected   []string
+	}{
+		{
+			desc:     "nested under the name of the source directory",
+			expected: []string{filepath.Join("apps", "ingress.yml"), filepath.Join("apps", "whoami", "overlays", "ingress.yml")},
+		},
+		{
+			desc:       "mirrored source directory",
+			mirrorTree: true,
+			expected:   []string{"ingress.yml", filepath.Join("whoami", "overlays", "ingress.yml")},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			dstDir := t.TempDir()
+
+			_, err := Convert(src, dstDir, Options{MirrorTree: test.mirrorTree})
+			require.NoError(t, err)
+
+			files, err := readTree(dstDir)
+			require.NoError(t, err)
+
+			var names []string
+			for name := range files {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestConvert_checkpoint(t *testing.T) {
+	src := t.TempDir()
+	for _, name := range []string{"ingress.yml", "ingress_with_request_modifier.yml"} {
+		content, err := os.ReadFile(filepath.Join("fixtures", "input", name))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(src, name), content, 0666))
+	}
+
+	dstDir := t.TempDir()
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	// An interrupted migration, which converted the first file.
+	cp, err := loadCheckpoint(checkpointFile, src, dstDir, false)
+	require.NoError(t, err)
+	require.NoError(t, cp.markDone(filepath.Join(src, "ingress.yml")))
+
+	_, err = Convert(src, dstDir, Options{Checkpoint: checkpointFile})
+	require.Error(t, err)
+
+	_, err = Convert(t.TempDir(), dstDir, Options{Checkpoint: checkpointFile, Resume: true})
+	require.Error(t, err)
+
+	_, err = Convert(src, dstDir, Options{Checkpoint: checkpointFile, Resume: true})
+	require.NoError(t, err)
+
+	outputDir := filepath.Join(dstDir, filepath.Base(src))
+	assert.NoFileExists(t, filepath.Join(outputDir, "ingress.yml"))
+	assert.FileExists(t, filepath.Join(outputDir, "ingress_with_request_modifier.yml"))
+	assert.NoFileExists(t, checkpointFile)
+}
+
+func TestConvert_nameTemplate(t *testing.T) {
+	tmpl, err := ParseNameTemplate("{{.Namespace}}/{{.Kind | lower}}-{{.Name}}.yaml")
+	require.NoError(t, err)
+
+	dstDir := t.TempDir()
+
+	_, err = Convert(filepath.Join("fixtures", "input", "ingress_and_service.yml"), dstDir, Options{NameTemplate: tmpl})
+	require.NoError(t, err)
+
+	files, err := readTree(dstDir)
+	require.NoError(t, err)
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	assert.Equal(t, []string{"testing/ingressroute-test.yaml", "testing/service-service1.yaml"}, names)
+	assert.Contains(t, string(files["testing/service-service1.yaml"]), "clusterIp: 10.0.0.1")
+}
+
+func Test_outputName(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		template string
+		content  string
+		expected string
+		err      bool
+	}{
+		{
+			desc:     "namespaced object",
+			template: "{{.Namespace}}/{{.Kind | lower}}-{{.Name}}.yaml",
+			content:  "kind: Middleware\nmetadata:\n  name: redirect\n  namespace: app\n",
+			expected: filepath.Join("app", "middleware-redirect.yaml"),
+		},
+		{
+			desc:     "default namespace",
+			template: "{{.Namespace}}/{{.File}}",
+			content:  "kind: IngressRoute\nmetadata:\n  name: test\n",
+			expected: filepath.Join("default", "ingress.yml"),
+		},
+		{
+			desc:     "split",
+			template: SplitNameTemplate,
+			content:  "kind: Middleware\nmetadata:\n  name: redirect\n  namespace: app\n",
+			expected: "app-middleware-redirect.yaml",
+		},
+		{
+			desc:     "outside of the output directory",
+			template: "../{{.Name}}.yaml",
+			content:  "kind: IngressRoute\nmetadata:\n  name: test\n",
+			err:      true,
+		},
+		{
+			desc:     "empty name",
+			template: "{{.Name}}",
+			content:  "kind: IngressRoute\n",
+			err:      true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, err := ParseNameTemplate(test.template)
+			require.NoError(t, err)
+
+			name, err := outputName(tmpl, filepath.Join("src", "ingress.yml"), test.content)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, name)
+		})
+	}
+}
+
+func Test_splitDocuments(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		content  string
+		expected []string
+	}{
+		{
+			desc:     "documents",
+			content:  "kind: Service\n---\nkind: Ingress\n",
+			expected: []string{"kind: Service\n", "kind: Ingress\n"},
+		},
+		{
+			desc:     "leading document marker and empty documents",
+			content:  "---\nkind: Service\n---\n---\n\n---\nkind: Ingress\n---\n",
+			expected: []string{"kind: Service\n", "kind: Ingress\n"},
+		},
+		{
+			desc:     "CRLF line endings",
+			content:  "kind: Service\r\n---\r\nkind: Ingress\r\n",
+			expected: []string{"kind: Service\n", "kind: Ingress\n"},
+		},
+		{
+			desc:     "trailing whitespace after the separator",
+			content:  "kind: Service\n---  \t\nkind: Ingress\n",
+			expected: []string{"kind: Service\n", "kind: Ingress\n"},
+		},
+		{
+			desc:     "dashes in values, comments, and block scalars",
+			content:  "kind: ConfigMap\ndata:\n  name: a---b\n  # -----\n  file: |\n    ---\n    key: value\n",
+			expected: []string{"kind: ConfigMap\ndata:\n  name: a---b\n  # -----\n  file: |\n    ---\n    key: value\n"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			documents := splitDocuments([]byte(test.content))
+
+			assert.Equal(t, test.expected, documents)
+		})
+	}
+}
+
+func TestConvert_progress(t *testing.T) {
+	src := filepath.Join("fixtures", "input", "ingress_with_buffering.yml")
+
+	events := &strings.Builder{}
+
+	_, err := Convert(src, t.TempDir(), Options{Notes: io.Discard, Progress: JSONProgress(events)})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(events.String()), "\n")
+	require.Len(t, lines, 4)
+
+	assert.JSONEq(t, `{"type":"file_started","file":"fixtures/input/ingress_with_buffering.yml"}`, lines[0])
+	assert.Contains(t, lines[1], `"type":"warning"`)
+	assert.Contains(t, lines[1], "ingress.kubernetes.io/buffering")
+	assert.Contains(t, lines[2], `"type":"ingress_converted"`)
+	assert.Contains(t, lines[3], `"type":"file_finished"`)
+}
+
+func TestConvert_review(t *testing.T) {
+	src := filepath.Join("fixtures", "input", "ingress.yml")
+
+	testCases := []struct {
+		desc     string
+		decision ReviewDecision
+		expected []string
+	}{
+		{
+			desc:     "skip",
+			decision: ReviewDecision{Skip: true},
+			expected: []string{"kind: Ingress", "name: test"},
+		},
+		{
+			desc:     "rename",
+			decision: ReviewDecision{Name: "renamed"},
+			expected: []string{"kind: IngressRoute", "name: renamed"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var reviewed []string
+			review := func(report IngressReport) (ReviewDecision, error) {
+				reviewed = append(reviewed, report.Namespace+"/"+report.Name)
+				return test.decision, nil
+			}
+
+			dstDir := t.TempDir()
+
+			report, err := Convert(src, dstDir, Options{Notes: io.Discard, Review: review})
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"testing/test"}, reviewed)
+
+			if test.decision.Skip {
+				assert.Empty(t, report.Ingresses)
+			}
+
+			output, err := os.ReadFile(filepath.Join(dstDir, "ingress.yml"))
+			require.NoError(t, err)
+
+			for _, expected := range test.expected {
+				assert.Contains(t, string(output), expected)
+			}
+		})
+	}
+}
+
+func TestConvert_plugins(t *testing.T) {
+	pluginsDir := t.TempDir()
+
+	plugin := `package waf
+
+import (
+	"encoding/json"
+)
+
+type ingress struct {
+	Kind     string
+	Metadata struct {
+		Annotations map[string]string
+	}
+}
+
+func Handle(data []byte) ([]byte, error) {
+	var i ingress
+	if err := json.Unmarshal(data, &i); err != nil {
+		return nil, err
+	}
+
+	if i.Kind != "Ingress" || i.Metadata.Annotations["example.com/waf"] != "strict" {
+		return nil, nil
+	}
+
+	return []byte(` + "`" + `{"middlewares":[{"name":"waf","spec":{"forwardAuth":{"address":"http://waf.security"}}}],"entryPoints":["websecure"]}` + "`" + `), nil
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(pluginsDir, "waf.go"), []byte(plugin), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginsDir, "README.md"), []byte("not a plugin"), 0644))
+
+	plugins, err := LoadPlugins(pluginsDir)
+	require.NoError(t, err)
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "waf", plugins[0].Name())
+
+	src := t.TempDir()
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: test
+  namespace: testing
+  annotations:
+    example.com/waf: strict
+spec:
+  rules:
+    - host: traefik.tchouk
+      http:
+        paths:
+          - path: /bar
+            backend:
+              serviceName: service1
+              servicePort: 80
+`
+	require.NoError(t, os.WriteFile(filepath.Join(src, "ingress.yml"), []byte(content), 0666))
+
+	dstDir := t.TempDir()
+
+	report, err := Convert(filepath.Join(src, "ingress.yml"), dstDir, Options{Plugins: plugins})
+	require.NoError(t, err)
+
+	require.Len(t, report.Ingresses, 1)
+	assert.Equal(t, []string{"waf"}, report.Ingresses[0].Middlewares)
+
+	output, err := os.ReadFile(filepath.Join(dstDir, "ingress.yml"))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(output), "address: http://waf.security")
+	assert.Contains(t, string(output), "- websecure")
+	assert.Contains(t, string(output), "middlewares:\n    - name: waf\n      namespace: testing")
+}
+
+func TestLoadPlugins_invalid(t *testing.T) {
+	pluginsDir := t.TempDir()
+
+	plugin := `package waf
+
+func Handle(annotations map[string]string) string {
+	return ""
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(pluginsDir, "waf.go"), []byte(plugin), 0644))
+
+	_, err := LoadPlugins(pluginsDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plugin waf.go: invalid Handle function")
+}
+
+func TestConvertStream_dualAPIVersion(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("fixtures", "input", "ingress_with_whitelist.yml"))
+	require.NoError(t, err)
+
+	output := &strings.Builder{}
+
+	_, err = ConvertStream(strings.NewReader(string(content)), output, Options{Notes: io.Discard, DualAPIVersion: true})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 4)
+
+	assert.Contains(t, documents[0], "apiVersion: traefik.containo.us/v1alpha1\nkind: IngressRoute")
+	assert.Contains(t, documents[1], "ipWhiteList:")
+	assert.Contains(t, documents[2], "apiVersion: traefik.io/v1alpha1\nkind: IngressRoute")
+	assert.Contains(t, documents[3], "ipAllowList:")
+}
+
+func TestConvertStream_outputKind(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("fixtures", "input", "ingress_with_whitelist.yml"))
+	require.NoError(t, err)
+
+	expected := &strings.Builder{}
+	_, err = ConvertStream(strings.NewReader(string(content)), expected, Options{Notes: io.Discard})
+	require.NoError(t, err)
+
+	output := &strings.Builder{}
+	_, err = ConvertStream(strings.NewReader(string(content)), output, Options{Notes: io.Discard, OutputKind: OutputKindIngressRoute})
+	require.NoError(t, err)
+
+	assert.Equal(t, expected.String(), output.String())
+	assert.NotContains(t, output.String(), "kind: Ingress\n")
+
+	_, err = ConvertStream(strings.NewReader(string(content)), io.Discard, Options{Notes: io.Discard, OutputKind: "service"})
+	require.Error(t, err)
+	assert.Equal(t, `unsupported output kind "service": ingressroute, gateway`, err.Error())
+}
+
+func TestConvertStream_gateway(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("fixtures", "input", "ingress.yml"))
+	require.NoError(t, err)
+
+	output := &strings.Builder{}
+	notes := &strings.Builder{}
+
+	gateway := &Gateway{Namespace: "traefik", Name: "public", ClassName: "traefik", Port: 8000}
+
+	_, err = ConvertStream(strings.NewReader(string(content)), output, Options{Notes: notes, OutputKind: OutputKindGateway, Gateway: gateway})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Equal(t, `apiVersion: networking.x-k8s.io/v1alpha1
+kind: HTTPRoute
+metadata:
+  name: test
+  namespace: testing
+spec:
+  gateways:
+    allow: FromList
+    gatewayRefs:
+    - name: public
+      namespace: traefik
+  hostnames:
+  - traefik.tchouk
+  rules:
+  - forwardTo:
+    - port: 80
+      serviceName: service1
+    matches:
+    - path:
+        type: Prefix
+        value: /bar
+  - forwardTo:
+    - port: 80
+      serviceName: service1
+    matches:
+    - path:
+        type: Prefix
+        value: /foo
+`, documents[0])
+
+	assert.Equal(t, `apiVersion: networking.x-k8s.io/v1alpha1
+kind: Gateway
+metadata:
+  name: public
+  namespace: traefik
+spec:
+  gatewayClassName: traefik
+  listeners:
+  - port: 8000
+    protocol: HTTP
+    routes:
+      kind: HTTPRoute
+      namespaces:
+        from: All
+`, documents[1])
+
+	assert.Contains(t, notes.String(), "testing/test: The entry points web are replaced by the listener of the Gateway traefik/public.\n")
+	assert.Contains(t, notes.String(), "testing/test: The priority 10 of the route \"Host(`traefik.tchouk`) && PathPrefix(`/bar`)\" is not supported by the HTTPRoutes.\n")
+}
+
+func TestConvertStream_gatewayFilters(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/custom-request-headers: "X-Forwarded-Prefix:/api||X-Debug:"
+    ingress.kubernetes.io/whitelist-source-range: 10.0.0.0/8
+spec:
+  rules:
+    - host: a.example.com
+      http:
+        paths:
+          - path: /api
+            backend:
+              serviceName: api
+              servicePort: 8080
+    - host: b.example.com
+      http:
+        paths:
+          - backend:
+              serviceName: web
+              servicePort: 80
+`
+
+	output := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{Notes: io.Discard, OutputKind: OutputKindGateway})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 4)
+
+	// One HTTPRoute per host.
+	assert.Contains(t, documents[0], "name: whoami-0\n")
+	assert.Contains(t, documents[0], "hostnames:\n  - a.example.com\n")
+	assert.Contains(t, documents[0], `    - requestHeaderModifier:
+        add:
+          X-Forwarded-Prefix: /api
+        remove:
+        - X-Debug
+      type: RequestHeaderModifier
+`)
+	assert.Contains(t, documents[0], "type: ExtensionRef\n")
+	assert.Contains(t, documents[1], "name: whoami-1\n")
+	assert.Contains(t, documents[1], "value: /\n")
+
+	// The headers middleware is replaced by the filter, the whitelist middleware is kept.
+	assert.Contains(t, documents[2], "kind: Middleware")
+	assert.Contains(t, documents[2], "ipWhiteList:")
+	assert.Contains(t, documents[3], "kind: Gateway")
+}
+
+func TestConvert_gateway(t *testing.T) {
+	dstDir := t.TempDir()
+
+	_, err := Convert(filepath.Join("fixtures", "input", "ingress.yml"), dstDir, Options{Notes: io.Discard, OutputKind: OutputKindGateway})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dstDir, gatewayFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "name: traefik-gateway\n")
+
+	content, err = os.ReadFile(filepath.Join(dstDir, "ingress.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "kind: HTTPRoute\n")
+
+	_, err = Convert(filepath.Join("fixtures", "input", "ingress.yml"), t.TempDir(), Options{Notes: io.Discard, OutputKind: OutputKindGateway, DualAPIVersion: true})
+	require.Error(t, err)
+	assert.Equal(t, "the canary and dual API version conversions require the ingressroute output kind", err.Error())
+
+	_, err = Convert(filepath.Join("fixtures", "input", "ingress.yml"), t.TempDir(), Options{Notes: io.Discard, OutputKind: OutputKindGateway, Gateway: &Gateway{Name: "public"}})
+	require.Error(t, err)
+	assert.Equal(t, "the namespace, name, and class of the Gateway are required", err.Error())
+}
+
+func TestConvert_servicePatches(t *testing.T) {
+	dstDir := t.TempDir()
+
+	_, err := Convert(filepath.Join("fixtures", "input", "ingress_with_service_annotations.yml"), dstDir, Options{Notes: io.Discard, ServicePatches: true})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dstDir, "ingress_with_service_annotations.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "passHostHeader: false\n")
+	assert.Contains(t, string(content), "flushInterval: 10ms\n")
+	assert.Contains(t, string(content), "scheme: https\n")
+	assert.Contains(t, string(content), "sticky:\n        cookie:\n          name: sticky\n")
+
+	content, err = os.ReadFile(filepath.Join(dstDir, servicePatchesFile))
+	require.NoError(t, err)
+
+	expected := `apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    traefik.ingress.kubernetes.io/service.passhostheader: "false"
+    traefik.ingress.kubernetes.io/service.serversscheme: https
+    traefik.ingress.kubernetes.io/service.sticky.cookie: "true"
+    traefik.ingress.kubernetes.io/service.sticky.cookie.name: sticky
+  name: service1
+  namespace: testing
+---
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    traefik.ingress.kubernetes.io/service.passhostheader: "false"
+    traefik.ingress.kubernetes.io/service.serversscheme: https
+    traefik.ingress.kubernetes.io/service.sticky.cookie: "true"
+    traefik.ingress.kubernetes.io/service.sticky.cookie.name: sticky
+  name: service2
+  namespace: testing
+`
+	assert.Equal(t, expected, string(content))
+
+	_, err = ConvertStream(strings.NewReader(""), io.Discard, Options{ServicePatches: true})
+	require.Error(t, err)
+}
+
+func TestConvertStream_syncWaves(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("fixtures", "input", "ingress_with_whitelist.yml"))
+	require.NoError(t, err)
+
+	output := &strings.Builder{}
+
+	_, err = ConvertStream(strings.NewReader(string(content)), output, Options{Notes: io.Discard, SyncWaves: true})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[0], "kind: IngressRoute")
+	assert.Contains(t, documents[0], "argocd.argoproj.io/sync-wave: \"1\"")
+	assert.Contains(t, documents[1], "kind: Middleware")
+	assert.Contains(t, documents[1], "argocd.argoproj.io/sync-wave: \"-1\"")
+}
+
+func TestConvertStream_errorPages(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/error-pages: |
+      notfound:
+        status:
+        - "404"
+        backend: errors
+        query: /{status}.html
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+      - path: /errors
+        backend:
+          serviceName: errors
+          servicePort: 8080
+`
+
+	output := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{Notes: io.Discard})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[0], "- name: errors-notfound-")
+	assert.Contains(t, documents[1], "errors:")
+	assert.Contains(t, documents[1], "query: /{status}.html")
+	assert.Contains(t, documents[1], "name: errors\n")
+	assert.Contains(t, documents[1], "port: 8080")
+}
+
+func TestConvertStream_errorPagesPort(t *testing.T) {
+	content := `apiVersion: v1
+kind: Service
+metadata:
+  name: errors
+  namespace: testing
+spec:
+  ports:
+  - name: http
+    port: 8081
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/error-pages: |
+      notfound:
+        status:
+        - "404"
+        backend: errors
+        query: /{status}.html
+      unavailable:
+        status:
+        - "503"
+        backend: maintenance
+        query: /maintenance.html
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+	notes := &strings.Builder{}
+
+	report, err := ConvertStream(strings.NewReader(content), output, Options{Notes: notes})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 4)
+
+	assert.Contains(t, documents[2], "name: errors\n      namespace: testing\n      port: 8081\n")
+	assert.Contains(t, documents[3], "name: maintenance\n      namespace: testing\n      port: 0\n")
+	assert.Equal(t, "testing/whoami: The port of the service maintenance of the error page unavailable is unknown, the port of its errors middleware must be set: "+
+		"the service is neither a backend of the Ingress, nor a service with a single port of the input.\n", notes.String())
+
+	require.Len(t, report.Ingresses, 1)
+	assert.Equal(t, []string{"The port of the service maintenance of the error page unavailable is unknown, the port of its errors middleware must be set: " +
+		"the service is neither a backend of the Ingress, nor a service with a single port of the input."}, report.Ingresses[0].Notes)
+}
+
+func TestConvertStream_rateLimit(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/rate-limit: |
+      extractorfunc = "request.host"
+      [rateset.fast]
+        period = "500ms"
+        average = 5
+        burst = 10
+      [rateset.slow]
+        period = "10s"
+        average = 25
+        burst = 50
+      [rateset.none]
+        average = 1
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+	notes := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{Notes: notes})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 3)
+
+	assert.Contains(t, documents[1], "name: middleware-fast-")
+	assert.Contains(t, documents[1], "average: 5\n")
+	assert.Contains(t, documents[1], "period: 500000000")
+	assert.Contains(t, documents[1], "requestHost: true")
+	assert.Contains(t, documents[2], "name: middleware-slow-")
+	assert.Contains(t, documents[2], "average: 25\n")
+	assert.Contains(t, documents[2], "period: 10000000000")
+
+	assert.Equal(t, `testing/whoami: The rate set none of the annotation ingress.kubernetes.io/rate-limit has no period, it is not converted.
+testing/whoami: The rate sets fast, slow of the annotation ingress.kubernetes.io/rate-limit are converted to chained rateLimit middlewares: unlike Traefik v1, a request rejected by one of them is still counted by the others.
+`, notes.String())
+}
+
+func TestConvertStream_digestAuth(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/auth-type: digest
+    ingress.kubernetes.io/auth-secret: %s
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+	notes := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(fmt.Sprintf(content, "testing/digest-users")), output, Options{Notes: notes})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[0], "- name: auth-")
+	assert.Contains(t, documents[1], "digestAuth:\n    secret: digest-users\n")
+	assert.Empty(t, notes.String())
+
+	output.Reset()
+
+	// The Ingress is kept instead of a route without authentication.
+	report, err := ConvertStream(strings.NewReader(fmt.Sprintf(content, "default/digest-users")), output, Options{Notes: notes})
+	require.NotNil(t, report)
+
+	var failed *FailedError
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, []string{"testing/whoami: the authentication cannot be converted: " +
+		"invalid ingress.kubernetes.io/auth-secret: the secret default/digest-users is not in the namespace testing of the Ingress"}, failed.Ingresses)
+
+	documents = splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 1)
+
+	assert.Contains(t, documents[0], "kind: Ingress\n")
+	assert.Contains(t, documents[0], "auth-secret: default/digest-users\n")
+	assert.Empty(t, report.Ingresses)
+	assert.Empty(t, notes.String())
+}
+
+func TestConvertStream_authHeaders(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/auth-type: %s
+    ingress.kubernetes.io/auth-url: https://auth.example.com
+    ingress.kubernetes.io/auth-secret: users
+    ingress.kubernetes.io/auth-response-headers: X-User, X-Email
+    ingress.kubernetes.io/auth-remove-header: "true"
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+	notes := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(fmt.Sprintf(content, "forward")), output, Options{Notes: notes})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[1], "authResponseHeaders:\n    - X-User\n    - X-Email\n")
+	assert.Equal(t, "testing/whoami: The annotation ingress.kubernetes.io/auth-remove-header is ignored by the forward authentication.\n", notes.String())
+
+	output.Reset()
+	notes.Reset()
+
+	_, err = ConvertStream(strings.NewReader(fmt.Sprintf(content, "basic")), output, Options{Notes: notes})
+	require.NoError(t, err)
+
+	documents = splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[1], "basicAuth:\n    removeHeader: true\n    secret: users\n")
+	assert.Equal(t, "testing/whoami: The annotation ingress.kubernetes.io/auth-response-headers is ignored by the basic authentication.\n", notes.String())
+}
+
+func TestConvertStream_invalidServiceWeights(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/service-weights: |
+      whoami-v1: 60%
+      whoami-v2: 60%
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami-v1
+          servicePort: 80
+      - path: /
+        backend:
+          serviceName: whoami-v2
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+
+	// The Ingress is kept instead of being dropped.
+	_, err := ConvertStream(strings.NewReader(content), output, Options{Notes: io.Discard})
+
+	var failed *FailedError
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, []string{"testing/whoami: invalid ingress.kubernetes.io/service-weights for the route \"Host(`whoami`) && PathPrefix(`/`)\": the weights exceed 100%"}, failed.Ingresses)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 1)
+
+	assert.Contains(t, documents[0], "kind: Ingress\n")
+	assert.Contains(t, documents[0], "whoami-v2: 60%")
+}
+
+func TestConvertStream_canary(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("fixtures", "input", "ingress.yml"))
+	require.NoError(t, err)
+
+	legacy, err := ParseService("kube-system/traefik-v1:80")
+	require.NoError(t, err)
+
+	canary := &Canary{EntryPoint: "canary", LegacyService: legacy, Weight: 10}
+	require.NoError(t, canary.Validate())
+
+	output := &strings.Builder{}
+
+	_, err = ConvertStream(strings.NewReader(string(content)), output, Options{Notes: io.Discard, Canary: canary})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 4)
+
+	// The Ingress is kept.
+	assert.Equal(t, strings.TrimSpace(string(content)), strings.TrimSpace(documents[0]))
+
+	assert.Contains(t, documents[1], "kind: IngressRoute")
+	assert.Contains(t, documents[1], "entryPoints:\n  - canary\n")
+	assert.Contains(t, documents[1], "- kind: TraefikService\n      name: test-canary-0\n      namespace: testing\n")
+
+	assert.Contains(t, documents[3], "kind: TraefikService")
+	assert.Contains(t, documents[3], "name: service1\n      namespace: testing\n      port: 80\n      weight: 10\n")
+	assert.Contains(t, documents[3], "name: traefik-v1\n      namespace: kube-system\n      port: 80\n      weight: 90\n")
+}
+
+func TestCanary_Validate(t *testing.T) {
+	legacy, err := ParseService("kube-system/traefik-v1:80")
+	require.NoError(t, err)
+
+	assert.Error(t, (&Canary{}).Validate())
+	assert.Error(t, (&Canary{LegacyService: legacy}).Validate())
+	assert.Error(t, (&Canary{LegacyService: legacy, Weight: 100}).Validate())
+	assert.NoError(t, (&Canary{EntryPoint: "canary"}).Validate())
+
+	for _, value := range []string{"traefik-v1:80", "kube-system/traefik-v1", "kube-system/traefik-v1:http", "/traefik-v1:80"} {
+		_, err = ParseService(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func Test_hasV1Annotations(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			desc: "without annotations",
+		},
+		{
+			desc:        "ingress class only",
+			annotations: map[string]string{annotationKubernetesIngressClass: "traefik"},
+		},
+		{
+			desc:        "Traefik v1 annotation",
+			annotations: map[string]string{annotationKubernetesRuleType: "PathPrefixStrip"},
+			expected:    true,
+		},
+		{
+			desc:        "Traefik v1 prefixed annotation",
+			annotations: map[string]string{"traefik.ingress.kubernetes.io/rule-type": "PathPrefixStrip"},
+			expected:    true,
+		},
+		{
+			desc:        "Traefik v1 label",
+			annotations: map[string]string{"traefik.frontend.priority": "10"},
+			expected:    true,
+		},
+		{
+			desc:        "Traefik v2 annotations",
+			annotations: map[string]string{"traefik.ingress.kubernetes.io/router.entrypoints": "websecure", "traefik.ingress.kubernetes.io/service.sticky.cookie": "true"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			ingress := &networking.Ingress{}
+			ingress.SetAnnotations(test.annotations)
+
+			assert.Equal(t, test.expected, hasV1Annotations(ingress))
+		})
+	}
+}
+
+func Test_getPriority(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		annotations map[string]string
+		expected    int
+		err         string
+	}{
+		{
+			desc: "without annotations",
+		},
+		{
+			desc:        "Traefik v1 annotation",
+			annotations: map[string]string{"traefik.ingress.kubernetes.io/priority": "10"},
+			expected:    10,
+		},
+		{
+			desc:        "Traefik v1 label",
+			annotations: map[string]string{"traefik.frontend.priority": "20"},
+			expected:    20,
+		},
+		{
+			desc:        "Traefik v2 annotation",
+			annotations: map[string]string{annotationRouterPriority: "30"},
+			expected:    30,
+		},
+		{
+			desc:        "Traefik v1 annotation before the Traefik v2 annotation",
+			annotations: map[string]string{annotationKubernetesPriority: "10", annotationRouterPriority: "30"},
+			expected:    10,
+		},
+		{
+			desc:        "invalid priority",
+			annotations: map[string]string{annotationKubernetesPriority: "high"},
+			err:         `invalid ingress.kubernetes.io/priority "high": a positive integer is expected`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			priority, err := getPriority(test.annotations)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, priority)
+		})
+	}
+}
+
+func Test_getAnnotationName(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		annotations map[string]string
+		expected    string
+	}{
+		{
+			desc:     "without annotations",
+			expected: annotationKubernetesRuleType,
+		},
+		{
+			desc:        "unprefixed annotation",
+			annotations: map[string]string{"ingress.kubernetes.io/rule-type": "PathPrefixStrip"},
+			expected:    "ingress.kubernetes.io/rule-type",
+		},
+		{
+			desc:        "prefixed annotation",
+			annotations: map[string]string{"traefik.ingress.kubernetes.io/rule-type": "PathPrefixStrip"},
+			expected:    "traefik.ingress.kubernetes.io/rule-type",
+		},
+		{
+			desc:        "Traefik v1 label",
+			annotations: map[string]string{"traefik.frontend.rule.type": "PathPrefixStrip"},
+			expected:    "traefik.frontend.rule.type",
+		},
+		{
+			desc: "unprefixed annotation before the prefixed annotation",
+			annotations: map[string]string{
+				"ingress.kubernetes.io/rule-type":         "PathPrefixStrip",
+				"traefik.ingress.kubernetes.io/rule-type": "PathStrip",
+			},
+			expected: "ingress.kubernetes.io/rule-type",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, getAnnotationName(test.annotations, annotationKubernetesRuleType))
+		})
+	}
+}
+
+func Test_getSecretName(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected string
+		err      string
+	}{
+		{
+			desc: "not set",
+		},
+		{
+			desc:     "name",
+			value:    "auth-secret",
+			expected: "auth-secret",
+		},
+		{
+			desc:     "name in the namespace of the Ingress",
+			value:    "testing/auth-secret",
+			expected: "auth-secret",
+		},
+		{
+			desc:  "name in another namespace",
+			value: "default/auth-secret",
+			err:   "the secret default/auth-secret is not in the namespace testing of the Ingress",
+		},
+		{
+			desc:  "invalid name",
+			value: "Auth_Secret",
+			err:   `invalid secret name "Auth_Secret": a DNS-1123 subdomain must consist of lower case alphanumeric characters`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			name, err := getSecretName("testing", test.value)
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, name)
+		})
+	}
+}
+
+func TestConvertStream(t *testing.T) {
+	input := `apiVersion: v1
+kind: Service
+metadata:
+  name: service1
+  namespace: testing
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: v2
+  namespace: testing
+  annotations:
+    traefik.ingress.kubernetes.io/router.entrypoints: websecure
+spec:
+  rules:
+  - host: v2.localhost
+    http:
+      paths:
+      - backend:
+          serviceName: service1
+          servicePort: 80
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: v1
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/request-modifier: "AddPrefix: /api"
+spec:
+  rules:
+  - host: v1.localhost
+    http:
+      paths:
+      - backend:
+          serviceName: service1
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+	notes := &strings.Builder{}
+
+	report, err := ConvertStream(strings.NewReader(input), output, Options{AnnotatedOnly: true, Notes: notes})
+	require.NoError(t, err)
+
+	require.Len(t, report.Ingresses, 1)
+	assert.Equal(t, "v1", report.Ingresses[0].Name)
+
+	assert.Contains(t, output.String(), "kind: Service\n")
+	assert.Contains(t, output.String(), "name: v2\n")
+	assert.Contains(t, output.String(), "kind: IngressRoute\n")
+	assert.Contains(t, output.String(), "kind: Middleware\n")
+	assert.Equal(t, 1, strings.Count(output.String(), "kind: Ingress\n"))
+	assert.Empty(t, notes.String())
+}
+
+// benchmarkIngresses returns a stream of n ingresses, each one converted to an IngressRoute and a middleware.
+func benchmarkIngresses(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		_, _ = fmt.Fprintf(&b, `---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami-%d
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/whitelist-source-range: 1.1.1.1/24
+spec:
+  rules:
+    - host: whoami-%d.example.com
+      http:
+        paths:
+          - backend:
+              serviceName: whoami
+              servicePort: 80
+            path: /
+`, i, i)
+	}
+
+	return b.String()
+}
+
+func BenchmarkConvertStream(b *testing.B) {
+	content := benchmarkIngresses(2000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := ConvertStream(strings.NewReader(content), io.Discard, Options{Notes: io.Discard})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_encodeYaml(b *testing.B) {
+	object := &v1alpha1.Middleware{
+		ObjectMeta: v1.ObjectMeta{Name: "whitelist", Namespace: "testing"},
+		Spec: v1alpha1.MiddlewareSpec{
+			IPWhiteList: &dynamic.IPWhiteList{SourceRange: []string{"1.1.1.1/24"}},
+		},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := encodeYaml(object, v1alpha1.GroupName+groupSuffix)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestConvertStream_entryPointMap(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/frontend-entry-points: http,https,admin
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{EntryPoints: map[string]string{"http": "web", "https": "websecure"}})
+	require.NoError(t, err)
+
+	assert.Contains(t, output.String(), "  entryPoints:\n  - web\n  - websecure\n  - admin\n")
+}
+
+func TestConvertStream_redirectEntryPoint(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/redirect-entry-point: %s
+    ingress.kubernetes.io/redirect-permanent: "true"
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	testCases := []struct {
+		desc        string
+		entryPoint  string
+		entryPoints map[string]string
+		scheme      string
+		notes       string
+	}{
+		{
+			desc:       "usual name",
+			entryPoint: "https",
+			scheme:     "https",
+		},
+		{
+			desc:        "mapped entry point",
+			entryPoint:  "insecure",
+			entryPoints: map[string]string{"insecure": "web"},
+			scheme:      "http",
+		},
+		{
+			desc:       "unknown entry point",
+			entryPoint: "secure",
+			scheme:     "https",
+			notes: "testing/whoami: The redirection to the entry point secure is converted to a redirection to https on the default port: " +
+				"map the entry point to web or websecure to change the scheme.\n",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			output := &strings.Builder{}
+			notes := &strings.Builder{}
+
+			_, err := ConvertStream(strings.NewReader(fmt.Sprintf(content, test.entryPoint)), output, Options{Notes: notes, EntryPoints: test.entryPoints})
+			require.NoError(t, err)
+
+			documents := splitDocuments([]byte(output.String()))
+			require.Len(t, documents, 2)
+
+			assert.Contains(t, documents[0], "- name: redirectscheme-")
+			assert.Contains(t, documents[1], fmt.Sprintf("redirectScheme:\n    permanent: true\n    scheme: %s\n", test.scheme))
+			assert.Equal(t, test.notes, notes.String())
+		})
+	}
+}
+
+func Test_getFrontendRedirect_permanent(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			desc:        "temporary redirect",
+			annotations: map[string]string{},
+		},
+		{
+			desc:        "Traefik v1 annotation",
+			annotations: map[string]string{"traefik.ingress.kubernetes.io/redirect-permanent": "true"},
+			expected:    true,
+		},
+		{
+			desc:        "Traefik v1 label",
+			annotations: map[string]string{"traefik.frontend.redirect.permanent": "true"},
+			expected:    true,
+		},
+		{
+			desc:        "explicit temporary redirect",
+			annotations: map[string]string{annotationKubernetesRedirectPermanent: "false"},
+		},
+	}
+
+	redirects := map[string]map[string]string{
+		"app-root":    {annotationKubernetesAppRoot: "/app"},
+		"entry point": {annotationKubernetesRedirectEntryPoint: "https"},
+		"regex":       {annotationKubernetesRedirectRegex: "^http://whoami/(.*)", annotationKubernetesRedirectReplacement: "https://whoami/$1"},
+	}
+
+	for _, test := range testCases {
+		for kind, redirect := range redirects {
+			test := test
+			annotations := map[string]string{}
+			for key, value := range redirect {
+				annotations[key] = value
+			}
+			for key, value := range test.annotations {
+				annotations[key] = value
+			}
+
+			t.Run(test.desc+" "+kind, func(t *testing.T) {
+				middleware := getFrontendRedirect("testing", annotations, "whoami/", "/", nil)
+				require.NotNil(t, middleware)
+
+				if middleware.Spec.RedirectScheme != nil {
+					assert.Equal(t, test.expected, middleware.Spec.RedirectScheme.Permanent)
+					return
+				}
+
+				require.NotNil(t, middleware.Spec.RedirectRegex)
+				assert.Equal(t, test.expected, middleware.Spec.RedirectRegex.Permanent)
+			})
+		}
+	}
+}
+
+func TestConvertStream_tlsOptions(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/frontend-entry-points: %s
+spec:
+  tls:
+  - hosts:
+    - whoami
+    secretName: whoami-cert
+  - hosts:
+    - whoami.example.com
+    secretName: example-cert
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	tlsOptions := map[string]v1alpha1.TLSOptionSpec{
+		"https":  {MinVersion: "VersionTLS12", ClientAuth: v1alpha1.ClientAuth{ClientAuthType: "NoClientCert"}},
+		"legacy": {MinVersion: "VersionTLS10", ClientAuth: v1alpha1.ClientAuth{ClientAuthType: "NoClientCert"}},
+	}
+
+	output := &strings.Builder{}
+	notes := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(fmt.Sprintf(content, "http,https")), output, Options{Notes: notes, TLSOptions: tlsOptions})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[0], "  tls:\n    domains:\n    - main: whoami\n    - main: whoami.example.com\n    options:\n      name: tls-")
+	assert.Contains(t, documents[0], "    secretName: whoami-cert\n")
+	assert.Contains(t, documents[1], "kind: TLSOption\n")
+	assert.Contains(t, documents[1], "  minVersion: VersionTLS12\n")
+	assert.Equal(t, "testing/whoami: The IngressRoute only references the secret whoami-cert: "+
+		"the certificates of the secrets example-cert must be referenced by a TLSStore or another IngressRoute.\n", notes.String())
+
+	output.Reset()
+	notes.Reset()
+
+	_, err = ConvertStream(strings.NewReader(fmt.Sprintf(content, "https,legacy")), output, Options{Notes: notes, TLSOptions: tlsOptions})
+	require.NoError(t, err)
+
+	documents = splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 1)
+
+	assert.NotContains(t, documents[0], "options:")
+	assert.Contains(t, notes.String(), "testing/whoami: The entry points https, legacy have different TLS options, the IngressRoute references no TLSOption.\n")
+}
+
+func TestConvertStream_ingressClass(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    kubernetes.io/ingress.class: traefik
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: whoami
+            port:
+              number: 80
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: nginx
+  namespace: testing
+  annotations:
+    kubernetes.io/ingress.class: nginx
+spec:
+  backend:
+    serviceName: nginx
+    servicePort: 80
+`
+
+	output := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{Notes: io.Discard, AnnotatedOnly: true, IngressClass: "traefik"})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 3)
+
+	assert.NotContains(t, documents[0], "kubernetes.io/ingress.class")
+	assert.NotContains(t, documents[0], "annotations:")
+	assert.Contains(t, documents[0], "  ingressClassName: traefik\n")
+	assert.Contains(t, documents[1], "kubernetes.io/ingress.class: nginx\n")
+	assert.NotContains(t, documents[1], "ingressClassName")
+	assert.Equal(t, "apiVersion: networking.k8s.io/v1\nkind: IngressClass\nmetadata:\n  name: traefik\nspec:\n  controller: traefik.io/ingress-controller\n", documents[2])
+}
+
+func Test_pathMatcher(t *testing.T) {
+	exact := networking.PathTypeExact
+	prefix := networking.PathTypePrefix
+	specific := networking.PathTypeImplementationSpecific
+
+	testCases := []struct {
+		desc        string
+		annotations map[string]string
+		pathType    *networking.PathType
+		expected    string
+	}{
+		{
+			desc:     "without path type",
+			expected: ruleTypePathPrefix,
+		},
+		{
+			desc:     "exact path type",
+			pathType: &exact,
+			expected: ruleTypePath,
+		},
+		{
+			desc:     "prefix path type",
+			pathType: &prefix,
+			expected: ruleTypePathPrefix,
+		},
+		{
+			desc:     "implementation specific path type",
+			pathType: &specific,
+			expected: ruleTypePathPrefix,
+		},
+		{
+			desc:        "rule-type annotation before the path type",
+			annotations: map[string]string{annotationKubernetesRuleType: ruleTypePath},
+			pathType:    &prefix,
+			expected:    ruleTypePath,
+		},
+		{
+			desc:        "modifier rule type with a value",
+			annotations: map[string]string{annotationKubernetesRuleType: "ReplacePath: /api"},
+			pathType:    &exact,
+			expected:    ruleTypePathPrefix,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			ruleType, _, err := extractRuleType(test.annotations)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, pathMatcher(test.annotations, ruleType, test.pathType))
+		})
+	}
+}
+
+func TestConvertStream_resourceBackend(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/frontend-entry-points: web
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /weighted
+        pathType: Prefix
+        backend:
+          resource:
+            apiGroup: traefik.containo.us
+            kind: TraefikService
+            name: weighted
+      - path: /static
+        pathType: Prefix
+        backend:
+          resource:
+            apiGroup: k8s.example.com
+            kind: StorageBucket
+            name: static-assets
+`
+
+	output := &strings.Builder{}
+	notes := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{Notes: notes})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 1)
+
+	assert.Contains(t, documents[0], "  - kind: Rule\n    match: Host(`whoami`) && PathPrefix(`/weighted`)\n")
+	assert.Contains(t, documents[0], "    - kind: TraefikService\n      name: weighted\n      namespace: testing\n")
+	assert.NotContains(t, documents[0], "/static")
+	assert.Equal(t, "testing/whoami: The paths of the resource backend StorageBucket static-assets are not converted: only the TraefikService resources are supported.\n", notes.String())
+}
+
+func TestConvertStream_namedPorts(t *testing.T) {
+	content := `apiVersion: v1
+kind: Service
+metadata:
+  name: whoami
+  namespace: testing
+spec:
+  ports:
+  - name: web
+    port: 8080
+    targetPort: http
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: whoami
+            port:
+              name: web
+      - path: /api
+        pathType: Prefix
+        backend:
+          service:
+            name: api
+            port:
+              name: http
+`
+
+	output := &strings.Builder{}
+	notes := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{Notes: notes, ResolveNamedPorts: true})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[1], "      name: whoami\n      namespace: testing\n      port: 8080\n")
+	assert.Contains(t, documents[1], "      name: api\n      namespace: testing\n      port: http\n")
+	assert.Equal(t, "testing/whoami: The port http of the service api is not resolved, the IngressRoute references it by name: Traefik v2.5 or later is required.\n", notes.String())
+
+	output.Reset()
+	notes.Reset()
+
+	_, err = ConvertStream(strings.NewReader(content), output, Options{Notes: notes})
+	require.NoError(t, err)
+
+	documents = splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[1], "      name: whoami\n      namespace: testing\n      port: web\n")
+}
+
+func TestConvertStream_externalName(t *testing.T) {
+	content := `apiVersion: v1
+kind: Service
+metadata:
+  name: external
+  namespace: testing
+spec:
+  type: ExternalName
+  externalName: example.com
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/protocol: https
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: external
+          servicePort: 443
+      - path: /api
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+	notes := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{Notes: notes})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	expected := "testing/whoami: The service external is an ExternalName service of example.com: allowExternalNameServices must be enabled on the Kubernetes CRD provider (Traefik v2.5 or later).\n" +
+		"testing/whoami: The requests to the ExternalName service external keep the Host header of the client: set ingress.kubernetes.io/preserve-host to false (passHostHeader) when example.com expects its own host name.\n"
+	assert.Equal(t, expected, notes.String())
+}
+
+func TestConvertStream_annotationPrefix(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    example.com/rule-type: PathPrefixStrip
+    example.com/priority: "10"
+    ingress.kubernetes.io/priority: "20"
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /api
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{AnnotationPrefix: "example.com/"})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[0], "    priority: 20\n")
+	assert.Contains(t, documents[1], "kind: Middleware\n")
+	assert.Contains(t, documents[1], "    prefixes:\n    - /api\n")
+}
+
+func TestConvertStream_hostless(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+spec:
+  rules:
+  - http:
+      paths:
+      - path: /api
+        backend:
+          serviceName: api
+          servicePort: 80
+      - backend:
+          serviceName: whoami
+          servicePort: 80
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	testCases := []struct {
+		desc     string
+		hostless string
+		expected []string
+		notes    string
+	}{
+		{
+			desc:     "catch-all",
+			expected: []string{"PathPrefix(`/api`)", "PathPrefix(`/`)", "Host(`whoami`) && PathPrefix(`/`)"},
+		},
+		{
+			desc:     "HostRegexp",
+			hostless: HostlessHostRegexp,
+			expected: []string{"HostRegexp(`{host:.+}`) && PathPrefix(`/api`)", "HostRegexp(`{host:.+}`)", "Host(`whoami`) && PathPrefix(`/`)"},
+		},
+		{
+			desc:     "skip",
+			hostless: HostlessSkip,
+			expected: []string{"Host(`whoami`) && PathPrefix(`/`)"},
+			notes:    "testing/whoami: The rules without host are skipped, the paths \"/api\", \"\" are not converted.\n",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			output := &strings.Builder{}
+			notes := &strings.Builder{}
+
+			_, err := ConvertStream(strings.NewReader(content), output, Options{Notes: notes, Hostless: test.hostless, Audit: true})
+			require.NoError(t, err)
+
+			object, err := parseYaml([]byte(output.String()))
+			require.NoError(t, err)
+
+			ingressRoute, ok := object.(*v1alpha1.IngressRoute)
+			require.True(t, ok)
+
+			var matches []string
+			for _, route := range ingressRoute.Spec.Routes {
+				matches = append(matches, route.Match)
+			}
+
+			assert.Equal(t, test.expected, matches)
+			assert.Equal(t, test.notes, notes.String())
+		})
+	}
+}
+
+func TestConvertStream_sslRedirect(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/ssl-redirect: "true"
+    ingress.kubernetes.io/ssl-host: secure.whoami
+    ingress.kubernetes.io/frame-deny: "true"
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+	notes := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{Notes: notes, SSLRedirect: SSLRedirectScheme})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 3)
+
+	assert.Contains(t, documents[1], "  headers:\n    frameDeny: true\n")
+	assert.NotContains(t, documents[1], "sslRedirect")
+	assert.Contains(t, documents[2], "  redirectScheme:\n    permanent: true\n    scheme: https\n")
+	assert.Equal(t, "testing/whoami: The redirection to the host secure.whoami is not converted: the RedirectScheme middleware redirects to the host of the request.\n", notes.String())
+
+	output.Reset()
+	notes.Reset()
+
+	_, err = ConvertStream(strings.NewReader(content), output, Options{Notes: notes})
+	require.NoError(t, err)
+
+	documents = splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[1], "    sslRedirect: true\n")
+	assert.Empty(t, notes.String())
+
+	output.Reset()
+	notes.Reset()
+
+	_, err = ConvertStream(strings.NewReader(content), output, Options{Notes: notes, SSLRedirectRef: "https-redirect@file"})
+	require.NoError(t, err)
+
+	documents = splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[0], "    - name: https-redirect@file\n")
+	assert.NotContains(t, documents[1], "sslRedirect")
+	assert.Equal(t, "testing/whoami: The redirection to the host secure.whoami is not converted: the middleware https-redirect@file redirects to the host of the request.\n", notes.String())
+}
+
+func TestConvertStream_middlewareNameTemplate(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/rule-type: PathPrefixStrip
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /api
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	tmpl, err := ParseMiddlewareNameTemplate("{{.Ingress}}-{{.Kind | lower}}-{{.Name}}")
+	require.NoError(t, err)
+
+	output := &strings.Builder{}
+
+	_, err = ConvertStream(strings.NewReader(content), output, Options{MiddlewareNameTemplate: tmpl})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[0], "    middlewares:\n    - name: whoami-stripprefix-whoami-api\n")
+	assert.Contains(t, documents[1], "  name: whoami-stripprefix-whoami-api\n")
+
+	tmpl, err = ParseMiddlewareNameTemplate("{{.Kind}}")
+	require.NoError(t, err)
+
+	_, err = ConvertStream(strings.NewReader(content), output, Options{MiddlewareNameTemplate: tmpl})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid middleware name "stripPrefix" for the middleware testing/whoami-api: `)
+}
+
+func TestConvertStream_dedupMiddlewares(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/rule-type: PathPrefixStrip
+    ingress.kubernetes.io/frame-deny: "true"
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /api
+        backend:
+          serviceName: whoami
+          servicePort: 80
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: other
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/rule-type: PathPrefixStrip
+    ingress.kubernetes.io/frame-deny: "true"
+spec:
+  rules:
+  - host: other
+    http:
+      paths:
+      - path: /api
+        backend:
+          serviceName: other
+          servicePort: 80
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: other
+  namespace: production
+  annotations:
+    ingress.kubernetes.io/frame-deny: "true"
+spec:
+  rules:
+  - host: other
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: other
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{DedupMiddlewares: true})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 6)
+
+	assert.Contains(t, documents[0], "  name: whoami\n")
+	assert.Contains(t, documents[1], "  headers:\n")
+	assert.Contains(t, documents[2], "  name: whoami-api\n")
+
+	assert.Contains(t, documents[3], "  name: other\n  namespace: testing\n")
+	assert.Contains(t, documents[3], "    - name: whoami-api\n")
+
+	assert.Contains(t, documents[4], "  name: other\n  namespace: production\n")
+	assert.Contains(t, documents[5], "  headers:\n")
+	assert.Contains(t, documents[5], "  namespace: production\n")
+}
+
+func TestConvertStream_middlewareCatalog(t *testing.T) {
+	catalog := `apiVersion: traefik.containo.us/v1alpha1
+kind: Middleware
+metadata:
+  name: strip-api
+  namespace: testing
+spec:
+  stripPrefix:
+    prefixes:
+    - /api
+---
+apiVersion: traefik.containo.us/v1alpha1
+kind: Middleware
+metadata:
+  name: frame-deny
+  namespace: production
+spec:
+  headers:
+    frameDeny: true
+`
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "middlewares.yml"), []byte(catalog), 0o600))
+
+	middlewares, err := LoadMiddlewareCatalog(dir)
+	require.NoError(t, err)
+	require.Len(t, middlewares, 2)
+
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/rule-type: PathPrefixStrip
+    ingress.kubernetes.io/frame-deny: "true"
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /api
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+
+	_, err = ConvertStream(strings.NewReader(content), output, Options{MiddlewareCatalog: middlewares})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	assert.Contains(t, documents[0], "    - name: strip-api\n")
+	assert.Contains(t, documents[1], "  headers:\n    frameDeny: true\n")
+}
+
+func TestConvertStream_middlewareCollisions(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: v1
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/rewrite-target: /v1
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /api
+        backend:
+          serviceName: whoami-v1
+          servicePort: 80
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: v2
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/rewrite-target: /v2
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /api
+        backend:
+          serviceName: whoami-v2
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 4)
+
+	assert.Contains(t, documents[0], "    - name: replace-path-whoami-api\n")
+	assert.Contains(t, documents[1], "  name: replace-path-whoami-api\n")
+	assert.Contains(t, documents[1], "    replacement: /v1$1\n")
+
+	assert.Contains(t, documents[2], "    - name: replace-path-whoami-api-d06c732e\n")
+	assert.Contains(t, documents[3], "  name: replace-path-whoami-api-d06c732e\n")
+	assert.Contains(t, documents[3], "    replacement: /v2$1\n")
+}
+
+func Test_normalizeObjectName(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		name     string
+		expected string
+	}{
+		{
+			desc:     "host and path",
+			name:     "replace-path-whoami.example.com/api/v1",
+			expected: "replace-path-whoami.example.com-api-v1",
+		},
+		{
+			desc:     "upper case and trailing separators",
+			name:     "Whoami.Example.com/API/",
+			expected: "whoami.example.com-api",
+		},
+		{
+			desc:     "empty labels and dashes",
+			name:     "whoami..example.com/-api-/_v1",
+			expected: "whoami.example.com-api-v1",
+		},
+		{
+			desc:     "long name",
+			name:     "replace-path-whoami.example.com/a/very/long/path/which/exceeds/the/length/of/a/label",
+			expected: "replace-path-whoami.example.com-a-very-long-path-which-921eaecc",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			name := normalizeObjectName(test.name)
+
+			assert.Equal(t, test.expected, name)
+			assert.LessOrEqual(t, len(name), 63)
+		})
+	}
+}
+
+func TestConvertStream_longNames(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/rule-type: PathPrefixStrip
+spec:
+  rules:
+  - host: whoami.example.com
+    http:
+      paths:
+      - path: /a/very/long/path/which/exceeds/the/length/of/a/label
+        backend:
+          serviceName: whoami
+          servicePort: 80
+      - path: /a/very/long/path/which/exceeds/the/length/of/a/label/too
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 3)
+
+	assert.Contains(t, documents[0], "    - name: whoami.example.com-a-very-long-path-which-exceeds-the-33a2078f\n")
+	assert.Contains(t, documents[0], "    - name: whoami.example.com-a-very-long-path-which-exceeds-the-d0e84ddc\n")
+	assert.Contains(t, documents[1], "  name: whoami.example.com-a-very-long-path-which-exceeds-the-33a2078f\n")
+	assert.Contains(t, documents[2], "  name: whoami.example.com-a-very-long-path-which-exceeds-the-d0e84ddc\n")
+}
+
+func TestConvert_deterministic(t *testing.T) {
+	src := filepath.Join("fixtures", "input")
+
+	first := t.TempDir()
+	_, err := Convert(src, first, Options{})
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		dstDir := t.TempDir()
+		_, err = Convert(src, dstDir, Options{})
+		require.NoError(t, err)
+
+		err = filepath.WalkDir(first, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+
+			name, err := filepath.Rel(first, path)
+			require.NoError(t, err)
+
+			expected, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			actual, err := os.ReadFile(filepath.Join(dstDir, name))
+			require.NoError(t, err)
+
+			assert.Equal(t, string(expected), string(actual), name)
+
+			return nil
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestConvertStream_keepMetadata(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: '{}'
+    ingress.kubernetes.io/frame-deny: "true"
+  creationTimestamp: "2018-02-27T10:03:59Z"
+  generation: 9
+  resourceVersion: "277178439"
+  uid: 87d8d657-1ba5-11e8-a9cd-06fa2d724cac
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+status:
+  loadBalancer: {}
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: other
+  namespace: testing
+  creationTimestamp: "2018-02-27T10:03:59Z"
+  resourceVersion: "277178440"
+spec:
+  rules:
+  - host: other
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: other
+          servicePort: 80
+status:
+  loadBalancer: {}
+`
+
+	testCases := []struct {
+		desc         string
+		keepMetadata bool
+	}{
+		{
+			desc: "server fields removed",
+		},
+		{
+			desc:         "server fields kept",
+			keepMetadata: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			output := &strings.Builder{}
+
+			_, err := ConvertStream(strings.NewReader(content), output, Options{AnnotatedOnly: true, KeepMetadata: test.keepMetadata})
+			require.NoError(t, err)
+
+			documents := splitDocuments([]byte(output.String()))
+			require.Len(t, documents, 3)
+
+			assert.Contains(t, documents[0], "kind: IngressRoute\n")
+			assert.Contains(t, documents[1], "kind: Middleware\n")
+			assert.Contains(t, documents[2], "kind: Ingress\n")
+
+			if test.keepMetadata {
+				assert.Contains(t, documents[0], "  creationTimestamp: null\n")
+				assert.Contains(t, documents[2], "  resourceVersion: \"277178440\"\n")
+				assert.Contains(t, documents[2], "status:\n")
+				return
+			}
+
+			for _, document := range documents {
+				assert.NotContains(t, document, "creationTimestamp")
+				assert.NotContains(t, document, "resourceVersion")
+				assert.NotContains(t, document, "status:")
+			}
+			assert.Contains(t, documents[2], "  name: other\n")
+		})
+	}
+}
+
+func TestConvertStream_comments(t *testing.T) {
+	content := `# Routes of the whoami application.
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    # The API is served without its prefix.
+    ingress.kubernetes.io/rule-type: PathPrefixStrip
+    ingress.kubernetes.io/frame-deny: "true" # required by the security review
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /api
+        backend:
+          serviceName: whoami
+          servicePort: 80
+---
+# Kept as is.
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: other
+  namespace: testing
+  resourceVersion: "277178440"
+  annotations:
+    # The ingress class of the other controller.
+    kubernetes.io/ingress.class: nginx
+spec:
+  rules:
+  - host: other
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: other
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{AnnotatedOnly: true})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 4)
+
+	assert.True(t, strings.HasPrefix(documents[0], "# Routes of the whoami application.\napiVersion: traefik.containo.us/v1alpha1\n"))
+	assert.Contains(t, documents[0], "  # The API is served without its prefix.\n  routes:\n")
+	assert.True(t, strings.HasPrefix(documents[1], "# required by the security review\n"))
+	assert.True(t, strings.HasPrefix(documents[2], "# The API is served without its prefix.\n"))
+
+	assert.True(t, strings.HasPrefix(documents[3], "# Kept as is.\n"))
+	assert.Contains(t, documents[3], "    # The ingress class of the other controller.\n    kubernetes.io/ingress.class: nginx\n")
+	assert.NotContains(t, documents[3], "resourceVersion")
+}
+
+func TestConvertStream_minimalDiff(t *testing.T) {
+	content := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/frame-deny: "true"
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+---
+kind: Service
+apiVersion: v1
+metadata:
+  name: whoami
+  namespace: testing
+spec:
+  ports:
+  - port: 80
+---
+kind: Ingress
+apiVersion: networking.k8s.io/v1beta1
+metadata:
+  namespace: testing
+  name: other
+  resourceVersion: "277178440"
+  annotations:
+    # Served by Traefik.
+    kubernetes.io/ingress.class: 'traefik'
+spec:
+  # The rules of the other application.
+  rules:
+  - host: 'other'
+    http:
+      paths:
+      - backend: {serviceName: other, servicePort: 80}
+status:
+  loadBalancer: {}
+`
+
+	output := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{AnnotatedOnly: true, IngressClass: "traefik", MinimalDiff: true})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 5)
+
+	assert.True(t, strings.HasPrefix(documents[0], "kind: Service\napiVersion: v1\n"))
+
+	expected := `kind: Ingress
+apiVersion: networking.k8s.io/v1beta1
+metadata:
+  namespace: testing
+  name: other
+spec:
+  ingressClassName: traefik
+  # The rules of the other application.
+  rules:
+  - host: 'other'
+    http:
+      paths:
+      - backend: {serviceName: other, servicePort: 80}
+`
+	assert.Equal(t, expected, documents[1])
+
+	assert.Contains(t, documents[2], "kind: IngressRoute\n")
+	assert.Contains(t, documents[3], "kind: Middleware\n")
+	assert.Contains(t, documents[4], "kind: IngressClass\n")
+}
+
+func TestConvertStream_wrapLists(t *testing.T) {
+	content := `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: whoami
+    namespace: testing
+  spec:
+    ports:
+    - port: 80
+- apiVersion: networking.k8s.io/v1beta1
+  kind: Ingress
+  metadata:
+    name: whoami
+    namespace: testing
+    annotations:
+      ingress.kubernetes.io/frame-deny: "true"
+  spec:
+    rules:
+    - host: whoami
+      http:
+        paths:
+        - path: /
+          backend:
+            serviceName: whoami
+            servicePort: 80
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: other
+  namespace: testing
+spec:
+  rules:
+  - host: other
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: other
+          servicePort: 80
+`
+
+	output := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(content), output, Options{WrapLists: true})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	list, err := createUnstructured([]byte(documents[0]))
+	require.NoError(t, err)
+	assert.True(t, list.IsList())
+
+	items, _, err := unstructured.NestedSlice(list.Object, "items")
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	var kinds []string
+	for _, item := range items {
+		kinds = append(kinds, item.(map[string]interface{})["kind"].(string))
+	}
+	assert.Equal(t, []string{"Service", "IngressRoute", "Middleware"}, kinds)
+
+	assert.Contains(t, documents[1], "kind: IngressRoute\n")
+	assert.Contains(t, documents[1], "  name: other\n")
+}
+
+func Test_fileFilter(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		include  []string
+		exclude  []string
+		path     string
+		dir      bool
+		expected bool
+	}{
+		{
+			desc:     "without patterns",
+			path:     "apps/ingress.json",
+			expected: true,
+		},
+		{
+			desc:     "included file name",
+			include:  []string{"*.yaml", "*.yml"},
+			path:     "apps/whoami/ingress.yml",
+			expected: true,
+		},
+		{
+			desc:    "file name not included",
+			include: []string{"*.yaml"},
+			path:    "apps/whoami/ingress.json",
+		},
+		{
+			desc:     "directory not filtered by the include patterns",
+			include:  []string{"*.yaml"},
+			path:     "apps",
+			dir:      true,
+			expected: true,
+		},
+		{
+			desc:    "excluded directory",
+			exclude: []string{"**/charts/**"},
+			path:    "apps/charts",
+			dir:     true,
+		},
+		{
+			desc:    "excluded top directory",
+			exclude: []string{"**/charts/**"},
+			path:    "charts",
+			dir:     true,
+		},
+		{
+			desc:    "excluded file of a directory",
+			exclude: []string{"apps/*/generated.yaml"},
+			path:    "apps/whoami/generated.yaml",
+		},
+		{
+			desc:     "file of a sub-directory not excluded",
+			exclude:  []string{"apps/*/generated.yaml"},
+			path:     "apps/whoami/overlays/generated.yaml",
+			expected: true,
+		},
+		{
+			desc:    "excluded before included",
+			include: []string{"*.yaml"},
+			exclude: []string{"generated-?.yaml"},
+			path:    "apps/generated-1.yaml",
+		},
+		{
+			desc:     "character class",
+			include:  []string{"ingress-[!0-9].yaml"},
+			path:     "ingress-a.yaml",
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			filter, err := newFileFilter("src", test.include, test.exclude)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, filter.match(filepath.Join("src", filepath.FromSlash(test.path)), test.dir))
+		})
+	}
+}
+
+func Test_newFileFilter_invalid(t *testing.T) {
+	_, err := newFileFilter("src", nil, []string{"ingress-[a.yaml"})
+	require.EqualError(t, err, `invalid exclude pattern "ingress-[a.yaml": unterminated character class at 8`)
+}
+
+func TestConvertStream_json(t *testing.T) {
+	content := `{
+    "apiVersion": "v1",
+    "kind": "List",
+    "items": [
+        {
+            "apiVersion": "networking.k8s.io/v1beta1",
+            "kind": "Ingress",
+            "metadata": {"name": "whoami", "namespace": "testing"},
+            "spec": {
+                "rules": [
+                    {
+                        "host": "whoami",
+                        "http": {"paths": [{"path": "/", "backend": {"serviceName": "whoami", "servicePort": 80}}]}
+                    }
+                ]
+            }
+        }
+    ]
+}
+`
+
+	testCases := []struct {
+		desc   string
+		format string
+		json   bool
+	}{
+		{
+			desc: "format of the input",
+			json: true,
+		},
+		{
+			desc:   "yaml",
+			format: ManifestFormatYAML,
+		},
+		{
+			desc:   "json",
+			format: ManifestFormatJSON,
+			json:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			output := &strings.Builder{}
+
+			_, err := ConvertStream(strings.NewReader(content), output, Options{ManifestFormat: test.format})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.json, isJSON([]byte(output.String())))
+			assert.Contains(t, output.String(), "Host(`whoami`) && PathPrefix(`/`)")
+
+			documents := splitDocuments([]byte(output.String()))
+			require.Len(t, documents, 1)
+
+			object, err := createUnstructured([]byte(documents[0]))
+			require.NoError(t, err)
+			assert.Equal(t, "IngressRoute", object.GetKind())
+		})
+	}
+}
+
+func Test_manifestFilename(t *testing.T) {
+	testCases := []struct {
+		filename string
+		format   string
+		expected string
+	}{
+		{filename: "ingress.json", format: ManifestFormatJSON, expected: "ingress.json"},
+		{filename: "ingress.json", format: ManifestFormatYAML, expected: "ingress.yml"},
+		{filename: "ingress.yaml", format: ManifestFormatJSON, expected: "ingress.json"},
+		{filename: "ingress.yml", format: ManifestFormatYAML, expected: "ingress.yml"},
+		{filename: "ingress", format: ManifestFormatJSON, expected: "ingress"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.filename+" "+test.format, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, manifestFilename(test.filename, test.format))
+		})
+	}
+}
+
+func TestConvertStream_marker(t *testing.T) {
+	ingress := func(name string) string {
+		return `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: ` + name + `
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/frame-deny: "true"
+spec:
+  rules:
+  - host: ` + name + `
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: ` + name + `
+          servicePort: 80
+`
+	}
+
+	output := &strings.Builder{}
+
+	_, err := ConvertStream(strings.NewReader(ingress("whoami")), output, Options{Marker: "v1.2.3"})
+	require.NoError(t, err)
+
+	documents := splitDocuments([]byte(output.String()))
+	require.Len(t, documents, 2)
+
+	for _, document := range documents {
+		object, err := createUnstructured([]byte(document))
+		require.NoError(t, err)
+		assert.Equal(t, "v1.2.3", object.GetAnnotations()[annotationConverted], object.GetKind())
+	}
+
+	// The partially migrated source holds the conversion of whoami, and the ingress other with the same middleware.
+	stamped := strings.Replace(ingress("stamped"), "  annotations:\n", "  annotations:\n    "+annotationConverted+": v1.0.0\n", 1)
+	source := output.String() + separator + "\n" + ingress("other") + separator + "\n" + stamped
+
+	output = &strings.Builder{}
+
+	_, err = ConvertStream(strings.NewReader(source), output, Options{})
+	require.NoError(t, err)
+
+	documents = splitDocuments([]byte(output.String()))
+
+	var kinds []string
+	for _, document := range documents {
+		object, err := createUnstructured([]byte(document))
+		require.NoError(t, err)
+		kinds = append(kinds, object.GetKind())
+	}
+
+	// The middleware of whoami is referenced by the route of other, and the stamped ingress is kept.
+	assert.Equal(t, []string{"IngressRoute", "Middleware", "IngressRoute", "Ingress"}, kinds)
+
+	middleware, err := createUnstructured([]byte(documents[1]))
+	require.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(output.String(), "name: "+middleware.GetName()))
+}
@@ -0,0 +1,70 @@
+package ingress
+
+import (
+	"sigs.k8s.io/yaml"
+)
+
+// ingressClassFile is the file of the IngressClass, in the output directory.
+const ingressClassFile = "ingress-class.yml"
+
+// ingressClassController is the controller of the IngressClass of Traefik.
+const ingressClassController = "traefik.io/ingress-controller"
+
+// ingressClassFragment returns the YAML of the IngressClass of Traefik.
+func ingressClassFragment(name string) (string, error) {
+	ingressClass := map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "IngressClass",
+		"metadata": map[string]interface{}{
+			"name": name,
+		},
+		"spec": map[string]interface{}{
+			"controller": ingressClassController,
+		},
+	}
+
+	data, err := yaml.Marshal(ingressClass)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// setIngressClassName replaces, in the YAML of an Ingress kept in the output, the kubernetes.io/ingress.class annotation by the spec.ingressClassName field,
+// when the annotation is the IngressClass of Traefik. The other documents are returned unchanged.
+func setIngressClassName(part, ingressClass string) (string, error) {
+	object, err := createUnstructured([]byte(part))
+	if err != nil {
+		return "", err
+	}
+
+	if object.GetKind() != "Ingress" {
+		return part, nil
+	}
+
+	annotations := object.GetAnnotations()
+	if annotations[annotationKubernetesIngressClass] != ingressClass {
+		return part, nil
+	}
+
+	delete(annotations, annotationKubernetesIngressClass)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	object.SetAnnotations(annotations)
+
+	spec, ok := object.Object["spec"].(map[string]interface{})
+	if !ok {
+		spec = map[string]interface{}{}
+		object.Object["spec"] = spec
+	}
+	spec["ingressClassName"] = ingressClass
+
+	data, err := yaml.Marshal(object.Object)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,96 @@
+package ingress
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Formats of the manifests.
+const (
+	// ManifestFormatYAML writes the manifests as a YAML stream.
+	ManifestFormatYAML = "yaml"
+	// ManifestFormatJSON writes the manifests as JSON: an object, or a List holding the objects when there are several.
+	ManifestFormatJSON = "json"
+)
+
+// ManifestFormats are the supported formats of the manifests.
+var ManifestFormats = []string{ManifestFormatYAML, ManifestFormatJSON}
+
+// isJSON returns whether the content of a manifest is JSON (ex: kubectl get -o json).
+func isJSON(content []byte) bool {
+	content = bytes.TrimSpace(content)
+	return len(content) > 0 && (content[0] == '{' || content[0] == '[')
+}
+
+// manifestFormat returns the format of the manifests converted from a source content: the ManifestFormat option, or the format of the content.
+func (c *converter) manifestFormat(content []byte) string {
+	if c.opts.ManifestFormat != "" {
+		return c.opts.ManifestFormat
+	}
+
+	if isJSON(content) {
+		return ManifestFormatJSON
+	}
+
+	return ManifestFormatYAML
+}
+
+// manifestFilename returns the name of a file of manifests written in a format: the extension of the YAML and JSON files is the one of the format.
+func manifestFilename(filename, format string) string {
+	ext := filepath.Ext(filename)
+
+	switch strings.ToLower(ext) {
+	case ".json":
+		if format == ManifestFormatYAML {
+			return strings.TrimSuffix(filename, ext) + ".yml"
+		}
+	case ".yml", ".yaml":
+		if format == ManifestFormatJSON {
+			return strings.TrimSuffix(filename, ext) + ".json"
+		}
+	}
+
+	return filename
+}
+
+// encodeFragments returns the content of a file holding the objects of fragments, in a format.
+func encodeFragments(fragments []string, format string) ([]byte, error) {
+	if format != ManifestFormatJSON {
+		return joinFragments(fragments), nil
+	}
+
+	if len(fragments) == 0 {
+		return nil, nil
+	}
+
+	fragment := fragments[0]
+	if len(fragments) > 1 {
+		// kubectl reads the objects of a JSON file from a List.
+		list, err := wrapList(fragments)
+		if err != nil {
+			return nil, err
+		}
+		fragment = list
+	}
+
+	object, err := createUnstructured([]byte(fragment))
+	if err != nil {
+		return nil, err
+	}
+
+	// The rules are not escaped (ex: && in a match).
+	data := &bytes.Buffer{}
+	encoder := json.NewEncoder(data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+
+	err = encoder.Encode(object.Object)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	return data.Bytes(), nil
+}
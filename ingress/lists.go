@@ -0,0 +1,77 @@
+package ingress
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// wrapLists wraps in a List the fragments of the documents extracted from the same List by expandContent:
+// the items of the List which are not ingresses, and the objects converted from its ingresses.
+// The starts are the indexes of the first fragment of each document, the other fragments are returned unchanged.
+func wrapLists(documents []document, starts []int, fragments []string) ([]string, error) {
+	// end returns the index following the last fragment of the documents before the document j.
+	end := func(j int) int {
+		if j < len(documents) {
+			return starts[j]
+		}
+		return len(fragments)
+	}
+
+	var wrapped []string
+	for i := 0; i < len(documents); {
+		// The documents extracted from a List follow each other.
+		j := i + 1
+		for documents[i].source != 0 && j < len(documents) && documents[j].source == documents[i].source {
+			j++
+		}
+
+		if documents[i].source == 0 {
+			wrapped = append(wrapped, fragments[starts[i]:end(j)]...)
+			i = j
+			continue
+		}
+
+		list, err := wrapList(fragments[starts[i]:end(j)])
+		if err != nil {
+			return nil, err
+		}
+
+		wrapped = append(wrapped, list)
+		i = j
+	}
+
+	return wrapped, nil
+}
+
+// wrapList returns the YAML of a List holding the objects of fragments, the items of the lists are added to the List.
+func wrapList(fragments []string) (string, error) {
+	items := []interface{}{}
+	for _, fragment := range fragments {
+		object, err := createUnstructured([]byte(fragment))
+		if err != nil {
+			return "", err
+		}
+
+		if !object.IsList() {
+			items = append(items, object.Object)
+			continue
+		}
+
+		listItems, _, err := unstructured.NestedSlice(object.Object, "items")
+		if err != nil {
+			return "", err
+		}
+		items = append(items, listItems...)
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,144 @@
+package ingress
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// annotationConverted is the annotation stamped with the Marker on the converted objects, and on the ingresses kept next to their conversion.
+const annotationConverted = "migration.traefik.io/converted"
+
+// isConverted returns whether an object is stamped by a previous conversion.
+func isConverted(object v1.Object) bool {
+	_, ok := object.GetAnnotations()[annotationConverted]
+	return ok
+}
+
+// setConvertedMarker stamps the converted objects with the marker.
+func setConvertedMarker(objects []runtime.Object, marker string) {
+	for _, object := range objects {
+		meta, ok := object.(v1.Object)
+		if !ok {
+			continue
+		}
+
+		annotations := meta.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[annotationConverted] = marker
+		meta.SetAnnotations(annotations)
+	}
+}
+
+// markedFragment returns the YAML of an Ingress kept next to its conversion, stamped with the Marker: it is not converted again by the next conversions.
+func (c *converter) markedFragment(fragment string) string {
+	if c.opts.Marker == "" {
+		return fragment
+	}
+
+	if c.opts.MinimalDiff {
+		if marked, ok := addField(fragment, []string{"metadata", "annotations"}, annotationConverted, c.opts.Marker); ok {
+			return marked
+		}
+	}
+
+	object, err := createUnstructured([]byte(fragment))
+	if err != nil {
+		return fragment
+	}
+
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[annotationConverted] = c.opts.Marker
+	object.SetAnnotations(annotations)
+
+	data, err := yaml.Marshal(object.Object)
+	if err != nil {
+		return fragment
+	}
+
+	return restoredFragment(fragment, string(data))
+}
+
+// addConverted records the middlewares stamped by a previous conversion: the routes of the converted ingresses reference them
+// instead of the converted middlewares with the same spec, and the converted middlewares with the same name and another spec are renamed.
+func (c *converter) addConverted(middlewares []*v1alpha1.Middleware) {
+	for key, name := range catalogMiddlewares(middlewares) {
+		if _, ok := c.middlewares[key]; !ok {
+			c.middlewares[key] = name
+		}
+	}
+
+	for _, middleware := range middlewares {
+		if c.written == nil {
+			c.written = middlewareNames{}
+		}
+
+		key := middleware.GetNamespace() + "/" + middleware.GetName()
+		if _, ok := c.written[key]; !ok {
+			c.written[key] = middlewareSpecKey(middleware.Spec)
+		}
+	}
+}
+
+// convertedMiddlewares returns the middlewares of manifests stamped by a previous conversion.
+func convertedMiddlewares(content []byte) []*v1alpha1.Middleware {
+	var middlewares []*v1alpha1.Middleware
+	for _, document := range splitDocuments(content) {
+		object, err := parseYaml([]byte(document))
+		if err != nil {
+			continue
+		}
+
+		if middleware, ok := object.(*v1alpha1.Middleware); ok && isConverted(middleware) {
+			middlewares = append(middlewares, middleware)
+		}
+	}
+
+	return middlewares
+}
+
+// loadConvertedMiddlewares reads the middlewares stamped by a previous conversion of the manifests of a file, or of the files of a directory selected by a filter.
+func loadConvertedMiddlewares(src string, filter *fileFilter) ([]*v1alpha1.Middleware, error) {
+	var middlewares []*v1alpha1.Middleware
+
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !filter.match(path, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		middlewares = append(middlewares, convertedMiddlewares(content)...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return middlewares, nil
+}
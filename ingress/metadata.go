@@ -0,0 +1,78 @@
+package ingress
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// annotationLastAppliedConfiguration is the annotation of the configuration applied by kubectl.
+const annotationLastAppliedConfiguration = "kubectl.kubernetes.io/last-applied-configuration"
+
+// serverMetadataFields are the metadata fields set by the API server.
+var serverMetadataFields = []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink"}
+
+// cleanMetadata removes, from the YAML of an object of a kind (any kind when empty), the fields set by the API server:
+// the status, the server metadata fields, and the last-applied-configuration annotation of kubectl.
+// The objects without these fields are returned unchanged, which keeps their formatting.
+func cleanMetadata(part, kind string) (string, error) {
+	object, err := createUnstructured([]byte(part))
+	if err != nil {
+		return "", err
+	}
+
+	if kind != "" && object.GetKind() != kind {
+		return part, nil
+	}
+
+	if !removeServerFields(object) {
+		return part, nil
+	}
+
+	data, err := yaml.Marshal(object.Object)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// serverFieldPaths returns the paths of the fields of an object set by the API server.
+// The creationTimestamp fields of the converted objects are null.
+func serverFieldPaths(object *unstructured.Unstructured) [][]string {
+	var paths [][]string
+
+	if _, ok := object.Object["status"]; ok {
+		paths = append(paths, []string{"status"})
+	}
+
+	metadata, ok := object.Object["metadata"].(map[string]interface{})
+	if !ok {
+		return paths
+	}
+
+	for _, field := range serverMetadataFields {
+		if _, ok := metadata[field]; ok {
+			paths = append(paths, []string{"metadata", field})
+		}
+	}
+
+	if _, ok := object.GetAnnotations()[annotationLastAppliedConfiguration]; ok {
+		paths = append(paths, []string{"metadata", "annotations", annotationLastAppliedConfiguration})
+	}
+
+	return paths
+}
+
+// removeServerFields removes the fields set by the API server from an object, it returns whether a field is removed.
+func removeServerFields(object *unstructured.Unstructured) bool {
+	paths := serverFieldPaths(object)
+	for _, path := range paths {
+		unstructured.RemoveNestedField(object.Object, path...)
+	}
+
+	if len(object.GetAnnotations()) == 0 {
+		object.SetAnnotations(nil)
+	}
+
+	return len(paths) > 0
+}
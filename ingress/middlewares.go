@@ -4,15 +4,20 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/mitchellh/hashstructure"
+	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
 	"gopkg.in/yaml.v2"
 	networking "k8s.io/api/networking/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // Rate holds a rate limiting configuration for a specific time period.
@@ -28,6 +33,13 @@ type RateLimit struct {
 	ExtractorFunc string           `json:"extractorFunc,omitempty"`
 }
 
+// ErrorPage holds an error pages configuration for a given frontend.
+type ErrorPage struct {
+	Status  []string `json:"status,omitempty"`
+	Backend string   `json:"backend,omitempty"`
+	Query   string   `json:"query,omitempty"`
+}
+
 // TLSClientHeaders holds the TLS client cert headers configuration.
 type TLSClientHeaders struct {
 	PEM   bool                       `description:"Enable header with escaped client pem" json:"pem"`
@@ -54,16 +66,16 @@ func (t *TLSClientHeaders) getPassTLSCert() *dynamic.PassTLSClientCert {
 				SerialNumber:    t.Infos.Issuer.SerialNumber,
 				DomainComponent: t.Infos.Issuer.DomainComponent,
 			}
-			if t.Infos.Subject != nil {
-				passTLS.Info.Subject = &dynamic.TLSCLientCertificateDNInfo{
-					Country:         t.Infos.Subject.Country,
-					Province:        t.Infos.Subject.Province,
-					Locality:        t.Infos.Subject.Locality,
-					Organization:    t.Infos.Subject.Organization,
-					CommonName:      t.Infos.Subject.CommonName,
-					SerialNumber:    t.Infos.Subject.SerialNumber,
-					DomainComponent: t.Infos.Subject.DomainComponent,
-				}
+		}
+		if t.Infos.Subject != nil {
+			passTLS.Info.Subject = &dynamic.TLSCLientCertificateDNInfo{
+				Country:         t.Infos.Subject.Country,
+				Province:        t.Infos.Subject.Province,
+				Locality:        t.Infos.Subject.Locality,
+				Organization:    t.Infos.Subject.Organization,
+				CommonName:      t.Infos.Subject.CommonName,
+				SerialNumber:    t.Infos.Subject.SerialNumber,
+				DomainComponent: t.Infos.Subject.DomainComponent,
 			}
 		}
 	}
@@ -91,7 +103,9 @@ type TLSCLientCertificateDNInfos struct {
 	DomainComponent bool `description:"Add Domain Component info in header" json:"domainComponent"`
 }
 
-func getHeadersMiddleware(ingress *networking.Ingress) *v1alpha1.Middleware {
+// getHeadersMiddleware returns the headers middleware of an Ingress, nil without headers annotations.
+// The SSL redirect options are set with sslRedirect only, when the ssl-redirect annotations are not converted into another middleware.
+func getHeadersMiddleware(ingress *networking.Ingress, sslRedirect bool) *v1alpha1.Middleware {
 	annotations := ingress.GetAnnotations()
 
 	headers := &dynamic.Headers{
@@ -117,6 +131,25 @@ func getHeadersMiddleware(ingress *networking.Ingress) *v1alpha1.Middleware {
 		PublicKey:               getStringValue(annotations, annotationKubernetesPublicKey, ""),
 		ReferrerPolicy:          getStringValue(annotations, annotationKubernetesReferrerPolicy, ""),
 		IsDevelopment:           getBoolValue(annotations, annotationKubernetesIsDevelopment, false),
+		FeaturePolicy:           getStringValue(annotations, annotationKubernetesFeaturePolicy, ""),
+	}
+
+	if !sslRedirect {
+		headers.SSLRedirect = false
+		headers.SSLTemporaryRedirect = false
+		headers.SSLHost = ""
+		headers.SSLForceHost = false
+	}
+
+	// The headers middleware has no permissions policy option, the header is added to the custom response headers.
+	if permissionsPolicy := getStringValue(annotations, annotationKubernetesPermissionsPolicy, ""); permissionsPolicy != "" {
+		if headers.CustomResponseHeaders == nil {
+			headers.CustomResponseHeaders = map[string]string{}
+		}
+
+		if _, ok := headers.CustomResponseHeaders["Permissions-Policy"]; !ok {
+			headers.CustomResponseHeaders["Permissions-Policy"] = permissionsPolicy
+		}
 	}
 
 	if !headers.HasCustomHeadersDefined() && !headers.HasCorsHeadersDefined() && !headers.HasSecureHeadersDefined() {
@@ -134,76 +167,177 @@ func getHeadersMiddleware(ingress *networking.Ingress) *v1alpha1.Middleware {
 	}
 }
 
-func getAuthMiddleware(ingress *networking.Ingress) *v1alpha1.Middleware {
+// getAuthMiddleware returns the middleware of the authentication annotations, nil without auth-type annotation.
+// An invalid authentication is an error: the routes cannot be converted without their protection.
+func getAuthMiddleware(ingress *networking.Ingress) (*v1alpha1.Middleware, error) {
+	middleware, err := getAuthConfig(ingress)
+	if err != nil {
+		return nil, fmt.Errorf("the authentication cannot be converted: %w", err)
+	}
+
+	if middleware == nil {
+		return nil, nil
+	}
+
+	hash, err := hashstructure.Hash(middleware, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return &v1alpha1.Middleware{
+		ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("%s-%d", "auth", hash), Namespace: ingress.GetNamespace()},
+		Spec:       *middleware,
+	}, nil
+}
+
+// getAuthConfig converts the authentication annotations, nil without auth-type annotation.
+func getAuthConfig(ingress *networking.Ingress) (*v1alpha1.MiddlewareSpec, error) {
 	authType := getStringValue(ingress.GetAnnotations(), annotationKubernetesAuthType, "")
 	if authType == "" {
-		return nil
+		return nil, nil
 	}
 
-	middleware := v1alpha1.MiddlewareSpec{}
+	middleware := &v1alpha1.MiddlewareSpec{}
 
 	switch strings.ToLower(authType) {
 	case "basic":
-		basic := getBasicAuthConfig(ingress.GetAnnotations())
+		basic, err := getBasicAuthConfig(ingress.GetNamespace(), ingress.GetAnnotations())
+		if err != nil {
+			return nil, err
+		}
 		middleware.BasicAuth = basic
 	case "digest":
-		digest := getDigestAuthConfig(ingress.GetAnnotations())
+		digest, err := getDigestAuthConfig(ingress.GetNamespace(), ingress.GetAnnotations())
+		if err != nil {
+			return nil, err
+		}
 		middleware.DigestAuth = digest
 	case "forward":
-		forward, err := getForwardAuthConfig(ingress.GetAnnotations())
+		forward, err := getForwardAuthConfig(ingress.GetNamespace(), ingress.GetAnnotations())
 		if err != nil {
-			log.Println(err)
-			return nil
+			return nil, err
 		}
 		middleware.ForwardAuth = forward
 	default:
+		return nil, fmt.Errorf("unsupported %s %q: basic, digest, forward", annotationKubernetesAuthType, authType)
+	}
+
+	return middleware, nil
+}
+
+// authNotes returns the notes about the authentication annotations ignored by the conversion, the invalid authentications fail the conversion of the Ingress.
+// As with Traefik v1, the response headers only apply to the forward authentication, and the header removal and header field to the basic and digest authentications.
+func authNotes(i *networking.Ingress) []string {
+	middleware, err := getAuthConfig(i)
+	if err != nil || middleware == nil {
 		return nil
 	}
 
-	hash, err := hashstructure.Hash(middleware, nil)
-	if err != nil {
-		panic(err)
+	ignored := []string{annotationKubernetesAuthForwardResponseHeaders}
+	if middleware.ForwardAuth != nil {
+		ignored = []string{annotationKubernetesAuthRemoveHeader, annotationKubernetesAuthHeaderField}
 	}
 
-	return &v1alpha1.Middleware{
-		ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("%s-%d", "auth", hash), Namespace: ingress.GetNamespace()},
-		Spec:       middleware,
+	var notes []string
+	for _, annotation := range presentAnnotations(i.GetAnnotations(), ignored...) {
+		notes = append(notes, fmt.Sprintf("The annotation %s is ignored by the %s authentication.", annotation, strings.ToLower(getStringValue(i.GetAnnotations(), annotationKubernetesAuthType, ""))))
 	}
+
+	return notes
 }
 
-func getBasicAuthConfig(annotations map[string]string) *v1alpha1.BasicAuth {
+func getBasicAuthConfig(namespace string, annotations map[string]string) (*v1alpha1.BasicAuth, error) {
+	secret, err := getAuthSecret(namespace, annotations)
+	if err != nil {
+		return nil, err
+	}
+
 	return &v1alpha1.BasicAuth{
-		Secret:       getStringValue(annotations, annotationKubernetesAuthSecret, ""),
+		Secret:       secret,
 		RemoveHeader: getBoolValue(annotations, annotationKubernetesAuthRemoveHeader, false),
 		HeaderField:  getStringValue(annotations, annotationKubernetesAuthHeaderField, ""),
-	}
+	}, nil
 }
 
-func getDigestAuthConfig(annotations map[string]string) *v1alpha1.DigestAuth {
+func getDigestAuthConfig(namespace string, annotations map[string]string) (*v1alpha1.DigestAuth, error) {
+	secret, err := getAuthSecret(namespace, annotations)
+	if err != nil {
+		return nil, err
+	}
+
 	return &v1alpha1.DigestAuth{
-		Secret:       getStringValue(annotations, annotationKubernetesAuthSecret, ""),
+		Secret:       secret,
 		RemoveHeader: getBoolValue(annotations, annotationKubernetesAuthRemoveHeader, false),
 		HeaderField:  getStringValue(annotations, annotationKubernetesAuthHeaderField, ""),
+	}, nil
+}
+
+// getAuthSecret returns the secret of the users of the basic and digest authentications, in the namespace of the Ingress.
+func getAuthSecret(namespace string, annotations map[string]string) (string, error) {
+	secret, err := getSecretName(namespace, getStringValue(annotations, annotationKubernetesAuthSecret, ""))
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", annotationKubernetesAuthSecret, err)
+	}
+
+	if secret == "" {
+		return "", fmt.Errorf("the %s authentication requires the %s annotation", getStringValue(annotations, annotationKubernetesAuthType, ""), annotationKubernetesAuthSecret)
 	}
+
+	return secret, nil
 }
 
-func getForwardAuthConfig(annotations map[string]string) (*v1alpha1.ForwardAuth, error) {
+// getForwardAuthConfig converts the forward authentication annotations.
+// The TLS configuration is only set with a client certificate secret, or to skip the verification of the authentication server certificate.
+func getForwardAuthConfig(namespace string, annotations map[string]string) (*v1alpha1.ForwardAuth, error) {
 	authURL := getStringValue(annotations, annotationKubernetesAuthForwardURL, "")
 	if authURL == "" {
 		return nil, fmt.Errorf("forward authentication requires a url")
 	}
 
-	return &v1alpha1.ForwardAuth{
+	forwardAuth := &v1alpha1.ForwardAuth{
 		Address:             authURL,
 		TrustForwardHeader:  getBoolValue(annotations, annotationKubernetesAuthForwardTrustHeaders, false),
 		AuthResponseHeaders: getSliceStringValue(annotations, annotationKubernetesAuthForwardResponseHeaders),
-		TLS: &v1alpha1.ClientTLS{
-			CASecret:           "",
-			CAOptional:         false,
-			CertSecret:         getStringValue(annotations, annotationKubernetesAuthForwardTLSSecret, ""),
-			InsecureSkipVerify: getBoolValue(annotations, annotationKubernetesAuthForwardTLSInsecure, false),
-		},
-	}, nil
+	}
+
+	certSecret, err := getSecretName(namespace, getStringValue(annotations, annotationKubernetesAuthForwardTLSSecret, ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", annotationKubernetesAuthForwardTLSSecret, err)
+	}
+
+	insecureSkipVerify := getBoolValue(annotations, annotationKubernetesAuthForwardTLSInsecure, false)
+
+	if certSecret != "" || insecureSkipVerify {
+		forwardAuth.TLS = &v1alpha1.ClientTLS{
+			CertSecret:         certSecret,
+			InsecureSkipVerify: insecureSkipVerify,
+		}
+	}
+
+	return forwardAuth, nil
+}
+
+// getSecretName returns the name of the secret of an annotation (<name> or <namespace>/<name>), empty when the annotation is not set.
+// The middlewares of the Traefik CRD provider only reference the secrets of their namespace, the namespace of the Ingress.
+func getSecretName(namespace, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	name := value
+	if parts := strings.Split(value, "/"); len(parts) == 2 {
+		if parts[0] != namespace {
+			return "", fmt.Errorf("the secret %s is not in the namespace %s of the Ingress", value, namespace)
+		}
+
+		name = parts[1]
+	}
+
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("invalid secret name %q: %s", value, strings.Join(errs, ", "))
+	}
+
+	return name, nil
 }
 
 func getWhiteList(ingress *networking.Ingress) *v1alpha1.Middleware {
@@ -222,6 +356,15 @@ func getWhiteList(ingress *networking.Ingress) *v1alpha1.Middleware {
 		middleware.IPWhiteList.IPStrategy = &dynamic.IPStrategy{}
 	}
 
+	depth := getInt64Value(ingress.GetAnnotations(), annotationKubernetesWhiteListIPStrategyDepth, 0)
+	excludedIPs := getSliceStringValue(ingress.GetAnnotations(), annotationKubernetesWhiteListIPStrategyExcludedIPs)
+	if depth > 0 || len(excludedIPs) > 0 {
+		middleware.IPWhiteList.IPStrategy = &dynamic.IPStrategy{
+			Depth:       int(depth),
+			ExcludedIPs: excludedIPs,
+		}
+	}
+
 	hash, err := hashstructure.Hash(middleware, nil)
 	if err != nil {
 		panic(err)
@@ -233,18 +376,45 @@ func getWhiteList(ingress *networking.Ingress) *v1alpha1.Middleware {
 	}
 }
 
-func getPassTLSClientCert(ingress *networking.Ingress) *v1alpha1.Middleware {
-	var passTLSClientCert *TLSClientHeaders
-
-	passRaw := getStringValue(ingress.GetAnnotations(), annotationKubernetesPassTLSClientCert, "")
-	if passRaw == "" {
+func getCircuitBreaker(ingress *networking.Ingress) *v1alpha1.Middleware {
+	expression := getStringValue(ingress.GetAnnotations(), annotationKubernetesCircuitBreakerExpression, "")
+	if expression == "" {
 		return nil
 	}
 
-	passTLSClientCert = &TLSClientHeaders{}
-	err := yaml.Unmarshal([]byte(passRaw), passTLSClientCert)
+	middleware := v1alpha1.MiddlewareSpec{
+		CircuitBreaker: &dynamic.CircuitBreaker{
+			Expression: expression,
+		},
+	}
+
+	hash, err := hashstructure.Hash(middleware, nil)
 	if err != nil {
-		log.Println(err)
+		panic(err)
+	}
+
+	return &v1alpha1.Middleware{
+		ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("%s-%d", "circuitbreaker", hash), Namespace: ingress.GetNamespace()},
+		Spec:       middleware,
+	}
+}
+
+func getPassTLSClientCert(ingress *networking.Ingress) *v1alpha1.Middleware {
+	passTLSClientCert := &TLSClientHeaders{}
+
+	passRaw := getStringValue(ingress.GetAnnotations(), annotationKubernetesPassTLSClientCert, "")
+	switch {
+	case passRaw != "":
+		err := yaml.Unmarshal([]byte(passRaw), passTLSClientCert)
+		if err != nil {
+			log.Println(err)
+			return nil
+		}
+	case getBoolValue(ingress.GetAnnotations(), annotationKubernetesPassTLSCert, false):
+		// The deprecated annotation only passes the PEM certificate.
+		passTLSClientCert.PEM = true
+	default:
+		return nil
 	}
 
 	middleware := v1alpha1.MiddlewareSpec{
@@ -262,10 +432,15 @@ func getPassTLSClientCert(ingress *networking.Ingress) *v1alpha1.Middleware {
 	}
 }
 
-func getFrontendRedirect(namespace string, annotations map[string]string, baseName, path string) *v1alpha1.Middleware {
+func getFrontendRedirect(namespace string, annotations map[string]string, baseName, path string, entryPoints map[string]string) *v1alpha1.Middleware {
 	permanent := getBoolValue(annotations, annotationKubernetesRedirectPermanent, false)
 
 	if appRoot := getStringValue(annotations, annotationKubernetesAppRoot, ""); appRoot != "" && (path == "/" || path == "") {
+		// The wildcard hosts are matched by the route: the root path of any host is redirected.
+		if isWildcardHost(baseName) {
+			return getRedirectMiddleware(namespace, anyHostRootRegex, fmt.Sprintf("${1}/%s", strings.TrimLeft(appRoot, "/")), permanent)
+		}
+
 		regex := fmt.Sprintf("%s$", baseName)
 		if path == "" {
 			regex = fmt.Sprintf("%s/$", baseName)
@@ -276,8 +451,7 @@ func getFrontendRedirect(namespace string, annotations map[string]string, baseNa
 
 	redirectEntryPoint := getStringValue(annotations, annotationKubernetesRedirectEntryPoint, "")
 	if len(redirectEntryPoint) > 0 {
-		log.Printf("EntryPoint redirect is not possible in v2")
-		return nil
+		return getRedirectSchemeMiddleware(namespace, entryPointScheme(redirectEntryPoint, entryPoints), permanent)
 	}
 
 	redirectRegex, err := getStringSafeValue(annotations, annotationKubernetesRedirectRegex, "")
@@ -299,6 +473,22 @@ func getFrontendRedirect(namespace string, annotations map[string]string, baseNa
 	return nil
 }
 
+// getDefaultBackendAppRoot returns the app-root redirect of the route of the default backend, nil without app-root annotation.
+// The route matches all the hosts: the root path of any host is redirected.
+func getDefaultBackendAppRoot(namespace string, annotations map[string]string) *v1alpha1.Middleware {
+	appRoot := getStringValue(annotations, annotationKubernetesAppRoot, "")
+	if appRoot == "" {
+		return nil
+	}
+
+	permanent := getBoolValue(annotations, annotationKubernetesRedirectPermanent, false)
+
+	return getRedirectMiddleware(namespace, anyHostRootRegex, fmt.Sprintf("${1}/%s", strings.TrimLeft(appRoot, "/")), permanent)
+}
+
+// anyHostRootRegex matches the root path of any host, the scheme and the host are the first group.
+const anyHostRootRegex = "^(https?://[^/]+)/$"
+
 func getRedirectMiddleware(namespace, regex, replacement string, permanent bool) *v1alpha1.Middleware {
 	middleware := v1alpha1.MiddlewareSpec{
 		RedirectRegex: &dynamic.RedirectRegex{
@@ -319,6 +509,136 @@ func getRedirectMiddleware(namespace, regex, replacement string, permanent bool)
 	}
 }
 
+// entryPointSchemes are the schemes of the usual Traefik v1 and Traefik v2 entry points.
+var entryPointSchemes = map[string]string{"http": "http", "web": "http", "https": "https", "websecure": "https"}
+
+// mapEntryPoints returns the Traefik v2 names of Traefik v1 entry points, the entry points which are not mapped keep their name.
+func mapEntryPoints(entryPoints []string, mapping map[string]string) []string {
+	if len(mapping) == 0 {
+		return entryPoints
+	}
+
+	names := make([]string, 0, len(entryPoints))
+	for _, entryPoint := range entryPoints {
+		if name, ok := mapping[entryPoint]; ok {
+			entryPoint = name
+		}
+
+		names = append(names, entryPoint)
+	}
+
+	return names
+}
+
+// entryPointScheme returns the scheme of the redirections to an entry point: the scheme of the entry point,
+// or of the Traefik v2 entry point it is mapped to, when it has a usual name, https otherwise.
+func entryPointScheme(entryPoint string, entryPoints map[string]string) string {
+	if scheme, ok := entryPointSchemes[entryPoint]; ok {
+		return scheme
+	}
+
+	if scheme, ok := entryPointSchemes[entryPoints[entryPoint]]; ok {
+		return scheme
+	}
+
+	return "https"
+}
+
+// redirectEntryPointNotes returns the note about the redirection to an entry point with an unusual name, redirected to https.
+// Traefik v2 redirects to a scheme instead of an entry point: the redirection uses the default port of the scheme.
+func redirectEntryPointNotes(i *networking.Ingress, entryPoints map[string]string) []string {
+	redirectEntryPoint := getStringValue(i.GetAnnotations(), annotationKubernetesRedirectEntryPoint, "")
+	if redirectEntryPoint == "" {
+		return nil
+	}
+
+	if _, ok := entryPointSchemes[redirectEntryPoint]; ok {
+		return nil
+	}
+
+	if _, ok := entryPointSchemes[entryPoints[redirectEntryPoint]]; ok {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("The redirection to the entry point %s is converted to a redirection to https on the default port: map the entry point to web or websecure to change the scheme.", redirectEntryPoint)}
+}
+
+// hasSSLRedirect returns whether an Ingress redirects the requests to https with the ssl-redirect annotations.
+func hasSSLRedirect(annotations map[string]string) bool {
+	return getBoolValue(annotations, annotationKubernetesSSLRedirect, false) || getBoolValue(annotations, annotationKubernetesSSLTemporaryRedirect, false)
+}
+
+// getSSLRedirect returns the RedirectScheme middleware to https of the ssl-redirect annotations converted with SSLRedirectScheme, nil otherwise.
+// As with Traefik v1, the redirection is temporary with ssl-temporary-redirect.
+func getSSLRedirect(ingress *networking.Ingress, opts Options) *v1alpha1.Middleware {
+	annotations := ingress.GetAnnotations()
+	if opts.SSLRedirect != SSLRedirectScheme || opts.SSLRedirectRef != "" || !hasSSLRedirect(annotations) {
+		return nil
+	}
+
+	temporary := getBoolValue(annotations, annotationKubernetesSSLTemporaryRedirect, false)
+
+	return getRedirectSchemeMiddleware(ingress.GetNamespace(), "https", !temporary)
+}
+
+// getSSLRedirectRef returns the reference to the SSLRedirectRef middleware of an Ingress with ssl-redirect annotations, nil otherwise.
+// The middlewares of another provider (ex: https-redirect@file) are referenced by name, the other middlewares by <namespace>/<name>,
+// in the namespace of the Ingress by default.
+func getSSLRedirectRef(ingress *networking.Ingress, ref string) *v1alpha1.MiddlewareRef {
+	if ref == "" || !hasSSLRedirect(ingress.GetAnnotations()) {
+		return nil
+	}
+
+	if strings.Contains(ref, "@") {
+		return &v1alpha1.MiddlewareRef{Name: ref}
+	}
+
+	if parts := strings.SplitN(ref, "/", 2); len(parts) == 2 {
+		return &v1alpha1.MiddlewareRef{Name: parts[1], Namespace: parts[0]}
+	}
+
+	return &v1alpha1.MiddlewareRef{Name: ref, Namespace: ingress.GetNamespace()}
+}
+
+// sslRedirectNotes returns the note about the ssl-host annotation which is not converted with SSLRedirectScheme or SSLRedirectRef:
+// the redirection does not change the host.
+func sslRedirectNotes(i *networking.Ingress, opts Options) []string {
+	if (opts.SSLRedirect != SSLRedirectScheme && opts.SSLRedirectRef == "") || !hasSSLRedirect(i.GetAnnotations()) {
+		return nil
+	}
+
+	sslHost := getStringValue(i.GetAnnotations(), annotationKubernetesSSLHost, "")
+	if sslHost == "" {
+		return nil
+	}
+
+	middleware := "the RedirectScheme middleware"
+	if opts.SSLRedirectRef != "" {
+		middleware = "the middleware " + opts.SSLRedirectRef
+	}
+
+	return []string{fmt.Sprintf("The redirection to the host %s is not converted: %s redirects to the host of the request.", sslHost, middleware)}
+}
+
+func getRedirectSchemeMiddleware(namespace, scheme string, permanent bool) *v1alpha1.Middleware {
+	middleware := v1alpha1.MiddlewareSpec{
+		RedirectScheme: &dynamic.RedirectScheme{
+			Scheme:    scheme,
+			Permanent: permanent,
+		},
+	}
+
+	hash, err := hashstructure.Hash(middleware, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return &v1alpha1.Middleware{
+		ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("%s-%d", "redirectscheme", hash), Namespace: namespace},
+		Spec:       middleware,
+	}
+}
+
 func parseRequestModifier(namespace, requestModifier string) (*v1alpha1.Middleware, error) {
 	trimmedRequestModifier := strings.TrimRight(requestModifier, " :")
 	if trimmedRequestModifier == "" {
@@ -374,8 +694,8 @@ func getRateLimit(i *networking.Ingress) []*v1alpha1.Middleware {
 	if rateRaw == "" {
 		return nil
 	}
-	rateLimit := &RateLimit{}
-	err := yaml.Unmarshal([]byte(rateRaw), rateLimit)
+
+	rateLimit, err := parseRateLimit(rateRaw)
 	if err != nil {
 		log.Println(err)
 		return nil
@@ -383,28 +703,25 @@ func getRateLimit(i *networking.Ingress) []*v1alpha1.Middleware {
 
 	var mids []*v1alpha1.Middleware
 	for rateSetKey, rateSet := range rateLimit.RateSet {
-		if rateSet.Period == 0 {
+		if rateSet.Period <= 0 {
 			continue
 		}
+
 		rateLimitMiddleware := v1alpha1.MiddlewareSpec{
 			RateLimit: &dynamic.RateLimit{
-				Average: rateSet.Average / int64(rateSet.Period/time.Second),
+				Average: rateSet.Average,
+				Period:  ptypes.Duration(rateSet.Period),
 				Burst:   rateSet.Burst,
 			},
 		}
 
-		if rateLimit.ExtractorFunc == "request.host" {
-			rateLimitMiddleware.RateLimit.SourceCriterion = &dynamic.SourceCriterion{
-				RequestHost: true,
-			}
+		// The average per second is kept when it is exact, the period is only needed otherwise.
+		if rateSet.Period%time.Second == 0 && rateSet.Average%int64(rateSet.Period/time.Second) == 0 {
+			rateLimitMiddleware.RateLimit.Average = rateSet.Average / int64(rateSet.Period/time.Second)
+			rateLimitMiddleware.RateLimit.Period = 0
 		}
 
-		if strings.HasPrefix(rateLimit.ExtractorFunc, "request.header.") {
-			sourceHeader := strings.ReplaceAll(rateLimit.ExtractorFunc, "request.header.", "")
-			rateLimitMiddleware.RateLimit.SourceCriterion = &dynamic.SourceCriterion{
-				RequestHeaderName: sourceHeader,
-			}
-		}
+		rateLimitMiddleware.RateLimit.SourceCriterion = getSourceCriterion(rateLimit.ExtractorFunc)
 
 		hash, err := hashstructure.Hash(rateLimitMiddleware, nil)
 		if err != nil {
@@ -420,20 +737,266 @@ func getRateLimit(i *networking.Ingress) []*v1alpha1.Middleware {
 	return mids
 }
 
+// parseRateLimit parses a rate-limit annotation, written in YAML as read by Traefik v1, or in TOML.
+func parseRateLimit(raw string) (*RateLimit, error) {
+	rateLimit := &RateLimit{}
+
+	err := yaml.Unmarshal([]byte(raw), rateLimit)
+	if err == nil {
+		return rateLimit, nil
+	}
+
+	content := map[string]interface{}{}
+	if _, errTOML := toml.Decode(raw, &content); errTOML != nil {
+		return nil, fmt.Errorf("invalid %s: %w", annotationKubernetesRateLimit, err)
+	}
+
+	// The TOML content is converted to YAML to read the periods as durations.
+	yml, err := yaml.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit = &RateLimit{}
+	if err = yaml.Unmarshal(yml, rateLimit); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", annotationKubernetesRateLimit, err)
+	}
+
+	return rateLimit, nil
+}
+
+// rateLimitNotes returns the notes about the rate sets of the rate-limit annotation which cannot be converted exactly.
+func rateLimitNotes(i *networking.Ingress) []string {
+	rateRaw := getStringValue(i.GetAnnotations(), annotationKubernetesRateLimit, "")
+	if rateRaw == "" {
+		return nil
+	}
+
+	rateLimit, err := parseRateLimit(rateRaw)
+	if err != nil {
+		return nil
+	}
+
+	var keys []string
+	for rateSetKey := range rateLimit.RateSet {
+		keys = append(keys, rateSetKey)
+	}
+	sort.Strings(keys)
+
+	var notes, converted []string
+	for _, rateSetKey := range keys {
+		if rateLimit.RateSet[rateSetKey].Period <= 0 {
+			notes = append(notes, fmt.Sprintf("The rate set %s of the annotation %s has no period, it is not converted.", rateSetKey, annotationKubernetesRateLimit))
+			continue
+		}
+
+		converted = append(converted, rateSetKey)
+	}
+
+	if len(converted) > 1 {
+		notes = append(notes, fmt.Sprintf("The rate sets %s of the annotation %s are converted to chained rateLimit middlewares: unlike Traefik v1, a request rejected by one of them is still counted by the others.", strings.Join(converted, ", "), annotationKubernetesRateLimit))
+	}
+
+	return notes
+}
+
+func getInFlightReq(ingress *networking.Ingress) *v1alpha1.Middleware {
+	amount := getInt64Value(ingress.GetAnnotations(), annotationKubernetesMaxConnAmount, 0)
+	if amount <= 0 {
+		return nil
+	}
+
+	middleware := v1alpha1.MiddlewareSpec{
+		InFlightReq: &dynamic.InFlightReq{
+			Amount:          amount,
+			SourceCriterion: getSourceCriterion(getStringValue(ingress.GetAnnotations(), annotationKubernetesMaxConnExtractorFunc, "")),
+		},
+	}
+
+	hash, err := hashstructure.Hash(middleware, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return &v1alpha1.Middleware{
+		ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("%s-%d", "inflightreq", hash), Namespace: ingress.GetNamespace()},
+		Spec:       middleware,
+	}
+}
+
+// getSourceCriterion converts a Traefik v1 extractor function (client.ip, request.host, request.header.<name>) to a source criterion,
+// nil for client.ip which is the default of Traefik v2.
+func getSourceCriterion(extractorFunc string) *dynamic.SourceCriterion {
+	switch {
+	case extractorFunc == "request.host":
+		return &dynamic.SourceCriterion{RequestHost: true}
+	case strings.HasPrefix(extractorFunc, "request.header."):
+		return &dynamic.SourceCriterion{RequestHeaderName: strings.TrimPrefix(extractorFunc, "request.header.")}
+	default:
+		return nil
+	}
+}
+
+// getErrorPages returns the errors middlewares of the error pages, the port of their service is not set when it is unknown (see errorPagesNotes).
+func getErrorPages(i *networking.Ingress, services inputServices) []*v1alpha1.Middleware {
+	errorPagesRaw := getStringValue(i.GetAnnotations(), annotationKubernetesErrorPages, "")
+	if errorPagesRaw == "" {
+		return nil
+	}
+
+	errorPages := map[string]*ErrorPage{}
+	err := yaml.Unmarshal([]byte(errorPagesRaw), errorPages)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	var mids []*v1alpha1.Middleware
+	for errorPageKey, errorPage := range errorPages {
+		if errorPage == nil || errorPage.Backend == "" {
+			continue
+		}
+
+		errorsMiddleware := v1alpha1.MiddlewareSpec{
+			Errors: &v1alpha1.ErrorPage{
+				Status: errorPage.Status,
+				Service: v1alpha1.Service{
+					LoadBalancerSpec: v1alpha1.LoadBalancerSpec{
+						Name:      errorPage.Backend,
+						Kind:      "Service",
+						Namespace: i.GetNamespace(),
+						Port:      getServicePort(i, errorPage.Backend, services),
+					},
+				},
+				Query: errorPage.Query,
+			},
+		}
+
+		hash, err := hashstructure.Hash(errorsMiddleware, nil)
+		if err != nil {
+			panic(err)
+		}
+
+		mids = append(mids, &v1alpha1.Middleware{
+			ObjectMeta: v1.ObjectMeta{Name: normalizeObjectName(fmt.Sprintf("%s-%s-%d", "errors", errorPageKey, hash)), Namespace: i.GetNamespace()},
+			Spec:       errorsMiddleware,
+		})
+	}
+
+	return mids
+}
+
+// getServicePort returns the port of a service: its port as a backend of the Ingress, the named ports are resolved with the services of the input,
+// or the port of the service of the input when it has a single port. It returns 0 when the port is unknown.
+func getServicePort(i *networking.Ingress, serviceName string, services inputServices) int32 {
+	for _, backend := range ingressBackends(i) {
+		if backend.ServiceName != serviceName {
+			continue
+		}
+
+		if port := services.port(i.GetNamespace(), *backend); port != 0 {
+			return port
+		}
+	}
+
+	if service := services.get(i.GetNamespace(), serviceName); service != nil && len(service.Spec.Ports) == 1 {
+		return service.Spec.Ports[0].Port
+	}
+
+	return 0
+}
+
+// errorPagesNotes returns the notes about the error pages whose service port is unknown: the port of their errors middleware must be set.
+func errorPagesNotes(i *networking.Ingress, services inputServices) []string {
+	errorPagesRaw := getStringValue(i.GetAnnotations(), annotationKubernetesErrorPages, "")
+	if errorPagesRaw == "" {
+		return nil
+	}
+
+	errorPages := map[string]*ErrorPage{}
+	err := yaml.Unmarshal([]byte(errorPagesRaw), errorPages)
+	if err != nil {
+		return nil
+	}
+
+	var notes []string
+	for key, errorPage := range errorPages {
+		if errorPage == nil || errorPage.Backend == "" || getServicePort(i, errorPage.Backend, services) != 0 {
+			continue
+		}
+
+		notes = append(notes, fmt.Sprintf("The port of the service %s of the error page %s is unknown, the port of its errors middleware must be set: "+
+			"the service is neither a backend of the Ingress, nor a service with a single port of the input.", errorPage.Backend, key))
+	}
+
+	sort.Strings(notes)
+
+	return notes
+}
+
+// getReplacePathRegex returns the middleware of a rewrite-target: the path prefix is replaced by the rewrite-target.
+// With capture group placeholders (ex: /$2), the path is a regex (ex: /api(/|$)(.*)), and the rewrite-target is the replacement of its match.
 func getReplacePathRegex(rule networking.IngressRule, path networking.HTTPIngressPath, namespace, rewriteTarget string) *v1alpha1.Middleware {
-	middlewareName := "replace-path-" + rule.Host + path.Path
+	middlewareName := "replace-path-" + objectBaseName(rule.Host, rewriteTargetPath(path.Path, rewriteTarget))
+
+	regex := fmt.Sprintf("^%s(.*)", path.Path)
+	replacement := fmt.Sprintf("%s$1", strings.TrimRight(rewriteTarget, "/"))
+
+	if exp := rewriteTargetRegex(path.Path, rewriteTarget); exp != nil {
+		regex, replacement = exp.String(), rewriteTarget
+		if exp.NumSubexp() == 0 {
+			regex += "(.*)"
+		}
+	}
 
 	return &v1alpha1.Middleware{
 		ObjectMeta: v1.ObjectMeta{Name: normalizeObjectName(middlewareName), Namespace: namespace},
 		Spec: v1alpha1.MiddlewareSpec{
 			ReplacePathRegex: &dynamic.ReplacePathRegex{
-				Regex:       fmt.Sprintf("^%s(.*)", path.Path),
-				Replacement: fmt.Sprintf("%s$1", strings.TrimRight(rewriteTarget, "/")),
+				Regex:       regex,
+				Replacement: replacement,
 			},
 		},
 	}
 }
 
+// captureGroupExp matches the capture group placeholders of a rewrite-target (ex: $1, ${2}).
+var captureGroupExp = regexp.MustCompile(`\$\{?[0-9]+\}?`)
+
+// rewriteTargetRegex returns the regex of a path rewritten by a rewrite-target with capture group placeholders,
+// nil without placeholders or when the path is not a valid regex.
+func rewriteTargetRegex(path, rewriteTarget string) *regexp.Regexp {
+	if !captureGroupExp.MatchString(rewriteTarget) {
+		return nil
+	}
+
+	exp, err := regexp.Compile("^" + path)
+	if err != nil {
+		return nil
+	}
+
+	return exp
+}
+
+// rewriteTargetPath returns the path matched by the route of a path rewritten by a rewrite-target:
+// the literal prefix of the paths with capture groups (ex: /api for /api(/|$)(.*)), the path otherwise.
+func rewriteTargetPath(path, rewriteTarget string) string {
+	exp := rewriteTargetRegex(path, rewriteTarget)
+	if exp == nil || exp.NumSubexp() == 0 {
+		return path
+	}
+
+	if i := strings.IndexAny(path, `\.+*?()|[]{}^$`); i >= 0 {
+		path = path[:i]
+	}
+
+	if path == "" {
+		return "/"
+	}
+
+	return path
+}
+
 func getStripPrefix(path networking.HTTPIngressPath, middlewareName, namespace string) *v1alpha1.Middleware {
 	return &v1alpha1.Middleware{
 		ObjectMeta: v1.ObjectMeta{Name: normalizeObjectName(middlewareName), Namespace: namespace},
@@ -0,0 +1,346 @@
+package ingress
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/mitchellh/hashstructure"
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// nameData is the data of the output name template of a converted object.
+type nameData struct {
+	Namespace string
+	Kind      string
+	Name      string
+	// File is the name of the source file of the object.
+	File string
+}
+
+// SplitNameTemplate is the output name template writing each object in its own file, named <namespace>-<kind>-<name>.yaml.
+const SplitNameTemplate = "{{.Namespace}}-{{.Kind | lower}}-{{.Name}}.yaml"
+
+// ParseNameTemplate parses a template of the paths of the output files, relative to the output directory.
+// The template is executed for each object with its Namespace (default when not set), Kind, Name, and source File,
+// and provides the lower and upper functions.
+func ParseNameTemplate(text string) (*template.Template, error) {
+	return template.New("output-name").
+		Funcs(template.FuncMap{"lower": strings.ToLower, "upper": strings.ToUpper}).
+		Option("missingkey=error").
+		Parse(text)
+}
+
+// outputName returns the path of the output file of an object, relative to the output directory.
+func outputName(tmpl *template.Template, source, content string) (string, error) {
+	object, err := createUnstructured([]byte(content))
+	if err != nil {
+		return "", err
+	}
+
+	data := nameData{
+		Namespace: object.GetNamespace(),
+		Kind:      object.GetKind(),
+		Name:      object.GetName(),
+		File:      filepath.Base(source),
+	}
+
+	if data.Namespace == "" {
+		data.Namespace = "default"
+	}
+
+	// The lists of the manifests don't always have a kind.
+	if data.Kind == "" && object.IsList() {
+		data.Kind = "List"
+	}
+
+	buf := &bytes.Buffer{}
+	err = tmpl.Execute(buf, data)
+	if err != nil {
+		return "", err
+	}
+
+	name := path.Clean(filepath.ToSlash(strings.TrimSpace(buf.String())))
+	if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return "", fmt.Errorf("invalid output name %q for %s %s/%s: the name must be a path in the output directory", buf.String(), data.Kind, data.Namespace, data.Name)
+	}
+
+	return filepath.FromSlash(name), nil
+}
+
+// middlewareNameData is the data of the middleware name template of a converted middleware.
+type middlewareNameData struct {
+	Namespace string
+	// Ingress is the name of the converted Ingress.
+	Ingress string
+	// Kind is the kind of the middleware, the name of its option (ex: stripPrefix).
+	Kind string
+	// Name is the name of the middleware given by the conversion.
+	Name string
+	// Hash is the hash of the spec of the middleware.
+	Hash string
+}
+
+// ParseMiddlewareNameTemplate parses a template of the names of the converted middlewares.
+// The template is executed for each middleware with its Namespace, Ingress, Kind, Name, and Hash,
+// and provides the lower and upper functions.
+func ParseMiddlewareNameTemplate(text string) (*template.Template, error) {
+	return template.New("middleware-name").
+		Funcs(template.FuncMap{"lower": strings.ToLower, "upper": strings.ToUpper}).
+		Option("missingkey=error").
+		Parse(text)
+}
+
+// renameMiddlewares names the middlewares converted from an Ingress with the middleware name template, and updates their references by the IngressRoutes.
+func renameMiddlewares(tmpl *template.Template, ingressName string, objects []runtime.Object) error {
+	if tmpl == nil {
+		return nil
+	}
+
+	names := map[string]string{}
+	specs := map[string]string{}
+	for _, object := range objects {
+		middleware, ok := object.(*v1alpha1.Middleware)
+		if !ok {
+			continue
+		}
+
+		name, err := middlewareName(tmpl, ingressName, middleware)
+		if err != nil {
+			return err
+		}
+
+		key := middleware.GetNamespace() + "/" + name
+		spec := middlewareSpecKey(middleware.Spec)
+		if known, ok := specs[key]; ok && known != spec {
+			return fmt.Errorf("the middleware name template gives the name %s to middlewares of the Ingress %s/%s with different specs", name, middleware.GetNamespace(), ingressName)
+		}
+		specs[key] = spec
+
+		names[middleware.GetNamespace()+"/"+middleware.GetName()] = name
+		middleware.SetName(name)
+	}
+
+	renameMiddlewareRefs(objects, names)
+
+	return nil
+}
+
+// renameMiddlewareRefs renames the middlewares referenced by the routes of IngressRoutes, the new names are by <namespace>/<name>.
+func renameMiddlewareRefs(objects []runtime.Object, names map[string]string) {
+	for _, object := range objects {
+		ingressRoute, ok := object.(*v1alpha1.IngressRoute)
+		if !ok {
+			continue
+		}
+
+		for i, route := range ingressRoute.Spec.Routes {
+			for j, ref := range route.Middlewares {
+				namespace := ref.Namespace
+				if namespace == "" {
+					namespace = ingressRoute.GetNamespace()
+				}
+
+				if name, ok := names[namespace+"/"+ref.Name]; ok {
+					ingressRoute.Spec.Routes[i].Middlewares[j].Name = name
+				}
+			}
+		}
+	}
+}
+
+// dedupMiddlewares removes, from the objects converted from an Ingress, the middlewares with the same spec as another middleware of the same namespace,
+// known (converted before, or from the middleware catalog) or, when shared, converted from the same Ingress: the routes reference this middleware instead.
+// The known middlewares, and the returned shared middlewares converted for the first time, are named by <namespace>/<spec key>.
+func dedupMiddlewares(known map[string]string, objects []runtime.Object, shared bool) ([]runtime.Object, map[string]string) {
+	added := map[string]string{}
+	names := map[string]string{}
+
+	var deduped []runtime.Object
+	for _, object := range objects {
+		middleware, ok := object.(*v1alpha1.Middleware)
+		if !ok {
+			deduped = append(deduped, object)
+			continue
+		}
+
+		key := middleware.GetNamespace() + "/" + middlewareSpecKey(middleware.Spec)
+
+		name, ok := known[key]
+		if !ok && shared {
+			name, ok = added[key]
+		}
+
+		if !ok {
+			if shared {
+				added[key] = middleware.GetName()
+			}
+			deduped = append(deduped, object)
+			continue
+		}
+
+		if name != middleware.GetName() {
+			names[middleware.GetNamespace()+"/"+middleware.GetName()] = name
+		}
+	}
+
+	renameMiddlewareRefs(deduped, names)
+
+	return deduped, added
+}
+
+// middlewareName returns the name of a middleware given by the middleware name template.
+func middlewareName(tmpl *template.Template, ingressName string, middleware *v1alpha1.Middleware) (string, error) {
+	hash, err := hashstructure.Hash(middleware.Spec, nil)
+	if err != nil {
+		return "", err
+	}
+
+	data := middlewareNameData{
+		Namespace: middleware.GetNamespace(),
+		Ingress:   ingressName,
+		Kind:      middlewareKind(middleware.Spec),
+		Name:      middleware.GetName(),
+		Hash:      strconv.FormatUint(hash, 10),
+	}
+
+	buf := &bytes.Buffer{}
+	err = tmpl.Execute(buf, data)
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSpace(buf.String())
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("invalid middleware name %q for the middleware %s/%s: %s", name, data.Namespace, data.Name, strings.Join(errs, ", "))
+	}
+
+	return name, nil
+}
+
+// middlewareKind returns the name of the option of a middleware spec (ex: stripPrefix),
+// the first name in alphabetical order for the specs with several options.
+func middlewareKind(spec v1alpha1.MiddlewareSpec) string {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+
+	options := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &options); err != nil {
+		return ""
+	}
+
+	kinds := make([]string, 0, len(options))
+	for kind := range options {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	if len(kinds) == 0 {
+		return ""
+	}
+
+	return kinds[0]
+}
+
+// middlewareNames holds the spec keys of middlewares by name.
+type middlewareNames map[string]string
+
+// unique renames a middleware with the name of a middleware of another spec (see collisionName), and records its name.
+func (n middlewareNames) unique(middleware *v1alpha1.Middleware) *v1alpha1.Middleware {
+	spec := middlewareSpecKey(middleware.Spec)
+	if known, ok := n[middleware.GetName()]; ok && known != spec {
+		middleware.SetName(collisionName(middleware))
+	}
+
+	n[middleware.GetName()] = spec
+
+	return middleware
+}
+
+// collisionName returns the name of a middleware colliding with a middleware of another spec: its name suffixed with a short hash of its spec.
+func collisionName(middleware *v1alpha1.Middleware) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(middlewareSpecKey(middleware.Spec)))
+
+	suffix := fmt.Sprintf("-%08x", hash.Sum32())
+
+	return shortenName(middleware.GetName(), validation.DNS1123SubdomainMaxLength-len(suffix)) + suffix
+}
+
+// resolveCollisions renames the middlewares converted from an Ingress with the name of a middleware of another spec written before (see collisionName),
+// and updates their references by the IngressRoutes. The written middlewares are by <namespace>/<name>.
+// It returns the notes about the renamed middlewares, and the middlewares of the Ingress by <namespace>/<name>, to record once written.
+func resolveCollisions(written middlewareNames, objects []runtime.Object) ([]string, middlewareNames) {
+	var notes []string
+	names := map[string]string{}
+	converted := middlewareNames{}
+
+	for _, object := range objects {
+		middleware, ok := object.(*v1alpha1.Middleware)
+		if !ok {
+			continue
+		}
+
+		key := middleware.GetNamespace() + "/" + middleware.GetName()
+		if known, ok := written[key]; ok && known != middlewareSpecKey(middleware.Spec) {
+			name := collisionName(middleware)
+			notes = append(notes, fmt.Sprintf("The middleware %s is renamed %s: a middleware with another spec has the same name.", middleware.GetName(), name))
+
+			names[key] = name
+			middleware.SetName(name)
+			key = middleware.GetNamespace() + "/" + name
+		}
+
+		converted[key] = middlewareSpecKey(middleware.Spec)
+	}
+
+	renameMiddlewareRefs(objects, names)
+
+	return notes, converted
+}
+
+// maxBaseNameLength is the maximum length of the names derived from the hosts and the paths of the rules, the length of a DNS label (RFC 1123).
+const maxBaseNameLength = validation.DNS1123LabelMaxLength
+
+// shortenName truncates a name longer than a maximum length, and suffixes it with a short hash of the full name, which keeps the truncated names distinct.
+func shortenName(name string, max int) string {
+	if len(name) <= max {
+		return name
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name))
+	suffix := fmt.Sprintf("-%08x", hash.Sum32())
+
+	return strings.TrimRight(name[:max-len(suffix)], "-.") + suffix
+}
+
+// validateObjectNames validates the names of the objects converted from an Ingress, which must be DNS subdomains (RFC 1123).
+// The objects without name, converted from an Ingress without name, are not validated.
+func validateObjectNames(ingress v1.Object, objects []runtime.Object) error {
+	for _, object := range objects {
+		accessor, ok := object.(v1.Object)
+		if !ok || accessor.GetName() == "" {
+			continue
+		}
+
+		if errs := validation.IsDNS1123Subdomain(accessor.GetName()); len(errs) > 0 {
+			return fmt.Errorf("invalid name %q of the %T converted from the Ingress %s/%s: %s",
+				accessor.GetName(), object, ingress.GetNamespace(), ingress.GetName(), strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
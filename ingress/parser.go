@@ -1,15 +1,21 @@
 package ingress
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
+	"sync"
+	"unicode"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
 	networking "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/scheme"
 )
 
@@ -27,24 +33,114 @@ func extensionsToNetworking(i proto.Marshaler) (*networking.Ingress, error) {
 	return ni, nil
 }
 
-func encodeYaml(object runtime.Object, groupName string) (string, error) {
-	err := v1alpha1.AddToScheme(scheme.Scheme)
-	if err != nil {
-		return "", err
+// networkingV1ToV1beta1 converts a networking/v1 Ingress to the networking/v1beta1 Ingress read by the converter.
+// The API versions have the same fields, except the backends: the service name and port of the v1 backends are moved to serviceName and servicePort.
+func networkingV1ToV1beta1(i *networkingv1.Ingress) *networking.Ingress {
+	ingress := &networking.Ingress{
+		TypeMeta:   i.TypeMeta,
+		ObjectMeta: i.ObjectMeta,
+		Spec: networking.IngressSpec{
+			IngressClassName: i.Spec.IngressClassName,
+			Backend:          backendToV1beta1(i.Spec.DefaultBackend),
+		},
+	}
+
+	for _, tls := range i.Spec.TLS {
+		ingress.Spec.TLS = append(ingress.Spec.TLS, networking.IngressTLS{Hosts: tls.Hosts, SecretName: tls.SecretName})
+	}
+
+	for _, rule := range i.Spec.Rules {
+		ingressRule := networking.IngressRule{Host: rule.Host}
+
+		if rule.HTTP != nil {
+			ingressRule.HTTP = &networking.HTTPIngressRuleValue{}
+
+			for _, path := range rule.HTTP.Paths {
+				ingressPath := networking.HTTPIngressPath{Path: path.Path, Backend: *backendToV1beta1(&path.Backend)}
+				if path.PathType != nil {
+					pathType := networking.PathType(*path.PathType)
+					ingressPath.PathType = &pathType
+				}
+
+				ingressRule.HTTP.Paths = append(ingressRule.HTTP.Paths, ingressPath)
+			}
+		}
+
+		ingress.Spec.Rules = append(ingress.Spec.Rules, ingressRule)
+	}
+
+	return ingress
+}
+
+func backendToV1beta1(backend *networkingv1.IngressBackend) *networking.IngressBackend {
+	if backend == nil {
+		return nil
+	}
+
+	ingressBackend := &networking.IngressBackend{Resource: backend.Resource}
+	if backend.Service != nil {
+		ingressBackend.ServiceName = backend.Service.Name
+
+		if backend.Service.Port.Name != "" {
+			ingressBackend.ServicePort = intstr.FromString(backend.Service.Port.Name)
+		} else {
+			ingressBackend.ServicePort = intstr.FromInt(int(backend.Service.Port.Number))
+		}
+	}
+
+	return ingressBackend
+}
+
+var (
+	// The Traefik types are registered in the scheme of the client once: the registration is not safe for concurrent use.
+	registerOnce sync.Once
+	registerErr  error
+
+	encodersMu sync.Mutex
+	// encoders are the YAML encoders by group version, reused by all the conversions.
+	encoders = map[string]runtime.Encoder{}
+
+	deserializer = scheme.Codecs.UniversalDeserializer()
+)
+
+// yamlEncoder returns the YAML encoder of a group version.
+func yamlEncoder(groupName string) (runtime.Encoder, error) {
+	registerOnce.Do(func() { registerErr = v1alpha1.AddToScheme(scheme.Scheme) })
+	if registerErr != nil {
+		return nil, registerErr
+	}
+
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+
+	if encoder, ok := encoders[groupName]; ok {
+		return encoder, nil
 	}
 
 	info, ok := runtime.SerializerInfoForMediaType(scheme.Codecs.SupportedMediaTypes(), "application/yaml")
 	if !ok {
-		return "", errors.New("unsupported media type application/yaml")
+		return nil, errors.New("unsupported media type application/yaml")
 	}
 
 	gv, err := schema.ParseGroupVersion(groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := scheme.Codecs.EncoderForVersion(info.Serializer, gv)
+	encoders[groupName] = encoder
+
+	return encoder, nil
+}
+
+func encodeYaml(object runtime.Object, groupName string) (string, error) {
+	encoder, err := yamlEncoder(groupName)
 	if err != nil {
 		return "", err
 	}
 
 	buffer := bytes.NewBuffer([]byte{})
-	err = scheme.Codecs.EncoderForVersion(info.Serializer, gv).Encode(object, buffer)
+	err = encoder.Encode(object, buffer)
 	if err != nil {
 		return "", err
 	}
@@ -52,12 +148,37 @@ func encodeYaml(object runtime.Object, groupName string) (string, error) {
 }
 
 func parseYaml(content []byte) (runtime.Object, error) {
-	decode := scheme.Codecs.UniversalDeserializer().Decode
-
-	obj, _, err := decode(content, nil, nil)
+	obj, _, err := deserializer.Decode(content, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error while decoding YAML object. Err was: %w", err)
 	}
 
 	return obj, nil
 }
+
+// splitDocuments returns the documents of a YAML stream, without the empty documents.
+func splitDocuments(content []byte) []string {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+
+	var documents []string
+	for {
+		// The reader of the content only fails at the end of the stream.
+		document, err := reader.Read()
+		if err != nil {
+			return documents
+		}
+
+		// The reader keeps the separators following an empty document (ex: a leading separator).
+		for {
+			index := bytes.IndexByte(document, '\n')
+			if index < 0 || string(bytes.TrimRightFunc(document[:index], unicode.IsSpace)) != separator {
+				break
+			}
+			document = document[index+1:]
+		}
+
+		if len(bytes.TrimSpace(document)) != 0 {
+			documents = append(documents, string(document))
+		}
+	}
+}
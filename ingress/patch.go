@@ -0,0 +1,172 @@
+package ingress
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// removeFields removes fields, with their values and their comments, from the YAML text of a document, which keeps the text of the other fields.
+// The mappings left without field are removed too.
+// It returns false when a field can't be removed from the text (ex: in a flow style mapping), the fields which are not in the document are ignored.
+func removeFields(part string, paths [][]string) (string, bool) {
+	for _, path := range paths {
+		var ok bool
+		part, ok = removeField(part, path)
+		if !ok {
+			return "", false
+		}
+	}
+
+	return part, true
+}
+
+// removeField removes a field from the YAML text of a document (see removeFields).
+func removeField(part string, path []string) (string, bool) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(part), &document); err != nil || len(document.Content) == 0 {
+		return "", false
+	}
+
+	lines := strings.Split(part, "\n")
+
+	// end is the last line of the field: the line before the next field of the innermost mapping with a next field.
+	end := len(lines)
+	node := document.Content[0]
+	var key *yaml.Node
+	for depth, elt := range path {
+		if node.Kind != yaml.MappingNode || node.Style&yaml.FlowStyle != 0 {
+			return "", false
+		}
+
+		index := -1
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == elt {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return part, true
+		}
+
+		// The mapping holding only the removed field is removed.
+		if depth == len(path)-1 && len(node.Content) == 2 && depth > 0 {
+			return removeField(part, path[:depth])
+		}
+
+		if index+2 < len(node.Content) {
+			next := node.Content[index+2]
+			end = next.Line - 1
+			if next.HeadComment != "" {
+				// The comment above the next field is kept.
+				end -= len(strings.Split(next.HeadComment, "\n"))
+			}
+		}
+
+		key = node.Content[index]
+		node = node.Content[index+1]
+	}
+
+	start := key.Line - 1
+	if key.HeadComment != "" {
+		start -= len(strings.Split(key.HeadComment, "\n"))
+	}
+
+	// The blank lines and the comments at the end of the document are kept.
+	for end > key.Line && isBlankOrComment(lines[end-1]) {
+		end--
+	}
+
+	return strings.Join(append(lines[:start:start], lines[end:]...), "\n"), true
+}
+
+// addField adds a field to a mapping of the YAML text of a document, above its first field, which keeps the text of the other fields.
+// It returns false when the field can't be added to the text (ex: to a flow style mapping, or to a mapping which is not in the document).
+func addField(part string, path []string, key, value string) (string, bool) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(part), &document); err != nil || len(document.Content) == 0 {
+		return "", false
+	}
+
+	node := lookupValue(document.Content[0], path)
+	if node == nil || node.Kind != yaml.MappingNode || node.Style&yaml.FlowStyle != 0 || len(node.Content) == 0 {
+		return "", false
+	}
+
+	data, err := yaml.Marshal(map[string]string{key: value})
+	if err != nil {
+		return "", false
+	}
+
+	first := node.Content[0]
+	line := first.Line - 1
+	if first.HeadComment != "" && len(path) > 0 {
+		line -= len(strings.Split(first.HeadComment, "\n"))
+	}
+
+	lines := strings.Split(part, "\n")
+	field := strings.Repeat(" ", first.Column-1) + strings.TrimSuffix(string(data), "\n")
+
+	return strings.Join(append(lines[:line:line], append([]string{field}, lines[line:]...)...), "\n"), true
+}
+
+// lookupValue returns the value of a field of a mapping from its path.
+func lookupValue(node *yaml.Node, path []string) *yaml.Node {
+	for _, elt := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == elt {
+				value = node.Content[i+1]
+				break
+			}
+		}
+
+		if value == nil {
+			return nil
+		}
+		node = value
+	}
+
+	return node
+}
+
+// isBlankOrComment returns whether a line is blank or a comment.
+func isBlankOrComment(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || strings.HasPrefix(line, "#")
+}
+
+// patchedFragment returns the YAML of an object kept in the output with the changes of keptFragment patched in its source text,
+// which keeps the order, the quoting and the comments of the other fields. It returns false when the changes can't be patched in the text.
+func (c *converter) patchedFragment(part string) (string, bool) {
+	object, err := createUnstructured([]byte(part))
+	if err != nil {
+		return "", false
+	}
+
+	if object.GetKind() != "Ingress" {
+		return part, true
+	}
+
+	var paths [][]string
+	if !c.opts.KeepMetadata {
+		paths = serverFieldPaths(object)
+	}
+
+	ingressClass := c.opts.IngressClass != "" && object.GetAnnotations()[annotationKubernetesIngressClass] == c.opts.IngressClass
+	if ingressClass {
+		paths = append(paths, []string{"metadata", "annotations", annotationKubernetesIngressClass})
+	}
+
+	fragment, ok := removeFields(part, paths)
+	if !ok || !ingressClass {
+		return fragment, ok
+	}
+
+	return addField(fragment, []string{"spec"}, "ingressClassName", c.opts.IngressClass)
+}
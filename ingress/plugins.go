@@ -0,0 +1,103 @@
+package ingress
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	networking "k8s.io/api/networking/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// Plugin converts the annotations unknown to the converter.
+type Plugin interface {
+	Name() string
+	// Handle returns the settings produced by the annotations of an Ingress, nil when the Ingress has none of its annotations.
+	Handle(ingress *networking.Ingress) (*PluginResult, error)
+}
+
+// PluginResult holds the settings produced by a plugin for an Ingress.
+type PluginResult struct {
+	// Middlewares are created in the namespace of the Ingress, and added to all the routes.
+	Middlewares []PluginMiddleware `json:"middlewares,omitempty"`
+	// EntryPoints are added to the entry points of the IngressRoute.
+	EntryPoints []string `json:"entryPoints,omitempty"`
+	// Priority, when set, is the priority of the routes.
+	Priority int `json:"priority,omitempty"`
+}
+
+// PluginMiddleware is a middleware produced by a plugin.
+type PluginMiddleware struct {
+	Name string                  `json:"name"`
+	Spec v1alpha1.MiddlewareSpec `json:"spec"`
+}
+
+// applyPlugins adds to the objects produced by the conversion of an Ingress the settings produced by the plugins.
+func applyPlugins(plugins []Plugin, ingress *networking.Ingress, objects []k8sruntime.Object) ([]k8sruntime.Object, error) {
+	if len(plugins) == 0 || len(objects) == 0 {
+		return objects, nil
+	}
+
+	ingressRoute, ok := objects[0].(*v1alpha1.IngressRoute)
+	if !ok {
+		return objects, nil
+	}
+
+	// The plugins get the whole Ingress object.
+	source := ingress.DeepCopy()
+	source.APIVersion = networking.SchemeGroupVersion.String()
+	source.Kind = "Ingress"
+
+	for _, plugin := range plugins {
+		result, err := plugin.Handle(source.DeepCopy())
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", plugin.Name(), err)
+		}
+
+		if result == nil {
+			continue
+		}
+
+		for _, m := range result.Middlewares {
+			if m.Name == "" {
+				return nil, fmt.Errorf("plugin %s: %w", plugin.Name(), errors.New("a middleware has no name"))
+			}
+
+			middleware := &v1alpha1.Middleware{
+				ObjectMeta: v1.ObjectMeta{Name: m.Name, Namespace: ingress.GetNamespace()},
+				Spec:       m.Spec,
+			}
+
+			for i := range ingressRoute.Spec.Routes {
+				ingressRoute.Spec.Routes[i].Middlewares = append(ingressRoute.Spec.Routes[i].Middlewares, toRef(middleware))
+			}
+
+			objects = append(objects, middleware)
+		}
+
+		for _, entryPoint := range result.EntryPoints {
+			if !contains(ingressRoute.Spec.EntryPoints, entryPoint) {
+				ingressRoute.Spec.EntryPoints = append(ingressRoute.Spec.EntryPoints, entryPoint)
+			}
+		}
+
+		if result.Priority != 0 {
+			for i := range ingressRoute.Spec.Routes {
+				ingressRoute.Spec.Routes[i].Priority = result.Priority
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,11 @@
+//go:build js
+// +build js
+
+package ingress
+
+import "errors"
+
+// LoadPlugins returns an error: the Yaegi interpreter of the plugin scripts is not part of the WebAssembly build.
+func LoadPlugins(_ string) ([]Plugin, error) {
+	return nil, errors.New("the plugins are not supported in WebAssembly")
+}
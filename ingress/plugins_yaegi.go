@@ -0,0 +1,129 @@
+//go:build !js
+// +build !js
+
+package ingress
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+	networking "k8s.io/api/networking/v1beta1"
+)
+
+// pluginExt is the extension of the plugin scripts.
+const pluginExt = ".go"
+
+// LoadPlugins returns the plugins of the Go scripts (.go files) of a directory, sorted by name, interpreted with Yaegi as the Traefik plugins.
+// Each script is a package of the standard library imports only, with a Handle function receiving the Ingress as JSON,
+// and returning the PluginResult as JSON (nil when the Ingress has none of its annotations):
+//
+//	func Handle(ingress []byte) ([]byte, error)
+func LoadPlugins(dir string) ([]Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && filepath.Ext(entry.Name()) == pluginExt && !strings.HasSuffix(entry.Name(), "_test.go") {
+			names = append(names, entry.Name())
+		}
+	}
+
+	sort.Strings(names)
+
+	var plugins []Plugin
+	for _, name := range names {
+		plugin, err := loadScriptPlugin(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", name, err)
+		}
+
+		plugins = append(plugins, plugin)
+	}
+
+	return plugins, nil
+}
+
+// scriptPlugin is a plugin implemented by a Go script.
+type scriptPlugin struct {
+	name   string
+	handle func([]byte) ([]byte, error)
+}
+
+// loadScriptPlugin interprets a plugin script, in its own interpreter: the scripts don't share their symbols.
+func loadScriptPlugin(filename string) (*scriptPlugin, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), filename, src, parser.PackageClauseOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	i := interp.New(interp.Options{})
+	i.Use(stdlib.Symbols)
+
+	_, err = i.Eval(string(src))
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := i.Eval(file.Name.Name + ".Handle")
+	if err != nil {
+		return nil, fmt.Errorf("missing Handle function: %w", err)
+	}
+
+	handle, ok := value.Interface().(func([]byte) ([]byte, error))
+	if !ok {
+		return nil, fmt.Errorf("invalid Handle function %s: func(ingress []byte) ([]byte, error) expected", value.Type())
+	}
+
+	return &scriptPlugin{name: strings.TrimSuffix(filepath.Base(filename), pluginExt), handle: handle}, nil
+}
+
+func (p *scriptPlugin) Name() string {
+	return p.name
+}
+
+func (p *scriptPlugin) Handle(ingress *networking.Ingress) (result *PluginResult, err error) {
+	input, err := json.Marshal(ingress)
+	if err != nil {
+		return nil, err
+	}
+
+	// The errors of the interpreted code are panics.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	output, err := p.handle(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(output) == 0 {
+		return nil, nil
+	}
+
+	result = &PluginResult{}
+	err = json.Unmarshal(output, result)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result: %w", err)
+	}
+
+	return result, nil
+}
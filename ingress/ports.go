@@ -0,0 +1,200 @@
+package ingress
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// inputServices holds the services of the manifests of the input, by <namespace>/<name>.
+type inputServices map[string]*corev1.Service
+
+// loadServices reads the services of the manifests of a file, or of the files of a directory selected by a filter.
+func loadServices(src string, filter *fileFilter) (inputServices, error) {
+	services := inputServices{}
+
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !filter.match(path, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		services.add(content)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// add reads the services of manifests, the other objects are ignored.
+func (s inputServices) add(content []byte) {
+	for _, document := range splitDocuments(content) {
+		object, err := parseYaml([]byte(document))
+		if err != nil {
+			continue
+		}
+
+		service, ok := object.(*corev1.Service)
+		if !ok {
+			continue
+		}
+
+		s[service.GetNamespace()+"/"+service.GetName()] = service
+	}
+}
+
+// get returns a service of the input, nil when it is unknown.
+func (s inputServices) get(namespace, name string) *corev1.Service {
+	return s[namespace+"/"+name]
+}
+
+// port returns the port of a backend: its number, or the number of its named port when the service is known, 0 otherwise.
+func (s inputServices) port(namespace string, backend networking.IngressBackend) int32 {
+	if backend.ServicePort.Type != intstr.String {
+		return backend.ServicePort.IntVal
+	}
+
+	service := s.get(namespace, backend.ServiceName)
+	if service == nil {
+		return 0
+	}
+
+	for _, port := range service.Spec.Ports {
+		if port.Name == backend.ServicePort.StrVal {
+			return port.Port
+		}
+	}
+
+	return 0
+}
+
+// backendPort returns the port of the service of a backend, the named ports are resolved with ResolveNamedPorts.
+func backendPort(namespace string, backend networking.IngressBackend, opts Options) int32 {
+	if !opts.ResolveNamedPorts {
+		return backend.ServicePort.IntVal
+	}
+
+	return opts.services.port(namespace, backend)
+}
+
+// namedPortNotes returns the notes about the named ports which are not resolved, referenced by name by the IngressRoute.
+func namedPortNotes(i *networking.Ingress, opts Options) []string {
+	var notes []string
+	seen := map[string]bool{}
+	for _, backend := range ingressBackends(i) {
+		if backend.ServiceName == "" || backend.ServicePort.Type != intstr.String || backendPort(i.GetNamespace(), *backend, opts) != 0 {
+			continue
+		}
+
+		key := backend.ServiceName + ":" + backend.ServicePort.StrVal
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		notes = append(notes, fmt.Sprintf("The port %s of the service %s is not resolved, the IngressRoute references it by name: Traefik v2.5 or later is required.",
+			backend.ServicePort.StrVal, backend.ServiceName))
+	}
+
+	return notes
+}
+
+// portNames returns the names of the ports of the services of an IngressRoute which are not resolved, by path of the port field.
+// The ports of the services used with several named ports are not named.
+func portNames(ingress *networking.Ingress, ingressRoute *v1alpha1.IngressRoute) map[string]string {
+	names := map[string]map[string]bool{}
+	for _, backend := range ingressBackends(ingress) {
+		if backend.ServiceName == "" || backend.ServicePort.Type != intstr.String {
+			continue
+		}
+
+		if names[backend.ServiceName] == nil {
+			names[backend.ServiceName] = map[string]bool{}
+		}
+		names[backend.ServiceName][backend.ServicePort.StrVal] = true
+	}
+
+	fields := map[string]string{}
+	for i, route := range ingressRoute.Spec.Routes {
+		for j, service := range route.Services {
+			if service.Kind != "Service" || service.Port != 0 || len(names[service.Name]) != 1 {
+				continue
+			}
+
+			for name := range names[service.Name] {
+				fields[fmt.Sprintf("spec.routes.%d.services.%d.port", i, j)] = name
+			}
+		}
+	}
+
+	return fields
+}
+
+// portExp matches the line of a port field.
+var portExp = regexp.MustCompile(`^(\s*)port: 0$`)
+
+// setPortNames replaces, in the YAML of an IngressRoute, the ports of the services by their names.
+// The ports are replaced in the encoded YAML, which keeps the formatting of the converted objects.
+func setPortNames(yml string, names map[string]string) (string, error) {
+	if len(names) == 0 {
+		return yml, nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(yml), &document); err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(yml, "\n")
+
+	fields := make([]string, 0, len(names))
+	for field := range names {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		node := lookupField(document.Content[0], strings.Split(field, "."))
+		if node == nil {
+			return "", fmt.Errorf("field %s not found", field)
+		}
+
+		line := lines[node.Line-1]
+		if !portExp.MatchString(line) {
+			return "", fmt.Errorf("unexpected port line %q", line)
+		}
+
+		lines[node.Line-1] = portExp.ReplaceAllString(line, "${1}port: "+names[field])
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
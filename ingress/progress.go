@@ -0,0 +1,48 @@
+package ingress
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Types of the progress events.
+const (
+	EventFileStarted      = "file_started"
+	EventFileFinished     = "file_finished"
+	EventIngressConverted = "ingress_converted"
+	EventWarning          = "warning"
+	// EventLog is a line of the messages of the command, when the manifests are written to the standard output.
+	EventLog = "log"
+)
+
+// ProgressEvent is a step of a conversion.
+type ProgressEvent struct {
+	Type string `json:"type"`
+	File string `json:"file,omitempty"`
+	// Namespace and Name are the ones of the converted Ingress.
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	// Objects is the number of objects produced by the conversion of an Ingress, or of a file.
+	Objects int    `json:"objects,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// JSONProgress returns a progress function writing each event to w as a line of JSON.
+func JSONProgress(w io.Writer) func(ProgressEvent) {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+
+	return func(event ProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		_ = encoder.Encode(event)
+	}
+}
+
+func (c *converter) progress(event ProgressEvent) {
+	if c.opts.Progress != nil {
+		c.opts.Progress(event)
+	}
+}
@@ -0,0 +1,252 @@
+package ingress
+
+import (
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	networking "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Report holds the results of an Ingress conversion.
+type Report struct {
+	Ingresses []IngressReport `json:"ingresses"`
+}
+
+// IngressReport holds the conversion result of an Ingress.
+type IngressReport struct {
+	File          string         `json:"file"`
+	Namespace     string         `json:"namespace"`
+	Name          string         `json:"name"`
+	Middlewares   []string       `json:"middlewares,omitempty"`
+	ManualActions []ManualAction `json:"manualActions,omitempty"`
+	// Notes are the settings of the Ingress which are not converted as is (ex: a named port, an ignored annotation), to review.
+	Notes []string   `json:"notes,omitempty"`
+	Diff  []DiffLine `json:"diff,omitempty"`
+}
+
+// ManualAction is an annotation which must be converted manually.
+type ManualAction struct {
+	Annotation string `json:"annotation"`
+	Message    string `json:"message"`
+	DocURL     string `json:"docURL,omitempty"`
+}
+
+// DiffLine is a line of the difference between an Ingress and its conversion.
+// Op is "-" for a removed line, "+" for an added line, and " " for a common line.
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+func (r *Report) addIngress(file string, ingress *networking.Ingress, objects []runtime.Object, before, after string, notes []string) {
+	r.Ingresses = append(r.Ingresses, newIngressReport(file, ingress, objects, before, after, notes))
+}
+
+func newIngressReport(file string, ingress *networking.Ingress, objects []runtime.Object, before, after string, notes []string) IngressReport {
+	namespace := ingress.GetNamespace()
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	ingressReport := IngressReport{
+		File:          file,
+		Namespace:     namespace,
+		Name:          ingress.GetName(),
+		ManualActions: manualActions(ingress),
+		Notes:         notes,
+		Diff:          diffLines(splitLines(before), splitLines(after)),
+	}
+
+	for _, object := range objects {
+		if middleware, ok := object.(*v1alpha1.Middleware); ok {
+			ingressReport.Middlewares = append(ingressReport.Middlewares, middleware.GetName())
+		}
+	}
+
+	return ingressReport
+}
+
+type namespaceReport struct {
+	Name          string
+	Ingresses     []IngressReport
+	Middlewares   int
+	ManualActions int
+	Notes         int
+}
+
+func (r *Report) namespaces() []namespaceReport {
+	byName := map[string]*namespaceReport{}
+	var names []string
+
+	for _, ingress := range r.Ingresses {
+		ns, ok := byName[ingress.Namespace]
+		if !ok {
+			ns = &namespaceReport{Name: ingress.Namespace}
+			byName[ingress.Namespace] = ns
+			names = append(names, ingress.Namespace)
+		}
+
+		ns.Ingresses = append(ns.Ingresses, ingress)
+		ns.Middlewares += len(ingress.Middlewares)
+		ns.ManualActions += len(ingress.ManualActions)
+		ns.Notes += len(ingress.Notes)
+	}
+
+	sort.Strings(names)
+
+	namespaces := make([]namespaceReport, 0, len(names))
+	for _, name := range names {
+		ns := byName[name]
+		sort.SliceStable(ns.Ingresses, func(i, j int) bool { return ns.Ingresses[i].Name < ns.Ingresses[j].Name })
+		namespaces = append(namespaces, *ns)
+	}
+
+	return namespaces
+}
+
+// SaveHTML writes the report to a standalone HTML page.
+func (r *Report) SaveHTML(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	err = r.WriteHTML(file)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// WriteHTML writes the report as a standalone HTML page.
+func (r *Report) WriteHTML(w io.Writer) error {
+	return reportTemplate.Execute(w, r.namespaces())
+}
+
+func splitLines(content string) []string {
+	content = strings.Trim(content, "\n")
+	if content == "" {
+		return nil
+	}
+
+	return strings.Split(content, "\n")
+}
+
+// diffLines computes the difference between two slices of lines, based on their longest common subsequence.
+func diffLines(before, after []string) []DiffLine {
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			switch {
+			case before[i] == after[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []DiffLine
+
+	i, j := 0, 0
+	for i < len(before) && j < len(after) {
+		switch {
+		case before[i] == after[j]:
+			lines = append(lines, DiffLine{Op: " ", Text: before[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, DiffLine{Op: "-", Text: before[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{Op: "+", Text: after[j]})
+			j++
+		}
+	}
+
+	for ; i < len(before); i++ {
+		lines = append(lines, DiffLine{Op: "-", Text: before[i]})
+	}
+
+	for ; j < len(after); j++ {
+		lines = append(lines, DiffLine{Op: "+", Text: after[j]})
+	}
+
+	return lines
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Traefik Migration Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h2 { border-bottom: 1px solid #ccc; padding-bottom: .2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: .3em .6em; text-align: left; vertical-align: top; }
+pre { background: #f7f7f7; padding: .5em; overflow-x: auto; }
+.add { color: #22863a; background: #f0fff4; }
+.del { color: #b31d28; background: #ffeef0; }
+.manual { color: #b31d28; }
+</style>
+</head>
+<body>
+<h1>Traefik Migration Report</h1>
+{{- range .}}
+<h2>Namespace {{.Name}}</h2>
+<p>Converted ingresses: {{len .Ingresses}}, generated middlewares: {{.Middlewares}}, manual actions: {{.ManualActions}}, notes: {{.Notes}}.</p>
+{{- range .Ingresses}}
+<h3>{{with .Name}}{{.}}{{else}}(unnamed){{end}}</h3>
+<p>File: <code>{{.File}}</code></p>
+{{- if .Middlewares}}
+<p>Generated middlewares:</p>
+<ul>
+{{- range .Middlewares}}
+<li><code>{{.}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .ManualActions}}
+<p class="manual">Manual actions:</p>
+<table>
+<tr><th>Annotation</th><th>Action</th></tr>
+{{- range .ManualActions}}
+<tr><td><code>{{.Annotation}}</code></td><td>{{if .DocURL}}Convert manually, see <a href="{{.DocURL}}">{{.DocURL}}</a>{{else}}{{.Message}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Notes}}
+<p>Notes:</p>
+<ul>
+{{- range .Notes}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+<details>
+<summary>Diff</summary>
+<pre>
+{{- range .Diff}}
+<span{{if eq .Op "+"}} class="add"{{else if eq .Op "-"}} class="del"{{end}}>{{.Op}} {{.Text}}</span>
+{{- end}}
+</pre>
+</details>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
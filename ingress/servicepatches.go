@@ -0,0 +1,111 @@
+package ingress
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	networking "k8s.io/api/networking/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+// servicePatchesFile is the file of the patches of the services, in the output directory.
+const servicePatchesFile = "service-patches.yml"
+
+// Traefik v2 annotations of the services, read by the Kubernetes Ingress provider.
+const (
+	annotationServiceServersScheme    = "traefik.ingress.kubernetes.io/service.serversscheme"
+	annotationServicePassHostHeader   = "traefik.ingress.kubernetes.io/service.passhostheader"
+	annotationServiceStickyCookie     = "traefik.ingress.kubernetes.io/service.sticky.cookie"
+	annotationServiceStickyCookieName = "traefik.ingress.kubernetes.io/service.sticky.cookie.name"
+)
+
+// serviceAnnotations returns the Traefik v2 service annotations of the Ingress annotations moved to the Service in Traefik v2.
+// The flush interval has no service annotation: it is only set on the services of the routes.
+func serviceAnnotations(annotations map[string]string) map[string]string {
+	converted := map[string]string{}
+
+	if scheme := getStringValue(annotations, annotationKubernetesProtocol, ""); scheme != "" {
+		converted[annotationServiceServersScheme] = scheme
+	}
+
+	if passHostHeader := getPassHostHeader(annotations); passHostHeader != nil {
+		converted[annotationServicePassHostHeader] = strconv.FormatBool(*passHostHeader)
+	}
+
+	if sticky := getSticky(annotations); sticky != nil {
+		converted[annotationServiceStickyCookie] = "true"
+
+		if sticky.Cookie.Name != "" {
+			converted[annotationServiceStickyCookieName] = sticky.Cookie.Name
+		}
+	}
+
+	return converted
+}
+
+// addServicePatches records the annotations to add to the backend services of an Ingress.
+// It returns the notes about the services patched with different values by several ingresses.
+func (c *converter) addServicePatches(ingress *networking.Ingress) []string {
+	annotations := serviceAnnotations(ingress.GetAnnotations())
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	var notes []string
+	for _, name := range backendServices(ingress) {
+		key := ingress.GetNamespace() + "/" + name
+
+		patch, ok := c.servicePatches[key]
+		if !ok {
+			patch = map[string]string{}
+			c.servicePatches[key] = patch
+		}
+
+		for annotation, value := range annotations {
+			if current, ok := patch[annotation]; ok && current != value {
+				notes = append(notes, fmt.Sprintf("The annotation %s of the service %s is patched with %q, instead of %q set by another Ingress.", annotation, key, value, current))
+			}
+
+			patch[annotation] = value
+		}
+	}
+
+	sort.Strings(notes)
+
+	return notes
+}
+
+// servicePatchesFragment returns the YAML documents of the strategic merge patches of the services, empty when there are none.
+func (c *converter) servicePatchesFragment() (string, error) {
+	var keys []string
+	for key := range c.servicePatches {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var fragments []string
+	for _, key := range keys {
+		parts := strings.SplitN(key, "/", 2)
+
+		patch := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata": map[string]interface{}{
+				"name":        parts[1],
+				"namespace":   parts[0],
+				"annotations": c.servicePatches[key],
+			},
+		}
+
+		data, err := yaml.Marshal(patch)
+		if err != nil {
+			return "", err
+		}
+
+		fragments = append(fragments, string(data))
+	}
+
+	return string(joinFragments(fragments)), nil
+}
@@ -0,0 +1,296 @@
+package ingress
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/hashstructure"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// weightPrecision is the number of weight units of a percentage of the service-weights annotation:
+// Traefik v1 reads the percentages with 3 decimals.
+const weightPrecision = 1000
+
+// getServiceWeights parses the service-weights annotation (ex: "whoami-v1: 10%"), it returns the weight of each service in weightPrecision units.
+func getServiceWeights(ingress *networking.Ingress) (map[string]int, error) {
+	raw := getStringValue(ingress.GetAnnotations(), annotationKubernetesServiceWeights, "")
+	if raw == "" {
+		return nil, nil
+	}
+
+	percentages := map[string]string{}
+	err := yaml.Unmarshal([]byte(raw), percentages)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", annotationKubernetesServiceWeights, err)
+	}
+
+	names := make([]string, 0, len(percentages))
+	for name := range percentages {
+		names = append(names, name)
+	}
+	// The services are sorted to always report the same invalid weight.
+	sort.Strings(names)
+
+	weights := map[string]int{}
+	for _, name := range names {
+		percentage := percentages[name]
+		value, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(percentage), "%"), 64)
+		if err != nil || value < 0 || value > 100 {
+			return nil, fmt.Errorf("invalid %s: the weight %q of the service %s is not a percentage", annotationKubernetesServiceWeights, percentage, name)
+		}
+
+		weights[name] = int(math.Round(value * weightPrecision))
+	}
+
+	return weights, nil
+}
+
+// applyServiceWeights replaces the services of the routes with weighted services by a weighted TraefikService.
+// As with Traefik v1, the paths of the same host and path are merged, and the services without weight share the remaining percentage.
+// With sticky services, the choice of the service is sticky too, as Traefik v1 weighted the servers of a single backend:
+// the cookie of the TraefikService is named by Traefik, to not override the cookie of the services.
+func applyServiceWeights(namespace string, routes []v1alpha1.Route, weights map[string]int, sticky bool) ([]v1alpha1.Route, []*v1alpha1.TraefikService, error) {
+	if len(weights) == 0 {
+		return routes, nil, nil
+	}
+
+	weighted := map[string]bool{}
+	for _, route := range routes {
+		for _, service := range route.Services {
+			if _, ok := weights[service.Name]; ok {
+				weighted[route.Match] = true
+			}
+		}
+	}
+
+	var merged []v1alpha1.Route
+	indexes := map[string]int{}
+	for _, route := range routes {
+		if i, ok := indexes[route.Match]; ok && weighted[route.Match] {
+			merged[i].Services = append(merged[i].Services, route.Services...)
+			continue
+		}
+
+		indexes[route.Match] = len(merged)
+		merged = append(merged, route)
+	}
+
+	var traefikServices []*v1alpha1.TraefikService
+	for i, route := range merged {
+		if !weighted[route.Match] {
+			continue
+		}
+
+		services, err := weightServices(route.Services, weights)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s for the route %q: %w", annotationKubernetesServiceWeights, route.Match, err)
+		}
+
+		spec := v1alpha1.ServiceSpec{Weighted: &v1alpha1.WeightedRoundRobin{Services: services}}
+		if sticky {
+			spec.Weighted.Sticky = &dynamic.Sticky{Cookie: &dynamic.Cookie{}}
+		}
+
+		hash, err := hashstructure.Hash(spec, nil)
+		if err != nil {
+			panic(err)
+		}
+
+		traefikService := &v1alpha1.TraefikService{
+			ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("%s-%d", "weighted", hash), Namespace: namespace},
+			Spec:       spec,
+		}
+
+		merged[i].Services = []v1alpha1.Service{{
+			LoadBalancerSpec: v1alpha1.LoadBalancerSpec{
+				Name:      traefikService.GetName(),
+				Namespace: traefikService.GetNamespace(),
+				Kind:      "TraefikService",
+			},
+		}}
+
+		traefikServices = append(traefikServices, traefikService)
+	}
+
+	return merged, traefikServices, nil
+}
+
+// weightServices sets the weight of the services of a route, the services without weight share the remaining percentage.
+// The weights are reduced by their greatest common divisor.
+func weightServices(services []v1alpha1.Service, weights map[string]int) ([]v1alpha1.Service, error) {
+	remaining := 100 * weightPrecision
+	var unweighted int
+	for _, service := range services {
+		weight, ok := weights[service.Name]
+		if !ok {
+			unweighted++
+			continue
+		}
+
+		remaining -= weight
+	}
+
+	if remaining < 0 {
+		return nil, errors.New("the weights exceed 100%")
+	}
+
+	values := make([]int, len(services))
+	divisor := 0
+	for i, service := range services {
+		weight, ok := weights[service.Name]
+		if !ok {
+			weight = remaining / unweighted
+		}
+
+		values[i] = weight
+		divisor = gcd(divisor, weight)
+	}
+
+	if divisor == 0 {
+		return nil, errors.New("the weights are all 0%")
+	}
+
+	weightedServices := make([]v1alpha1.Service, len(services))
+	for i, service := range services {
+		weight := values[i] / divisor
+		service.Weight = &weight
+		weightedServices[i] = service
+	}
+
+	return weightedServices, nil
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
+
+// getPassHostHeader returns the passHostHeader of the services of the routes, nil when the preserve-host annotation is not set.
+func getPassHostHeader(annotations map[string]string) *bool {
+	if _, ok := annotations[getAnnotationName(annotations, annotationKubernetesPreserveHost)]; !ok {
+		return nil
+	}
+
+	passHostHeader := getBoolValue(annotations, annotationKubernetesPreserveHost, true)
+	return &passHostHeader
+}
+
+// getResponseForwarding returns the responseForwarding of the services of the routes, nil when the flush interval annotation is not set.
+func getResponseForwarding(annotations map[string]string) *dynamic.ResponseForwarding {
+	flushInterval := getStringValue(annotations, annotationKubernetesResponseForwardingFlushInterval, "")
+	if flushInterval == "" {
+		return nil
+	}
+
+	return &dynamic.ResponseForwarding{FlushInterval: flushInterval}
+}
+
+// getSticky returns the sticky cookie of the services of the routes, nil without the affinity annotation.
+// As with Traefik v1, the session-cookie-name annotation is only read with the affinity annotation.
+func getSticky(annotations map[string]string) *dynamic.Sticky {
+	if !getBoolValue(annotations, annotationKubernetesAffinity, false) {
+		return nil
+	}
+
+	return &dynamic.Sticky{
+		Cookie: &dynamic.Cookie{Name: getStringValue(annotations, annotationKubernetesSessionCookieName, "")},
+	}
+}
+
+// isTraefikServiceResource tells whether a resource backend is a TraefikService, referenced by the routes instead of a service.
+func isTraefikServiceResource(resource *corev1.TypedLocalObjectReference) bool {
+	return resource.Kind == "TraefikService" && resource.APIGroup != nil && *resource.APIGroup == v1alpha1.GroupName
+}
+
+// resourceBackendNotes returns the notes about the resource backends which are not converted, the routes of their paths are skipped.
+func resourceBackendNotes(i *networking.Ingress) []string {
+	var notes []string
+	for _, backend := range ingressBackends(i) {
+		if backend.Resource == nil || isTraefikServiceResource(backend.Resource) {
+			continue
+		}
+
+		notes = append(notes, fmt.Sprintf("The paths of the resource backend %s %s are not converted: only the TraefikService resources are supported.",
+			backend.Resource.Kind, backend.Resource.Name))
+	}
+
+	return notes
+}
+
+// externalNameNotes returns the notes about the backends of ExternalName services, read from the Service manifests of the input:
+// Traefik v2 ignores them unless the Kubernetes CRD provider allows them, and the requests keep the Host header of the client unless preserve-host is false.
+func externalNameNotes(i *networking.Ingress, services inputServices) []string {
+	annotations := i.GetAnnotations()
+
+	var notes []string
+	for _, name := range backendServices(i) {
+		service := services.get(i.GetNamespace(), name)
+		if service == nil || service.Spec.Type != corev1.ServiceTypeExternalName {
+			continue
+		}
+
+		notes = append(notes, fmt.Sprintf("The service %s is an ExternalName service of %s: allowExternalNameServices must be enabled on the Kubernetes CRD provider (Traefik v2.5 or later).",
+			name, service.Spec.ExternalName))
+
+		if getBoolValue(annotations, annotationKubernetesPreserveHost, true) {
+			notes = append(notes, fmt.Sprintf("The requests to the ExternalName service %s keep the Host header of the client: set %s to false (passHostHeader) when %s expects its own host name.",
+				name, annotationKubernetesPreserveHost, service.Spec.ExternalName))
+		}
+
+		if getStringValue(annotations, annotationKubernetesProtocol, "") == "" {
+			notes = append(notes, fmt.Sprintf("The requests to the ExternalName service %s use the http scheme, except on the port 443: set %s to https (scheme) when %s is served over TLS.",
+				name, annotationKubernetesProtocol, service.Spec.ExternalName))
+		}
+	}
+
+	return notes
+}
+
+// backendServices returns the names of the services used as backends of an Ingress.
+func backendServices(i *networking.Ingress) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, backend := range ingressBackends(i) {
+		if backend.ServiceName == "" || seen[backend.ServiceName] {
+			continue
+		}
+
+		seen[backend.ServiceName] = true
+		names = append(names, backend.ServiceName)
+	}
+
+	return names
+}
+
+// ingressBackends returns the default backend and the backends of the paths of an Ingress.
+func ingressBackends(i *networking.Ingress) []*networking.IngressBackend {
+	var backends []*networking.IngressBackend
+	if i.Spec.Backend != nil {
+		backends = append(backends, i.Spec.Backend)
+	}
+
+	for _, rule := range i.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for idx := range rule.HTTP.Paths {
+			backends = append(backends, &rule.HTTP.Paths[idx].Backend)
+		}
+	}
+
+	return backends
+}
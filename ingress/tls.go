@@ -0,0 +1,129 @@
+package ingress
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/hashstructure"
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	"github.com/traefik/traefik/v2/pkg/types"
+	networking "k8s.io/api/networking/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getTLS converts the TLS section of an Ingress into the TLS of the IngressRoute, nil without TLS section.
+// The hosts of each TLS entry are a domain of the IngressRoute.
+// An IngressRoute references a single secret, the secret of the first TLS entry: the other secrets are reported by tlsNotes.
+// Unlike the Traefik v1 frontends, the routes of an IngressRoute with TLS only match the HTTPS requests.
+func getTLS(ingress *networking.Ingress) *v1alpha1.TLS {
+	if len(ingress.Spec.TLS) == 0 {
+		return nil
+	}
+
+	tls := &v1alpha1.TLS{}
+	for _, ingressTLS := range ingress.Spec.TLS {
+		if tls.SecretName == "" {
+			tls.SecretName = ingressTLS.SecretName
+		}
+
+		if len(ingressTLS.Hosts) > 0 {
+			tls.Domains = append(tls.Domains, types.Domain{Main: ingressTLS.Hosts[0], SANs: ingressTLS.Hosts[1:]})
+		}
+	}
+
+	return tls
+}
+
+// getTLSOption returns the TLSOption of an Ingress with a TLS section: the TLS options of its entry points, converted from the Traefik v1 static configuration.
+// It returns nil when the entry points have no TLS options, or different TLS options.
+func getTLSOption(ingress *networking.Ingress, tlsOptions map[string]v1alpha1.TLSOptionSpec) *v1alpha1.TLSOption {
+	if len(ingress.Spec.TLS) == 0 {
+		return nil
+	}
+
+	specs, _ := entryPointsTLSOptions(ingress, tlsOptions)
+	if len(specs) != 1 {
+		return nil
+	}
+
+	spec := specs[0]
+
+	hash, err := hashstructure.Hash(spec, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return &v1alpha1.TLSOption{
+		ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("%s-%d", "tls", hash), Namespace: ingress.GetNamespace()},
+		Spec:       spec,
+	}
+}
+
+// entryPointsTLSOptions returns the distinct TLS options of the entry points of an Ingress, and the entry points with TLS options.
+// Without frontend-entry-points annotation, the Ingress is served by all the entry points.
+func entryPointsTLSOptions(ingress *networking.Ingress, tlsOptions map[string]v1alpha1.TLSOptionSpec) ([]v1alpha1.TLSOptionSpec, []string) {
+	entryPoints := getSliceStringValue(ingress.GetAnnotations(), annotationKubernetesFrontendEntryPoints)
+	if len(entryPoints) == 0 {
+		for name := range tlsOptions {
+			entryPoints = append(entryPoints, name)
+		}
+		sort.Strings(entryPoints)
+	}
+
+	var specs []v1alpha1.TLSOptionSpec
+	var names []string
+	for _, name := range entryPoints {
+		spec, ok := tlsOptions[name]
+		if !ok {
+			continue
+		}
+
+		names = append(names, name)
+
+		known := false
+		for _, s := range specs {
+			if reflect.DeepEqual(s, spec) {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			specs = append(specs, spec)
+		}
+	}
+
+	return specs, names
+}
+
+// tlsNotes returns the notes about the TLS section of an Ingress which cannot be fully converted:
+// the secrets which are not referenced by the IngressRoute, and the entry points with different TLS options.
+func tlsNotes(i *networking.Ingress, tlsOptions map[string]v1alpha1.TLSOptionSpec) []string {
+	var notes []string
+
+	var secrets []string
+	seen := map[string]bool{}
+	for _, ingressTLS := range i.Spec.TLS {
+		if ingressTLS.SecretName == "" || seen[ingressTLS.SecretName] {
+			continue
+		}
+
+		seen[ingressTLS.SecretName] = true
+		secrets = append(secrets, ingressTLS.SecretName)
+	}
+
+	if len(secrets) > 1 {
+		notes = append(notes, fmt.Sprintf("The IngressRoute only references the secret %s: the certificates of the secrets %s must be referenced by a TLSStore or another IngressRoute.",
+			secrets[0], strings.Join(secrets[1:], ", ")))
+	}
+
+	if len(i.Spec.TLS) > 0 {
+		if specs, names := entryPointsTLSOptions(i, tlsOptions); len(specs) > 1 {
+			notes = append(notes, fmt.Sprintf("The entry points %s have different TLS options, the IngressRoute references no TLSOption.", strings.Join(names, ", ")))
+		}
+	}
+
+	return notes
+}
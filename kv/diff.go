@@ -0,0 +1,56 @@
+package kv
+
+import (
+	"fmt"
+	"sort"
+)
+
+// keysDiff holds the changes of the keys of a store.
+type keysDiff struct {
+	created  []string
+	modified []string
+	// orphaned are the existing keys that are not part of the new keys.
+	orphaned []string
+}
+
+func diffKeys(existing, keys map[string]string) keysDiff {
+	var diff keysDiff
+
+	for key, value := range keys {
+		current, ok := existing[key]
+		switch {
+		case !ok:
+			diff.created = append(diff.created, key)
+		case current != value:
+			diff.modified = append(diff.modified, key)
+		}
+	}
+
+	for key := range existing {
+		if _, ok := keys[key]; !ok {
+			diff.orphaned = append(diff.orphaned, key)
+		}
+	}
+
+	sort.Strings(diff.created)
+	sort.Strings(diff.modified)
+	sort.Strings(diff.orphaned)
+
+	return diff
+}
+
+func (d keysDiff) print(existing, keys map[string]string) {
+	for _, key := range d.created {
+		fmt.Printf("+ %s: %s\n", key, keys[key])
+	}
+
+	for _, key := range d.modified {
+		fmt.Printf("~ %s: %s -> %s\n", key, existing[key], keys[key])
+	}
+
+	for _, key := range d.orphaned {
+		fmt.Printf("- %s (left behind)\n", key)
+	}
+
+	fmt.Printf("%d keys to create, %d keys to modify, %d keys left behind.\n", len(d.created), len(d.modified), len(d.orphaned))
+}
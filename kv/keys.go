@@ -0,0 +1,107 @@
+package kv
+
+// Traefik v1 KV layout, relative to the prefix of the tree.
+const (
+	pathBackends  = "backends"
+	pathFrontends = "frontends"
+	pathTLS       = "tls"
+
+	// Backend.
+	pathBackendServers                          = "servers"
+	pathBackendServerURL                        = "url"
+	pathBackendServerWeight                     = "weight"
+	pathBackendLoadBalancerMethod               = "loadbalancer/method"
+	pathBackendLoadBalancerSticky               = "loadbalancer/sticky" // Deprecated
+	pathBackendLoadBalancerStickiness           = "loadbalancer/stickiness"
+	pathBackendLoadBalancerStickinessCookieName = "loadbalancer/stickiness/cookiename"
+	pathBackendHealthCheckScheme                = "healthcheck/scheme"
+	pathBackendHealthCheckPath                  = "healthcheck/path"
+	pathBackendHealthCheckPort                  = "healthcheck/port"
+	pathBackendHealthCheckInterval              = "healthcheck/interval"
+	pathBackendHealthCheckTimeout               = "healthcheck/timeout"
+	pathBackendHealthCheckHostname              = "healthcheck/hostname"
+	pathBackendHealthCheckHeaders               = "healthcheck/headers"
+	pathBackendResponseForwardingFlushInterval  = "responseforwarding/flushinterval"
+	pathBackendCircuitBreakerExpression         = "circuitbreaker/expression"
+	pathBackendMaxConnAmount                    = "maxconn/amount"
+	pathBackendMaxConnExtractorFunc             = "maxconn/extractorfunc"
+	pathBackendBufferingMaxRequestBodyBytes     = "buffering/maxrequestbodybytes"
+	pathBackendBufferingMemRequestBodyBytes     = "buffering/memrequestbodybytes"
+	pathBackendBufferingMaxResponseBodyBytes    = "buffering/maxresponsebodybytes"
+	pathBackendBufferingMemResponseBodyBytes    = "buffering/memresponsebodybytes"
+	pathBackendBufferingRetryExpression         = "buffering/retryexpression"
+
+	// Frontend.
+	pathFrontendBackend        = "backend"
+	pathFrontendPriority       = "priority"
+	pathFrontendPassHostHeader = "passhostheader"
+	pathFrontendPassTLSCert    = "passtlscert"
+	pathFrontendEntryPoints    = "entrypoints"
+	pathFrontendRoutes         = "routes"
+	pathFrontendRouteRule      = "rule"
+
+	pathFrontendWhiteListSourceRange      = "whitelist/sourcerange"
+	pathFrontendWhiteListUseXForwardedFor = "whitelist/usexforwardedfor"
+	pathFrontendWhitelistSourceRange      = "whitelistsourcerange" // Deprecated
+
+	pathFrontendBasicAuth                        = "basicauth" // Deprecated
+	pathFrontendAuthBasicUsers                   = "auth/basic/users"
+	pathFrontendAuthBasicUsersFile               = "auth/basic/usersfile"
+	pathFrontendAuthBasicRemoveHeader            = "auth/basic/removeheader"
+	pathFrontendAuthDigestUsers                  = "auth/digest/users"
+	pathFrontendAuthDigestUsersFile              = "auth/digest/usersfile"
+	pathFrontendAuthDigestRemoveHeader           = "auth/digest/removeheader"
+	pathFrontendAuthHeaderField                  = "auth/headerfield"
+	pathFrontendAuthForwardAddress               = "auth/forward/address"
+	pathFrontendAuthForwardTrustForwardHeader    = "auth/forward/trustforwardheader"
+	pathFrontendAuthForwardAuthResponseHeaders   = "auth/forward/authresponseheaders"
+	pathFrontendAuthForwardTLSCa                 = "auth/forward/tls/ca"
+	pathFrontendAuthForwardTLSCaOptional         = "auth/forward/tls/caoptional"
+	pathFrontendAuthForwardTLSCert               = "auth/forward/tls/cert"
+	pathFrontendAuthForwardTLSKey                = "auth/forward/tls/key"
+	pathFrontendAuthForwardTLSInsecureSkipVerify = "auth/forward/tls/insecureskipverify"
+
+	pathFrontendRedirectEntryPoint  = "redirect/entrypoint"
+	pathFrontendRedirectRegex       = "redirect/regex"
+	pathFrontendRedirectReplacement = "redirect/replacement"
+	pathFrontendRedirectPermanent   = "redirect/permanent"
+
+	pathFrontendErrorPages        = "errors"
+	pathFrontendErrorPagesBackend = "backend"
+	pathFrontendErrorPagesQuery   = "query"
+	pathFrontendErrorPagesStatus  = "status"
+
+	pathFrontendRateLimitExtractorFunc = "ratelimit/extractorfunc"
+	pathFrontendRateLimitRateSets      = "ratelimit/rateset"
+	pathFrontendRateLimitPeriod        = "period"
+	pathFrontendRateLimitAverage       = "average"
+	pathFrontendRateLimitBurst         = "burst"
+
+	pathFrontendCustomRequestHeaders    = "headers/customrequestheaders"
+	pathFrontendCustomResponseHeaders   = "headers/customresponseheaders"
+	pathFrontendAllowedHosts            = "headers/allowedhosts"
+	pathFrontendHostsProxyHeaders       = "headers/hostsproxyheaders"
+	pathFrontendSSLForceHost            = "headers/sslforcehost"
+	pathFrontendSSLRedirect             = "headers/sslredirect"
+	pathFrontendSSLTemporaryRedirect    = "headers/ssltemporaryredirect"
+	pathFrontendSSLHost                 = "headers/sslhost"
+	pathFrontendSSLProxyHeaders         = "headers/sslproxyheaders"
+	pathFrontendSTSSeconds              = "headers/stsseconds"
+	pathFrontendSTSIncludeSubdomains    = "headers/stsincludesubdomains"
+	pathFrontendSTSPreload              = "headers/stspreload"
+	pathFrontendForceSTSHeader          = "headers/forcestsheader"
+	pathFrontendFrameDeny               = "headers/framedeny"
+	pathFrontendCustomFrameOptionsValue = "headers/customframeoptionsvalue"
+	pathFrontendContentTypeNosniff      = "headers/contenttypenosniff"
+	pathFrontendBrowserXSSFilter        = "headers/browserxssfilter"
+	pathFrontendCustomBrowserXSSValue   = "headers/custombrowserxssvalue"
+	pathFrontendContentSecurityPolicy   = "headers/contentsecuritypolicy"
+	pathFrontendPublicKey               = "headers/publickey"
+	pathFrontendReferrerPolicy          = "headers/referrerpolicy"
+	pathFrontendIsDevelopment           = "headers/isdevelopment"
+
+	// TLS.
+	pathTLSEntryPoints = "entrypoints"
+	pathTLSCertFile    = "certificate/certfile"
+	pathTLSKeyFile     = "certificate/keyfile"
+)
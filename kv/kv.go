@@ -0,0 +1,529 @@
+// Package kv migrates a Traefik v1 KV store tree to the Traefik v2 KV layout.
+package kv
+
+import (
+	"context"
+	cryptotls "crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abronan/valkeyrie"
+	"github.com/abronan/valkeyrie/store"
+	"github.com/abronan/valkeyrie/store/consul"
+	etcdv2 "github.com/abronan/valkeyrie/store/etcd/v2"
+	etcdv3 "github.com/abronan/valkeyrie/store/etcd/v3"
+	"github.com/traefik/traefik-migration-tool/rule"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/tls"
+	"github.com/traefik/traefik/v2/pkg/types"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	consul.Register()
+	etcdv2.Register()
+	etcdv3.Register()
+}
+
+// zkMaxValueSize is the default maximum size of the data of a znode (jute.maxbuffer).
+const zkMaxValueSize = 1024 * 1024
+
+// zkEmptyValue is the value of the znodes created without data by the KV clients (SOH).
+const zkEmptyValue = "\x01"
+
+// defaultEndpoints are the endpoints used when none are provided.
+var defaultEndpoints = map[store.Backend][]string{
+	store.CONSUL: {"127.0.0.1:8500"},
+	store.ETCD:   {"127.0.0.1:2379"},
+	store.ETCDV3: {"127.0.0.1:2379"},
+	store.ZK:     {"127.0.0.1:2181"},
+	store.REDIS:  {"127.0.0.1:6379"},
+}
+
+// StoreConfig holds the configuration of a KV store.
+type StoreConfig struct {
+	// Backend is the type of the store: consul, etcd (etcd v2 API), etcdv3 (etcd v3 API), zk, or redis.
+	Backend   string
+	Endpoints []string
+	Prefix    string
+
+	// Username and Password are used by etcd, ZooKeeper (digest authentication), and Redis (password only).
+	Username string
+	Password string
+	// Token is the Consul ACL token.
+	Token string
+	// DB is the Redis database.
+	DB  int
+	TLS types.ClientTLS
+}
+
+// kvStore is the part of store.Store used by the migration.
+type kvStore interface {
+	Put(key string, value []byte, options *store.WriteOptions) error
+	List(directory string, options *store.ReadOptions) ([]*store.KVPair, error)
+	Delete(key string) error
+	Close()
+}
+
+// MigrateOptions holds the options of the migration to a KV store.
+type MigrateOptions struct {
+	// DryRun only shows the changes to the destination store.
+	DryRun bool
+	// Prune deletes the keys of the destination prefix that are not part of the converted configuration
+	// (ex: the v1 tree when the source and the destination are the same), once the new keys are written.
+	Prune bool
+}
+
+// Migrate reads the Traefik v1 tree of the source store and writes the equivalent Traefik v2 keys in the destination store.
+func Migrate(source, destination StoreConfig, opts MigrateOptions) error {
+	srcStore, err := newStore(source)
+	if err != nil {
+		return fmt.Errorf("source store: %w", err)
+	}
+	defer srcStore.Close()
+
+	dstStore, err := newStore(destination)
+	if err != nil {
+		return fmt.Errorf("destination store: %w", err)
+	}
+	defer dstStore.Close()
+
+	return migrate(srcStore, source, dstStore, destination, opts)
+}
+
+// Export reads the Traefik v1 tree of the source store and writes the equivalent Traefik v2 file provider configuration.
+func Export(source StoreConfig, dstFile string) error {
+	srcStore, err := newStore(source)
+	if err != nil {
+		return fmt.Errorf("source store: %w", err)
+	}
+	defer srcStore.Close()
+
+	return export(srcStore, source, dstFile)
+}
+
+func newStore(config StoreConfig) (kvStore, error) {
+	backend := store.Backend(config.Backend)
+
+	endpoints := config.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = defaultEndpoints[backend]
+	}
+
+	var tlsConfig *cryptotls.Config
+	if config.TLS != (types.ClientTLS{}) {
+		var err error
+		tlsConfig, err = config.TLS.CreateTLSConfig(context.Background())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch backend {
+	case store.REDIS:
+		return newRedisStore(endpoints, config, tlsConfig)
+	case store.ZK:
+		return newZKStore(endpoints, config, tlsConfig)
+	}
+
+	return valkeyrie.NewStore(backend, endpoints, &store.Config{
+		ConnectionTimeout: 3 * time.Second,
+		TLS:               tlsConfig,
+		Username:          config.Username,
+		Password:          config.Password,
+		Token:             config.Token,
+	})
+}
+
+func migrate(src kvStore, source StoreConfig, dst kvStore, destination StoreConfig, opts MigrateOptions) error {
+	conf, err := readConfiguration(src, source.Prefix)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := encodePairs(conf, destination.Prefix)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(encoded))
+	for key := range encoded {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		err = checkValue(destination, key, encoded[key])
+		if err != nil {
+			return err
+		}
+	}
+
+	existing, err := readKeys(dst, destination.Prefix)
+	if err != nil {
+		return err
+	}
+
+	diff := diffKeys(existing, encoded)
+	diff.print(existing, encoded)
+
+	if opts.DryRun {
+		return nil
+	}
+
+	for _, key := range keys {
+		if existing[key] == encoded[key] {
+			continue
+		}
+
+		err = dst.Put(key, []byte(encoded[key]), nil)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", key, err)
+		}
+	}
+
+	fmt.Printf("%d keys written under %s.\n", len(diff.created)+len(diff.modified), destination.Prefix)
+
+	if !opts.Prune {
+		return nil
+	}
+
+	for _, key := range diff.orphaned {
+		err = dst.Delete(key)
+		if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+
+	fmt.Printf("%d keys deleted under %s.\n", len(diff.orphaned), destination.Prefix)
+
+	return nil
+}
+
+// readKeys reads the keys of the tree, the keys are not relative to the prefix.
+func readKeys(kvStore kvStore, prefix string) (map[string]string, error) {
+	root := strings.Trim(prefix, "/")
+
+	pairs, err := readTree(kvStore, root)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]string{}
+	for key, value := range pairs {
+		keys[root+"/"+key] = value
+	}
+
+	return keys, nil
+}
+
+func export(src kvStore, source StoreConfig, dstFile string) error {
+	conf, err := readConfiguration(src, source.Prefix)
+	if err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(conf)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dstFile), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(dstFile, out, 0666)
+}
+
+// readConfiguration reads the v1 tree and converts it to a v2 dynamic configuration.
+func readConfiguration(src kvStore, prefix string) (*dynamic.Configuration, error) {
+	pairs, err := readTree(src, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("no key found under %s", strings.Trim(prefix, "/"))
+	}
+
+	return convertTree(pairs)
+}
+
+// checkValue checks that a value can be written in the destination store.
+// The values are not chunked under child znodes: Traefik reads each key from a single znode, it wouldn't read the chunks of a value.
+func checkValue(destination StoreConfig, key, value string) error {
+	if store.Backend(destination.Backend) == store.ZK && len(value) >= zkMaxValueSize {
+		return fmt.Errorf("the value of %s (%d bytes) exceeds the maximum size of a znode, and the values are not chunked because Traefik reads each key from a single znode: use a file for this value", key, len(value))
+	}
+
+	return nil
+}
+
+// readTree reads the keys of the tree, relative to the prefix.
+// The directories returned by the etcd v2 API and the parent znodes of ZooKeeper are skipped.
+func readTree(src kvStore, prefix string) (map[string]string, error) {
+	root := strings.Trim(prefix, "/")
+
+	kvPairs, err := src.List(root+"/", nil)
+	if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
+		return nil, err
+	}
+
+	pairs := map[string]string{}
+	for _, pair := range kvPairs {
+		key := strings.TrimPrefix(pair.Key, "/")
+		if !strings.HasPrefix(key, root+"/") || strings.HasSuffix(key, "/") {
+			continue
+		}
+
+		value := string(pair.Value)
+		if value == zkEmptyValue {
+			value = ""
+		}
+
+		pairs[strings.TrimPrefix(key, root+"/")] = value
+	}
+
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for i := 0; i < len(keys)-1; i++ {
+		if strings.HasPrefix(keys[i+1], keys[i]+"/") {
+			delete(pairs, keys[i])
+		}
+	}
+
+	return pairs, nil
+}
+
+// convertTree converts the keys of a v1 tree (relative to the prefix) to a v2 dynamic configuration.
+func convertTree(pairs map[string]string) (*dynamic.Configuration, error) {
+	root := newTree(pairs)
+
+	conf := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers:     map[string]*dynamic.Router{},
+			Services:    map[string]*dynamic.Service{},
+			Middlewares: map[string]*dynamic.Middleware{},
+		},
+	}
+
+	backendMiddlewares := map[string][]string{}
+	for _, name := range root.children(pathBackends) {
+		backend := root.sub(pathBackends, name)
+
+		conf.HTTP.Services[name] = getService(name, backend)
+
+		middlewares := getBackendMiddlewares(backend)
+		for _, key := range sortedKeys(middlewares) {
+			middlewareName := name + "-" + key
+			conf.HTTP.Middlewares[middlewareName] = middlewares[key]
+			backendMiddlewares[name] = append(backendMiddlewares[name], middlewareName)
+		}
+	}
+
+	for _, name := range root.children(pathFrontends) {
+		err := convertFrontend(conf.HTTP, name, root.sub(pathFrontends, name), backendMiddlewares)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range root.children(pathTLS) {
+		if cert := getCertificate(name, root.sub(pathTLS, name)); cert != nil {
+			if conf.TLS == nil {
+				conf.TLS = &dynamic.TLSConfiguration{}
+			}
+			conf.TLS.Certificates = append(conf.TLS.Certificates, cert)
+		}
+	}
+
+	logUnused(root)
+
+	return conf, nil
+}
+
+func getService(name string, backend *tree) *dynamic.Service {
+	lb := &dynamic.ServersLoadBalancer{}
+	lb.SetDefaults()
+
+	lb.Servers = getServers(name, backend)
+	lb.Sticky = getSticky(backend)
+	lb.HealthCheck = getHealthCheck(backend)
+	lb.ResponseForwarding = getResponseForwarding(backend)
+
+	if method := backend.getString(pathBackendLoadBalancerMethod, "wrr"); !strings.EqualFold(method, "wrr") {
+		fmt.Printf("%s: the load balancer method %s is not supported, the default round robin is used.\n", name, method)
+	}
+
+	return &dynamic.Service{LoadBalancer: lb}
+}
+
+func convertFrontend(conf *dynamic.HTTPConfiguration, name string, frontend *tree, backendMiddlewares map[string][]string) error {
+	var matchers []string
+	modifiers := map[string]*dynamic.Middleware{}
+
+	for _, route := range frontend.children(pathFrontendRoutes) {
+		rawRule := frontend.getString(path.Join(pathFrontendRoutes, route, pathFrontendRouteRule), "")
+		if rawRule == "" {
+			continue
+		}
+
+		converted, err := rule.Convert(rawRule)
+		if err != nil {
+			return fmt.Errorf("frontend %s: %w", name, err)
+		}
+
+		if converted.Match != "" {
+			matchers = append(matchers, converted.Match)
+		}
+		for key, middleware := range converted.Modifiers {
+			modifiers[key] = middleware
+		}
+	}
+
+	backend := frontend.getString(pathFrontendBackend, "")
+
+	if service, ok := conf.Services[backend]; ok && frontend.has(pathFrontendPassHostHeader) {
+		passHostHeader := frontend.getBool(pathFrontendPassHostHeader, true)
+		service.LoadBalancer.PassHostHeader = &passHostHeader
+	}
+
+	middlewares := getFrontendMiddlewares(frontend)
+	for key, middleware := range modifiers {
+		middlewares[key] = middleware
+	}
+
+	if len(matchers) == 0 {
+		fmt.Printf("%s: no route rule defined, the router must be created manually. See https://docs.traefik.io/routing/routers/#rule\n", name)
+		return nil
+	}
+
+	router := &dynamic.Router{
+		EntryPoints: frontend.getList(pathFrontendEntryPoints),
+		Service:     backend,
+		Rule:        strings.Join(matchers, " && "),
+		Priority:    frontend.getInt(pathFrontendPriority, 0),
+	}
+
+	for _, key := range sortedKeys(middlewares) {
+		middlewareName := name + "-" + key
+		conf.Middlewares[middlewareName] = middlewares[key]
+		router.Middlewares = append(router.Middlewares, middlewareName)
+	}
+	router.Middlewares = append(router.Middlewares, backendMiddlewares[backend]...)
+
+	conf.Routers[name] = router
+
+	return nil
+}
+
+func getCertificate(name string, certificate *tree) *tls.CertAndStores {
+	certFile := certificate.getString(pathTLSCertFile, "")
+	keyFile := certificate.getString(pathTLSKeyFile, "")
+	if certFile == "" || keyFile == "" {
+		fmt.Printf("%s: incomplete TLS certificate, skipping.\n", name)
+		return nil
+	}
+
+	if entryPoints := certificate.getList(pathTLSEntryPoints); len(entryPoints) > 0 {
+		fmt.Printf("%s: the certificate is not bound to the entry points %s anymore, use TLS stores and options if needed. See https://docs.traefik.io/https/tls/\n", name, strings.Join(entryPoints, ", "))
+	}
+
+	return &tls.CertAndStores{
+		Certificate: tls.Certificate{
+			CertFile: tls.FileOrContent(certFile),
+			KeyFile:  tls.FileOrContent(keyFile),
+		},
+	}
+}
+
+// logUnused prints the keys that have not been converted.
+// The v1 static configuration stored in the KV store is reported by root key.
+func logUnused(root *tree) {
+	static := map[string]struct{}{}
+
+	for _, key := range root.unused() {
+		parts := strings.SplitN(key, "/", 2)
+
+		switch strings.ToLower(parts[0]) {
+		case pathBackends, pathFrontends, pathTLS:
+			fmt.Printf("The key %s must be converted manually.\n", key)
+		default:
+			if _, ok := static[parts[0]]; ok {
+				continue
+			}
+			static[parts[0]] = struct{}{}
+
+			fmt.Printf("The static configuration %s must be converted manually. See https://docs.traefik.io/migration/v1-to-v2/\n", parts[0])
+		}
+	}
+}
+
+// encodePairs converts a dynamic configuration to the v2 KV layout (ex: traefik/http/routers/foo/entryPoints/0).
+// The empty structures are encoded as "true", as expected by the v2 KV provider (ex: ipWhiteList/ipStrategy).
+func encodePairs(conf *dynamic.Configuration, prefix string) (map[string]string, error) {
+	toEncode := &dynamic.Configuration{TLS: conf.TLS}
+	if conf.HTTP != nil {
+		toEncode.HTTP = &dynamic.HTTPConfiguration{}
+		if len(conf.HTTP.Routers) > 0 {
+			toEncode.HTTP.Routers = conf.HTTP.Routers
+		}
+		if len(conf.HTTP.Services) > 0 {
+			toEncode.HTTP.Services = conf.HTTP.Services
+		}
+		if len(conf.HTTP.Middlewares) > 0 {
+			toEncode.HTTP.Middlewares = conf.HTTP.Middlewares
+		}
+	}
+
+	raw, err := json.Marshal(toEncode)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree interface{}
+	err = json.Unmarshal(raw, &tree)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := map[string]string{}
+	flatten(pairs, strings.Trim(prefix, "/"), tree)
+
+	return pairs, nil
+}
+
+func flatten(pairs map[string]string, key string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			pairs[key] = "true"
+			return
+		}
+		for name, child := range v {
+			flatten(pairs, key+"/"+name, child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flatten(pairs, key+"/"+strconv.Itoa(i), child)
+		}
+	case float64:
+		pairs[key] = strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+	default:
+		pairs[key] = fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,273 @@
+package kv
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	traefikkv "github.com/traefik/traefik/v2/pkg/config/kv"
+	"gopkg.in/yaml.v2"
+)
+
+var updateExpected = flag.Bool("update_expected", false, "Update expected files in testdata")
+
+// memoryStore is an in-memory kvStore.
+type memoryStore struct {
+	pairs map[string]string
+}
+
+func (m *memoryStore) Delete(key string) error {
+	delete(m.pairs, key)
+	return nil
+}
+
+func (m *memoryStore) Close() {}
+
+func (m *memoryStore) Put(key string, value []byte, _ *store.WriteOptions) error {
+	m.pairs[key] = string(value)
+	return nil
+}
+
+func (m *memoryStore) List(directory string, _ *store.ReadOptions) ([]*store.KVPair, error) {
+	var pairs []*store.KVPair
+	for key, value := range m.pairs {
+		if strings.HasPrefix(strings.TrimPrefix(key, "/"), strings.TrimPrefix(directory, "/")) {
+			pairs = append(pairs, &store.KVPair{Key: key, Value: []byte(value)})
+		}
+	}
+
+	if len(pairs) == 0 {
+		return nil, store.ErrKeyNotFound
+	}
+
+	return pairs, nil
+}
+
+func readPairs(t *testing.T, filename string) map[string]string {
+	t.Helper()
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	pairs := map[string]string{}
+	require.NoError(t, yaml.Unmarshal(content, &pairs))
+
+	return pairs
+}
+
+func Test_migrate(t *testing.T) {
+	testCases := []string{
+		"simple.yml",
+		"unsupported.yml",
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test, func(t *testing.T) {
+			src := &memoryStore{pairs: map[string]string{}}
+			for key, value := range readPairs(t, filepath.Join("fixtures", "input", test)) {
+				src.pairs["traefik/"+key] = value
+			}
+
+			dst := &memoryStore{pairs: map[string]string{}}
+
+			err := migrate(src, StoreConfig{Prefix: "traefik"}, dst, StoreConfig{Prefix: "traefik"}, MigrateOptions{})
+			require.NoError(t, err)
+
+			fixtureFile := filepath.Join("fixtures", "output", test)
+
+			if *updateExpected {
+				output, err := yaml.Marshal(dst.pairs)
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(fixtureFile, output, 0666))
+			}
+
+			assert.Equal(t, readPairs(t, fixtureFile), dst.pairs)
+
+			// The keys must be readable by the Traefik v2 KV provider.
+			var kvPairs []*store.KVPair
+			for key, value := range dst.pairs {
+				kvPairs = append(kvPairs, &store.KVPair{Key: key, Value: []byte(value)})
+			}
+			require.NoError(t, traefikkv.Decode(kvPairs, &dynamic.Configuration{}, "traefik"))
+		})
+	}
+}
+
+func Test_export(t *testing.T) {
+	testCases := []string{
+		"simple.yml",
+		"unsupported.yml",
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test, func(t *testing.T) {
+			src := &memoryStore{pairs: map[string]string{}}
+			for key, value := range readPairs(t, filepath.Join("fixtures", "input", test)) {
+				src.pairs["traefik/"+key] = value
+			}
+
+			dstFile := filepath.Join(t.TempDir(), "dynamic.yml")
+
+			err := export(src, StoreConfig{Prefix: "traefik"}, dstFile)
+			require.NoError(t, err)
+
+			output, err := os.ReadFile(dstFile)
+			require.NoError(t, err)
+
+			fixtureFile := filepath.Join("fixtures", "output", "file", test)
+
+			if *updateExpected {
+				require.NoError(t, os.WriteFile(fixtureFile, output, 0666))
+			}
+
+			fixture, err := os.ReadFile(fixtureFile)
+			require.NoError(t, err)
+
+			assert.YAMLEq(t, string(fixture), string(output))
+		})
+	}
+}
+
+func Test_migrate_inPlace(t *testing.T) {
+	v1 := map[string]string{
+		"traefik/backends/backend1/servers/server1/url": "http://172.17.0.2:80",
+		"traefik/frontends/frontend1/backend":           "backend1",
+		"traefik/frontends/frontend1/routes/test/rule":  "Host:test.localhost",
+	}
+
+	testCases := []struct {
+		desc     string
+		opts     MigrateOptions
+		expected []string
+	}{
+		{
+			desc: "dry run",
+			opts: MigrateOptions{DryRun: true, Prune: true},
+			expected: []string{
+				"traefik/backends/backend1/servers/server1/url",
+				"traefik/frontends/frontend1/backend",
+				"traefik/frontends/frontend1/routes/test/rule",
+				"traefik/http/routers/frontend1/service",
+			},
+		},
+		{
+			desc: "keep the v1 keys",
+			expected: []string{
+				"traefik/backends/backend1/servers/server1/url",
+				"traefik/frontends/frontend1/backend",
+				"traefik/frontends/frontend1/routes/test/rule",
+				"traefik/http/routers/frontend1/rule",
+				"traefik/http/routers/frontend1/service",
+				"traefik/http/services/backend1/loadBalancer/passHostHeader",
+				"traefik/http/services/backend1/loadBalancer/servers/0/url",
+			},
+		},
+		{
+			desc: "prune",
+			opts: MigrateOptions{Prune: true},
+			expected: []string{
+				"traefik/http/routers/frontend1/rule",
+				"traefik/http/routers/frontend1/service",
+				"traefik/http/services/backend1/loadBalancer/passHostHeader",
+				"traefik/http/services/backend1/loadBalancer/servers/0/url",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			kvStore := &memoryStore{pairs: map[string]string{
+				// Existing v2 key, with an outdated value.
+				"traefik/http/routers/frontend1/service": "old",
+			}}
+			for key, value := range v1 {
+				kvStore.pairs[key] = value
+			}
+
+			err := migrate(kvStore, StoreConfig{Prefix: "traefik"}, kvStore, StoreConfig{Prefix: "traefik"}, test.opts)
+			require.NoError(t, err)
+
+			var keys []string
+			for key := range kvStore.pairs {
+				keys = append(keys, key)
+			}
+			assert.ElementsMatch(t, test.expected, keys)
+		})
+	}
+}
+
+func Test_diffKeys(t *testing.T) {
+	existing := map[string]string{"a": "1", "b": "2", "c": "3"}
+	keys := map[string]string{"a": "1", "b": "20", "d": "4"}
+
+	diff := diffKeys(existing, keys)
+
+	assert.Equal(t, []string{"d"}, diff.created)
+	assert.Equal(t, []string{"b"}, diff.modified)
+	assert.Equal(t, []string{"c"}, diff.orphaned)
+}
+
+func Test_migrate_noKeys(t *testing.T) {
+	src := &memoryStore{pairs: map[string]string{"other/frontends/frontend1/backend": "backend1"}}
+
+	err := migrate(src, StoreConfig{Prefix: "traefik"}, &memoryStore{pairs: map[string]string{}}, StoreConfig{Prefix: "traefik"}, MigrateOptions{})
+	require.EqualError(t, err, "no key found under traefik")
+}
+
+func Test_tree_getList(t *testing.T) {
+	root := newTree(map[string]string{
+		"a":   "foo, bar",
+		"b/0": "foo",
+		"b/1": "bar",
+	})
+
+	assert.Equal(t, []string{"foo", "bar"}, root.getList("a"))
+	assert.Equal(t, []string{"foo", "bar"}, root.getList("b"))
+	assert.Nil(t, root.getList("c"))
+	assert.Empty(t, root.unused())
+}
+
+func Test_readTree(t *testing.T) {
+	// The etcd v2 API returns the directories, with a leading slash.
+	// The ZooKeeper parent znodes can hold the SOH character.
+	src := &memoryStore{pairs: map[string]string{
+		"/traefik/frontends":                              "\x01",
+		"/traefik/frontends/frontend1/passhostheader":     "\x01",
+		"/traefik/frontends/frontend1":                    "",
+		"/traefik/frontends/frontend1/backend":            "backend1",
+		"/traefik/frontends/frontend1/routes/test_1/rule": "Host:test.localhost",
+		"/traefik2/frontends/frontend2/backend":           "backend2",
+	}}
+
+	pairs, err := readTree(src, "/traefik")
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"frontends/frontend1/backend":            "backend1",
+		"frontends/frontend1/passhostheader":     "",
+		"frontends/frontend1/routes/test_1/rule": "Host:test.localhost",
+	}
+	assert.Equal(t, expected, pairs)
+}
+
+func Test_migrate_zkValueSize(t *testing.T) {
+	src := &memoryStore{pairs: map[string]string{
+		"traefik/tls/mycert/certificate/certfile": strings.Repeat("a", zkMaxValueSize),
+		"traefik/tls/mycert/certificate/keyfile":  "/certs/key.pem",
+	}}
+	dst := &memoryStore{pairs: map[string]string{}}
+
+	err := migrate(src, StoreConfig{Prefix: "traefik"}, dst, StoreConfig{Backend: "zk", Prefix: "traefik"}, MigrateOptions{})
+	require.EqualError(t, err, "the value of traefik/tls/certificates/0/certFile (1048576 bytes) exceeds the maximum size of a znode, and the values are not chunked because Traefik reads each key from a single znode: use a file for this value")
+
+	assert.Empty(t, dst.pairs)
+}
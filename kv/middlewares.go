@@ -0,0 +1,360 @@
+package kv
+
+import (
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+func getFrontendMiddlewares(frontend *tree) map[string]*dynamic.Middleware {
+	middlewares := map[string]*dynamic.Middleware{}
+
+	single := map[string]func(*tree) *dynamic.Middleware{
+		"headers":     getHeadersMiddleware,
+		"auth":        getAuthMiddleware,
+		"whitelist":   getWhiteListMiddleware,
+		"redirect":    getRedirectMiddleware,
+		"passtlscert": getPassTLSClientCertMiddleware,
+	}
+
+	for key, fn := range single {
+		if middleware := fn(frontend); middleware != nil {
+			middlewares[key] = middleware
+		}
+	}
+
+	for key, middleware := range getErrorPagesMiddlewares(frontend) {
+		middlewares[key] = middleware
+	}
+
+	for key, middleware := range getRateLimitMiddlewares(frontend) {
+		middlewares[key] = middleware
+	}
+
+	return middlewares
+}
+
+func getBackendMiddlewares(backend *tree) map[string]*dynamic.Middleware {
+	middlewares := map[string]*dynamic.Middleware{}
+
+	single := map[string]func(*tree) *dynamic.Middleware{
+		"circuitbreaker": getCircuitBreakerMiddleware,
+		"inflightreq":    getInFlightReqMiddleware,
+		"buffering":      getBufferingMiddleware,
+	}
+
+	for key, fn := range single {
+		if middleware := fn(backend); middleware != nil {
+			middlewares[key] = middleware
+		}
+	}
+
+	return middlewares
+}
+
+func getHeadersMiddleware(frontend *tree) *dynamic.Middleware {
+	headers := &dynamic.Headers{
+		CustomRequestHeaders:    frontend.getMap(pathFrontendCustomRequestHeaders),
+		CustomResponseHeaders:   frontend.getMap(pathFrontendCustomResponseHeaders),
+		AllowedHosts:            frontend.getList(pathFrontendAllowedHosts),
+		HostsProxyHeaders:       frontend.getList(pathFrontendHostsProxyHeaders),
+		SSLForceHost:            frontend.getBool(pathFrontendSSLForceHost, false),
+		SSLRedirect:             frontend.getBool(pathFrontendSSLRedirect, false),
+		SSLTemporaryRedirect:    frontend.getBool(pathFrontendSSLTemporaryRedirect, false),
+		SSLHost:                 frontend.getString(pathFrontendSSLHost, ""),
+		SSLProxyHeaders:         frontend.getMap(pathFrontendSSLProxyHeaders),
+		STSSeconds:              frontend.getInt64(pathFrontendSTSSeconds, 0),
+		STSIncludeSubdomains:    frontend.getBool(pathFrontendSTSIncludeSubdomains, false),
+		STSPreload:              frontend.getBool(pathFrontendSTSPreload, false),
+		ForceSTSHeader:          frontend.getBool(pathFrontendForceSTSHeader, false),
+		FrameDeny:               frontend.getBool(pathFrontendFrameDeny, false),
+		CustomFrameOptionsValue: frontend.getString(pathFrontendCustomFrameOptionsValue, ""),
+		ContentTypeNosniff:      frontend.getBool(pathFrontendContentTypeNosniff, false),
+		BrowserXSSFilter:        frontend.getBool(pathFrontendBrowserXSSFilter, false),
+		CustomBrowserXSSValue:   frontend.getString(pathFrontendCustomBrowserXSSValue, ""),
+		ContentSecurityPolicy:   frontend.getString(pathFrontendContentSecurityPolicy, ""),
+		PublicKey:               frontend.getString(pathFrontendPublicKey, ""),
+		ReferrerPolicy:          frontend.getString(pathFrontendReferrerPolicy, ""),
+		IsDevelopment:           frontend.getBool(pathFrontendIsDevelopment, false),
+	}
+
+	if !headers.HasCustomHeadersDefined() && !headers.HasCorsHeadersDefined() && !headers.HasSecureHeadersDefined() {
+		return nil
+	}
+
+	return &dynamic.Middleware{Headers: headers}
+}
+
+func getAuthMiddleware(frontend *tree) *dynamic.Middleware {
+	headerField := frontend.getString(pathFrontendAuthHeaderField, "")
+
+	if frontend.has(pathFrontendAuthForwardAddress) {
+		forward := &dynamic.ForwardAuth{
+			Address:             frontend.getString(pathFrontendAuthForwardAddress, ""),
+			TrustForwardHeader:  frontend.getBool(pathFrontendAuthForwardTrustForwardHeader, false),
+			AuthResponseHeaders: frontend.getList(pathFrontendAuthForwardAuthResponseHeaders),
+		}
+
+		if frontend.has("auth/forward/tls") {
+			forward.TLS = &dynamic.ClientTLS{
+				CA:                 frontend.getString(pathFrontendAuthForwardTLSCa, ""),
+				CAOptional:         frontend.getBool(pathFrontendAuthForwardTLSCaOptional, false),
+				Cert:               frontend.getString(pathFrontendAuthForwardTLSCert, ""),
+				Key:                frontend.getString(pathFrontendAuthForwardTLSKey, ""),
+				InsecureSkipVerify: frontend.getBool(pathFrontendAuthForwardTLSInsecureSkipVerify, false),
+			}
+		}
+
+		return &dynamic.Middleware{ForwardAuth: forward}
+	}
+
+	if frontend.has(pathFrontendAuthDigestUsers) || frontend.has(pathFrontendAuthDigestUsersFile) {
+		return &dynamic.Middleware{
+			DigestAuth: &dynamic.DigestAuth{
+				Users:        frontend.getList(pathFrontendAuthDigestUsers),
+				UsersFile:    frontend.getString(pathFrontendAuthDigestUsersFile, ""),
+				RemoveHeader: frontend.getBool(pathFrontendAuthDigestRemoveHeader, false),
+				HeaderField:  headerField,
+			},
+		}
+	}
+
+	users := frontend.getList(pathFrontendAuthBasicUsers)
+	if len(users) == 0 {
+		users = frontend.getList(pathFrontendBasicAuth)
+	}
+
+	if len(users) > 0 || frontend.has(pathFrontendAuthBasicUsersFile) {
+		return &dynamic.Middleware{
+			BasicAuth: &dynamic.BasicAuth{
+				Users:        users,
+				UsersFile:    frontend.getString(pathFrontendAuthBasicUsersFile, ""),
+				RemoveHeader: frontend.getBool(pathFrontendAuthBasicRemoveHeader, false),
+				HeaderField:  headerField,
+			},
+		}
+	}
+
+	return nil
+}
+
+func getWhiteListMiddleware(frontend *tree) *dynamic.Middleware {
+	ranges := frontend.getList(pathFrontendWhiteListSourceRange)
+	if len(ranges) == 0 {
+		ranges = frontend.getList(pathFrontendWhitelistSourceRange)
+	}
+
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	middleware := &dynamic.Middleware{
+		IPWhiteList: &dynamic.IPWhiteList{SourceRange: ranges},
+	}
+
+	if frontend.getBool(pathFrontendWhiteListUseXForwardedFor, false) {
+		middleware.IPWhiteList.IPStrategy = &dynamic.IPStrategy{}
+	}
+
+	return middleware
+}
+
+func getRedirectMiddleware(frontend *tree) *dynamic.Middleware {
+	permanent := frontend.getBool(pathFrontendRedirectPermanent, false)
+
+	if entryPoint := frontend.getString(pathFrontendRedirectEntryPoint, ""); entryPoint != "" {
+		log.Printf("The redirect to the entry point %q is converted to a redirect to https: check the entry point configuration.", entryPoint)
+
+		return &dynamic.Middleware{
+			RedirectScheme: &dynamic.RedirectScheme{Scheme: "https", Permanent: permanent},
+		}
+	}
+
+	regex := frontend.getString(pathFrontendRedirectRegex, "")
+	replacement := frontend.getString(pathFrontendRedirectReplacement, "")
+	if regex == "" || replacement == "" {
+		return nil
+	}
+
+	return &dynamic.Middleware{
+		RedirectRegex: &dynamic.RedirectRegex{Regex: regex, Replacement: replacement, Permanent: permanent},
+	}
+}
+
+func getPassTLSClientCertMiddleware(frontend *tree) *dynamic.Middleware {
+	if !frontend.getBool(pathFrontendPassTLSCert, false) {
+		return nil
+	}
+
+	return &dynamic.Middleware{
+		PassTLSClientCert: &dynamic.PassTLSClientCert{PEM: true},
+	}
+}
+
+func getErrorPagesMiddlewares(frontend *tree) map[string]*dynamic.Middleware {
+	middlewares := map[string]*dynamic.Middleware{}
+
+	for _, name := range frontend.children(pathFrontendErrorPages) {
+		page := frontend.sub(pathFrontendErrorPages, name)
+
+		middlewares["errors-"+name] = &dynamic.Middleware{
+			Errors: &dynamic.ErrorPage{
+				Status:  page.getList(pathFrontendErrorPagesStatus),
+				Service: page.getString(pathFrontendErrorPagesBackend, ""),
+				Query:   page.getString(pathFrontendErrorPagesQuery, ""),
+			},
+		}
+	}
+
+	return middlewares
+}
+
+func getRateLimitMiddlewares(frontend *tree) map[string]*dynamic.Middleware {
+	sourceCriterion := getSourceCriterion(frontend.getString(pathFrontendRateLimitExtractorFunc, ""))
+
+	middlewares := map[string]*dynamic.Middleware{}
+	for _, name := range frontend.children(pathFrontendRateLimitRateSets) {
+		rateSet := frontend.sub(pathFrontendRateLimitRateSets, name)
+
+		rawPeriod := rateSet.getString(pathFrontendRateLimitPeriod, "")
+		period, err := time.ParseDuration(rawPeriod)
+		if err != nil || period < time.Second {
+			log.Printf("Invalid rate limit period %q for the rate set %q, skipping...", rawPeriod, name)
+			continue
+		}
+
+		middlewares["ratelimit-"+name] = &dynamic.Middleware{
+			RateLimit: &dynamic.RateLimit{
+				Average:         rateSet.getInt64(pathFrontendRateLimitAverage, 0) / int64(period/time.Second),
+				Burst:           rateSet.getInt64(pathFrontendRateLimitBurst, 0),
+				SourceCriterion: sourceCriterion,
+			},
+		}
+	}
+
+	return middlewares
+}
+
+func getCircuitBreakerMiddleware(backend *tree) *dynamic.Middleware {
+	expression := backend.getString(pathBackendCircuitBreakerExpression, "")
+	if expression == "" {
+		return nil
+	}
+
+	return &dynamic.Middleware{
+		CircuitBreaker: &dynamic.CircuitBreaker{Expression: expression},
+	}
+}
+
+func getInFlightReqMiddleware(backend *tree) *dynamic.Middleware {
+	amount := backend.getInt64(pathBackendMaxConnAmount, 0)
+	if amount <= 0 {
+		return nil
+	}
+
+	return &dynamic.Middleware{
+		InFlightReq: &dynamic.InFlightReq{
+			Amount:          amount,
+			SourceCriterion: getSourceCriterion(backend.getString(pathBackendMaxConnExtractorFunc, "")),
+		},
+	}
+}
+
+func getBufferingMiddleware(backend *tree) *dynamic.Middleware {
+	if !backend.has("buffering") {
+		return nil
+	}
+
+	return &dynamic.Middleware{
+		Buffering: &dynamic.Buffering{
+			MaxRequestBodyBytes:  backend.getInt64(pathBackendBufferingMaxRequestBodyBytes, 0),
+			MemRequestBodyBytes:  backend.getInt64(pathBackendBufferingMemRequestBodyBytes, 0),
+			MaxResponseBodyBytes: backend.getInt64(pathBackendBufferingMaxResponseBodyBytes, 0),
+			MemResponseBodyBytes: backend.getInt64(pathBackendBufferingMemResponseBodyBytes, 0),
+			RetryExpression:      backend.getString(pathBackendBufferingRetryExpression, ""),
+		},
+	}
+}
+
+func getSourceCriterion(extractorFunc string) *dynamic.SourceCriterion {
+	switch {
+	case extractorFunc == "request.host":
+		return &dynamic.SourceCriterion{RequestHost: true}
+	case strings.HasPrefix(extractorFunc, "request.header."):
+		return &dynamic.SourceCriterion{RequestHeaderName: strings.TrimPrefix(extractorFunc, "request.header.")}
+	default:
+		return nil
+	}
+}
+
+func getSticky(backend *tree) *dynamic.Sticky {
+	if !backend.getBool(pathBackendLoadBalancerStickiness, false) && !backend.getBool(pathBackendLoadBalancerSticky, false) {
+		return nil
+	}
+
+	return &dynamic.Sticky{
+		Cookie: &dynamic.Cookie{
+			Name: backend.getString(pathBackendLoadBalancerStickinessCookieName, ""),
+		},
+	}
+}
+
+func getHealthCheck(backend *tree) *dynamic.HealthCheck {
+	healthCheckPath := backend.getString(pathBackendHealthCheckPath, "")
+	if healthCheckPath == "" {
+		return nil
+	}
+
+	healthCheck := &dynamic.HealthCheck{
+		Scheme:   backend.getString(pathBackendHealthCheckScheme, ""),
+		Path:     healthCheckPath,
+		Port:     backend.getInt(pathBackendHealthCheckPort, 0),
+		Interval: backend.getString(pathBackendHealthCheckInterval, ""),
+		Timeout:  backend.getString(pathBackendHealthCheckTimeout, ""),
+		Hostname: backend.getString(pathBackendHealthCheckHostname, ""),
+		Headers:  backend.getMap(pathBackendHealthCheckHeaders),
+	}
+	healthCheck.SetDefaults()
+
+	return healthCheck
+}
+
+func getResponseForwarding(backend *tree) *dynamic.ResponseForwarding {
+	flushInterval := backend.getString(pathBackendResponseForwardingFlushInterval, "")
+	if flushInterval == "" {
+		return nil
+	}
+
+	return &dynamic.ResponseForwarding{FlushInterval: flushInterval}
+}
+
+func getServers(name string, backend *tree) []dynamic.Server {
+	var servers []dynamic.Server
+	for _, server := range backend.children(pathBackendServers) {
+		url := backend.getString(path.Join(pathBackendServers, server, pathBackendServerURL), "")
+		if url == "" {
+			continue
+		}
+
+		if weight := backend.getInt(path.Join(pathBackendServers, server, pathBackendServerWeight), 1); weight != 1 {
+			log.Printf("The weight of the server %q of the backend %q must be converted manually. See https://docs.traefik.io/routing/services/#weighted-round-robin-service", server, name)
+		}
+
+		servers = append(servers, dynamic.Server{URL: url})
+	}
+
+	return servers
+}
+
+func sortedKeys(m map[string]*dynamic.Middleware) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
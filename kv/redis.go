@@ -0,0 +1,97 @@
+package kv
+
+import (
+	"crypto/tls"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"gopkg.in/redis.v5"
+)
+
+// redisStore is a Redis client writing the keys as the Traefik v2 Redis provider reads them (raw values).
+// It supports the database selection and TLS, which the valkeyrie Redis store doesn't.
+type redisStore struct {
+	client *redis.Client
+}
+
+func newRedisStore(endpoints []string, config StoreConfig, tlsConfig *tls.Config) (*redisStore, error) {
+	if len(endpoints) != 1 {
+		return nil, errors.New("redis: exactly one endpoint is required")
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         endpoints[0],
+		Password:     config.Password,
+		DB:           config.DB,
+		TLSConfig:    tlsConfig,
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	})
+
+	if err := client.Ping().Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	return &redisStore{client: client}, nil
+}
+
+func (r *redisStore) Put(key string, value []byte, _ *store.WriteOptions) error {
+	return r.client.Set(normalizeKey(key), value, 0).Err()
+}
+
+func (r *redisStore) List(directory string, _ *store.ReadOptions) ([]*store.KVPair, error) {
+	var keys []string
+
+	var cursor uint64
+	for {
+		page, next, err := r.client.Scan(cursor, normalizeKey(directory)+"*", 100).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, page...)
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, store.ErrKeyNotFound
+	}
+
+	values, err := r.client.MGet(keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []*store.KVPair
+	for i, key := range keys {
+		value, ok := values[i].(string)
+		if !ok {
+			continue
+		}
+
+		pairs = append(pairs, &store.KVPair{Key: key, Value: []byte(value)})
+	}
+
+	return pairs, nil
+}
+
+func (r *redisStore) Delete(key string) error {
+	return r.client.Del(normalizeKey(key)).Err()
+}
+
+func (r *redisStore) Close() {
+	_ = r.client.Close()
+}
+
+// normalizeKey removes the leading slash of a key, as the valkeyrie stores do.
+func normalizeKey(key string) string {
+	return strings.TrimPrefix(key, "/")
+}
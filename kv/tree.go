@@ -0,0 +1,177 @@
+package kv
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tree is a view on a v1 KV tree, the keys are relative to the root of the view (ex: "frontends/frontend1").
+// The keys are matched case-insensitively, as the v1 KV provider did.
+// The keys read through a view are tracked to report the keys that are not converted.
+type tree struct {
+	root  string
+	pairs map[string]string
+	used  map[string]struct{}
+}
+
+func newTree(pairs map[string]string) *tree {
+	return &tree{pairs: pairs, used: map[string]struct{}{}}
+}
+
+func (t *tree) sub(names ...string) *tree {
+	return &tree{root: t.key(names...), pairs: t.pairs, used: t.used}
+}
+
+func (t *tree) key(names ...string) string {
+	return path.Join(append([]string{t.root}, names...)...)
+}
+
+func (t *tree) lookup(name string) (string, bool) {
+	key := t.key(name)
+
+	if value, ok := t.pairs[key]; ok {
+		t.used[key] = struct{}{}
+		return value, true
+	}
+
+	for k, value := range t.pairs {
+		if strings.EqualFold(k, key) {
+			t.used[k] = struct{}{}
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// children returns the sorted names of the direct children of a key.
+func (t *tree) children(name string) []string {
+	prefix := strings.ToLower(t.key(name)) + "/"
+	if prefix == "/" {
+		prefix = ""
+	}
+
+	seen := map[string]struct{}{}
+	var names []string
+	for k := range t.pairs {
+		if !strings.HasPrefix(strings.ToLower(k), prefix) {
+			continue
+		}
+
+		child := strings.SplitN(k[len(prefix):], "/", 2)[0]
+		if _, ok := seen[child]; ok || child == "" {
+			continue
+		}
+
+		seen[child] = struct{}{}
+		names = append(names, child)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (t *tree) has(name string) bool {
+	if _, ok := t.lookup(name); ok {
+		return true
+	}
+
+	return len(t.children(name)) > 0
+}
+
+func (t *tree) getString(name, defaultValue string) string {
+	if value, ok := t.lookup(name); ok {
+		return value
+	}
+
+	return defaultValue
+}
+
+func (t *tree) getBool(name string, defaultValue bool) bool {
+	if value, ok := t.lookup(name); ok {
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v
+		}
+	}
+
+	return defaultValue
+}
+
+func (t *tree) getInt(name string, defaultValue int) int {
+	if value, ok := t.lookup(name); ok {
+		if v, err := strconv.Atoi(value); err == nil {
+			return v
+		}
+	}
+
+	return defaultValue
+}
+
+func (t *tree) getInt64(name string, defaultValue int64) int64 {
+	if value, ok := t.lookup(name); ok {
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	}
+
+	return defaultValue
+}
+
+// getList returns the values of the children of a key (ex: entrypoints/0, entrypoints/1),
+// or the comma separated values of the key.
+func (t *tree) getList(name string) []string {
+	var values []string
+
+	children := t.children(name)
+	if len(children) == 0 {
+		value, _ := t.lookup(name)
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+
+		return values
+	}
+
+	for _, child := range children {
+		if value, ok := t.lookup(path.Join(name, child)); ok {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// getMap returns the children of a key as a map (ex: headers/customrequestheaders/X-Foo).
+func (t *tree) getMap(name string) map[string]string {
+	values := map[string]string{}
+	for _, child := range t.children(name) {
+		if value, ok := t.lookup(path.Join(name, child)); ok {
+			values[child] = value
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values
+}
+
+// unused returns the sorted keys of the tree that have not been read.
+func (t *tree) unused() []string {
+	var keys []string
+	for k := range t.pairs {
+		if _, ok := t.used[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,126 @@
+package kv
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// zkStore is a ZooKeeper client supporting the digest authentication and TLS, which the valkeyrie ZooKeeper store doesn't.
+// The keys are znodes paths, the parent znodes are created without data.
+type zkStore struct {
+	conn *zk.Conn
+	acl  []zk.ACL
+}
+
+func newZKStore(endpoints []string, config StoreConfig, tlsConfig *tls.Config) (*zkStore, error) {
+	dialer := net.DialTimeout
+	if tlsConfig != nil {
+		dialer = func(network, address string, timeout time.Duration) (net.Conn, error) {
+			return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, network, address, tlsConfig)
+		}
+	}
+
+	conn, _, err := zk.Connect(endpoints, 10*time.Second, zk.WithDialer(dialer))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &zkStore{conn: conn, acl: zk.WorldACL(zk.PermAll)}
+
+	if config.Username != "" {
+		err = conn.AddAuth("digest", []byte(config.Username+":"+config.Password))
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		// The created znodes are restricted to the authenticated user.
+		s.acl = zk.AuthACL(zk.PermAll)
+	}
+
+	return s, nil
+}
+
+func (s *zkStore) Put(key string, value []byte, _ *store.WriteOptions) error {
+	znode := "/" + normalizeKey(key)
+
+	exists, _, err := s.conn.Exists(znode)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		_, err = s.conn.Set(znode, value, -1)
+		return err
+	}
+
+	parts := strings.Split(strings.Trim(znode, "/"), "/")
+	for i := 1; i < len(parts); i++ {
+		_, err = s.conn.Create("/"+strings.Join(parts[:i], "/"), nil, 0, s.acl)
+		if err != nil && !errors.Is(err, zk.ErrNodeExists) {
+			return err
+		}
+	}
+
+	_, err = s.conn.Create(znode, value, 0, s.acl)
+	return err
+}
+
+func (s *zkStore) List(directory string, _ *store.ReadOptions) ([]*store.KVPair, error) {
+	var pairs []*store.KVPair
+
+	err := s.list("/"+strings.Trim(normalizeKey(directory), "/"), &pairs)
+	if errors.Is(err, zk.ErrNoNode) {
+		return nil, store.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
+func (s *zkStore) list(znode string, pairs *[]*store.KVPair) error {
+	children, _, err := s.conn.Children(znode)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		childPath := path.Join(znode, child)
+
+		value, _, err := s.conn.Get(childPath)
+		if err != nil {
+			return err
+		}
+
+		*pairs = append(*pairs, &store.KVPair{Key: childPath, Value: value})
+
+		err = s.list(childPath, pairs)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *zkStore) Delete(key string) error {
+	err := s.conn.Delete("/"+normalizeKey(key), -1)
+	if errors.Is(err, zk.ErrNoNode) {
+		return store.ErrKeyNotFound
+	}
+
+	return err
+}
+
+func (s *zkStore) Close() {
+	s.conn.Close()
+}
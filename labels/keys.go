@@ -0,0 +1,200 @@
+package labels
+
+const (
+	labelEnable   = "traefik.enable"
+	labelPort     = "traefik.port"
+	labelProtocol = "traefik.protocol"
+	labelWeight   = "traefik.weight"
+	labelBackend  = "traefik.backend"
+	labelTags     = "traefik.tags"
+
+	// Router.
+	labelFrontendRule           = "traefik.frontend.rule"
+	labelFrontendEntryPoints    = "traefik.frontend.entryPoints"
+	labelFrontendPriority       = "traefik.frontend.priority"
+	labelFrontendPassHostHeader = "traefik.frontend.passHostHeader"
+	labelFrontendPassTLSCert    = "traefik.frontend.passTLSCert"
+
+	// AuthMiddleware.
+	labelFrontendAuthBasic                        = "traefik.frontend.auth.basic" // Deprecated
+	labelFrontendAuthBasicUsers                   = "traefik.frontend.auth.basic.users"
+	labelFrontendAuthBasicUsersFile               = "traefik.frontend.auth.basic.usersFile"
+	labelFrontendAuthBasicRemoveHeader            = "traefik.frontend.auth.basic.removeHeader"
+	labelFrontendAuthDigestUsers                  = "traefik.frontend.auth.digest.users"
+	labelFrontendAuthDigestUsersFile              = "traefik.frontend.auth.digest.usersFile"
+	labelFrontendAuthDigestRemoveHeader           = "traefik.frontend.auth.digest.removeHeader"
+	labelFrontendAuthHeaderField                  = "traefik.frontend.auth.headerField"
+	labelFrontendAuthForwardAddress               = "traefik.frontend.auth.forward.address"
+	labelFrontendAuthForwardTrustForwardHeader    = "traefik.frontend.auth.forward.trustForwardHeader"
+	labelFrontendAuthForwardAuthResponseHeaders   = "traefik.frontend.auth.forward.authResponseHeaders"
+	labelFrontendAuthForwardTLSCa                 = "traefik.frontend.auth.forward.tls.ca"
+	labelFrontendAuthForwardTLSCaOptional         = "traefik.frontend.auth.forward.tls.caOptional"
+	labelFrontendAuthForwardTLSCert               = "traefik.frontend.auth.forward.tls.cert"
+	labelFrontendAuthForwardTLSKey                = "traefik.frontend.auth.forward.tls.key"
+	labelFrontendAuthForwardTLSInsecureSkipVerify = "traefik.frontend.auth.forward.tls.insecureSkipVerify"
+
+	// HeadersMiddleware.
+	labelFrontendRequestHeaders          = "traefik.frontend.headers.customRequestHeaders"
+	labelFrontendResponseHeaders         = "traefik.frontend.headers.customResponseHeaders"
+	labelFrontendAllowedHosts            = "traefik.frontend.headers.allowedHosts"
+	labelFrontendHostsProxyHeaders       = "traefik.frontend.headers.hostsProxyHeaders"
+	labelFrontendSSLForceHost            = "traefik.frontend.headers.SSLForceHost"
+	labelFrontendSSLRedirect             = "traefik.frontend.headers.SSLRedirect"
+	labelFrontendSSLTemporaryRedirect    = "traefik.frontend.headers.SSLTemporaryRedirect"
+	labelFrontendSSLHost                 = "traefik.frontend.headers.SSLHost"
+	labelFrontendSSLProxyHeaders         = "traefik.frontend.headers.SSLProxyHeaders"
+	labelFrontendSTSSeconds              = "traefik.frontend.headers.STSSeconds"
+	labelFrontendSTSIncludeSubdomains    = "traefik.frontend.headers.STSIncludeSubdomains"
+	labelFrontendSTSPreload              = "traefik.frontend.headers.STSPreload"
+	labelFrontendForceSTSHeader          = "traefik.frontend.headers.forceSTSHeader"
+	labelFrontendFrameDeny               = "traefik.frontend.headers.frameDeny"
+	labelFrontendCustomFrameOptionsValue = "traefik.frontend.headers.customFrameOptionsValue"
+	labelFrontendContentTypeNosniff      = "traefik.frontend.headers.contentTypeNosniff"
+	labelFrontendBrowserXSSFilter        = "traefik.frontend.headers.browserXSSFilter"
+	labelFrontendCustomBrowserXSSValue   = "traefik.frontend.headers.customBrowserXSSValue"
+	labelFrontendContentSecurityPolicy   = "traefik.frontend.headers.contentSecurityPolicy"
+	labelFrontendPublicKey               = "traefik.frontend.headers.publicKey"
+	labelFrontendReferrerPolicy          = "traefik.frontend.headers.referrerPolicy"
+	labelFrontendIsDevelopment           = "traefik.frontend.headers.isDevelopment"
+
+	// WhitelistMiddleware.
+	labelFrontendWhitelistSourceRange      = "traefik.frontend.whitelistSourceRange" // Deprecated
+	labelFrontendWhiteListSourceRange      = "traefik.frontend.whiteList.sourceRange"
+	labelFrontendWhiteListUseXForwardedFor = "traefik.frontend.whiteList.useXForwardedFor"
+
+	// RedirectMiddleware.
+	labelFrontendRedirectEntryPoint  = "traefik.frontend.redirect.entryPoint"
+	labelFrontendRedirectRegex       = "traefik.frontend.redirect.regex"
+	labelFrontendRedirectReplacement = "traefik.frontend.redirect.replacement"
+	labelFrontendRedirectPermanent   = "traefik.frontend.redirect.permanent"
+
+	// ErrorPagesMiddleware.
+	labelFrontendErrorsPrefix = "traefik.frontend.errors."
+
+	// RateLimitMiddleware.
+	labelFrontendRateLimitExtractorFunc = "traefik.frontend.rateLimit.extractorFunc"
+	labelFrontendRateLimitRateSetPrefix = "traefik.frontend.rateLimit.rateSet."
+
+	// Service.
+	labelBackendLoadBalancerStickiness           = "traefik.backend.loadbalancer.stickiness"
+	labelBackendLoadBalancerStickinessCookieName = "traefik.backend.loadbalancer.stickiness.cookieName"
+	labelBackendLoadBalancerSticky               = "traefik.backend.loadbalancer.sticky" // Deprecated
+	labelBackendHealthCheckScheme                = "traefik.backend.healthcheck.scheme"
+	labelBackendHealthCheckPath                  = "traefik.backend.healthcheck.path"
+	labelBackendHealthCheckPort                  = "traefik.backend.healthcheck.port"
+	labelBackendHealthCheckInterval              = "traefik.backend.healthcheck.interval"
+	labelBackendHealthCheckTimeout               = "traefik.backend.healthcheck.timeout"
+	labelBackendHealthCheckHostname              = "traefik.backend.healthcheck.hostname"
+	labelBackendHealthCheckHeaders               = "traefik.backend.healthcheck.headers"
+	labelBackendResponseForwardingFlushInterval  = "traefik.backend.responseForwarding.flushInterval"
+
+	// CircuitBreakerMiddleware.
+	labelBackendCircuitBreakerExpression = "traefik.backend.circuitbreaker.expression"
+
+	// InFlightReqMiddleware.
+	labelBackendMaxConnAmount        = "traefik.backend.maxconn.amount"
+	labelBackendMaxConnExtractorFunc = "traefik.backend.maxconn.extractorfunc"
+
+	// BufferingMiddleware.
+	labelBackendBufferingMaxRequestBodyBytes  = "traefik.backend.buffering.maxRequestBodyBytes"
+	labelBackendBufferingMemRequestBodyBytes  = "traefik.backend.buffering.memRequestBodyBytes"
+	labelBackendBufferingMaxResponseBodyBytes = "traefik.backend.buffering.maxResponseBodyBytes"
+	labelBackendBufferingMemResponseBodyBytes = "traefik.backend.buffering.memResponseBodyBytes"
+	labelBackendBufferingRetryExpression      = "traefik.backend.buffering.retryExpression"
+)
+
+// knownLabels are the labels handled by the converter.
+var knownLabels = map[string]struct{}{
+	labelEnable:   {},
+	labelPort:     {},
+	labelProtocol: {},
+	labelWeight:   {},
+	labelBackend:  {},
+	labelTags:     {},
+
+	labelFrontendRule:           {},
+	labelFrontendEntryPoints:    {},
+	labelFrontendPriority:       {},
+	labelFrontendPassHostHeader: {},
+	labelFrontendPassTLSCert:    {},
+
+	labelFrontendAuthBasic:                        {},
+	labelFrontendAuthBasicUsers:                   {},
+	labelFrontendAuthBasicUsersFile:               {},
+	labelFrontendAuthBasicRemoveHeader:            {},
+	labelFrontendAuthDigestUsers:                  {},
+	labelFrontendAuthDigestUsersFile:              {},
+	labelFrontendAuthDigestRemoveHeader:           {},
+	labelFrontendAuthHeaderField:                  {},
+	labelFrontendAuthForwardAddress:               {},
+	labelFrontendAuthForwardTrustForwardHeader:    {},
+	labelFrontendAuthForwardAuthResponseHeaders:   {},
+	labelFrontendAuthForwardTLSCa:                 {},
+	labelFrontendAuthForwardTLSCaOptional:         {},
+	labelFrontendAuthForwardTLSCert:               {},
+	labelFrontendAuthForwardTLSKey:                {},
+	labelFrontendAuthForwardTLSInsecureSkipVerify: {},
+
+	labelFrontendRequestHeaders:          {},
+	labelFrontendResponseHeaders:         {},
+	labelFrontendAllowedHosts:            {},
+	labelFrontendHostsProxyHeaders:       {},
+	labelFrontendSSLForceHost:            {},
+	labelFrontendSSLRedirect:             {},
+	labelFrontendSSLTemporaryRedirect:    {},
+	labelFrontendSSLHost:                 {},
+	labelFrontendSSLProxyHeaders:         {},
+	labelFrontendSTSSeconds:              {},
+	labelFrontendSTSIncludeSubdomains:    {},
+	labelFrontendSTSPreload:              {},
+	labelFrontendForceSTSHeader:          {},
+	labelFrontendFrameDeny:               {},
+	labelFrontendCustomFrameOptionsValue: {},
+	labelFrontendContentTypeNosniff:      {},
+	labelFrontendBrowserXSSFilter:        {},
+	labelFrontendCustomBrowserXSSValue:   {},
+	labelFrontendContentSecurityPolicy:   {},
+	labelFrontendPublicKey:               {},
+	labelFrontendReferrerPolicy:          {},
+	labelFrontendIsDevelopment:           {},
+
+	labelFrontendWhitelistSourceRange:      {},
+	labelFrontendWhiteListSourceRange:      {},
+	labelFrontendWhiteListUseXForwardedFor: {},
+
+	labelFrontendRedirectEntryPoint:  {},
+	labelFrontendRedirectRegex:       {},
+	labelFrontendRedirectReplacement: {},
+	labelFrontendRedirectPermanent:   {},
+
+	labelFrontendRateLimitExtractorFunc: {},
+
+	labelBackendLoadBalancerStickiness:           {},
+	labelBackendLoadBalancerStickinessCookieName: {},
+	labelBackendLoadBalancerSticky:               {},
+	labelBackendHealthCheckScheme:                {},
+	labelBackendHealthCheckPath:                  {},
+	labelBackendHealthCheckPort:                  {},
+	labelBackendHealthCheckInterval:              {},
+	labelBackendHealthCheckTimeout:               {},
+	labelBackendHealthCheckHostname:              {},
+	labelBackendHealthCheckHeaders:               {},
+	labelBackendResponseForwardingFlushInterval:  {},
+
+	labelBackendCircuitBreakerExpression: {},
+
+	labelBackendMaxConnAmount:        {},
+	labelBackendMaxConnExtractorFunc: {},
+
+	labelBackendBufferingMaxRequestBodyBytes:  {},
+	labelBackendBufferingMemRequestBodyBytes:  {},
+	labelBackendBufferingMaxResponseBodyBytes: {},
+	labelBackendBufferingMemResponseBodyBytes: {},
+	labelBackendBufferingRetryExpression:      {},
+}
+
+// knownPrefixes are the label prefixes handled by the converter.
+var knownPrefixes = []string{
+	labelFrontendErrorsPrefix,
+	labelFrontendRateLimitRateSetPrefix,
+}
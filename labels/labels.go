@@ -0,0 +1,437 @@
+// Package labels convert Traefik v1 Docker labels to a Traefik v2 dynamic configuration.
+package labels
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/traefik/traefik-migration-tool/label"
+	"github.com/traefik/traefik-migration-tool/rule"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"gopkg.in/yaml.v2"
+)
+
+const defaultProtocol = "http"
+
+var segmentRegexp = regexp.MustCompile(`^traefik\.([^.]+)\.(port|protocol|weight|frontend\..+)$`)
+
+// reservedSegments are the v1 label roots that can't be segment names.
+var reservedSegments = map[string]struct{}{
+	"frontend": {},
+	"backend":  {},
+	"docker":   {},
+}
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Labels composeLabels `yaml:"labels"`
+	Deploy struct {
+		Labels composeLabels `yaml:"labels"`
+	} `yaml:"deploy"`
+}
+
+// composeLabels handles the list ("key=value") and the map syntaxes of the compose labels.
+// The compose escaping of "$" ("$$") is removed from the values.
+type composeLabels map[string]string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *composeLabels) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	*c = composeLabels{}
+
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		for _, item := range list {
+			parts := strings.SplitN(item, "=", 2)
+			if len(parts) == 2 {
+				(*c)[strings.TrimSpace(parts[0])] = unescape(strings.TrimSpace(parts[1]))
+			} else {
+				(*c)[strings.TrimSpace(parts[0])] = ""
+			}
+		}
+		return nil
+	}
+
+	var raw map[string]interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	for key, value := range raw {
+		if value == nil {
+			(*c)[key] = ""
+			continue
+		}
+		(*c)[key] = unescape(fmt.Sprint(value))
+	}
+
+	return nil
+}
+
+func unescape(value string) string {
+	return strings.ReplaceAll(value, "$$", "$")
+}
+
+// Convert converts the Traefik v1 labels of a compose file, or of all the compose files of a directory tree,
+// into Traefik v2 file provider dynamic configurations.
+// In strict mode, the conversion fails if a Traefik label is not handled by the converter.
+func Convert(src, dst string, strict bool) (*Report, error) {
+	if _, err := os.Stat(src); err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+
+	err := WalkComposeFiles(src, dst, func(srcFile, dstFile string) error {
+		return convertFile(report, srcFile, dstFile, strict)
+	})
+	if err != nil {
+		return report, err
+	}
+
+	if unknown := report.unknownLabels(); strict && len(unknown) > 0 {
+		return report, fmt.Errorf("unknown Traefik labels:\n  %s", strings.Join(unknown, "\n  "))
+	}
+
+	return report, nil
+}
+
+// WalkComposeFiles calls fn for the compose file src, or for all the compose files (.yml and .yaml) of the directory tree src.
+// The destination file of a file of the tree is at the same relative path under dst.
+func WalkComposeFiles(src, dst string, fn func(srcFile, dstFile string) error) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fn(src, dst)
+	}
+
+	return filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		ext := filepath.Ext(path)
+		if fi.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		return fn(path, filepath.Join(dst, rel))
+	})
+}
+
+func convertFile(report *Report, src, dstFile string, strict bool) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	compose := composeFile{}
+	err = yaml.Unmarshal(content, &compose)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+
+	conf := &dynamic.HTTPConfiguration{
+		Routers:     map[string]*dynamic.Router{},
+		Services:    map[string]*dynamic.Service{},
+		Middlewares: map[string]*dynamic.Middleware{},
+	}
+
+	var names []string
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fileReport := FileReport{Path: src}
+	for _, name := range names {
+		service := compose.Services[name]
+
+		labels := map[string]string{}
+		for k, v := range service.Labels {
+			labels[k] = v
+		}
+		for k, v := range service.Deploy.Labels {
+			labels[k] = v
+		}
+
+		if !label.HasPrefix(labels, label.Prefix) {
+			continue
+		}
+
+		if !label.GetBoolValue(labels, labelEnable, true) {
+			fmt.Printf("%s: skipped (%s=false).\n", name, labelEnable)
+			fileReport.Skipped = append(fileReport.Skipped, name)
+			continue
+		}
+
+		if tags := label.GetSliceStringValue(labels, labelTags); len(tags) > 0 {
+			fmt.Printf("%s: The tags must be converted to provider constraints. Suggested expression: %s. See https://docs.traefik.io/providers/docker/#constraints\n", name, constraintsExpression(tags))
+		}
+
+		if unknown := unknownLabels(labels); len(unknown) > 0 {
+			if fileReport.UnknownLabels == nil {
+				fileReport.UnknownLabels = map[string][]string{}
+			}
+			fileReport.UnknownLabels[name] = unknown
+
+			if !strict {
+				for _, key := range unknown {
+					fmt.Printf("%s: The label %s must be converted manually.\n", name, key)
+				}
+			}
+		}
+
+		err = convertService(conf, name, labels)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", src, name, err)
+		}
+
+		fileReport.Services = append(fileReport.Services, name)
+	}
+
+	if len(fileReport.Services) == 0 && len(fileReport.Skipped) == 0 {
+		return nil
+	}
+
+	report.Files = append(report.Files, fileReport)
+
+	if strict && len(fileReport.UnknownLabels) > 0 {
+		return nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(dstFile), 0755)
+	if err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(&dynamic.Configuration{HTTP: conf})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(dstFile, out, 0666)
+}
+
+func convertService(conf *dynamic.HTTPConfiguration, name string, labels map[string]string) error {
+	segments := extractSegments(labels)
+	if len(segments) == 0 {
+		return convertSegment(conf, name, name, labels)
+	}
+
+	var segmentNames []string
+	for segmentName := range segments {
+		segmentNames = append(segmentNames, segmentName)
+	}
+	sort.Strings(segmentNames)
+
+	for _, segmentName := range segmentNames {
+		err := convertSegment(conf, name, name+"-"+segmentName, segments[segmentName])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractSegments splits the labels by segment (traefik.<segment>.frontend.rule) and merges them with the default labels.
+func extractSegments(labels map[string]string) map[string]map[string]string {
+	segments := map[string]map[string]string{}
+	base := map[string]string{}
+
+	for key, value := range labels {
+		match := segmentRegexp.FindStringSubmatch(key)
+		if match == nil {
+			base[key] = value
+			continue
+		}
+
+		if _, reserved := reservedSegments[match[1]]; reserved {
+			base[key] = value
+			continue
+		}
+
+		if _, ok := segments[match[1]]; !ok {
+			segments[match[1]] = map[string]string{}
+		}
+		segments[match[1]][label.Prefix+match[2]] = value
+	}
+
+	for _, segment := range segments {
+		for key, value := range base {
+			if _, ok := segment[key]; !ok {
+				segment[key] = value
+			}
+		}
+	}
+
+	return segments
+}
+
+func convertSegment(conf *dynamic.HTTPConfiguration, host, name string, labels map[string]string) error {
+	serviceName := label.GetStringValue(labels, labelBackend, name)
+
+	if _, exists := conf.Services[serviceName]; !exists {
+		conf.Services[serviceName] = getService(host, labels)
+	}
+
+	rawRule := label.GetStringValue(labels, labelFrontendRule, "")
+	if rawRule == "" {
+		fmt.Printf("%s: no frontend rule defined, the router must be created manually. See https://docs.traefik.io/routing/routers/#rule\n", name)
+		return nil
+	}
+
+	converted, err := rule.Convert(rawRule)
+	if err != nil {
+		return err
+	}
+
+	router := &dynamic.Router{
+		EntryPoints: label.GetSliceStringValue(labels, labelFrontendEntryPoints),
+		Service:     serviceName,
+		Rule:        converted.Match,
+		Priority:    label.GetIntValue(labels, labelFrontendPriority, 0),
+	}
+	if len(router.EntryPoints) == 0 {
+		router.EntryPoints = nil
+	}
+
+	middlewares := getMiddlewares(labels)
+	for key, middleware := range converted.Modifiers {
+		middlewares[key] = middleware
+	}
+
+	for _, key := range sortedKeys(middlewares) {
+		middlewareName := name + "-" + key
+		conf.Middlewares[middlewareName] = middlewares[key]
+		router.Middlewares = append(router.Middlewares, middlewareName)
+	}
+
+	conf.Routers[name] = router
+
+	return nil
+}
+
+func getService(host string, labels map[string]string) *dynamic.Service {
+	protocol := label.GetStringValue(labels, labelProtocol, defaultProtocol)
+
+	url := fmt.Sprintf("%s://%s", protocol, host)
+	if port := label.GetStringValue(labels, labelPort, ""); port != "" {
+		url = fmt.Sprintf("%s:%s", url, port)
+	} else {
+		fmt.Printf("%s: %s is not defined, the server port must be checked manually.\n", host, labelPort)
+	}
+
+	lb := &dynamic.ServersLoadBalancer{
+		Servers:            []dynamic.Server{{URL: url}},
+		Sticky:             getSticky(labels),
+		HealthCheck:        getHealthCheck(labels),
+		ResponseForwarding: getResponseForwarding(labels),
+	}
+	lb.SetDefaults()
+
+	if label.Has(labels, labelFrontendPassHostHeader) {
+		passHostHeader := label.GetBoolValue(labels, labelFrontendPassHostHeader, true)
+		lb.PassHostHeader = &passHostHeader
+	}
+
+	return &dynamic.Service{LoadBalancer: lb}
+}
+
+func getMiddlewares(labels map[string]string) map[string]*dynamic.Middleware {
+	middlewares := map[string]*dynamic.Middleware{}
+
+	single := map[string]func(map[string]string) *dynamic.Middleware{
+		"headers":        getHeadersMiddleware,
+		"auth":           getAuthMiddleware,
+		"whitelist":      getWhiteListMiddleware,
+		"redirect":       getRedirectMiddleware,
+		"passtlscert":    getPassTLSClientCertMiddleware,
+		"circuitbreaker": getCircuitBreakerMiddleware,
+		"inflightreq":    getInFlightReqMiddleware,
+		"buffering":      getBufferingMiddleware,
+	}
+
+	for key, fn := range single {
+		if middleware := fn(labels); middleware != nil {
+			middlewares[key] = middleware
+		}
+	}
+
+	for key, middleware := range getErrorPagesMiddlewares(labels) {
+		middlewares[key] = middleware
+	}
+
+	for key, middleware := range getRateLimitMiddlewares(labels) {
+		middlewares[key] = middleware
+	}
+
+	return middlewares
+}
+
+// constraintsExpression creates a v2 constraints expression matching one of the v1 tags kept in the traefik.tags label.
+func constraintsExpression(tags []string) string {
+	var matchers []string
+	for _, tag := range tags {
+		matchers = append(matchers, fmt.Sprintf("LabelRegex(`%s`, `(^|.*,)\\s*%s\\s*(,.*|$)`)", labelTags, regexp.QuoteMeta(tag)))
+	}
+
+	return strings.Join(matchers, " || ")
+}
+
+// unknownLabels returns the Traefik labels not handled by the converter.
+func unknownLabels(labels map[string]string) []string {
+	var unknown []string
+
+	for key := range labels {
+		if !strings.HasPrefix(key, label.Prefix) {
+			continue
+		}
+
+		name := key
+		if match := segmentRegexp.FindStringSubmatch(key); match != nil {
+			if _, reserved := reservedSegments[match[1]]; !reserved {
+				name = label.Prefix + match[2]
+			}
+		}
+
+		if isKnownLabel(name) {
+			continue
+		}
+
+		unknown = append(unknown, key)
+	}
+
+	sort.Strings(unknown)
+
+	return unknown
+}
+
+func isKnownLabel(key string) bool {
+	if _, ok := knownLabels[key]; ok {
+		return true
+	}
+
+	for _, prefix := range knownPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
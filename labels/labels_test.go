@@ -0,0 +1,98 @@
+package labels
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateExpected = flag.Bool("update_expected", false, "Update expected files in testdata")
+
+func TestConvert(t *testing.T) {
+	testCases := []string{
+		"compose_simple.yml",
+		"compose_middlewares.yml",
+		"compose_segments.yml",
+		"compose_enable.yml",
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test, func(t *testing.T) {
+			dstFile := filepath.Join(t.TempDir(), test)
+
+			_, err := Convert(filepath.Join("fixtures", "input", test), dstFile, false)
+			require.NoError(t, err)
+
+			output, err := os.ReadFile(dstFile)
+			require.NoError(t, err)
+
+			fixtureFile := filepath.Join("fixtures", "output", test)
+
+			if *updateExpected {
+				require.NoError(t, os.WriteFile(fixtureFile, output, 0666))
+			}
+
+			fixture, err := os.ReadFile(fixtureFile)
+			require.NoError(t, err)
+
+			assert.YAMLEq(t, string(fixture), string(output))
+		})
+	}
+}
+
+func TestConvert_strict(t *testing.T) {
+	dstFile := filepath.Join(t.TempDir(), "dynamic.yml")
+
+	src := filepath.Join("fixtures", "input", "compose_middlewares.yml")
+
+	_, err := Convert(src, dstFile, true)
+	require.EqualError(t, err, "unknown Traefik labels:\n  "+src+": api: traefik.backend.loadbalancer.method")
+
+	assert.NoFileExists(t, dstFile)
+
+	_, err = Convert(filepath.Join("fixtures", "input", "compose_simple.yml"), dstFile, true)
+	require.NoError(t, err)
+
+	assert.FileExists(t, dstFile)
+}
+
+func TestConvert_directory(t *testing.T) {
+	src := filepath.Join("fixtures", "input", "estate")
+	dstDir := t.TempDir()
+
+	report, err := Convert(src, dstDir, false)
+	require.NoError(t, err)
+
+	expected := &Report{
+		Files: []FileReport{
+			{
+				Path:     filepath.Join(src, "api", "docker-compose.yml"),
+				Services: []string{"api"},
+				UnknownLabels: map[string][]string{
+					"api": {"traefik.backend.loadbalancer.method"},
+				},
+			},
+			{
+				Path:     filepath.Join(src, "web", "stack.yaml"),
+				Services: []string{"web"},
+				Skipped:  []string{"worker"},
+			},
+		},
+	}
+	assert.Equal(t, expected, report)
+
+	assert.FileExists(t, filepath.Join(dstDir, "api", "docker-compose.yml"))
+	assert.FileExists(t, filepath.Join(dstDir, "web", "stack.yaml"))
+	assert.NoFileExists(t, filepath.Join(dstDir, "db", "docker-compose.yml"))
+}
+
+func Test_constraintsExpression(t *testing.T) {
+	expression := constraintsExpression([]string{"api", "v1.0"})
+
+	assert.Equal(t, "LabelRegex(`traefik.tags`, `(^|.*,)\\s*api\\s*(,.*|$)`) || LabelRegex(`traefik.tags`, `(^|.*,)\\s*v1\\.0\\s*(,.*|$)`)", expression)
+}
@@ -0,0 +1,332 @@
+package labels
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/traefik/traefik-migration-tool/label"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+func getHeadersMiddleware(labels map[string]string) *dynamic.Middleware {
+	headers := &dynamic.Headers{
+		CustomRequestHeaders:    label.GetMapValue(labels, labelFrontendRequestHeaders),
+		CustomResponseHeaders:   label.GetMapValue(labels, labelFrontendResponseHeaders),
+		AllowedHosts:            label.GetSliceStringValue(labels, labelFrontendAllowedHosts),
+		HostsProxyHeaders:       label.GetSliceStringValue(labels, labelFrontendHostsProxyHeaders),
+		SSLForceHost:            label.GetBoolValue(labels, labelFrontendSSLForceHost, false),
+		SSLRedirect:             label.GetBoolValue(labels, labelFrontendSSLRedirect, false),
+		SSLTemporaryRedirect:    label.GetBoolValue(labels, labelFrontendSSLTemporaryRedirect, false),
+		SSLHost:                 label.GetStringValue(labels, labelFrontendSSLHost, ""),
+		SSLProxyHeaders:         label.GetMapValue(labels, labelFrontendSSLProxyHeaders),
+		STSSeconds:              label.GetInt64Value(labels, labelFrontendSTSSeconds, 0),
+		STSIncludeSubdomains:    label.GetBoolValue(labels, labelFrontendSTSIncludeSubdomains, false),
+		STSPreload:              label.GetBoolValue(labels, labelFrontendSTSPreload, false),
+		ForceSTSHeader:          label.GetBoolValue(labels, labelFrontendForceSTSHeader, false),
+		FrameDeny:               label.GetBoolValue(labels, labelFrontendFrameDeny, false),
+		CustomFrameOptionsValue: label.GetStringValue(labels, labelFrontendCustomFrameOptionsValue, ""),
+		ContentTypeNosniff:      label.GetBoolValue(labels, labelFrontendContentTypeNosniff, false),
+		BrowserXSSFilter:        label.GetBoolValue(labels, labelFrontendBrowserXSSFilter, false),
+		CustomBrowserXSSValue:   label.GetStringValue(labels, labelFrontendCustomBrowserXSSValue, ""),
+		ContentSecurityPolicy:   label.GetStringValue(labels, labelFrontendContentSecurityPolicy, ""),
+		PublicKey:               label.GetStringValue(labels, labelFrontendPublicKey, ""),
+		ReferrerPolicy:          label.GetStringValue(labels, labelFrontendReferrerPolicy, ""),
+		IsDevelopment:           label.GetBoolValue(labels, labelFrontendIsDevelopment, false),
+	}
+
+	if len(headers.AllowedHosts) == 0 {
+		headers.AllowedHosts = nil
+	}
+	if len(headers.HostsProxyHeaders) == 0 {
+		headers.HostsProxyHeaders = nil
+	}
+
+	if !headers.HasCustomHeadersDefined() && !headers.HasCorsHeadersDefined() && !headers.HasSecureHeadersDefined() {
+		return nil
+	}
+
+	return &dynamic.Middleware{Headers: headers}
+}
+
+func getAuthMiddleware(labels map[string]string) *dynamic.Middleware {
+	headerField := label.GetStringValue(labels, labelFrontendAuthHeaderField, "")
+
+	if label.Has(labels, labelFrontendAuthForwardAddress) {
+		forward := &dynamic.ForwardAuth{
+			Address:             label.GetStringValue(labels, labelFrontendAuthForwardAddress, ""),
+			TrustForwardHeader:  label.GetBoolValue(labels, labelFrontendAuthForwardTrustForwardHeader, false),
+			AuthResponseHeaders: label.GetSliceStringValue(labels, labelFrontendAuthForwardAuthResponseHeaders),
+		}
+		if len(forward.AuthResponseHeaders) == 0 {
+			forward.AuthResponseHeaders = nil
+		}
+
+		if label.HasPrefix(labels, "traefik.frontend.auth.forward.tls.") {
+			forward.TLS = &dynamic.ClientTLS{
+				CA:                 label.GetStringValue(labels, labelFrontendAuthForwardTLSCa, ""),
+				CAOptional:         label.GetBoolValue(labels, labelFrontendAuthForwardTLSCaOptional, false),
+				Cert:               label.GetStringValue(labels, labelFrontendAuthForwardTLSCert, ""),
+				Key:                label.GetStringValue(labels, labelFrontendAuthForwardTLSKey, ""),
+				InsecureSkipVerify: label.GetBoolValue(labels, labelFrontendAuthForwardTLSInsecureSkipVerify, false),
+			}
+		}
+
+		return &dynamic.Middleware{ForwardAuth: forward}
+	}
+
+	if label.Has(labels, labelFrontendAuthDigestUsers) || label.Has(labels, labelFrontendAuthDigestUsersFile) {
+		return &dynamic.Middleware{
+			DigestAuth: &dynamic.DigestAuth{
+				Users:        label.GetSliceStringValue(labels, labelFrontendAuthDigestUsers),
+				UsersFile:    label.GetStringValue(labels, labelFrontendAuthDigestUsersFile, ""),
+				RemoveHeader: label.GetBoolValue(labels, labelFrontendAuthDigestRemoveHeader, false),
+				HeaderField:  headerField,
+			},
+		}
+	}
+
+	users := label.GetSliceStringValue(labels, labelFrontendAuthBasicUsers)
+	if len(users) == 0 {
+		users = label.GetSliceStringValue(labels, labelFrontendAuthBasic)
+	}
+
+	if len(users) > 0 || label.Has(labels, labelFrontendAuthBasicUsersFile) {
+		if len(users) == 0 {
+			users = nil
+		}
+
+		return &dynamic.Middleware{
+			BasicAuth: &dynamic.BasicAuth{
+				Users:        users,
+				UsersFile:    label.GetStringValue(labels, labelFrontendAuthBasicUsersFile, ""),
+				RemoveHeader: label.GetBoolValue(labels, labelFrontendAuthBasicRemoveHeader, false),
+				HeaderField:  headerField,
+			},
+		}
+	}
+
+	return nil
+}
+
+func getWhiteListMiddleware(labels map[string]string) *dynamic.Middleware {
+	ranges := label.GetSliceStringValue(labels, labelFrontendWhiteListSourceRange)
+	if len(ranges) == 0 {
+		ranges = label.GetSliceStringValue(labels, labelFrontendWhitelistSourceRange)
+	}
+
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	middleware := &dynamic.Middleware{
+		IPWhiteList: &dynamic.IPWhiteList{SourceRange: ranges},
+	}
+
+	if label.GetBoolValue(labels, labelFrontendWhiteListUseXForwardedFor, false) {
+		middleware.IPWhiteList.IPStrategy = &dynamic.IPStrategy{}
+	}
+
+	return middleware
+}
+
+func getRedirectMiddleware(labels map[string]string) *dynamic.Middleware {
+	permanent := label.GetBoolValue(labels, labelFrontendRedirectPermanent, false)
+
+	if entryPoint := label.GetStringValue(labels, labelFrontendRedirectEntryPoint, ""); entryPoint != "" {
+		log.Printf("The redirect to the entry point %q is converted to a redirect to https: check the entry point configuration.", entryPoint)
+
+		return &dynamic.Middleware{
+			RedirectScheme: &dynamic.RedirectScheme{Scheme: "https", Permanent: permanent},
+		}
+	}
+
+	regex := label.GetStringValue(labels, labelFrontendRedirectRegex, "")
+	replacement := label.GetStringValue(labels, labelFrontendRedirectReplacement, "")
+	if regex == "" || replacement == "" {
+		return nil
+	}
+
+	return &dynamic.Middleware{
+		RedirectRegex: &dynamic.RedirectRegex{Regex: regex, Replacement: replacement, Permanent: permanent},
+	}
+}
+
+func getPassTLSClientCertMiddleware(labels map[string]string) *dynamic.Middleware {
+	if !label.GetBoolValue(labels, labelFrontendPassTLSCert, false) {
+		return nil
+	}
+
+	return &dynamic.Middleware{
+		PassTLSClientCert: &dynamic.PassTLSClientCert{PEM: true},
+	}
+}
+
+func getErrorPagesMiddlewares(labels map[string]string) map[string]*dynamic.Middleware {
+	pages := groupByName(labels, labelFrontendErrorsPrefix)
+
+	middlewares := map[string]*dynamic.Middleware{}
+	for name, page := range pages {
+		middlewares["errors-"+name] = &dynamic.Middleware{
+			Errors: &dynamic.ErrorPage{
+				Status:  label.SplitAndTrimString(page["status"], ","),
+				Service: page["backend"],
+				Query:   page["query"],
+			},
+		}
+	}
+
+	return middlewares
+}
+
+func getRateLimitMiddlewares(labels map[string]string) map[string]*dynamic.Middleware {
+	rateSets := groupByName(labels, labelFrontendRateLimitRateSetPrefix)
+
+	sourceCriterion := getSourceCriterion(label.GetStringValue(labels, labelFrontendRateLimitExtractorFunc, ""))
+
+	middlewares := map[string]*dynamic.Middleware{}
+	for name, rateSet := range rateSets {
+		period, err := time.ParseDuration(rateSet["period"])
+		if err != nil || period < time.Second {
+			log.Printf("Invalid rate limit period %q for the rate set %q, skipping...", rateSet["period"], name)
+			continue
+		}
+
+		middlewares["ratelimit-"+name] = &dynamic.Middleware{
+			RateLimit: &dynamic.RateLimit{
+				Average:         label.GetInt64Value(rateSet, "average", 0) / int64(period/time.Second),
+				Burst:           label.GetInt64Value(rateSet, "burst", 0),
+				SourceCriterion: sourceCriterion,
+			},
+		}
+	}
+
+	return middlewares
+}
+
+func getCircuitBreakerMiddleware(labels map[string]string) *dynamic.Middleware {
+	expression := label.GetStringValue(labels, labelBackendCircuitBreakerExpression, "")
+	if expression == "" {
+		return nil
+	}
+
+	return &dynamic.Middleware{
+		CircuitBreaker: &dynamic.CircuitBreaker{Expression: expression},
+	}
+}
+
+func getInFlightReqMiddleware(labels map[string]string) *dynamic.Middleware {
+	amount := label.GetInt64Value(labels, labelBackendMaxConnAmount, 0)
+	if amount <= 0 {
+		return nil
+	}
+
+	return &dynamic.Middleware{
+		InFlightReq: &dynamic.InFlightReq{
+			Amount:          amount,
+			SourceCriterion: getSourceCriterion(label.GetStringValue(labels, labelBackendMaxConnExtractorFunc, "")),
+		},
+	}
+}
+
+func getBufferingMiddleware(labels map[string]string) *dynamic.Middleware {
+	if !label.HasPrefix(labels, "traefik.backend.buffering.") {
+		return nil
+	}
+
+	return &dynamic.Middleware{
+		Buffering: &dynamic.Buffering{
+			MaxRequestBodyBytes:  label.GetInt64Value(labels, labelBackendBufferingMaxRequestBodyBytes, 0),
+			MemRequestBodyBytes:  label.GetInt64Value(labels, labelBackendBufferingMemRequestBodyBytes, 0),
+			MaxResponseBodyBytes: label.GetInt64Value(labels, labelBackendBufferingMaxResponseBodyBytes, 0),
+			MemResponseBodyBytes: label.GetInt64Value(labels, labelBackendBufferingMemResponseBodyBytes, 0),
+			RetryExpression:      label.GetStringValue(labels, labelBackendBufferingRetryExpression, ""),
+		},
+	}
+}
+
+func getSourceCriterion(extractorFunc string) *dynamic.SourceCriterion {
+	switch {
+	case extractorFunc == "request.host":
+		return &dynamic.SourceCriterion{RequestHost: true}
+	case strings.HasPrefix(extractorFunc, "request.header."):
+		return &dynamic.SourceCriterion{RequestHeaderName: strings.TrimPrefix(extractorFunc, "request.header.")}
+	default:
+		return nil
+	}
+}
+
+func getSticky(labels map[string]string) *dynamic.Sticky {
+	if !label.GetBoolValue(labels, labelBackendLoadBalancerStickiness, false) && !label.GetBoolValue(labels, labelBackendLoadBalancerSticky, false) {
+		return nil
+	}
+
+	return &dynamic.Sticky{
+		Cookie: &dynamic.Cookie{
+			Name: label.GetStringValue(labels, labelBackendLoadBalancerStickinessCookieName, ""),
+		},
+	}
+}
+
+func getHealthCheck(labels map[string]string) *dynamic.HealthCheck {
+	path := label.GetStringValue(labels, labelBackendHealthCheckPath, "")
+	if path == "" {
+		return nil
+	}
+
+	healthCheck := &dynamic.HealthCheck{
+		Scheme:   label.GetStringValue(labels, labelBackendHealthCheckScheme, ""),
+		Path:     path,
+		Port:     label.GetIntValue(labels, labelBackendHealthCheckPort, 0),
+		Interval: label.GetStringValue(labels, labelBackendHealthCheckInterval, ""),
+		Timeout:  label.GetStringValue(labels, labelBackendHealthCheckTimeout, ""),
+		Hostname: label.GetStringValue(labels, labelBackendHealthCheckHostname, ""),
+		Headers:  label.GetMapValue(labels, labelBackendHealthCheckHeaders),
+	}
+	healthCheck.SetDefaults()
+
+	return healthCheck
+}
+
+func getResponseForwarding(labels map[string]string) *dynamic.ResponseForwarding {
+	flushInterval := label.GetStringValue(labels, labelBackendResponseForwardingFlushInterval, "")
+	if flushInterval == "" {
+		return nil
+	}
+
+	return &dynamic.ResponseForwarding{FlushInterval: flushInterval}
+}
+
+// groupByName groups the labels "<prefix><name>.<option>" by name.
+func groupByName(labels map[string]string, prefix string) map[string]map[string]string {
+	groups := map[string]map[string]string{}
+
+	for key, value := range labels {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(key, prefix), ".", 2)
+		if len(parts) != 2 {
+			log.Printf("Invalid label %q, skipping...", key)
+			continue
+		}
+
+		if _, ok := groups[parts[0]]; !ok {
+			groups[parts[0]] = map[string]string{}
+		}
+		groups[parts[0]][parts[1]] = value
+	}
+
+	return groups
+}
+
+func sortedKeys(m map[string]*dynamic.Middleware) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
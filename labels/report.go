@@ -0,0 +1,87 @@
+package labels
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Report holds the results of a labels conversion.
+type Report struct {
+	Files []FileReport `json:"files"`
+}
+
+// FileReport holds the results of the conversion of a compose file.
+type FileReport struct {
+	Path          string              `json:"path"`
+	Services      []string            `json:"services,omitempty"`
+	Skipped       []string            `json:"skipped,omitempty"`
+	UnknownLabels map[string][]string `json:"unknownLabels,omitempty"`
+}
+
+// Save writes the report as JSON.
+func (r *Report) Save(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
+
+// String returns a human readable summary of the report.
+func (r *Report) String() string {
+	var services, skipped int
+	for _, file := range r.Files {
+		services += len(file.Services)
+		skipped += len(file.Skipped)
+	}
+
+	unknown := r.unknownLabels()
+
+	b := &strings.Builder{}
+	_, _ = fmt.Fprintf(b, "Files: %d, converted services: %d, skipped services: %d, unsupported labels: %d\n", len(r.Files), services, skipped, len(unknown))
+
+	for _, file := range r.Files {
+		_, _ = fmt.Fprintf(b, "  %s\n", file.Path)
+		if len(file.Services) > 0 {
+			_, _ = fmt.Fprintf(b, "    converted: %s\n", strings.Join(file.Services, ", "))
+		}
+		if len(file.Skipped) > 0 {
+			_, _ = fmt.Fprintf(b, "    skipped: %s\n", strings.Join(file.Skipped, ", "))
+		}
+		for _, service := range sortedServices(file.UnknownLabels) {
+			_, _ = fmt.Fprintf(b, "    unsupported (%s): %s\n", service, strings.Join(file.UnknownLabels[service], ", "))
+		}
+	}
+
+	return b.String()
+}
+
+// unknownLabels returns the unknown labels of all the files ("<file>: <service>: <label>").
+func (r *Report) unknownLabels() []string {
+	var unknown []string
+	for _, file := range r.Files {
+		for _, service := range sortedServices(file.UnknownLabels) {
+			for _, key := range file.UnknownLabels[service] {
+				unknown = append(unknown, fmt.Sprintf("%s: %s: %s", file.Path, service, key))
+			}
+		}
+	}
+	return unknown
+}
+
+func sortedServices(m map[string][]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
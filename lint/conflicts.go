@@ -0,0 +1,126 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// route is a router collected for the conflicts detection.
+type route struct {
+	file      string
+	line      int
+	kind      string
+	namespace string
+	name      string
+	rule      string
+	// priority is the effective priority: the explicit one, or the length of the rule as Traefik computes it.
+	priority    int
+	entryPoints []string
+}
+
+func (l *linter) addRoute(file, kind, namespace, name, rule string, priority int, entryPoints []string) {
+	if !l.opts.CheckConflicts || rule == "" {
+		return
+	}
+
+	if priority == 0 {
+		priority = len(rule)
+	}
+
+	l.routes = append(l.routes, route{file: file, line: l.line, kind: kind, namespace: namespace, name: name, rule: rule, priority: priority, entryPoints: entryPoints})
+}
+
+// checkConflicts reports the routers matching the same requests with the same priority:
+// the router handling the requests is not predictable.
+func (l *linter) checkConflicts() {
+	groups := map[string][]route{}
+	var keys []string
+
+	for _, r := range l.routes {
+		key := fmt.Sprintf("%d|%s", r.priority, normalizeRule(r.rule))
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], r)
+	}
+
+	for _, key := range keys {
+		routes := groups[key]
+
+		for i := 1; i < len(routes); i++ {
+			for j := 0; j < i; j++ {
+				current, previous := routes[i], routes[j]
+
+				// The routes of a same resource (ex: a resource defined in several files) are not conflicting.
+				if current.kind == previous.kind && current.namespace == previous.namespace && current.name == previous.name {
+					continue
+				}
+
+				if !overlap(current.entryPoints, previous.entryPoints) {
+					continue
+				}
+
+				l.reportAt(current.file, current.line, current.kind, current.name, fmt.Sprintf(
+					"the rule %q matches the same requests as %s %s (%s) with the same priority (%d): the routing is not predictable, set an explicit priority (ex: %d) on the router which must win",
+					current.rule, previous.kind, previous.name, previous.file, current.priority, current.priority+1))
+			}
+		}
+	}
+}
+
+// normalizeRule returns a canonical form of a rule: the spaces outside of the values are removed,
+// the hosts are in lowercase, and the matchers of a conjunction are sorted.
+func normalizeRule(rule string) string {
+	var b strings.Builder
+
+	var quote rune
+	for _, c := range rule {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '`' || c == '"':
+			quote = c
+		case c == ' ' || c == '\t' || c == '\n':
+			continue
+		}
+
+		b.WriteRune(c)
+	}
+
+	normalized := b.String()
+	if strings.Contains(normalized, "||") || strings.Contains(normalized, "!") {
+		return normalized
+	}
+
+	matchers := strings.Split(normalized, "&&")
+	for i, matcher := range matchers {
+		matcher = strings.TrimSuffix(strings.TrimPrefix(matcher, "("), ")")
+		if strings.HasPrefix(strings.ToLower(matcher), "host(") {
+			matcher = strings.ToLower(matcher)
+		}
+		matchers[i] = matcher
+	}
+	sort.Strings(matchers)
+
+	return strings.Join(matchers, "&&")
+}
+
+// overlap returns true if two routers listen on a common entry point, no entry point means all the entry points.
+func overlap(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,562 @@
+// Package lint checks Kubernetes manifests against the Traefik v2 CRD schemas and Ingress annotations.
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/config/label"
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/ingress"
+	"github.com/traefik/traefik/v2/pkg/rules"
+	networking "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	annotationsPrefix   = "traefik.ingress.kubernetes.io/"
+	v1AnnotationsPrefix = "ingress.kubernetes.io/"
+)
+
+// apiVersion is the API version of the Traefik v2 custom resources.
+// The resources of the traefik.io API group are Traefik v3 resources, they are not checked.
+const apiVersion = v1alpha1.GroupName + "/v1alpha1"
+
+// kinds are the Traefik custom resources, by kind.
+var kinds = map[string]func() interface{}{
+	"IngressRoute":     func() interface{} { return &v1alpha1.IngressRoute{} },
+	"IngressRouteTCP":  func() interface{} { return &v1alpha1.IngressRouteTCP{} },
+	"IngressRouteUDP":  func() interface{} { return &v1alpha1.IngressRouteUDP{} },
+	"Middleware":       func() interface{} { return &v1alpha1.Middleware{} },
+	"TraefikService":   func() interface{} { return &v1alpha1.TraefikService{} },
+	"TLSOption":        func() interface{} { return &v1alpha1.TLSOption{} },
+	"TLSStore":         func() interface{} { return &v1alpha1.TLSStore{} },
+	"ServersTransport": func() interface{} { return &v1alpha1.ServersTransport{} },
+}
+
+var annotationsRegex = regexp.MustCompile(`(.+)\.(\w+)\.(\d+)\.(.+)`)
+
+// Options holds the lint options.
+type Options struct {
+	// CheckRules parses the routers rules of the IngressRoutes and of the file provider dynamic configurations with the Traefik rule parser.
+	CheckRules bool
+	// CheckReferences checks that the middlewares referenced by the routers are defined in the linted files, or are known.
+	CheckReferences bool
+	// KnownMiddlewares are the middlewares defined outside of the linted files (ex: ssl-redirect@file).
+	KnownMiddlewares []string
+	// CheckConflicts reports the routers matching the same requests with the same priority.
+	CheckConflicts bool
+}
+
+// Problem is an issue found in a manifest.
+type Problem struct {
+	File string
+	// Line is the first line of the document holding the problem in the file, 0 when unknown.
+	Line    int
+	Kind    string
+	Name    string
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Kind == "" {
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	}
+
+	return fmt.Sprintf("%s: %s %s: %s", p.File, p.Kind, p.Name, p.Message)
+}
+
+type linter struct {
+	opts     Options
+	problems []Problem
+
+	// middlewares are the qualified names of the defined middlewares.
+	middlewares map[string]struct{}
+	references  []reference
+	routes      []route
+
+	// line is the first line of the linted document.
+	line int
+}
+
+// Lint checks the manifests of src (a file or a directory tree).
+// With the rules, the references, or the conflicts check, the file provider dynamic configurations (YAML, TOML, or JSON) are also checked.
+func Lint(src string, opts Options) ([]Problem, error) {
+	l := &linter{opts: opts}
+
+	err := filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if fi.IsDir() {
+			return nil
+		}
+
+		switch filepath.Ext(path) {
+		case ".yml", ".yaml", ".json":
+			return l.lintFile(path)
+		case ".toml":
+			if l.checksDynamic() {
+				return l.lintTOMLFile(path)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.checkReferences()
+	l.checkConflicts()
+
+	return l.problems, nil
+}
+
+func (l *linter) checksDynamic() bool {
+	return l.opts.CheckRules || l.opts.CheckReferences || l.opts.CheckConflicts
+}
+
+func (l *linter) report(file, kind, name string, messages ...string) {
+	l.reportAt(file, l.line, kind, name, messages...)
+}
+
+func (l *linter) reportAt(file string, line int, kind, name string, messages ...string) {
+	for _, message := range messages {
+		l.problems = append(l.problems, Problem{File: file, Line: line, Kind: kind, Name: name, Message: message})
+	}
+}
+
+func (l *linter) lintFile(filename string) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+
+	// offset is the position of the end of the previous document in the file.
+	var offset int
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+
+		l.line = 0
+		if index := bytes.Index(content[offset:], document); index >= 0 {
+			l.line = bytes.Count(content[:offset+index], []byte("\n")) + 1
+			offset += index + len(document)
+		}
+
+		data, err := yaml.YAMLToJSON(document)
+		if err != nil {
+			l.report(filename, "", "", err.Error())
+			continue
+		}
+
+		if len(bytes.TrimSpace(data)) == 0 || string(bytes.TrimSpace(data)) == "null" {
+			continue
+		}
+
+		l.lintDocument(filename, data)
+	}
+}
+
+func (l *linter) lintTOMLFile(filename string) error {
+	l.line = 0
+
+	cfg := map[string]interface{}{}
+	if _, err := toml.DecodeFile(filename, &cfg); err != nil {
+		l.report(filename, "", "", err.Error())
+		return nil
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	l.lintDynamic(filename, data)
+
+	return nil
+}
+
+func (l *linter) lintDocument(filename string, data []byte) {
+	object := &unstructured.Unstructured{}
+	if err := object.UnmarshalJSON(data); err != nil {
+		if l.checksDynamic() && isDynamic(data) {
+			l.lintDynamic(filename, data)
+			return
+		}
+
+		l.report(filename, "", "", err.Error())
+		return
+	}
+
+	l.lintObject(filename, object, data)
+}
+
+func (l *linter) lintObject(filename string, object *unstructured.Unstructured, data []byte) {
+	if object.IsList() {
+		_ = object.EachListItem(func(item runtime.Object) error {
+			itemData, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+
+			l.lintDocument(filename, itemData)
+			return nil
+		})
+		return
+	}
+
+	kind, name := object.GetKind(), object.GetName()
+
+	if object.GetAPIVersion() == apiVersion {
+		l.lintResource(filename, object, data)
+	}
+
+	switch kind {
+	case "Ingress":
+		l.report(filename, kind, name, lintAnnotations(object.GetAnnotations(), "router", &ingress.RouterConfig{})...)
+
+		for _, middleware := range strings.Split(object.GetAnnotations()[annotationsPrefix+"router.middlewares"], ",") {
+			if middleware = strings.TrimSpace(middleware); middleware != "" {
+				l.addReference(filename, kind, name, qualifyMiddleware(middleware, "kubernetes"))
+			}
+		}
+
+		l.addIngressRoutes(filename, object, data)
+	case "Service":
+		l.report(filename, kind, name, lintAnnotations(object.GetAnnotations(), "service", &ingress.ServiceConfig{})...)
+	}
+}
+
+// lintResource checks a Traefik custom resource against the CRD schema.
+func (l *linter) lintResource(filename string, object *unstructured.Unstructured, data []byte) {
+	kind, name := object.GetKind(), object.GetName()
+
+	newResource, ok := kinds[kind]
+	if !ok {
+		l.report(filename, kind, name, fmt.Sprintf("unknown kind in the %s API group", object.GetAPIVersion()))
+		return
+	}
+
+	if name == "" {
+		l.report(filename, kind, name, "missing metadata.name")
+		return
+	}
+
+	if kind == "IngressRoute" || kind == "TraefikService" {
+		var invalid []string
+		data, invalid = clearNamedPorts(object, data)
+		for _, port := range invalid {
+			l.report(filename, kind, name, fmt.Sprintf("invalid port name %q", port))
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	resource := newResource()
+	if err := decoder.Decode(resource); err != nil {
+		l.report(filename, kind, name, fmt.Sprintf("invalid spec: %v", err))
+		return
+	}
+
+	namespace := object.GetNamespace()
+
+	switch r := resource.(type) {
+	case *v1alpha1.Middleware:
+		l.defineMiddleware(crdMiddleware(namespace, name))
+
+		if r.Spec.Chain != nil {
+			for _, ref := range r.Spec.Chain.Middlewares {
+				l.addReference(filename, kind, name, crdReference(namespace, ref))
+			}
+		}
+
+	case *v1alpha1.IngressRoute:
+		for _, route := range r.Spec.Routes {
+			l.addRoute(filename, kind, namespace, name, route.Match, route.Priority, r.Spec.EntryPoints)
+
+			for _, ref := range route.Middlewares {
+				l.addReference(filename, kind, name, crdReference(namespace, ref))
+			}
+
+			if !l.opts.CheckRules {
+				continue
+			}
+
+			if err := checkRule(route.Match); err != nil {
+				l.report(filename, kind, name, fmt.Sprintf("invalid rule %q: %v", route.Match, err))
+			}
+		}
+
+	case *v1alpha1.IngressRouteTCP:
+		if !l.opts.CheckRules {
+			return
+		}
+
+		for _, route := range r.Spec.Routes {
+			if err := checkTCPRule(route.Match); err != nil {
+				l.report(filename, kind, name, fmt.Sprintf("invalid rule %q: %v", route.Match, err))
+			}
+		}
+	}
+}
+
+// clearNamedPorts returns the document of a resource where the named ports of the services (ex: port: http) are replaced with 0,
+// and the invalid port names: the ports are numbers in the Traefik v2.4 schema, the ingress conversion references by name the ports
+// it cannot resolve, which requires Traefik v2.5 or later.
+func clearNamedPorts(object *unstructured.Unstructured, data []byte) ([]byte, []string) {
+	spec, ok := object.Object["spec"]
+	if !ok {
+		return data, nil
+	}
+
+	spec = runtime.DeepCopyJSONValue(spec)
+
+	var named bool
+	var invalid []string
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, field := range v {
+				port, ok := field.(string)
+				if key != "port" || !ok {
+					walk(field)
+					continue
+				}
+
+				if len(validation.IsValidPortName(port)) > 0 {
+					invalid = append(invalid, port)
+				}
+				v[key] = int64(0)
+				named = true
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(spec)
+
+	if !named {
+		return data, nil
+	}
+
+	cleared := object.DeepCopy()
+	cleared.Object["spec"] = spec
+
+	clearedData, err := cleared.MarshalJSON()
+	if err != nil {
+		return data, invalid
+	}
+
+	sort.Strings(invalid)
+
+	return clearedData, invalid
+}
+
+// isDynamic returns true if the document is a file provider dynamic configuration.
+func isDynamic(data []byte) bool {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(data, &root); err != nil {
+		return false
+	}
+
+	for key := range root {
+		switch strings.ToLower(key) {
+		case "http", "tcp", "udp", "tls":
+		default:
+			return false
+		}
+	}
+
+	return len(root) > 0
+}
+
+// lintDynamic checks the routers of a file provider dynamic configuration.
+func (l *linter) lintDynamic(filename string, data []byte) {
+	cfg := &dynamic.Configuration{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		l.report(filename, "", "", fmt.Sprintf("invalid dynamic configuration: %v", err))
+		return
+	}
+
+	if cfg.HTTP != nil {
+		for name, middleware := range cfg.HTTP.Middlewares {
+			l.defineMiddleware(qualifyMiddleware(name, providerFile))
+
+			if middleware.Chain != nil {
+				for _, ref := range middleware.Chain.Middlewares {
+					l.addReference(filename, "middleware", name, qualifyMiddleware(ref, providerFile))
+				}
+			}
+		}
+
+		for _, name := range sortedKeys(cfg.HTTP.Routers) {
+			router := cfg.HTTP.Routers[name]
+
+			l.addRoute(filename, "router", "", name, router.Rule, router.Priority, router.EntryPoints)
+
+			for _, ref := range router.Middlewares {
+				l.addReference(filename, "router", name, qualifyMiddleware(ref, providerFile))
+			}
+
+			if !l.opts.CheckRules {
+				continue
+			}
+
+			if err := checkRule(router.Rule); err != nil {
+				l.report(filename, "router", name, fmt.Sprintf("invalid rule %q: %v", router.Rule, err))
+			}
+		}
+	}
+
+	if cfg.TCP != nil && l.opts.CheckRules {
+		var names []string
+		for name := range cfg.TCP.Routers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			rule := cfg.TCP.Routers[name].Rule
+			if err := checkTCPRule(rule); err != nil {
+				l.report(filename, "TCP router", name, fmt.Sprintf("invalid rule %q: %v", rule, err))
+			}
+		}
+	}
+}
+
+// checkRule parses a HTTP router rule as Traefik does.
+func checkRule(rule string) error {
+	router, err := rules.NewRouter()
+	if err != nil {
+		return err
+	}
+
+	return router.AddRoute(rule, 0, http.NotFoundHandler())
+}
+
+// checkTCPRule parses a TCP router rule as Traefik does.
+func checkTCPRule(rule string) error {
+	_, err := rules.ParseHostSNI(rule)
+	return err
+}
+
+func sortedKeys(routers map[string]*dynamic.Router) []string {
+	var keys []string
+	for key := range routers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// lintAnnotations checks the Traefik annotations of an object, as the Traefik v2 Kubernetes Ingress provider reads them.
+func lintAnnotations(annotations map[string]string, root string, cfg interface{}) []string {
+	var messages []string
+
+	for key, value := range annotations {
+		if strings.HasPrefix(key, v1AnnotationsPrefix) {
+			messages = append(messages, fmt.Sprintf("the Traefik v1 annotation %s is ignored by Traefik v2", key))
+			continue
+		}
+
+		if !strings.HasPrefix(key, annotationsPrefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(key, annotationsPrefix)
+		if !strings.HasPrefix(name, root+".") {
+			messages = append(messages, fmt.Sprintf("unknown annotation %s", key))
+			continue
+		}
+
+		if annotationsRegex.MatchString(name) {
+			name = annotationsRegex.ReplaceAllString(name, "$1.$2[$3].$4")
+		}
+
+		if err := label.Decode(map[string]string{"traefik." + name: value}, cfg, "traefik."+root+"."); err != nil {
+			messages = append(messages, fmt.Sprintf("invalid annotation %s: %v", key, err))
+		}
+	}
+
+	sort.Strings(messages)
+
+	return messages
+}
+
+// addIngressRoutes collects the routers created by the Traefik v2 Kubernetes Ingress provider for an Ingress.
+func (l *linter) addIngressRoutes(filename string, object *unstructured.Unstructured, data []byte) {
+	if !l.opts.CheckConflicts {
+		return
+	}
+
+	ing := &networking.Ingress{}
+	if err := json.Unmarshal(data, ing); err != nil {
+		return
+	}
+
+	annotations := object.GetAnnotations()
+
+	pathMatcher := annotations[annotationsPrefix+"router.pathmatcher"]
+	if pathMatcher == "" {
+		pathMatcher = "PathPrefix"
+	}
+
+	priority, _ := strconv.Atoi(annotations[annotationsPrefix+"router.priority"])
+
+	var entryPoints []string
+	for _, entryPoint := range strings.Split(annotations[annotationsPrefix+"router.entrypoints"], ",") {
+		if entryPoint = strings.TrimSpace(entryPoint); entryPoint != "" {
+			entryPoints = append(entryPoints, entryPoint)
+		}
+	}
+
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for _, path := range rule.HTTP.Paths {
+			var matchers []string
+			if rule.Host != "" {
+				matchers = append(matchers, fmt.Sprintf("Host(`%s`)", rule.Host))
+			}
+			if path.Path != "" {
+				matchers = append(matchers, fmt.Sprintf("%s(`%s`)", pathMatcher, path.Path))
+			}
+
+			l.addRoute(filename, object.GetKind(), object.GetNamespace(), object.GetName(), strings.Join(matchers, " && "), priority, entryPoints)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package lint
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	testCases := []struct {
+		src      string
+		expected []string
+	}{
+		{
+			src: filepath.Join("fixtures", "manifests", "valid.yml"),
+		},
+		{
+			// The ports not resolved by the ingress conversion are referenced by name.
+			src: filepath.Join("..", "ingress", "fixtures", "output_convertFile", "ingress_with_named_port.yml"),
+		},
+		{
+			src: filepath.Join("fixtures", "manifests"),
+			expected: []string{
+				"fixtures/manifests/app/crd.yml: Middleware stripprefix: invalid spec: json: unknown field \"prefix\"",
+				"fixtures/manifests/app/crd.yml: IngressRoute invalid: invalid spec: json: unknown field \"middleware\"",
+				"fixtures/manifests/app/crd.yml: Ingress unknown: unknown kind in the traefik.containo.us/v1alpha1 API group",
+				"fixtures/manifests/app/crd.yml: IngressRoute port: invalid port name \"web_http\"",
+				"fixtures/manifests/app/ingress.yml: Ingress whoami: invalid annotation traefik.ingress.kubernetes.io/router.priority: strconv.ParseInt: parsing \"high\": invalid syntax",
+				"fixtures/manifests/app/ingress.yml: Ingress whoami: the Traefik v1 annotation ingress.kubernetes.io/ssl-redirect is ignored by Traefik v2",
+				"fixtures/manifests/app/ingress.yml: Ingress whoami: unknown annotation traefik.ingress.kubernetes.io/rule-type",
+				"fixtures/manifests/app/ingress.yml: Service whoami: invalid annotation traefik.ingress.kubernetes.io/service.foo: field not found, node: foo",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.src, func(t *testing.T) {
+			t.Parallel()
+
+			problems, err := Lint(test.src, Options{})
+			require.NoError(t, err)
+
+			var messages []string
+			for _, problem := range problems {
+				messages = append(messages, problem.String())
+			}
+
+			assert.Equal(t, test.expected, messages)
+		})
+	}
+}
+
+func TestLint_checkRules(t *testing.T) {
+	problems, err := Lint(filepath.Join("fixtures", "rules"), Options{CheckRules: true})
+	require.NoError(t, err)
+
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+
+	expected := []string{
+		"fixtures/rules/dynamic.toml: router broken: invalid rule \"Host(example.com)\": ",
+		"fixtures/rules/dynamic.yml: TCP router db: invalid rule \"HostSNI(`db.example.com`) || Host(`db.example.com`)\": ",
+		"fixtures/rules/ingressroutes.yml: IngressRoute whoami: invalid rule \"Host(`example.com`) && Foo(`bar`)\": ",
+		"fixtures/rules/ingressroutes.yml: IngressRoute whoami: invalid rule \"Host(`example.com`\": ",
+		"fixtures/rules/ingressroutes.yml: IngressRouteTCP db: invalid rule \"Host(`db.example.com`)\": ",
+	}
+
+	require.Len(t, messages, len(expected))
+	for i, message := range messages {
+		assert.True(t, strings.HasPrefix(message, expected[i]), message)
+	}
+}
+
+func TestLint_checkReferences(t *testing.T) {
+	problems, err := Lint(filepath.Join("fixtures", "references"), Options{
+		CheckReferences:  true,
+		KnownMiddlewares: []string{"ssl-redirect"},
+	})
+	require.NoError(t, err)
+
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+
+	expected := []string{
+		"fixtures/references/dynamic.yml: router api: unknown middleware compress@file",
+		"fixtures/references/manifests.yml: Middleware chain: unknown middleware apps-compress@kubernetescrd",
+		"fixtures/references/manifests.yml: IngressRoute whoami: unknown middleware apps-stripprefx@kubernetescrd",
+		"fixtures/references/manifests.yml: IngressRoute whoami: unknown middleware other-stripprefix@kubernetescrd",
+		"fixtures/references/manifests.yml: Ingress whoami: unknown middleware apps-strip@kubernetescrd",
+	}
+
+	assert.Equal(t, expected, messages)
+}
+
+func TestLint_checkConflicts(t *testing.T) {
+	problems, err := Lint(filepath.Join("fixtures", "conflicts"), Options{CheckConflicts: true})
+	require.NoError(t, err)
+
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+
+	file := filepath.Join("fixtures", "conflicts", "manifests.yml")
+	expected := []string{
+		file + ": IngressRoute api-legacy: the rule \"PathPrefix(`/api`) && Host(`Example.com`)\" matches the same requests as IngressRoute api (" + file + ") with the same priority (41): the routing is not predictable, set an explicit priority (ex: 42) on the router which must win",
+		file + ": IngressRoute api-secure: the rule \"Host(`example.com`) && PathPrefix(`/api`)\" matches the same requests as IngressRoute api-legacy (" + file + ") with the same priority (41): the routing is not predictable, set an explicit priority (ex: 42) on the router which must win",
+		file + ": Ingress api: the rule \"Host(`example.com`) && PathPrefix(`/api`)\" matches the same requests as IngressRoute api (" + file + ") with the same priority (41): the routing is not predictable, set an explicit priority (ex: 42) on the router which must win",
+		file + ": Ingress api: the rule \"Host(`example.com`) && PathPrefix(`/api`)\" matches the same requests as IngressRoute api-legacy (" + file + ") with the same priority (41): the routing is not predictable, set an explicit priority (ex: 42) on the router which must win",
+	}
+
+	assert.Equal(t, expected, messages)
+}
+
+func TestLint_line(t *testing.T) {
+	problems, err := Lint(filepath.Join("fixtures", "manifests", "app", "crd.yml"), Options{})
+	require.NoError(t, err)
+
+	var lines []int
+	for _, problem := range problems {
+		lines = append(lines, problem.Line)
+	}
+
+	assert.Equal(t, []int{15, 24, 35, 40}, lines)
+}
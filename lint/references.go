@@ -0,0 +1,90 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+)
+
+const (
+	providerCRD  = "kubernetescrd"
+	providerFile = "file"
+)
+
+// reference is a middleware reference of a router.
+type reference struct {
+	file string
+	line int
+	kind string
+	name string
+	// middleware is the qualified name of the referenced middleware (<name>@<provider>).
+	middleware string
+}
+
+// qualifyMiddleware returns the qualified name of a middleware reference, as Traefik resolves it.
+func qualifyMiddleware(name, provider string) string {
+	if strings.Contains(name, "@") {
+		return name
+	}
+
+	return name + "@" + provider
+}
+
+// crdMiddleware returns the qualified name of a Middleware resource.
+func crdMiddleware(namespace, name string) string {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	return namespace + "-" + name + "@" + providerCRD
+}
+
+// crdReference returns the qualified name of a Middleware reference of a resource in namespace.
+func crdReference(namespace string, ref v1alpha1.MiddlewareRef) string {
+	if strings.Contains(ref.Name, "@") {
+		return ref.Name
+	}
+
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+
+	return crdMiddleware(namespace, ref.Name)
+}
+
+func (l *linter) defineMiddleware(middleware string) {
+	if l.middlewares == nil {
+		l.middlewares = map[string]struct{}{}
+	}
+
+	l.middlewares[middleware] = struct{}{}
+}
+
+func (l *linter) addReference(file, kind, name, middleware string) {
+	if !l.opts.CheckReferences {
+		return
+	}
+
+	l.references = append(l.references, reference{file: file, line: l.line, kind: kind, name: name, middleware: middleware})
+}
+
+// checkReferences reports the references to middlewares which are neither defined in the linted files nor known.
+func (l *linter) checkReferences() {
+	known := map[string]struct{}{}
+	for _, middleware := range l.opts.KnownMiddlewares {
+		known[qualifyMiddleware(middleware, providerFile)] = struct{}{}
+	}
+
+	for _, ref := range l.references {
+		if _, ok := l.middlewares[ref.middleware]; ok {
+			continue
+		}
+
+		if _, ok := known[ref.middleware]; ok {
+			continue
+		}
+
+		l.reportAt(ref.file, ref.line, ref.kind, ref.name, fmt.Sprintf("unknown middleware %s", ref.middleware))
+	}
+}
@@ -1,17 +1,41 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
 	"github.com/traefik/traefik-migration-tool/acme"
+	"github.com/traefik/traefik-migration-tool/annotations"
+	"github.com/traefik/traefik-migration-tool/archive"
+	"github.com/traefik/traefik-migration-tool/cluster"
+	"github.com/traefik/traefik-migration-tool/gitops"
 	"github.com/traefik/traefik-migration-tool/ingress"
+	"github.com/traefik/traefik-migration-tool/kv"
+	"github.com/traefik/traefik-migration-tool/labels"
+	"github.com/traefik/traefik-migration-tool/lint"
+	"github.com/traefik/traefik-migration-tool/plan"
+	"github.com/traefik/traefik-migration-tool/preflight"
+	"github.com/traefik/traefik-migration-tool/review"
+	"github.com/traefik/traefik-migration-tool/server"
 	"github.com/traefik/traefik-migration-tool/static"
+	"github.com/traefik/traefik-migration-tool/v2tov3"
+	"github.com/traefik/traefik/v2/pkg/types"
 )
 
 var (
@@ -27,41 +51,291 @@ type acmeConfig struct {
 }
 
 type ingressConfig struct {
+	input       string
+	output      string
+	reportHTML  string
+	audit       bool
+	explain     bool
+	check       bool
+	checkpoint  string
+	resume      bool
+	restart     bool
+	nameTmpl    string
+	split       bool
+	archive     string
+	progress    string
+	interactive bool
+	pluginsDir  string
+	gitops      gitops.Options
+	outputKind  string
+	gateway     string
+	gatewayCfg  ingress.Gateway
+	dualAPI     bool
+	canary      canaryConfig
+	svcPatches  bool
+	entryPoints map[string]string
+	staticFile  string
+	class       string
+	namedPorts  bool
+	annotPrefix string
+	hostless    string
+	sslRedirect string
+	sslRedirRef string
+	miNameTmpl  string
+	dedup       bool
+	catalog     string
+	keepMeta    bool
+	minDiff     bool
+	wrapLists   bool
+	mirrorTree  bool
+	include     []string
+	exclude     []string
+	manifestFmt string
+	mark        bool
+}
+
+type canaryConfig struct {
+	entryPoint    string
+	legacyService string
+	weight        int
+}
+
+type postRenderConfig struct {
+	all        bool
+	pluginsDir string
+}
+
+type staticConfig struct {
+	input     string
+	outputDir string
+	from      string
+	to        string
+}
+
+type labelsConfig struct {
 	input  string
 	output string
+	strict bool
+	report string
 }
 
-type staticConfig struct {
+type kvConfig struct {
+	source      kv.StoreConfig
+	destination kv.StoreConfig
+	output      string
+	migrate     kv.MigrateOptions
+}
+
+type lintConfig struct {
+	input string
+	lint  lint.Options
+}
+
+type serveConfig struct {
+	addr     string
+	grpcAddr string
+}
+
+type preflightConfig struct {
+	preflight preflight.Options
+}
+
+type planConfig struct {
+	input     string
+	namespace string
+	report    string
+	apply     bool
+	cluster   plan.ApplyOptions
+}
+
+type v2tov3CRDConfig struct {
+	input       string
+	output      string
+	crdsVersion string
+}
+
+type v2tov3FileConfig struct {
+	input  string
+	output string
+}
+
+type v2tov3LabelsConfig struct {
+	input  string
+	output string
+}
+
+type v2tov3ClusterConfig struct {
+	cluster     v2tov3.ClusterOptions
+	report      string
+	crdsVersion string
+}
+
+type v2tov3StaticConfig struct {
 	input     string
 	outputDir string
 }
 
+func (c canaryConfig) build() (*ingress.Canary, error) {
+	canary := &ingress.Canary{EntryPoint: c.entryPoint, Weight: c.weight}
+
+	if c.legacyService != "" {
+		legacyService, err := ingress.ParseService(c.legacyService)
+		if err != nil {
+			return nil, err
+		}
+		canary.LegacyService = legacyService
+	}
+
+	return canary, canary.Validate()
+}
+
+// pluginsDirUsage is the usage of the plugins-dir flag of the commands converting the ingresses.
+const pluginsDirUsage = "Directory of the plugins converting the annotations unknown to the tool: Go scripts (.go files) interpreted with Yaegi, " +
+	"with a func Handle(ingress []byte) ([]byte, error) function receiving the Ingress as JSON, and returning the middlewares to add to the routes, entry points, and priority as JSON " +
+	`(ex: {"middlewares": [{"name": "waf", "spec": {"forwardAuth": {"address": "http://waf"}}}], "entryPoints": ["websecure"], "priority": 10}), or nil.`
+
+// Exit codes of the commands.
+const (
+	exitError    = 1
+	exitProblems = 2
+)
+
+// problemsError is returned by the commands finding problems in their inputs, as opposed to failing.
+type problemsError struct {
+	msg string
+}
+
+func (e problemsError) Error() string {
+	return e.msg
+}
+
 func main() {
 	log.SetFlags(log.Lshortfile)
 
+	var outputAnnotations string
+
 	rootCmd := &cobra.Command{
-		Use:     "traefik-migration-tool",
-		Short:   "A tool to migrate from Traefik v1 to Traefik v2.",
-		Long:    `A tool to migrate from Traefik v1 to Traefik v2.`,
+		Use:   "traefik-migration-tool",
+		Short: "A tool to migrate from Traefik v1 to Traefik v2.",
+		Long: `A tool to migrate from Traefik v1 to Traefik v2.
+Exit codes: 0 on success, 1 when the command fails, 2 when problems are found in the inputs (lint, preflight, ingress --check).`,
 		Version: Version,
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return annotations.Validate(outputAnnotations)
+		},
 	}
 
+	rootCmd.PersistentFlags().StringVar(&outputAnnotations, "output-annotations", "", "Print the problems found by the lint and ingress commands as CI annotations: github.")
+
 	var ingressCfg ingressConfig
 
 	ingressCmd := &cobra.Command{
 		Use:   "ingress",
 		Short: "Migrate 'Ingress' to Traefik 'IngressRoute' resources.",
-		Long:  "Migrate 'Ingress' to Traefik 'IngressRoute' resources.",
+		Long: `Migrate 'Ingress' to Traefik 'IngressRoute' resources.
+The converted routers matching the same requests with the same priority are reported: Traefik v1 and Traefik v2 don't resolve these conflicts the same way.
+With - as input or output, the manifests are read from the standard input or written to the standard output, and the messages are printed on the standard error:
+  kubectl get ingress -o yaml | traefik-migration-tool ingress -i - -o - | kubectl apply -f -`,
 		PreRunE: func(_ *cobra.Command, _ []string) error {
-			fmt.Printf("Traefik Migration: %s - %s - %s\n", Version, Date, ShortCommit)
+			// With the manifests on the standard output, the standard error only holds the progress events.
+			if ingressCfg.output != stdio || ingressCfg.progress == "" {
+				printBanner(ingressCfg.output)
+			}
 
 			if ingressCfg.input == "" || ingressCfg.output == "" {
 				return errors.New("input and output flags are requires")
 			}
 
+			if (ingressCfg.resume || ingressCfg.restart) && ingressCfg.checkpoint == "" {
+				return errors.New("the resume and restart flags require the checkpoint flag")
+			}
+
+			if ingressCfg.resume && ingressCfg.restart {
+				return errors.New("the resume and restart flags are mutually exclusive")
+			}
+
+			if ingressCfg.check && ingressCfg.checkpoint != "" {
+				return errors.New("the check and checkpoint flags are mutually exclusive")
+			}
+
+			if ingressCfg.interactive && ingressCfg.check {
+				return errors.New("the interactive and check flags are mutually exclusive")
+			}
+
+			if ingressCfg.output == stdio {
+				if ingressCfg.check {
+					return errors.New("the check flag cannot be used with the standard output")
+				}
+				if ingressCfg.checkpoint != "" {
+					return errors.New("the checkpoint flag cannot be used with the standard output")
+				}
+				if ingressCfg.gitops.Format != "" {
+					return errors.New("the output-format flag cannot be used with the standard output")
+				}
+			}
+
+			if ingressCfg.input == stdio {
+				if ingressCfg.check {
+					return errors.New("the check flag cannot be used with the standard input")
+				}
+				if ingressCfg.checkpoint != "" {
+					return errors.New("the checkpoint flag cannot be used with the standard input")
+				}
+				if ingressCfg.interactive {
+					return errors.New("the interactive flag cannot be used with the standard input")
+				}
+			}
+
+			if ingressCfg.progress != "" && ingressCfg.progress != "json" {
+				return fmt.Errorf("unsupported progress format %q: json", ingressCfg.progress)
+			}
+
+			if ingressCfg.split {
+				if ingressCfg.nameTmpl != "" {
+					return errors.New("the split and output-name-template flags are mutually exclusive")
+				}
+				if ingressCfg.checkpoint != "" {
+					return errors.New("the split and checkpoint flags are mutually exclusive")
+				}
+				// The objects of both API groups have the same namespace, kind and name.
+				if ingressCfg.dualAPI {
+					return errors.New("the split and dual-api-version flags are mutually exclusive")
+				}
+				ingressCfg.nameTmpl = ingress.SplitNameTemplate
+			}
+
+			if ingressCfg.nameTmpl != "" && ingressCfg.checkpoint != "" {
+				return errors.New("the output-name-template and checkpoint flags are mutually exclusive")
+			}
+
+			if ingressCfg.gitops.Format != "" {
+				if ingressCfg.gitops.Path == "" {
+					if filepath.IsAbs(ingressCfg.output) {
+						return errors.New("the gitops-path flag is required when the output directory is absolute")
+					}
+					ingressCfg.gitops.Path = ingressCfg.output
+				}
+
+				err := ingressCfg.gitops.Validate()
+				if err != nil {
+					return err
+				}
+			}
+
+			if ingressCfg.restart {
+				err := os.Remove(ingressCfg.checkpoint)
+				if err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+
+			if ingressCfg.output == stdio {
+				return nil
+			}
+
 			info, err := os.Stat(ingressCfg.output)
 			if err != nil {
-				if !os.IsNotExist(err) {
+				if !os.IsNotExist(err) || ingressCfg.check {
 					return err
 				}
 				err = os.MkdirAll(ingressCfg.output, 0755)
@@ -74,29 +348,343 @@ func main() {
 
 			return nil
 		},
-		RunE: func(_ *cobra.Command, _ []string) error {
-			return ingress.Convert(ingressCfg.input, ingressCfg.output)
+		RunE: func(_ *cobra.Command, _ []string) (err error) {
+			// With the standard output, the files are converted into a temporary directory.
+			output, done, err := stdoutPath(ingressCfg.output, "")
+			if err != nil {
+				return err
+			}
+			defer func() { err = done(err) }()
+
+			opts := ingress.Options{
+				Audit:             ingressCfg.audit,
+				Explain:           ingressCfg.explain,
+				Checkpoint:        ingressCfg.checkpoint,
+				Resume:            ingressCfg.resume,
+				DualAPIVersion:    ingressCfg.dualAPI,
+				SyncWaves:         ingressCfg.gitops.Format == gitops.FormatArgoCD,
+				OutputKind:        ingressCfg.outputKind,
+				ServicePatches:    ingressCfg.svcPatches,
+				EntryPoints:       ingressCfg.entryPoints,
+				IngressClass:      ingressCfg.class,
+				ResolveNamedPorts: ingressCfg.namedPorts,
+				AnnotationPrefix:  ingressCfg.annotPrefix,
+				Hostless:          ingressCfg.hostless,
+				SSLRedirect:       ingressCfg.sslRedirect,
+				SSLRedirectRef:    ingressCfg.sslRedirRef,
+				DedupMiddlewares:  ingressCfg.dedup,
+				KeepMetadata:      ingressCfg.keepMeta,
+				MinimalDiff:       ingressCfg.minDiff,
+				WrapLists:         ingressCfg.wrapLists,
+				MirrorTree:        ingressCfg.mirrorTree,
+				Include:           ingressCfg.include,
+				Exclude:           ingressCfg.exclude,
+				ManifestFormat:    ingressCfg.manifestFmt,
+			}
+
+			if ingressCfg.mark {
+				opts.Marker = Version
+			}
+
+			if ingressCfg.outputKind == ingress.OutputKindGateway {
+				parts := strings.Split(ingressCfg.gateway, "/")
+				if len(parts) != 2 {
+					return fmt.Errorf("invalid gateway %q: <namespace>/<name>", ingressCfg.gateway)
+				}
+
+				gateway := ingressCfg.gatewayCfg
+				gateway.Namespace, gateway.Name = parts[0], parts[1]
+				opts.Gateway = &gateway
+			}
+
+			if ingressCfg.canary.entryPoint != "" || ingressCfg.canary.legacyService != "" {
+				canary, err := ingressCfg.canary.build()
+				if err != nil {
+					return err
+				}
+				opts.Canary = canary
+			}
+
+			if ingressCfg.staticFile != "" {
+				tlsOptions, err := static.TLSOptions(ingressCfg.staticFile)
+				if err != nil {
+					return err
+				}
+				opts.TLSOptions = tlsOptions
+			}
+
+			if ingressCfg.catalog != "" {
+				catalog, err := ingress.LoadMiddlewareCatalog(ingressCfg.catalog)
+				if err != nil {
+					return err
+				}
+				opts.MiddlewareCatalog = catalog
+			}
+
+			if ingressCfg.pluginsDir != "" {
+				plugins, err := ingress.LoadPlugins(ingressCfg.pluginsDir)
+				if err != nil {
+					return err
+				}
+				opts.Plugins = plugins
+			}
+
+			if ingressCfg.interactive {
+				opts.Review = review.Prompt(os.Stdin, os.Stdout)
+			}
+
+			if ingressCfg.progress == "json" {
+				opts.Progress = ingress.JSONProgress(os.Stderr)
+
+				// The standard error only holds the progress events: the messages go to the standard output,
+				// or are log events when the manifests are written to the standard output.
+				if ingressCfg.output == stdio {
+					restore, err := progressStdout(opts.Progress)
+					if err != nil {
+						return err
+					}
+					defer restore()
+
+					// The notes are warning events.
+					opts.Notes = io.Discard
+				}
+
+				log.SetOutput(os.Stdout)
+			}
+
+			if ingressCfg.nameTmpl != "" {
+				tmpl, err := ingress.ParseNameTemplate(ingressCfg.nameTmpl)
+				if err != nil {
+					return fmt.Errorf("invalid output name template: %w", err)
+				}
+				opts.NameTemplate = tmpl
+			}
+
+			if ingressCfg.miNameTmpl != "" {
+				tmpl, err := ingress.ParseMiddlewareNameTemplate(ingressCfg.miNameTmpl)
+				if err != nil {
+					return fmt.Errorf("invalid middleware name template: %w", err)
+				}
+				opts.MiddlewareNameTemplate = tmpl
+			}
+
+			if ingressCfg.check {
+				diffs, err := ingress.Check(ingressCfg.input, ingressCfg.output, opts)
+				if err != nil {
+					return err
+				}
+
+				for _, diff := range diffs {
+					fmt.Println(diff)
+				}
+
+				if len(diffs) > 0 {
+					return problemsError{msg: fmt.Sprintf("the output directory %s is not up to date: %d files differ", ingressCfg.output, len(diffs))}
+				}
+
+				return nil
+			}
+
+			// The ingresses whose conversion failed are kept in the output: the command fails after reporting the other ingresses.
+			report, err := convertIngress(ingressCfg.input, output, opts)
+			var failed *ingress.FailedError
+			if err != nil && !errors.As(err, &failed) {
+				return err
+			}
+
+			if ingressCfg.reportHTML != "" {
+				err = report.SaveHTML(ingressCfg.reportHTML)
+				if err != nil {
+					return err
+				}
+			}
+
+			if ingressCfg.gitops.Format != "" {
+				err = gitops.Write(gitopsManifestPath(ingressCfg.output, ingressCfg.gitops.Format), ingressCfg.gitops)
+				if err != nil {
+					return err
+				}
+			}
+
+			if ingressCfg.archive != "" {
+				err = writeIngressArchive(ingressCfg.archive, output, report)
+				if err != nil {
+					return fmt.Errorf("writing the archive: %w", err)
+				}
+			}
+
+			for _, ing := range report.Ingresses {
+				for _, action := range ing.ManualActions {
+					printAnnotation(outputAnnotations, annotations.Annotation{
+						Level:   annotations.LevelWarning,
+						File:    ing.File,
+						Message: fmt.Sprintf("Ingress %s/%s: the annotation %s must be converted manually. %s", ing.Namespace, ing.Name, action.Annotation, action.Message),
+					})
+				}
+
+				for _, note := range ing.Notes {
+					printAnnotation(outputAnnotations, annotations.Annotation{
+						Level:   annotations.LevelWarning,
+						File:    ing.File,
+						Message: fmt.Sprintf("Ingress %s/%s: %s", ing.Namespace, ing.Name, note),
+					})
+				}
+			}
+
+			// Traefik v1 and v2 don't order the routers the same way: the conflicts of the converted routers are reported.
+			conflicts, err := lint.Lint(output, lint.Options{CheckConflicts: true})
+			if err != nil {
+				return err
+			}
+
+			for _, conflict := range conflicts {
+				if output != ingressCfg.output {
+					conflict.File, _ = filepath.Rel(output, conflict.File)
+				}
+				fmt.Println(conflict)
+				printAnnotation(outputAnnotations, problemAnnotation(conflict, annotations.LevelWarning))
+			}
+
+			if failed != nil {
+				return failed
+			}
+
+			return nil
 		},
 	}
 
-	ingressCmd.Flags().StringVarP(&ingressCfg.input, "input", "i", "", "Input directory.")
-	ingressCmd.Flags().StringVarP(&ingressCfg.output, "output", "o", "./output", "Output directory.")
+	ingressCmd.Flags().StringVarP(&ingressCfg.input, "input", "i", "", "Input file or directory, - for the standard input.")
+	ingressCmd.Flags().StringVarP(&ingressCfg.output, "output", "o", "./output", "Output directory, - for the standard output.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.audit, "audit", false, "Record on each converted object, in the migration.traefik.io/audit annotation, the transformations applied to the Ingress.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.explain, "explain", false, "Add above each converted field and middleware a '# migrated from' comment with the Traefik v1 setting which produced it.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.check, "check", false, "Check, without writing anything, that the output directory is up to date with the conversion of the input: fail when the files differ.")
+	ingressCmd.Flags().StringVar(&ingressCfg.reportHTML, "report-html", "", "Path to a standalone HTML page where the migration report (per namespace: converted ingresses, generated middlewares, manual actions, notes) is written.")
+	ingressCmd.Flags().StringVar(&ingressCfg.checkpoint, "checkpoint", "", "Path to a file recording the converted files, to resume an interrupted migration. The file is removed when the migration completes.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.resume, "resume", false, "Resume the migration recorded by the checkpoint: the files already converted are skipped, and are not part of the report.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.restart, "restart", false, "Discard the checkpoint of a previous migration, and convert all the files.")
+	ingressCmd.Flags().StringVar(&ingressCfg.pluginsDir, "plugins-dir", "", pluginsDirUsage)
+	ingressCmd.Flags().StringVar(&ingressCfg.canary.entryPoint, "canary-entrypoint", "", "Keep the ingresses, and bind their conversion to this entry point only, to shadow-test the Traefik v2 routing.")
+	ingressCmd.Flags().StringVar(&ingressCfg.canary.legacyService, "canary-legacy-service", "", "Keep the ingresses, and send through a weighted TraefikService the traffic of each converted route to its services (canary-weight percent) or to this Traefik v1 service: <namespace>/<name>:<port>.")
+	ingressCmd.Flags().IntVar(&ingressCfg.canary.weight, "canary-weight", 10, "Percentage of the traffic of the converted routes sent to their services, the rest is sent to the canary-legacy-service.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.dualAPI, "dual-api-version", false, "Write each converted object twice: in the traefik.containo.us API group of Traefik v2, and converted to the traefik.io API group of Traefik v3, to run Traefik v2 and Traefik v3 side by side.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.interactive, "interactive", false, "Review the conversion of each Ingress (diff, generated middlewares, manual actions, notes) before writing it: accept it, skip it to keep the Ingress, or rename the IngressRoute.")
+	ingressCmd.Flags().StringVar(&ingressCfg.progress, "progress", "", "Print the progress events (file_started, file_finished, ingress_converted, warning, and log with - as output) on the standard error, one per line, in the given format: json.")
+	ingressCmd.Flags().StringVar(&ingressCfg.archive, "output-archive", "", "Path to a tar.gz archive where the output directory (under output/) and the migration report (report.json and report.html) are bundled.")
+	ingressCmd.Flags().StringVar(&ingressCfg.outputKind, "output-kind", ingress.OutputKindIngressRoute, "Kind of the resources the ingresses are converted to: "+
+		"ingressroute (the default, existing conversion: IngressRoute of the Traefik CRD provider, with Host and PathPrefix matchers and the middlewares of the annotations, the Ingress is not kept), "+
+		"or gateway (HTTPRoutes of the Gateway API, for the experimental Traefik provider, with the middlewares as filters, and the Gateway written to gateway.yml).")
+	ingressCmd.Flags().StringVar(&ingressCfg.gateway, "gateway", "default/traefik-gateway", "Gateway of the HTTPRoutes converted with the gateway output kind: <namespace>/<name>.")
+	ingressCmd.Flags().StringVar(&ingressCfg.gatewayCfg.ClassName, "gateway-class", "traefik", "GatewayClass of the Gateway converted with the gateway output kind.")
+	ingressCmd.Flags().Int32Var(&ingressCfg.gatewayCfg.Port, "gateway-port", 80, "Port of the HTTP listener of the Gateway converted with the gateway output kind, the port of a Traefik entry point.")
+	ingressCmd.Flags().StringVar(&ingressCfg.gitops.Format, "output-format", "", "Wrap the output directory in the manifest of a GitOps tool, written next to the output directory (<output>-<format>.yml): "+
+		"flux (Flux Kustomization) or argocd (Argo CD Application, the converted objects are annotated with sync waves applying the middlewares before the routes).")
+	ingressCmd.Flags().StringVar(&ingressCfg.gitops.Name, "gitops-name", "traefik-migration", "Name of the Flux Kustomization or of the Argo CD Application.")
+	ingressCmd.Flags().StringVar(&ingressCfg.gitops.Repository, "gitops-repository", "", "Flux GitRepository source (default to flux-system), or URL of the Git repository of the Argo CD Application.")
+	ingressCmd.Flags().StringVar(&ingressCfg.gitops.Path, "gitops-path", "", "Path of the output directory in the Git repository (default to the output flag).")
+	ingressCmd.Flags().BoolVar(&ingressCfg.svcPatches, "service-patches", false, "Write in service-patches.yml, in the output directory, the strategic merge patches adding to the backend services the Traefik v2 annotations "+
+		"(service.serversscheme, service.passhostheader, service.sticky.cookie) of the protocol, preserve-host, and affinity annotations, for the Kubernetes Ingress provider.")
+	ingressCmd.Flags().StringToStringVar(&ingressCfg.entryPoints, "entrypoint-map", nil, "Names of the Traefik v2 entry points of the Traefik v1 entry points (ex: http=web,https=websecure), "+
+		"used to rename the entry points of the frontend-entry-points annotation, and to convert the redirect-entry-point annotation: the redirections to an entry point mapped to web or websecure use its scheme.")
+	ingressCmd.Flags().StringVar(&ingressCfg.staticFile, "static-config", "", "Path to the Traefik v1 static configuration file (traefik.toml): the TLS options of its entry points (minVersion, cipherSuites, sniStrict) "+
+		"are converted to a TLSOption referenced by the IngressRoutes of the ingresses with a TLS section.")
+	ingressCmd.Flags().StringVar(&ingressCfg.class, "ingress-class", "", "Name of the IngressClass of Traefik written in ingress-class.yml, in the output directory: "+
+		"the ingresses kept in the output with this kubernetes.io/ingress.class annotation reference it with spec.ingressClassName instead.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.namedPorts, "resolve-named-ports", false, "Resolve the named ports of the backends with the Service manifests of the input: "+
+		"the ports which are not resolved are referenced by name, which requires Traefik v2.5 or later.")
+	ingressCmd.Flags().StringVar(&ingressCfg.annotPrefix, "annotation-prefix", "", "Custom prefix of the Traefik v1 annotations of the ingresses (ex: example.com/ for example.com/rule-type), "+
+		"read as the ingress.kubernetes.io/ annotations: the annotations already set with the ingress.kubernetes.io/ prefix take precedence.")
+	ingressCmd.Flags().StringVar(&ingressCfg.hostless, "hostless", ingress.HostlessCatchAll, "Conversion of the ingress rules without host: "+
+		"catchall (routes matching their path on all the hosts, or all the requests without path), hostregexp (routes matching all the hosts with HostRegexp, and their path), "+
+		"or skip (the rules are not converted, they are reported).")
+	ingressCmd.Flags().StringVar(&ingressCfg.sslRedirect, "ssl-redirect", ingress.SSLRedirectHeaders, "Conversion of the ssl-redirect and ssl-temporary-redirect annotations: "+
+		"headers (SSL redirect options of the headers middleware, deprecated in Traefik v2), "+
+		"or redirectscheme (RedirectScheme middleware to https, in the namespace of the Ingress, the ssl-host annotation is reported).")
+	ingressCmd.Flags().StringVar(&ingressCfg.sslRedirRef, "ssl-redirect-ref", "", "Middleware referenced by the routes of the ingresses with ssl-redirect annotations, instead of their conversion: "+
+		"a middleware of another provider (ex: https-redirect@file), <namespace>/<name>, or the name of a middleware of the namespace of the Ingress.")
+	ingressCmd.Flags().StringVar(&ingressCfg.miNameTmpl, "middleware-name-template", "", "Go template of the names of the converted middlewares (ex: '{{.Ingress}}-{{.Kind | lower}}-{{.Hash}}'), "+
+		"with the fields Namespace, Ingress (name of the Ingress), Kind (option of the middleware, ex: stripPrefix), Name (default name), Hash (hash of the spec), and the lower and upper functions.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.dedup, "dedup-middlewares", false, "Write a single middleware per spec and namespace, with the name and in the file of its first conversion: "+
+		"the routes of the other ingresses of the namespace reference it.")
+	ingressCmd.Flags().StringVar(&ingressCfg.catalog, "middleware-catalog", "", "Path to the manifests (a file or a directory) of the middlewares already deployed: "+
+		"the routes reference them instead of the converted middlewares with the same spec, in the same namespace, which are not written.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.keepMeta, "keep-metadata", false, "Keep the fields set by the API server (status, creationTimestamp, resourceVersion, uid, managedFields...) "+
+		"in the converted objects and the ingresses kept in the output: they are removed by default.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.minDiff, "minimal-diff", false, "Keep the text of the source manifests: the changes of the objects kept in the output are patched in place, "+
+		"preserving the field order and the quoting, and the converted objects are written after the source documents.")
+	ingressCmd.Flags().StringSliceVar(&ingressCfg.include, "include", nil, "Glob patterns of the files of the input directory to convert (ex: '*.yaml'), "+
+		"relative to the input directory, ** matches any number of directories, the patterns without / match the file names.")
+	ingressCmd.Flags().StringSliceVar(&ingressCfg.exclude, "exclude", nil, "Glob patterns of the files and directories of the input directory to not convert (ex: '**/charts/**'), "+
+		"the excluded files are not written in the output directory.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.mirrorTree, "mirror-tree", false, "Write the files of an input directory at the same paths relative to the output directory, "+
+		"instead of under a directory named after the input directory.")
+	ingressCmd.Flags().StringVar(&ingressCfg.manifestFmt, "manifest-format", "", "Format of the written manifests: yaml, or json (an object, or a List of the objects when there are several, per file). "+
+		"By default, the manifests converted from a JSON input (ex: kubectl get -o json) are written as JSON, the others as YAML. "+
+		"It is not named output-format: --output-format is the GitOps manifest wrapping the output directory.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.mark, "mark-converted", false, "Stamp the converted objects, and the ingresses kept next to their conversion, with the migration.traefik.io/converted annotation holding the version of the tool. "+
+		"The stamped ingresses of the input are kept as is, and the routes reference the stamped middlewares of the input instead of converting them again: a partially migrated input can be converted again.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.wrapLists, "wrap-lists", false, "Write the objects converted from the ingresses of a List, with the other items of the List, in a List.")
+	ingressCmd.Flags().StringVar(&ingressCfg.nameTmpl, "output-name-template", "", "Go template of the path, relative to the output directory, of the file of each object (ex: '{{.Namespace}}/{{.Kind | lower}}-{{.Name}}.yaml'), with the fields Namespace, Kind, Name, File (source file name), and the lower and upper functions. By default, the output files mirror the input files.")
+	ingressCmd.Flags().BoolVar(&ingressCfg.split, "split", false, "Write each object in its own file, named <namespace>-<kind>-<name>.yaml, instead of a file per input file (not with --dual-api-version).")
 
 	rootCmd.AddCommand(ingressCmd)
 
+	postRenderCfg := postRenderConfig{}
+
+	postRenderCmd := &cobra.Command{
+		Use:   "post-render",
+		Short: "Convert the Ingresses of the manifests rendered by Helm, as a Helm post-renderer.",
+		Long: `Convert the Ingresses of the manifests rendered by Helm, as a Helm post-renderer.
+Read the manifests from the standard input, convert the Ingresses using Traefik v1 annotations to 'IngressRoute' resources, and write all the manifests to the standard output.
+The Ingresses without Traefik v1 annotations are kept, unless the all flag is set. The annotations which must be converted manually are reported on the standard error.
+Helm runs the post-renderer without arguments: use a wrapper script running 'traefik-migration-tool post-render', or the --post-renderer-args option when the Helm version supports it:
+  helm install my-release my-chart --post-renderer traefik-migration-tool --post-renderer-args post-render`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			opts := ingress.Options{AnnotatedOnly: !postRenderCfg.all, Notes: os.Stderr}
+
+			if postRenderCfg.pluginsDir != "" {
+				plugins, err := ingress.LoadPlugins(postRenderCfg.pluginsDir)
+				if err != nil {
+					return err
+				}
+				opts.Plugins = plugins
+			}
+
+			_, err := ingress.ConvertStream(os.Stdin, os.Stdout, opts)
+			return err
+		},
+	}
+
+	postRenderCmd.Flags().BoolVar(&postRenderCfg.all, "all", false, "Convert all the Ingresses, including the ones without Traefik v1 annotations.")
+	postRenderCmd.Flags().StringVar(&postRenderCfg.pluginsDir, "plugins-dir", "", pluginsDirUsage)
+
+	rootCmd.AddCommand(postRenderCmd)
+
 	acmeCfg := acmeConfig{}
 
 	acmeCmd := &cobra.Command{
 		Use:   "acme",
 		Short: "Migrate acme.json file from Traefik v1 to Traefik v2.",
 		Long:  "Migrate acme.json file from Traefik v1 to Traefik v2.",
-		RunE: func(_ *cobra.Command, _ []string) error {
-			return acme.Convert(acmeCfg.input, acmeCfg.output, acmeCfg.resolverName)
+		RunE: func(_ *cobra.Command, _ []string) (err error) {
+			input, cleanup, err := stdinPath(acmeCfg.input, "acme.json")
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			output, done, err := stdoutPath(acmeCfg.output, "acme.json")
+			if err != nil {
+				return err
+			}
+			defer func() { err = done(err) }()
+
+			return acme.Convert(input, output, acmeCfg.resolverName)
 		},
 	}
 
-	acmeCmd.Flags().StringVarP(&acmeCfg.input, "input", "i", "./acme.json", "Path to the acme.json file from Traefik v1.")
-	acmeCmd.Flags().StringVarP(&acmeCfg.output, "output", "o", "./acme-new.json", "Path to the acme.json file for Traefik v2.")
+	acmeCmd.Flags().StringVarP(&acmeCfg.input, "input", "i", "./acme.json", "Path to the acme.json file from Traefik v1, - for the standard input.")
+	acmeCmd.Flags().StringVarP(&acmeCfg.output, "output", "o", "./acme-new.json", "Path to the acme.json file for Traefik v2, - for the standard output.")
 	acmeCmd.Flags().StringVar(&acmeCfg.resolverName, "resolver", "default", "The name of the certificates resolver.")
 
 	rootCmd.AddCommand(acmeCmd)
@@ -107,17 +695,486 @@ func main() {
 		Use:   "static",
 		Short: "Migrate static configuration file from Traefik v1 to Traefik v2.",
 		Long: `Migrate static configuration file from Traefik v1 to Traefik v2.
-Convert only the static configuration.`,
+Convert only the static configuration.
+With --from=v2 --to=v3, migrate a Traefik v2 static configuration file (TOML or YAML) to Traefik v3, as the v2tov3 static command.`,
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return static.Convert(staticCfg.input, staticCfg.outputDir)
+			switch staticCfg.from + "-" + staticCfg.to {
+			case "v1-v2":
+				return static.Convert(staticCfg.input, staticCfg.outputDir, nil)
+			case "v2-v3":
+				return v2tov3.ConvertStatic(staticCfg.input, staticCfg.outputDir, nil)
+			default:
+				return fmt.Errorf("unsupported migration from %q to %q: v1 to v2, v2 to v3", staticCfg.from, staticCfg.to)
+			}
 		},
 	}
 
-	staticCmd.Flags().StringVarP(&staticCfg.input, "input", "i", "./traefik.toml", "Path to the traefik.toml file from Traefik v1.")
+	staticCmd.Flags().StringVarP(&staticCfg.input, "input", "i", "./traefik.toml", "Path to the static configuration file: traefik.toml from Traefik v1, TOML or YAML from Traefik v2.")
 	staticCmd.Flags().StringVarP(&staticCfg.outputDir, "output-dir", "d", "./static", "Path to the directory of the created files")
+	staticCmd.Flags().StringVar(&staticCfg.from, "from", "v1", "Traefik version of the input file: v1 or v2.")
+	staticCmd.Flags().StringVar(&staticCfg.to, "to", "v2", "Traefik version of the created files: v2 or v3.")
 
 	rootCmd.AddCommand(staticCmd)
 
+	labelsCfg := labelsConfig{}
+
+	labelsCmd := &cobra.Command{
+		Use:   "labels",
+		Short: "Migrate Docker labels from Traefik v1 to a Traefik v2 file provider configuration.",
+		Long: `Migrate Docker labels from Traefik v1 to a Traefik v2 file provider configuration.
+Convert the labels of the services defined in a docker-compose file to an equivalent dynamic configuration.
+When the input is a directory, all the compose files of the directory tree are converted and the output is a directory.`,
+		RunE: func(_ *cobra.Command, _ []string) (err error) {
+			input, cleanup, err := stdinPath(labelsCfg.input, "docker-compose.yml")
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			output, done, err := stdoutPath(labelsCfg.output, "dynamic.yml")
+			if err != nil {
+				return err
+			}
+			defer func() { err = done(err) }()
+
+			report, err := labels.Convert(input, output, labelsCfg.strict)
+			if report != nil {
+				fmt.Print(report)
+
+				if labelsCfg.report != "" {
+					if errReport := report.Save(labelsCfg.report); errReport != nil {
+						return errReport
+					}
+				}
+			}
+
+			return err
+		},
+	}
+
+	labelsCmd.Flags().StringVarP(&labelsCfg.input, "input", "i", "./docker-compose.yml", "Path to the docker-compose file, or to a directory of compose files, using Traefik v1 labels, - for the standard input.")
+	labelsCmd.Flags().StringVarP(&labelsCfg.output, "output", "o", "./dynamic.yml", "Path to the file provider dynamic configuration for Traefik v2 (a directory when the input is a directory), - for the standard output.")
+	labelsCmd.Flags().BoolVar(&labelsCfg.strict, "strict", false, "Fail if a Traefik label cannot be converted.")
+	labelsCmd.Flags().StringVar(&labelsCfg.report, "report", "", "Path to a JSON file where the consolidated conversion report is written.")
+
+	rootCmd.AddCommand(labelsCmd)
+
+	kvCfg := kvConfig{}
+
+	kvCmd := &cobra.Command{
+		Use:   "kv",
+		Short: "Migrate a KV store tree from the Traefik v1 layout to the Traefik v2 layout.",
+		Long: `Migrate a KV store tree from the Traefik v1 layout to the Traefik v2 layout.
+Read the frontends, backends, and TLS certificates of the v1 tree and write the equivalent v2 keys (routers, services, middlewares, and certificates).
+The source and the destination can be different stores: a tree read with the etcd v2 API can be written with the etcd v3 API.
+The values are not chunked: Traefik reads each key from a single znode, so a value of 1 MB or more fails the migration to ZooKeeper.
+With the output flag, the configuration is written to a file provider configuration instead of a KV store.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if kvCfg.output != "" {
+				return kv.Export(kvCfg.source, kvCfg.output)
+			}
+
+			if kvCfg.destination.Backend == "" {
+				kvCfg.destination.Backend = kvCfg.source.Backend
+			}
+
+			if len(kvCfg.destination.Endpoints) == 0 {
+				kvCfg.destination.Endpoints = kvCfg.source.Endpoints
+
+				if kvCfg.destination.Username == "" && kvCfg.destination.Password == "" {
+					kvCfg.destination.Username = kvCfg.source.Username
+					kvCfg.destination.Password = kvCfg.source.Password
+				}
+				if kvCfg.destination.Token == "" {
+					kvCfg.destination.Token = kvCfg.source.Token
+				}
+				if kvCfg.destination.TLS == (types.ClientTLS{}) {
+					kvCfg.destination.TLS = kvCfg.source.TLS
+				}
+			}
+
+			return kv.Migrate(kvCfg.source, kvCfg.destination, kvCfg.migrate)
+		},
+	}
+
+	addKVStoreFlags(kvCmd, &kvCfg.source, "source", "the store containing the Traefik v1 tree", "consul")
+	addKVStoreFlags(kvCmd, &kvCfg.destination, "destination", "the store where the Traefik v2 keys are written", "")
+	kvCmd.Flags().BoolVar(&kvCfg.migrate.DryRun, "dry-run", false, "Show the keys that would be created, modified, or left behind in the destination store, without writing them.")
+	kvCmd.Flags().BoolVar(&kvCfg.migrate.Prune, "prune", false, "Delete the keys left behind under the destination prefix (ex: the v1 keys) after a successful migration.")
+	kvCmd.Flags().StringVarP(&kvCfg.output, "output", "o", "", "Path to a file provider dynamic configuration for Traefik v2, written instead of the destination store.")
+
+	rootCmd.AddCommand(kvCmd)
+
+	lintCfg := lintConfig{}
+
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check Kubernetes manifests against the Traefik v2 CRD schemas and Ingress annotations.",
+		Long: `Check Kubernetes manifests against the Traefik v2 CRD schemas and Ingress annotations.
+Report the Traefik custom resources with an unknown kind or an invalid spec, and the Ingress and Service annotations not recognized by Traefik v2.
+With the check-rules flag, the routers rules are parsed as Traefik does, including the rules of the file provider dynamic configurations.
+With the check-conflicts flag, the routers matching the same requests with the same priority are reported, the routing between them is not predictable.
+With the check-references flag, the references to middlewares which are neither defined in the linted files nor known are reported.
+The command fails when a problem is found.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			problems, err := lint.Lint(lintCfg.input, lintCfg.lint)
+			if err != nil {
+				return err
+			}
+
+			for _, problem := range problems {
+				fmt.Println(problem)
+				printAnnotation(outputAnnotations, problemAnnotation(problem, annotations.LevelError))
+			}
+
+			if len(problems) > 0 {
+				return problemsError{msg: fmt.Sprintf("%d problems found", len(problems))}
+			}
+
+			return nil
+		},
+	}
+
+	lintCmd.Flags().StringVarP(&lintCfg.input, "input", "i", ".", "Path to a manifest file, or to a directory of manifests.")
+	lintCmd.Flags().BoolVar(&lintCfg.lint.CheckReferences, "check-references", false, "Check that the middlewares referenced by the routers (IngressRoutes, Ingress annotations, file provider) are defined in the linted files, or are known.")
+	lintCmd.Flags().StringSliceVar(&lintCfg.lint.KnownMiddlewares, "known-middlewares", nil, "Middlewares defined outside of the linted files (ex: ssl-redirect@file), a name without provider is a file provider middleware.")
+	lintCmd.Flags().BoolVar(&lintCfg.lint.CheckConflicts, "check-conflicts", false, "Report the routers matching the same requests (same host and path matchers) with the same priority.")
+	lintCmd.Flags().BoolVar(&lintCfg.lint.CheckRules, "check-rules", false, "Parse the routers rules of the IngressRoutes and of the file provider dynamic configurations with the Traefik rule parser.")
+
+	rootCmd.AddCommand(lintCmd)
+
+	preflightCfg := preflightConfig{}
+
+	preflightCmd := &cobra.Command{
+		Use:   "preflight",
+		Short: "Check that a cluster is ready for the Traefik v2 custom resources.",
+		Long: `Check that a cluster is ready for the Traefik v2 custom resources.
+Verify that the Traefik v2.4 CRDs are installed, that the Traefik service account can read the custom resources, and that the entry points exist in the static configuration of the running Traefik (read with the Traefik API).
+The command fails when the cluster is not ready.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			report, err := preflight.Run(preflightCfg.preflight)
+			if err != nil {
+				return err
+			}
+
+			fmt.Print(report)
+
+			if !report.Ready() {
+				return problemsError{msg: "the cluster is not ready"}
+			}
+
+			return nil
+		},
+	}
+
+	preflightCmd.Flags().StringVar(&preflightCfg.preflight.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (default to the KUBECONFIG environment variable or ~/.kube/config).")
+	preflightCmd.Flags().StringVar(&preflightCfg.preflight.Context, "context", "", "Name of the kubeconfig context (default to the current context).")
+	preflightCmd.Flags().StringVar(&preflightCfg.preflight.ServiceAccount, "service-account", "", "Service account of Traefik (<namespace>/<name>), used to check the RBAC.")
+	preflightCmd.Flags().StringVar(&preflightCfg.preflight.TraefikAPI, "traefik-api", "", "URL of the Traefik API (ex: http://localhost:8080), used to check the entry points.")
+	preflightCmd.Flags().StringSliceVar(&preflightCfg.preflight.EntryPoints, "entrypoints", nil, "Entry points used by the resources to apply.")
+
+	registerCompletion(preflightCmd, "context", completeContexts(&preflightCfg.preflight.Kubeconfig))
+	registerCompletion(preflightCmd, "service-account", completeServiceAccounts(&preflightCfg.preflight.Kubeconfig, &preflightCfg.preflight.Context))
+
+	rootCmd.AddCommand(preflightCmd)
+
+	planCfg := planConfig{}
+
+	planCmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Order the manifests of a migration by their dependencies.",
+		Long: `Order the manifests of a migration by their dependencies.
+Print the objects of the manifests in the order to apply them: the namespaces, the secrets, the TLS options, the services, the middlewares, the Traefik services, and then the Ingress and IngressRoute, each object after the objects of the plan it references.
+With the apply flag, the objects are created or updated in the cluster in this order, so the routes never reference missing objects.`,
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if planCfg.input == "" {
+				return errors.New("the input flag is required")
+			}
+
+			return nil
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			p, err := plan.Build(planCfg.input, planCfg.namespace)
+			if err != nil {
+				return err
+			}
+
+			fmt.Print(p)
+
+			if planCfg.report != "" {
+				err = p.Save(planCfg.report)
+				if err != nil {
+					return err
+				}
+			}
+
+			if !planCfg.apply {
+				return nil
+			}
+
+			return plan.Apply(p, planCfg.cluster, os.Stdout)
+		},
+	}
+
+	planCmd.Flags().StringVarP(&planCfg.input, "input", "i", "", "Input file or directory of manifests.")
+	planCmd.Flags().StringVarP(&planCfg.namespace, "namespace", "n", "", "Namespace of the objects to plan, with the cluster objects (default to all the namespaces).")
+	planCmd.Flags().StringVar(&planCfg.report, "report", "", "Path to a JSON file where the plan is written.")
+	planCmd.Flags().BoolVar(&planCfg.apply, "apply", false, "Create or update the objects in the cluster in the order of the plan.")
+	planCmd.Flags().StringVar(&planCfg.cluster.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (default to the KUBECONFIG environment variable or ~/.kube/config).")
+	planCmd.Flags().StringVar(&planCfg.cluster.Context, "context", "", "Name of the kubeconfig context (default to the current context).")
+
+	registerCompletion(planCmd, "context", completeContexts(&planCfg.cluster.Kubeconfig))
+
+	rootCmd.AddCommand(planCmd)
+
+	serveCfg := serveConfig{}
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the conversions over HTTP.",
+		Long: `Serve the conversions over HTTP.
+The source payload is sent as the body of a POST request, and the converted files and the report of the conversion are returned as JSON:
+  - /convert/ingress: Kubernetes manifests with Ingress (query parameters: audit, explain), the ingresses which cannot be converted are kept, and listed in the failures of the report.
+  - /convert/acme: acme.json file from Traefik v1 (query parameter: resolver).
+  - /convert/static: static configuration file (TOML) from Traefik v1, the report holds the warnings of the settings to convert manually.
+  - /convert/dynamic: file provider dynamic configuration from Traefik v2 to Traefik v3 (query parameter: format, yaml or toml), the report holds the warnings of the settings to convert manually.
+The conversion metrics are exposed, in the Prometheus format, on /metrics.
+The same conversions are exposed by the gRPC API (server/api/converter.proto) when --grpc-addr is set: ConvertIngress streams the documents of large multi-document inputs.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			log.Printf("Listening on %s", serveCfg.addr)
+			if serveCfg.grpcAddr != "" {
+				log.Printf("Listening on %s (gRPC)", serveCfg.grpcAddr)
+			}
+
+			return server.ListenAndServe(ctx, serveCfg.addr, serveCfg.grpcAddr)
+		},
+	}
+
+	serveCmd.Flags().StringVar(&serveCfg.addr, "addr", ":8080", "Address to listen on.")
+	serveCmd.Flags().StringVar(&serveCfg.grpcAddr, "grpc-addr", "", "Address to listen on for the gRPC API (disabled when empty).")
+
+	rootCmd.AddCommand(serveCmd)
+
+	v2tov3Cmd := &cobra.Command{
+		Use:     "v2tov3",
+		Aliases: []string{"v3"},
+		Short:   "Migrate from Traefik v2 to Traefik v3.",
+		Long:    "Migrate from Traefik v2 to Traefik v3.",
+	}
+
+	v2tov3CRDCfg := v2tov3CRDConfig{}
+
+	v2tov3CRDCmd := &cobra.Command{
+		Use:   "crd",
+		Short: "Migrate Traefik custom resources from the traefik.containo.us API group to the traefik.io API group.",
+		Long: `Migrate Traefik custom resources from the traefik.containo.us API group to the traefik.io API group.
+Rewrite the apiVersion of the IngressRoute, IngressRouteTCP, IngressRouteUDP, Middleware, MiddlewareTCP, TraefikService, TLSOption, TLSStore, and ServersTransport resources.
+The routes rules and the middleware options renamed in Traefik v3 are converted (ex: ipWhiteList to ipAllowList), the removed middleware and TLS options are reported.
+The other resources of the manifests, and the comments, are kept as is.`,
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			printBanner(v2tov3CRDCfg.output)
+
+			if v2tov3CRDCfg.input == "" || v2tov3CRDCfg.output == "" {
+				return errors.New("input and output flags are requires")
+			}
+
+			return nil
+		},
+		RunE: func(_ *cobra.Command, _ []string) (err error) {
+			input, cleanup, err := stdinPath(v2tov3CRDCfg.input, "stdin.yml")
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			output, done, err := stdoutPath(v2tov3CRDCfg.output, "")
+			if err != nil {
+				return err
+			}
+			defer func() { err = done(err) }()
+
+			err = v2tov3.ConvertCRD(input, output)
+			if err != nil {
+				return err
+			}
+
+			if v2tov3CRDCfg.crdsVersion != "" {
+				return v2tov3.WriteDefinitions(v2tov3CRDCfg.crdsVersion, output)
+			}
+
+			return nil
+		},
+	}
+
+	v2tov3CRDCmd.Flags().StringVarP(&v2tov3CRDCfg.input, "input", "i", "", "Input file or directory of manifests, - for the standard input.")
+	v2tov3CRDCmd.Flags().StringVarP(&v2tov3CRDCfg.output, "output", "o", "./output", "Output directory, - for the standard output.")
+	v2tov3CRDCmd.Flags().StringVar(&v2tov3CRDCfg.crdsVersion, "crds-version", "", "Traefik v3 minor version (ex: v3.1) of the traefik.io CRD definitions written to the output directory.")
+
+	v2tov3Cmd.AddCommand(v2tov3CRDCmd)
+
+	v2tov3StaticCfg := v2tov3StaticConfig{}
+
+	v2tov3StaticCmd := &cobra.Command{
+		Use:   "static",
+		Short: "Migrate static configuration file from Traefik v2 to Traefik v3.",
+		Long: `Migrate static configuration file from Traefik v2 to Traefik v3.
+Remove the options not available anymore (ex: Pilot), move the renamed ones (ex: tracing and metrics OpenTelemetry, Docker Swarm mode), and report the options needing a decision.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return v2tov3.ConvertStatic(v2tov3StaticCfg.input, v2tov3StaticCfg.outputDir, nil)
+		},
+	}
+
+	v2tov3StaticCmd.Flags().StringVarP(&v2tov3StaticCfg.input, "input", "i", "./traefik.toml", "Path to the static configuration file (TOML or YAML) from Traefik v2.")
+	v2tov3StaticCmd.Flags().StringVarP(&v2tov3StaticCfg.outputDir, "output-dir", "d", "./static", "Path to the directory of the created files")
+
+	v2tov3Cmd.AddCommand(v2tov3StaticCmd)
+
+	v2tov3FileCfg := v2tov3FileConfig{}
+
+	v2tov3FileCmd := &cobra.Command{
+		Use:   "file",
+		Short: "Migrate file provider dynamic configuration from Traefik v2 to Traefik v3.",
+		Long: `Migrate file provider dynamic configuration from Traefik v2 to Traefik v3.
+Convert the routers rules to the Traefik v3 syntax (one value per matcher, HostHeader to Host, templates to regular expressions) and the middleware options renamed in Traefik v3. The TLS options removed in Traefik v3 are reported.
+The converted configuration is checked against the Traefik v3 matchers and middlewares, the problems are reported.`,
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			printBanner(v2tov3FileCfg.output)
+
+			if v2tov3FileCfg.input == "" || v2tov3FileCfg.output == "" {
+				return errors.New("input and output flags are requires")
+			}
+
+			return nil
+		},
+		RunE: func(_ *cobra.Command, _ []string) (err error) {
+			input, cleanup, err := stdinPath(v2tov3FileCfg.input, "stdin.yml")
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			output, done, err := stdoutPath(v2tov3FileCfg.output, "")
+			if err != nil {
+				return err
+			}
+			defer func() { err = done(err) }()
+
+			return v2tov3.ConvertFile(input, output, nil)
+		},
+	}
+
+	v2tov3FileCmd.Flags().StringVarP(&v2tov3FileCfg.input, "input", "i", "", "Input file or directory of dynamic configurations (TOML or YAML), - for the standard input (YAML).")
+	v2tov3FileCmd.Flags().StringVarP(&v2tov3FileCfg.output, "output", "o", "./output", "Output directory, - for the standard output.")
+
+	v2tov3Cmd.AddCommand(v2tov3FileCmd)
+
+	v2tov3LabelsCfg := v2tov3LabelsConfig{}
+
+	v2tov3LabelsCmd := &cobra.Command{
+		Use:   "labels",
+		Short: "Migrate Docker labels from Traefik v2 to Traefik v3.",
+		Long: `Migrate Docker labels from Traefik v2 to Traefik v3.
+Rewrite the labels of the services defined in a docker-compose file: the routers rules, the renamed middlewares options (ex: ipwhitelist to ipallowlist), and the deploy labels read by the Swarm provider.
+When the input is a directory, all the compose files of the directory tree are converted and the output is a directory.`,
+		RunE: func(_ *cobra.Command, _ []string) (err error) {
+			input, cleanup, err := stdinPath(v2tov3LabelsCfg.input, "docker-compose.yml")
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			output, done, err := stdoutPath(v2tov3LabelsCfg.output, "docker-compose.yml")
+			if err != nil {
+				return err
+			}
+			defer func() { err = done(err) }()
+
+			return v2tov3.ConvertLabels(input, output)
+		},
+	}
+
+	v2tov3LabelsCmd.Flags().StringVarP(&v2tov3LabelsCfg.input, "input", "i", "./docker-compose.yml", "Path to the docker-compose file, or to a directory of compose files, using Traefik v2 labels, - for the standard input.")
+	v2tov3LabelsCmd.Flags().StringVarP(&v2tov3LabelsCfg.output, "output", "o", "./docker-compose-v3.yml", "Path to the docker-compose file using Traefik v3 labels (a directory when the input is a directory), - for the standard output.")
+
+	v2tov3Cmd.AddCommand(v2tov3LabelsCmd)
+
+	v2tov3ClusterCfg := v2tov3ClusterConfig{}
+
+	v2tov3ClusterCmd := &cobra.Command{
+		Use:   "cluster",
+		Short: "List the Traefik custom resources of a cluster to migrate to the traefik.io API group.",
+		Long: `List the Traefik custom resources of a cluster to migrate to the traefik.io API group.
+Scan all the namespaces for the resources of the traefik.containo.us API group and print the migration plan: the resources per namespace, and the CRDs to install in the traefik.io API group.
+With the output flag, the converted manifests are written to the output directory, one file per namespace.`,
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			if v2tov3ClusterCfg.crdsVersion != "" && v2tov3ClusterCfg.cluster.OutputDir == "" {
+				return errors.New("the crds-version flag requires the output flag")
+			}
+
+			return nil
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			plan, err := v2tov3.ScanCluster(v2tov3ClusterCfg.cluster)
+			if err != nil {
+				return err
+			}
+
+			fmt.Print(plan)
+
+			if v2tov3ClusterCfg.report != "" {
+				err = plan.Save(v2tov3ClusterCfg.report)
+				if err != nil {
+					return err
+				}
+			}
+
+			if v2tov3ClusterCfg.crdsVersion != "" {
+				return v2tov3.WriteDefinitions(v2tov3ClusterCfg.crdsVersion, v2tov3ClusterCfg.cluster.OutputDir)
+			}
+
+			return nil
+		},
+	}
+
+	v2tov3ClusterCmd.Flags().StringVar(&v2tov3ClusterCfg.cluster.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (default to the KUBECONFIG environment variable or ~/.kube/config).")
+	v2tov3ClusterCmd.Flags().StringVar(&v2tov3ClusterCfg.cluster.Context, "context", "", "Name of the kubeconfig context (default to the current context).")
+	v2tov3ClusterCmd.Flags().StringVarP(&v2tov3ClusterCfg.cluster.OutputDir, "output", "o", "", "Directory where the converted manifests are written, one file per namespace.")
+	v2tov3ClusterCmd.Flags().StringVar(&v2tov3ClusterCfg.report, "report", "", "Path to a JSON file where the migration plan is written.")
+	v2tov3ClusterCmd.Flags().StringVar(&v2tov3ClusterCfg.crdsVersion, "crds-version", "", "Traefik v3 minor version (ex: v3.1) of the traefik.io CRD definitions written to the output directory.")
+
+	registerCompletion(v2tov3ClusterCmd, "context", completeContexts(&v2tov3ClusterCfg.cluster.Kubeconfig))
+
+	v2tov3Cmd.AddCommand(v2tov3ClusterCmd)
+
+	rootCmd.AddCommand(v2tov3Cmd)
+
+	completionCmd := &cobra.Command{
+		Use:   "completion bash|fish",
+		Short: "Generate the shell completion script.",
+		Long: `Generate the shell completion script.
+The kubeconfig contexts and the service accounts of the cluster commands are completed from the kubeconfig and the cluster.
+
+Bash:
+  source <(traefik-migration-tool completion bash)
+
+Fish:
+  traefik-migration-tool completion fish | source`,
+		ValidArgs: []string{"bash", "fish"},
+		Args:      cobra.ExactValidArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if args[0] == "fish" {
+				return rootCmd.GenFishCompletion(os.Stdout, true)
+			}
+
+			return rootCmd.GenBashCompletion(os.Stdout)
+		},
+	}
+
+	rootCmd.AddCommand(completionCmd)
+
 	docCmd := &cobra.Command{
 		Use:    "doc",
 		Short:  "Generate documentation",
@@ -141,8 +1198,249 @@ Convert only the static configuration.`,
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+
+		if errors.As(err, &problemsError{}) {
+			os.Exit(exitProblems)
+		}
+		os.Exit(exitError)
+	}
+}
+
+// writeIngressArchive bundles the converted files and the report of an ingress migration in an archive.
+func writeIngressArchive(filename, outputDir string, report *ingress.Report) error {
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	reportHTML := &bytes.Buffer{}
+	err = report.WriteHTML(reportHTML)
+	if err != nil {
+		return err
+	}
+
+	return archive.Write(filename, outputDir, "output", map[string][]byte{
+		"report.json": reportJSON,
+		"report.html": reportHTML.Bytes(),
+	})
+}
+
+// gitopsManifestPath returns the path of the GitOps manifest of an output directory, next to the directory, so it is not deployed with the directory.
+func gitopsManifestPath(outputDir, format string) string {
+	return filepath.Clean(outputDir) + "-" + format + ".yml"
+}
+
+// stdio is the value of the input and output flags reading the standard input and writing to the standard output.
+const stdio = "-"
+
+// stdinFile is the name of the file converted from the standard input by the ingress command.
+const stdinFile = "stdin.yml"
+
+// printBanner prints the version of the tool, on the standard error when the output is the standard output.
+func printBanner(output string) {
+	w := os.Stdout
+	if output == stdio {
+		w = os.Stderr
 	}
+
+	fmt.Fprintf(w, "Traefik Migration: %s - %s - %s\n", Version, Date, ShortCommit)
+}
+
+// convertIngress converts the ingresses of the input into the output directory, the standard input is converted into the stdin.yml file.
+func convertIngress(input, outputDir string, opts ingress.Options) (*ingress.Report, error) {
+	if input != stdio {
+		return ingress.Convert(input, outputDir, opts)
+	}
+
+	file, err := os.Create(filepath.Join(outputDir, stdinFile))
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := ingress.ConvertStream(os.Stdin, file, opts)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return report, file.Close()
+}
+
+// stdinPath returns the path of the input of a command: with the standard input, the path of a temporary file, named name, holding its content.
+// The returned function removes the temporary file.
+func stdinPath(input, name string) (string, func(), error) {
+	if input != stdio {
+		return input, func() {}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "traefik-migration-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	path := filepath.Join(dir, name)
+
+	err = os.WriteFile(path, content, 0o600)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return path, cleanup, nil
+}
+
+// stdoutPath returns the path of the output of a command: with the standard output, the path of a temporary file named name, or of a temporary directory when name is empty.
+// The returned function, called with the error of the command, writes the temporary files to the standard output and removes them.
+// Until then, the messages of the command are printed on the standard error: the standard output only holds the converted files.
+func stdoutPath(output, name string) (string, func(error) error, error) {
+	if output != stdio {
+		return output, func(err error) error { return err }, nil
+	}
+
+	dir, err := os.MkdirTemp("", "traefik-migration-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+
+	path := dir
+	if name != "" {
+		path = filepath.Join(dir, name)
+	}
+
+	return path, func(err error) error {
+		os.Stdout = stdout
+		defer func() { _ = os.RemoveAll(dir) }()
+
+		if err != nil {
+			return err
+		}
+
+		return writeStream(stdout, dir)
+	}, nil
+}
+
+// progressStdout redirects the standard output to log events, until the returned function is called.
+func progressStdout(progress func(ingress.ProgressEvent)) (func(), error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			progress(ingress.ProgressEvent{Type: ingress.EventLog, Message: scanner.Text()})
+		}
+	}()
+
+	return func() {
+		os.Stdout = stdout
+		_ = w.Close()
+		<-done
+		_ = r.Close()
+	}, nil
+}
+
+// writeStream writes the files of a directory tree to w, in the order of their paths, separated as the documents of a YAML stream.
+func writeStream(w io.Writer, dir string) error {
+	first := true
+
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if !first {
+			_, err = io.WriteString(w, "---\n")
+			if err != nil {
+				return err
+			}
+		}
+		first = false
+
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			content = append(content, '\n')
+		}
+
+		_, err = w.Write(content)
+		return err
+	})
+}
+
+// completionTimeout is the maximum duration of the queries to the cluster of the shell completion.
+const completionTimeout = 5 * time.Second
+
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+func registerCompletion(cmd *cobra.Command, flagName string, f completionFunc) {
+	if err := cmd.RegisterFlagCompletionFunc(flagName, f); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// completeContexts completes the contexts of the kubeconfig.
+func completeContexts(kubeconfig *string) completionFunc {
+	return func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		contexts, err := cluster.Contexts(*kubeconfig)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		return contexts, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeServiceAccounts completes the service accounts (<namespace>/<name>) of the cluster.
+func completeServiceAccounts(kubeconfig, kubeContext *string) completionFunc {
+	return func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+
+		serviceAccounts, err := cluster.ServiceAccounts(ctx, *kubeconfig, *kubeContext)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		return serviceAccounts, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// printAnnotation prints an annotation, when an annotations format is set.
+func printAnnotation(format string, annotation annotations.Annotation) {
+	if line := annotation.Format(format); line != "" {
+		fmt.Println(line)
+	}
+}
+
+func problemAnnotation(problem lint.Problem, level string) annotations.Annotation {
+	message := problem.Message
+	if problem.Kind != "" {
+		message = fmt.Sprintf("%s %s: %s", problem.Kind, problem.Name, problem.Message)
+	}
+
+	return annotations.Annotation{Level: level, File: problem.File, Line: problem.Line, Message: message}
 }
 
 func displayVersion(name string) {
@@ -155,3 +1453,30 @@ func displayVersion(name string) {
  platform    : %s/%s
 `, Version, ShortCommit, Date, runtime.Version(), runtime.Compiler, runtime.GOOS, runtime.GOARCH)
 }
+
+// addKVStoreFlags adds the flags of a KV store, prefixed by name.
+// Without default backend, the backend and the endpoints default to the source ones.
+func addKVStoreFlags(cmd *cobra.Command, config *kv.StoreConfig, name, description, defaultBackend string) {
+	defaults := ""
+	if defaultBackend == "" {
+		defaults = " (default to the source value)"
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&config.Backend, name+"-backend", defaultBackend, fmt.Sprintf("Type of %s: consul, etcd, etcdv3, zk, redis%s.", description, defaults))
+	endpointsDefault := defaults
+	if defaultBackend != "" {
+		endpointsDefault = " (default to the local address of the backend)"
+	}
+
+	flags.StringSliceVar(&config.Endpoints, name+"-endpoints", nil, fmt.Sprintf("Endpoints of %s%s.", description, endpointsDefault))
+	flags.StringVar(&config.Prefix, name+"-prefix", "traefik", fmt.Sprintf("Prefix of the keys in %s.", description))
+	flags.StringVar(&config.Username, name+"-username", "", fmt.Sprintf("Username of %s (etcd, zk).", description))
+	flags.StringVar(&config.Password, name+"-password", "", fmt.Sprintf("Password of %s (etcd, zk, redis).", description))
+	flags.StringVar(&config.Token, name+"-token", "", fmt.Sprintf("ACL token of %s (consul).", description))
+	flags.IntVar(&config.DB, name+"-db", 0, fmt.Sprintf("Database of %s (redis).", description))
+	flags.StringVar(&config.TLS.CA, name+"-tls-ca", "", fmt.Sprintf("Path to the CA bundle used to verify %s.", description))
+	flags.StringVar(&config.TLS.Cert, name+"-tls-cert", "", fmt.Sprintf("Path to the client certificate for %s.", description))
+	flags.StringVar(&config.TLS.Key, name+"-tls-key", "", fmt.Sprintf("Path to the client key for %s.", description))
+	flags.BoolVar(&config.TLS.InsecureSkipVerify, name+"-tls-insecure-skip-verify", false, fmt.Sprintf("Skip the TLS verification of %s.", description))
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The test binary runs the command when the environment variable is set.
+const runMainEnv = "TRAEFIK_MIGRATION_TOOL_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) != "" {
+		main()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+func runMain(t *testing.T, args ...string) (stdout, stderr []byte) {
+	t.Helper()
+
+	var outBuf, errBuf bytes.Buffer
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), runMainEnv+"=1")
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+
+	err := cmd.Run()
+	require.NoError(t, err, errBuf.String())
+
+	return outBuf.Bytes(), errBuf.Bytes()
+}
+
+func TestIngress_progressJSONStdout(t *testing.T) {
+	stdout, stderr := runMain(t, "ingress", "-i", "./ingress/fixtures/input/ingress.yml", "-o", "-", "--progress", "json")
+
+	assert.Contains(t, string(stdout), "kind: IngressRoute")
+
+	var events int
+	scanner := bufio.NewScanner(bytes.NewReader(stderr))
+	for scanner.Scan() {
+		var event map[string]interface{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &event), scanner.Text())
+		events++
+	}
+	require.NoError(t, scanner.Err())
+
+	assert.NotZero(t, events)
+}
@@ -0,0 +1,56 @@
+//go:build !js
+// +build !js
+
+// Package metrics exposes the conversion metrics in the Prometheus format.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Statuses of a converted object.
+const (
+	StatusConverted = "converted"
+	StatusSkipped   = "skipped"
+	StatusFailed    = "failed"
+)
+
+var (
+	objects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "traefik_migration",
+		Name:      "objects_total",
+		Help:      "Number of objects processed by the conversions, by kind of conversion and status.",
+	}, []string{"kind", "status"})
+
+	durations = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "traefik_migration",
+		Name:      "conversion_duration_seconds",
+		Help:      "Duration of the conversions, by kind of conversion.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"kind"})
+
+	registry = prometheus.NewRegistry()
+)
+
+func init() {
+	registry.MustRegister(objects, durations)
+}
+
+// ObserveObject counts an object processed by a conversion.
+func ObserveObject(kind, status string) {
+	objects.WithLabelValues(kind, status).Inc()
+}
+
+// ObserveConversion records the duration of a conversion started at start.
+func ObserveConversion(kind string, start time.Time) {
+	durations.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+}
+
+// Handler returns the handler of the /metrics endpoint, for the long-lived modes.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,27 @@
+//go:build js
+// +build js
+
+package metrics
+
+import (
+	"net/http"
+	"time"
+)
+
+// Statuses of a converted object.
+const (
+	StatusConverted = "converted"
+	StatusSkipped   = "skipped"
+	StatusFailed    = "failed"
+)
+
+// ObserveObject does nothing: the Prometheus client doesn't support WebAssembly.
+func ObserveObject(_, _ string) {}
+
+// ObserveConversion does nothing: the Prometheus client doesn't support WebAssembly.
+func ObserveConversion(_ string, _ time.Time) {}
+
+// Handler returns a handler responding 404: the Prometheus client doesn't support WebAssembly.
+func Handler() http.Handler {
+	return http.NotFoundHandler()
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	ObserveObject("ingress", StatusConverted)
+	ObserveObject("ingress", StatusConverted)
+	ObserveObject("ingress", StatusSkipped)
+	ObserveConversion("ingress", time.Now())
+
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `traefik_migration_objects_total{kind="ingress",status="converted"} 2`)
+	assert.Contains(t, string(body), `traefik_migration_objects_total{kind="ingress",status="skipped"} 1`)
+	assert.Contains(t, string(body), `traefik_migration_conversion_duration_seconds_count{kind="ingress"} 1`)
+}
@@ -0,0 +1,87 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/traefik/traefik-migration-tool/cluster"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// ApplyOptions holds the options of the application of a plan.
+type ApplyOptions struct {
+	// Kubeconfig is the path to the kubeconfig file, the default loading rules are used when empty.
+	Kubeconfig string
+	// Context is the kubeconfig context, the current context is used when empty.
+	Context string
+}
+
+// Apply creates or updates the objects of a plan in a cluster, in the order of the steps.
+// The application stops at the first error, the objects of the previous steps are kept.
+func Apply(p *Plan, opts ApplyOptions, out io.Writer) error {
+	config, err := cluster.Config(opts.Kubeconfig, opts.Context)
+	if err != nil {
+		return err
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	groupResources, err := restmapper.GetAPIGroupResources(discoveryClient)
+	if err != nil {
+		return err
+	}
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	return apply(context.Background(), restmapper.NewDiscoveryRESTMapper(groupResources), client, p, out)
+}
+
+func apply(ctx context.Context, mapper meta.RESTMapper, client dynamic.Interface, p *Plan, out io.Writer) error {
+	for i, step := range p.Steps {
+		gvk := step.object.GroupVersionKind()
+
+		mapping, err := mapper.RESTMapping(schema.GroupKind{Group: gvk.Group, Kind: gvk.Kind}, gvk.Version)
+		if err != nil {
+			return fmt.Errorf("%s: %w", step.ID(), err)
+		}
+
+		var resource dynamic.ResourceInterface = client.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			step.object.SetNamespace(step.Namespace)
+			resource = client.Resource(mapping.Resource).Namespace(step.Namespace)
+		}
+
+		action := "updated"
+
+		existing, err := resource.Get(ctx, step.Name, metav1.GetOptions{})
+		switch {
+		case kerrors.IsNotFound(err):
+			action = "created"
+			_, err = resource.Create(ctx, step.object, metav1.CreateOptions{})
+		case err == nil:
+			step.object.SetResourceVersion(existing.GetResourceVersion())
+			_, err = resource.Update(ctx, step.object, metav1.UpdateOptions{})
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", step.ID(), err)
+		}
+
+		_, _ = fmt.Fprintf(out, "%d. %s %s\n", i+1, step.ID(), action)
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package plan
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	annotationRouterMiddlewares = "traefik.ingress.kubernetes.io/router.middlewares"
+	annotationRouterTLSOptions  = "traefik.ingress.kubernetes.io/router.tls.options"
+)
+
+// dependencies returns the identifiers of the objects referenced by an object, some of them may not exist.
+func dependencies(object *unstructured.Unstructured) []string {
+	namespace := namespaceOf(object)
+	spec, _, _ := unstructured.NestedMap(object.Object, "spec")
+
+	switch object.GetKind() {
+	case "IngressRoute", "IngressRouteTCP", "IngressRouteUDP":
+		return routeDependencies(object.GetKind(), namespace, spec)
+	case "Middleware", "MiddlewareTCP":
+		return middlewareDependencies(object.GetKind(), namespace, spec)
+	case "TraefikService":
+		return traefikServiceDependencies(namespace, spec)
+	case "TLSOption":
+		var deps []string
+		for _, name := range stringSlice(spec, "clientAuth", "secretNames") {
+			deps = append(deps, objectID("Secret", namespace, name))
+		}
+		return deps
+	case "TLSStore":
+		if name := stringField(spec, "defaultCertificate", "secretName"); name != "" {
+			return []string{objectID("Secret", namespace, name)}
+		}
+	case "Ingress":
+		return ingressDependencies(object, namespace, spec)
+	}
+
+	return nil
+}
+
+func routeDependencies(kind, namespace string, spec map[string]interface{}) []string {
+	middlewareKind := "Middleware"
+	if kind == "IngressRouteTCP" {
+		middlewareKind = "MiddlewareTCP"
+	}
+
+	var deps []string
+	for _, route := range mapSlice(spec, "routes") {
+		for _, middleware := range mapSlice(route, "middlewares") {
+			deps = append(deps, reference(middlewareKind, namespace, middleware))
+		}
+
+		for _, service := range mapSlice(route, "services") {
+			deps = append(deps, serviceReference(namespace, service)...)
+		}
+	}
+
+	if name := stringField(spec, "tls", "secretName"); name != "" {
+		deps = append(deps, objectID("Secret", namespace, name))
+	}
+
+	if options, ok := mapField(spec, "tls", "options"); ok {
+		deps = append(deps, reference("TLSOption", namespace, options))
+	}
+
+	if store, ok := mapField(spec, "tls", "store"); ok {
+		deps = append(deps, reference("TLSStore", namespace, store))
+	}
+
+	return deps
+}
+
+func middlewareDependencies(kind, namespace string, spec map[string]interface{}) []string {
+	var deps []string
+	for _, fields := range [][]string{
+		{"basicAuth", "secret"},
+		{"digestAuth", "secret"},
+		{"forwardAuth", "tls", "caSecret"},
+		{"forwardAuth", "tls", "certSecret"},
+	} {
+		if name := stringField(spec, fields...); name != "" {
+			deps = append(deps, objectID("Secret", namespace, name))
+		}
+	}
+
+	for _, middleware := range mapSlice(spec, "chain", "middlewares") {
+		deps = append(deps, reference(kind, namespace, middleware))
+	}
+
+	if service, ok := mapField(spec, "errors", "service"); ok {
+		deps = append(deps, serviceReference(namespace, service)...)
+	}
+
+	return deps
+}
+
+func traefikServiceDependencies(namespace string, spec map[string]interface{}) []string {
+	var deps []string
+	for _, service := range mapSlice(spec, "weighted", "services") {
+		deps = append(deps, serviceReference(namespace, service)...)
+	}
+
+	if mirroring, ok := mapField(spec, "mirroring"); ok {
+		deps = append(deps, serviceReference(namespace, mirroring)...)
+
+		for _, mirror := range mapSlice(mirroring, "mirrors") {
+			deps = append(deps, serviceReference(namespace, mirror)...)
+		}
+	}
+
+	return deps
+}
+
+func ingressDependencies(object *unstructured.Unstructured, namespace string, spec map[string]interface{}) []string {
+	var deps []string
+	for _, tls := range mapSlice(spec, "tls") {
+		if name := stringField(tls, "secretName"); name != "" {
+			deps = append(deps, objectID("Secret", namespace, name))
+		}
+	}
+
+	backends := []map[string]interface{}{}
+	for _, fields := range [][]string{{"defaultBackend"}, {"backend"}} {
+		if backend, ok := mapField(spec, fields...); ok {
+			backends = append(backends, backend)
+		}
+	}
+
+	for _, rule := range mapSlice(spec, "rules") {
+		for _, path := range mapSlice(rule, "http", "paths") {
+			if backend, ok := mapField(path, "backend"); ok {
+				backends = append(backends, backend)
+			}
+		}
+	}
+
+	for _, backend := range backends {
+		// networking.k8s.io/v1 and v1beta1 backends.
+		for _, name := range []string{stringField(backend, "service", "name"), stringField(backend, "serviceName")} {
+			if name != "" {
+				deps = append(deps, objectID("Service", namespace, name))
+			}
+		}
+	}
+
+	annotations := object.GetAnnotations()
+	deps = append(deps, providerReferences("Middleware", annotations[annotationRouterMiddlewares])...)
+	deps = append(deps, providerReferences("TLSOption", annotations[annotationRouterTLSOptions])...)
+
+	return deps
+}
+
+// providerReferences returns the candidate identifiers of the kubernetescrd references of an annotation (<namespace>-<name>@kubernetescrd).
+// The namespace and the name may both contain dashes, so each split is a candidate.
+func providerReferences(kind, value string) []string {
+	var deps []string
+	for _, ref := range strings.Split(value, ",") {
+		ref = strings.TrimSpace(ref)
+		if !strings.HasSuffix(ref, "@kubernetescrd") {
+			continue
+		}
+
+		ref = strings.TrimSuffix(ref, "@kubernetescrd")
+		for i := strings.Index(ref, "-"); i > 0; i = next(ref, i) {
+			deps = append(deps, objectID(kind, ref[:i], ref[i+1:]))
+		}
+	}
+
+	return deps
+}
+
+func next(s string, i int) int {
+	j := strings.Index(s[i+1:], "-")
+	if j < 0 {
+		return -1
+	}
+
+	return i + 1 + j
+}
+
+// reference returns the identifier of a {name, namespace} reference.
+func reference(kind, namespace string, ref map[string]interface{}) string {
+	if ns := stringField(ref, "namespace"); ns != "" {
+		namespace = ns
+	}
+
+	return objectID(kind, namespace, stringField(ref, "name"))
+}
+
+// serviceReference returns the identifier of a {name, namespace, kind} service reference, the Kubernetes service or the TraefikService.
+func serviceReference(namespace string, ref map[string]interface{}) []string {
+	if stringField(ref, "name") == "" {
+		return nil
+	}
+
+	kind := stringField(ref, "kind")
+	if kind == "" {
+		kind = "Service"
+	}
+
+	return []string{reference(kind, namespace, ref)}
+}
+
+func stringField(object map[string]interface{}, fields ...string) string {
+	value, _, _ := unstructured.NestedString(object, fields...)
+	return value
+}
+
+func stringSlice(object map[string]interface{}, fields ...string) []string {
+	value, _, _ := unstructured.NestedStringSlice(object, fields...)
+	return value
+}
+
+func mapField(object map[string]interface{}, fields ...string) (map[string]interface{}, bool) {
+	value, ok, err := unstructured.NestedMap(object, fields...)
+	return value, ok && err == nil
+}
+
+func mapSlice(object map[string]interface{}, fields ...string) []map[string]interface{} {
+	values, _, _ := unstructured.NestedSlice(object, fields...)
+
+	var maps []map[string]interface{}
+	for _, value := range values {
+		if m, ok := value.(map[string]interface{}); ok {
+			maps = append(maps, m)
+		}
+	}
+
+	return maps
+}
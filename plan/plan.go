@@ -0,0 +1,316 @@
+// Package plan orders the objects of manifests by their dependencies, to apply them without dangling references.
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// phases are the ranks of the kinds, the objects of the lower ranks are applied first when the dependencies allow it.
+var phases = map[string]int{
+	"Namespace":        0,
+	"Secret":           1,
+	"ConfigMap":        1,
+	"TLSOption":        2,
+	"TLSStore":         2,
+	"ServersTransport": 2,
+	"Service":          3,
+	"Middleware":       4,
+	"MiddlewareTCP":    4,
+	"TraefikService":   5,
+	"Ingress":          6,
+	"IngressRoute":     6,
+	"IngressRouteTCP":  6,
+	"IngressRouteUDP":  6,
+}
+
+// defaultPhase is the rank of the other kinds.
+const defaultPhase = 3
+
+// Plan is the ordered list of the objects to apply.
+type Plan struct {
+	Steps []Step `json:"steps"`
+}
+
+// Step is an object to apply.
+type Step struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	File      string `json:"file"`
+	// Dependencies are the objects of the plan referenced by the object, applied before it.
+	Dependencies []string `json:"dependencies,omitempty"`
+
+	object *unstructured.Unstructured
+}
+
+// ID returns the identifier of the object of a step: <Kind> <namespace>/<name>.
+func (s Step) ID() string {
+	return objectID(s.Kind, s.Namespace, s.Name)
+}
+
+func objectID(kind, namespace, name string) string {
+	if namespace == "" {
+		return kind + " " + name
+	}
+
+	return kind + " " + namespace + "/" + name
+}
+
+// Save writes the plan as JSON.
+func (p *Plan) Save(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(p)
+}
+
+func (p *Plan) String() string {
+	var b strings.Builder
+
+	for i, step := range p.Steps {
+		_, _ = fmt.Fprintf(&b, "%d. %s (%s)\n", i+1, step.ID(), step.File)
+
+		for _, dependency := range step.Dependencies {
+			_, _ = fmt.Fprintf(&b, "     after %s\n", dependency)
+		}
+	}
+
+	return b.String()
+}
+
+// Build returns the plan of the objects of the manifests of src (a file, or a directory of YAML and JSON files).
+// Only the objects of namespace, and the cluster objects, are planned when namespace is not empty.
+func Build(src, namespace string) (*Plan, error) {
+	all, err := readSteps(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []Step
+	for _, step := range all {
+		if namespace == "" || step.Namespace == "" || step.Namespace == namespace {
+			steps = append(steps, step)
+		}
+	}
+
+	byID := map[string]*Step{}
+	for i := range steps {
+		id := steps[i].ID()
+		if _, ok := byID[id]; ok {
+			return nil, fmt.Errorf("%s is defined twice", id)
+		}
+		byID[id] = &steps[i]
+	}
+
+	for i := range steps {
+		seen := map[string]bool{}
+		for _, dependency := range dependencies(steps[i].object) {
+			if _, ok := byID[dependency]; !ok || seen[dependency] || dependency == steps[i].ID() {
+				continue
+			}
+
+			seen[dependency] = true
+			steps[i].Dependencies = append(steps[i].Dependencies, dependency)
+		}
+
+		sort.Strings(steps[i].Dependencies)
+	}
+
+	ordered, err := sortSteps(steps)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Plan{Steps: ordered}, nil
+}
+
+// sortSteps sorts the steps after their dependencies, by phase, namespace, and name.
+func sortSteps(steps []Step) ([]Step, error) {
+	remaining := map[string]int{}
+	dependents := map[string][]int{}
+	for i, step := range steps {
+		remaining[step.ID()] = len(step.Dependencies)
+		for _, dependency := range step.Dependencies {
+			dependents[dependency] = append(dependents[dependency], i)
+		}
+	}
+
+	var ready []int
+	for i, step := range steps {
+		if remaining[step.ID()] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	var ordered []Step
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return less(steps[ready[i]], steps[ready[j]]) })
+
+		next := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, steps[next])
+
+		for _, dependent := range dependents[steps[next].ID()] {
+			remaining[steps[dependent].ID()]--
+			if remaining[steps[dependent].ID()] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(ordered) != len(steps) {
+		var cycle []string
+		for _, step := range steps {
+			if remaining[step.ID()] > 0 {
+				cycle = append(cycle, step.ID())
+			}
+		}
+		sort.Strings(cycle)
+
+		return nil, fmt.Errorf("circular dependencies between: %s", strings.Join(cycle, ", "))
+	}
+
+	return ordered, nil
+}
+
+func less(a, b Step) bool {
+	if phaseOf(a.Kind) != phaseOf(b.Kind) {
+		return phaseOf(a.Kind) < phaseOf(b.Kind)
+	}
+
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+
+	return a.Name < b.Name
+}
+
+func phaseOf(kind string) int {
+	if phase, ok := phases[kind]; ok {
+		return phase
+	}
+
+	return defaultPhase
+}
+
+// readSteps reads the objects of the manifests of src, the lists are expanded.
+func readSteps(src string) ([]Step, error) {
+	var steps []Step
+
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yml", ".yaml", ".json":
+		default:
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		objects, err := decodeObjects(content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		for _, object := range objects {
+			steps = append(steps, Step{
+				Kind:      object.GetKind(),
+				Namespace: namespaceOf(object),
+				Name:      object.GetName(),
+				File:      path,
+				object:    object,
+			})
+		}
+
+		return nil
+	})
+
+	return steps, err
+}
+
+func decodeObjects(content []byte) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+
+	var objects []*unstructured.Unstructured
+	for {
+		object := &unstructured.Unstructured{}
+		err := decoder.Decode(&object.Object)
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(object.Object) == 0 {
+			continue
+		}
+
+		if !object.IsList() {
+			objects = append(objects, object)
+			continue
+		}
+
+		list, err := object.ToList()
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	}
+}
+
+// clusterKinds are the kinds of the objects without namespace.
+var clusterKinds = map[string]bool{
+	"Namespace":                true,
+	"ClusterRole":              true,
+	"ClusterRoleBinding":       true,
+	"CustomResourceDefinition": true,
+	"IngressClass":             true,
+}
+
+// namespaceOf returns the namespace of an object, default for the namespaced objects without namespace.
+func namespaceOf(object *unstructured.Unstructured) string {
+	if clusterKinds[object.GetKind()] {
+		return ""
+	}
+
+	if object.GetNamespace() == "" {
+		return "default"
+	}
+
+	return object.GetNamespace()
+}
@@ -0,0 +1,279 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+const manifests = `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: web-apps
+  annotations:
+    traefik.ingress.kubernetes.io/router.middlewares: web-apps-auth@kubernetescrd
+spec:
+  tls:
+    - secretName: whoami-tls
+  rules:
+    - http:
+        paths:
+          - path: /
+            backend:
+              service:
+                name: whoami
+                port:
+                  number: 80
+---
+apiVersion: traefik.containo.us/v1alpha1
+kind: IngressRoute
+metadata:
+  name: dashboard
+  namespace: web-apps
+spec:
+  routes:
+    - match: Host(` + "`dashboard.example.com`" + `)
+      kind: Rule
+      middlewares:
+        - name: chain
+      services:
+        - name: api@internal
+          kind: TraefikService
+  tls:
+    secretName: whoami-tls
+    options:
+      name: modern
+---
+apiVersion: traefik.containo.us/v1alpha1
+kind: Middleware
+metadata:
+  name: chain
+  namespace: web-apps
+spec:
+  chain:
+    middlewares:
+      - name: auth
+---
+apiVersion: traefik.containo.us/v1alpha1
+kind: Middleware
+metadata:
+  name: auth
+  namespace: web-apps
+spec:
+  basicAuth:
+    secret: users
+`
+
+const resources = `apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: Secret
+    metadata:
+      name: users
+      namespace: web-apps
+  - apiVersion: v1
+    kind: Secret
+    metadata:
+      name: whoami-tls
+      namespace: web-apps
+  - apiVersion: v1
+    kind: Service
+    metadata:
+      name: whoami
+      namespace: web-apps
+  - apiVersion: traefik.containo.us/v1alpha1
+    kind: TLSOption
+    metadata:
+      name: modern
+      namespace: web-apps
+    spec:
+      minVersion: VersionTLS13
+  - apiVersion: v1
+    kind: Namespace
+    metadata:
+      name: web-apps
+  - apiVersion: v1
+    kind: Service
+    metadata:
+      name: other
+      namespace: other
+`
+
+func writeManifests(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ingresses.yml"), []byte(manifests), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "base"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base", "resources.yaml"), []byte(resources), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "readme.md"), []byte("# manifests"), 0o644))
+
+	return dir
+}
+
+func TestBuild(t *testing.T) {
+	dir := writeManifests(t)
+
+	p, err := Build(dir, "web-apps")
+	require.NoError(t, err)
+
+	var ids []string
+	for _, step := range p.Steps {
+		ids = append(ids, step.ID())
+	}
+
+	assert.Equal(t, []string{
+		"Namespace web-apps",
+		"Secret web-apps/users",
+		"Secret web-apps/whoami-tls",
+		"TLSOption web-apps/modern",
+		"Service web-apps/whoami",
+		"Middleware web-apps/auth",
+		"Middleware web-apps/chain",
+		"Ingress web-apps/whoami",
+		"IngressRoute web-apps/dashboard",
+	}, ids)
+
+	assert.Equal(t, []string{"Secret web-apps/users"}, p.Steps[5].Dependencies)
+	assert.Equal(t, []string{"Middleware web-apps/auth"}, p.Steps[6].Dependencies)
+	assert.Equal(t, []string{"Middleware web-apps/auth", "Secret web-apps/whoami-tls", "Service web-apps/whoami"}, p.Steps[7].Dependencies)
+	assert.Equal(t, []string{"Middleware web-apps/chain", "Secret web-apps/whoami-tls", "TLSOption web-apps/modern"}, p.Steps[8].Dependencies)
+	assert.Equal(t, filepath.Join(dir, "ingresses.yml"), p.Steps[7].File)
+
+	all, err := Build(dir, "")
+	require.NoError(t, err)
+	assert.Len(t, all.Steps, 10)
+}
+
+func TestBuild_errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		content  string
+		expected string
+	}{
+		{
+			desc: "circular dependencies",
+			content: `apiVersion: traefik.containo.us/v1alpha1
+kind: Middleware
+metadata:
+  name: a
+spec:
+  chain:
+    middlewares:
+      - name: b
+---
+apiVersion: traefik.containo.us/v1alpha1
+kind: Middleware
+metadata:
+  name: b
+spec:
+  chain:
+    middlewares:
+      - name: a
+`,
+			expected: "circular dependencies between: Middleware default/a, Middleware default/b",
+		},
+		{
+			desc: "duplicate",
+			content: `apiVersion: v1
+kind: Secret
+metadata:
+  name: users
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: users
+  namespace: default
+`,
+			expected: "Secret default/users is defined twice",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			filename := filepath.Join(t.TempDir(), "manifests.yml")
+			require.NoError(t, os.WriteFile(filename, []byte(test.content), 0o644))
+
+			_, err := Build(filename, "")
+			require.Error(t, err)
+			assert.Equal(t, test.expected, err.Error())
+		})
+	}
+}
+
+func Test_providerReferences(t *testing.T) {
+	assert.Equal(t, []string{
+		"Middleware web/apps-auth",
+		"Middleware web-apps/auth",
+		"Middleware default/strip",
+	}, providerReferences("Middleware", "web-apps-auth@kubernetescrd, default-strip@kubernetescrd,compress@file"))
+}
+
+func Test_apply(t *testing.T) {
+	dir := writeManifests(t)
+
+	p, err := Build(dir, "web-apps")
+	require.NoError(t, err)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for kind, scope := range map[schema.GroupVersionKind]meta.RESTScope{
+		{Version: "v1", Kind: "Namespace"}:                                        meta.RESTScopeRoot,
+		{Version: "v1", Kind: "Secret"}:                                           meta.RESTScopeNamespace,
+		{Version: "v1", Kind: "Service"}:                                          meta.RESTScopeNamespace,
+		{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}:              meta.RESTScopeNamespace,
+		{Group: "traefik.containo.us", Version: "v1alpha1", Kind: "IngressRoute"}: meta.RESTScopeNamespace,
+		{Group: "traefik.containo.us", Version: "v1alpha1", Kind: "Middleware"}:   meta.RESTScopeNamespace,
+		{Group: "traefik.containo.us", Version: "v1alpha1", Kind: "TLSOption"}:    meta.RESTScopeNamespace,
+	} {
+		mapper.Add(kind, scope)
+	}
+
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "traefik.containo.us/v1alpha1",
+		"kind":       "TLSOption",
+		"metadata": map[string]interface{}{
+			"name":            "modern",
+			"namespace":       "web-apps",
+			"resourceVersion": "42",
+		},
+		"spec": map[string]interface{}{"minVersion": "VersionTLS12"},
+	}}
+
+	client := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), existing)
+
+	var out bytes.Buffer
+	err = apply(context.Background(), mapper, client, p, &out)
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "1. Namespace web-apps created\n")
+	assert.Contains(t, out.String(), "4. TLSOption web-apps/modern updated\n")
+	assert.Contains(t, out.String(), "8. Ingress web-apps/whoami created\n")
+
+	tlsOptions := schema.GroupVersionResource{Group: "traefik.containo.us", Version: "v1alpha1", Resource: "tlsoptions"}
+	option, err := client.Resource(tlsOptions).Namespace("web-apps").Get(context.Background(), "modern", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	minVersion, _, _ := unstructured.NestedString(option.Object, "spec", "minVersion")
+	assert.Equal(t, "VersionTLS13", minVersion)
+
+	ingresses := schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	_, err = client.Resource(ingresses).Namespace("web-apps").Get(context.Background(), "whoami", metav1.GetOptions{})
+	require.NoError(t, err)
+}
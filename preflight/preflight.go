@@ -0,0 +1,287 @@
+// Package preflight checks that a cluster is ready for the Traefik v2 custom resources, before they are applied.
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/traefik/traefik-migration-tool/cluster"
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	authorization "k8s.io/api/authorization/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Check statuses.
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+const crdVersion = "v1alpha1"
+
+// resources are the Traefik v2.4 custom resources, read by the Kubernetes CRD provider.
+var resources = []string{
+	"ingressroutes",
+	"ingressroutetcps",
+	"ingressrouteudps",
+	"middlewares",
+	"serverstransports",
+	"tlsoptions",
+	"tlsstores",
+	"traefikservices",
+}
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// Options holds the preflight options.
+type Options struct {
+	Kubeconfig string
+	Context    string
+	// ServiceAccount is the service account of Traefik (<namespace>/<name>), the RBAC check is skipped when empty.
+	ServiceAccount string
+	// TraefikAPI is the URL of the Traefik API, the entry points check is skipped when empty.
+	TraefikAPI string
+	// EntryPoints are the entry points used by the resources to apply.
+	EntryPoints []string
+}
+
+// Report holds the results of the preflight checks.
+type Report struct {
+	Checks []Check `json:"checks"`
+}
+
+// Check is the result of a preflight check.
+type Check struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Ready returns true if no check failed.
+func (r *Report) Ready() bool {
+	for _, check := range r.Checks {
+		if check.Status == StatusFailed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+
+	for _, check := range r.Checks {
+		if check.Message == "" {
+			_, _ = fmt.Fprintf(&b, "[%s] %s\n", check.Status, check.Name)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(&b, "[%s] %s: %s\n", check.Status, check.Name, check.Message)
+	}
+
+	return b.String()
+}
+
+// Run runs the preflight checks against a cluster.
+func Run(opts Options) (*Report, error) {
+	config, err := cluster.Config(opts.Kubeconfig, opts.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return run(context.Background(), dynamicClient, kubeClient, &http.Client{Timeout: 10 * time.Second}, opts), nil
+}
+
+func run(ctx context.Context, dynamicClient dynamic.Interface, kubeClient kubernetes.Interface, httpClient *http.Client, opts Options) *Report {
+	return &Report{Checks: []Check{
+		checkCRDs(ctx, dynamicClient),
+		checkRBAC(ctx, kubeClient, opts.ServiceAccount),
+		checkEntryPoints(httpClient, opts.TraefikAPI, opts.EntryPoints),
+	}}
+}
+
+// checkCRDs checks that the Traefik v2.4 CRDs are installed and serve the v1alpha1 version.
+func checkCRDs(ctx context.Context, client dynamic.Interface) Check {
+	check := Check{Name: fmt.Sprintf("Traefik CRDs (%s/%s)", v1alpha1.GroupName, crdVersion)}
+
+	var problems []string
+	for _, resource := range resources {
+		name := resource + "." + v1alpha1.GroupName
+
+		crd, err := client.Resource(crdGVR).Get(ctx, name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			problems = append(problems, fmt.Sprintf("%s is not installed", name))
+			continue
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+
+		if !servesVersion(crd, crdVersion) {
+			problems = append(problems, fmt.Sprintf("%s doesn't serve the %s version", name, crdVersion))
+		}
+	}
+
+	if len(problems) > 0 {
+		check.Status = StatusFailed
+		check.Message = strings.Join(problems, ", ") + ". Install the CRDs of Traefik v2.4: https://doc.traefik.io/traefik/v2.4/reference/dynamic-configuration/kubernetes-crd/"
+		return check
+	}
+
+	check.Status = StatusOK
+
+	return check
+}
+
+func servesVersion(crd *unstructured.Unstructured, version string) bool {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		v, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if v["name"] == version && v["served"] == true {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkRBAC checks that the Traefik service account can read the Traefik custom resources, as the Kubernetes CRD provider does.
+func checkRBAC(ctx context.Context, client kubernetes.Interface, serviceAccount string) Check {
+	check := Check{Name: "RBAC of the Traefik service account"}
+
+	if serviceAccount == "" {
+		check.Status = StatusSkipped
+		check.Message = "no service account"
+		return check
+	}
+
+	parts := strings.SplitN(serviceAccount, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		check.Status = StatusFailed
+		check.Message = fmt.Sprintf("invalid service account %q: <namespace>/<name> is expected", serviceAccount)
+		return check
+	}
+
+	user := fmt.Sprintf("system:serviceaccount:%s:%s", parts[0], parts[1])
+
+	var denied []string
+	for _, resource := range resources {
+		for _, verb := range []string{"get", "list", "watch"} {
+			review := &authorization.SubjectAccessReview{
+				Spec: authorization.SubjectAccessReviewSpec{
+					User: user,
+					ResourceAttributes: &authorization.ResourceAttributes{
+						Group:    v1alpha1.GroupName,
+						Resource: resource,
+						Verb:     verb,
+					},
+				},
+			}
+
+			result, err := client.AuthorizationV1().SubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+			if err != nil {
+				check.Status = StatusFailed
+				check.Message = err.Error()
+				return check
+			}
+
+			if !result.Status.Allowed {
+				denied = append(denied, verb+" "+resource)
+			}
+		}
+	}
+
+	if len(denied) > 0 {
+		check.Status = StatusFailed
+		check.Message = fmt.Sprintf("%s cannot %s in all the namespaces", user, strings.Join(denied, ", "))
+		return check
+	}
+
+	check.Status = StatusOK
+
+	return check
+}
+
+// checkEntryPoints checks that the entry points exist in the static configuration of the running Traefik, read with the Traefik API.
+func checkEntryPoints(client *http.Client, api string, entryPoints []string) Check {
+	check := Check{Name: "Traefik entry points"}
+
+	if api == "" || len(entryPoints) == 0 {
+		check.Status = StatusSkipped
+		check.Message = "no Traefik API or no entry point"
+		return check
+	}
+
+	resp, err := client.Get(strings.TrimSuffix(api, "/") + "/api/entrypoints")
+	if err != nil {
+		check.Status = StatusFailed
+		check.Message = err.Error()
+		return check
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		check.Status = StatusFailed
+		check.Message = fmt.Sprintf("unexpected status %s from the Traefik API", resp.Status)
+		return check
+	}
+
+	var running []struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&running); err != nil {
+		check.Status = StatusFailed
+		check.Message = err.Error()
+		return check
+	}
+
+	existing := map[string]struct{}{}
+	for _, entryPoint := range running {
+		existing[entryPoint.Name] = struct{}{}
+	}
+
+	var missing []string
+	for _, entryPoint := range entryPoints {
+		if _, ok := existing[entryPoint]; !ok {
+			missing = append(missing, entryPoint)
+		}
+	}
+	sort.Strings(missing)
+
+	if len(missing) > 0 {
+		check.Status = StatusFailed
+		check.Message = fmt.Sprintf("the entry points %s are not defined in the static configuration", strings.Join(missing, ", "))
+		return check
+	}
+
+	check.Status = StatusOK
+
+	return check
+}
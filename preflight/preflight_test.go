@@ -0,0 +1,148 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authorization "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newCRD(resource string, served bool) runtime.Object {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": resource + ".traefik.containo.us"},
+		"spec": map[string]interface{}{
+			"group": "traefik.containo.us",
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1alpha1", "served": served, "storage": true},
+			},
+		},
+	}}
+}
+
+func newKubeClient(denied map[string]bool) *fakekube.Clientset {
+	client := fakekube.NewSimpleClientset()
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorization.SubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+
+		review.Status.Allowed = review.Spec.User == "system:serviceaccount:kube-system:traefik" && !denied[attributes.Verb+" "+attributes.Resource]
+
+		return true, review, nil
+	})
+
+	return client
+}
+
+func Test_run(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/entrypoints" {
+			http.NotFound(rw, req)
+			return
+		}
+
+		_, _ = fmt.Fprint(rw, `[{"name":"web","address":":80"},{"name":"websecure","address":":443"}]`)
+	}))
+	t.Cleanup(server.Close)
+
+	var allCRDs []runtime.Object
+	for _, resource := range resources {
+		allCRDs = append(allCRDs, newCRD(resource, true))
+	}
+
+	testCases := []struct {
+		desc     string
+		crds     []runtime.Object
+		denied   map[string]bool
+		opts     Options
+		expected []Check
+		ready    bool
+	}{
+		{
+			desc: "ready",
+			crds: allCRDs,
+			opts: Options{ServiceAccount: "kube-system/traefik", TraefikAPI: server.URL, EntryPoints: []string{"web", "websecure"}},
+			expected: []Check{
+				{Name: "Traefik CRDs (traefik.containo.us/v1alpha1)", Status: StatusOK},
+				{Name: "RBAC of the Traefik service account", Status: StatusOK},
+				{Name: "Traefik entry points", Status: StatusOK},
+			},
+			ready: true,
+		},
+		{
+			desc: "skipped checks",
+			crds: allCRDs,
+			expected: []Check{
+				{Name: "Traefik CRDs (traefik.containo.us/v1alpha1)", Status: StatusOK},
+				{Name: "RBAC of the Traefik service account", Status: StatusSkipped, Message: "no service account"},
+				{Name: "Traefik entry points", Status: StatusSkipped, Message: "no Traefik API or no entry point"},
+			},
+			ready: true,
+		},
+		{
+			desc: "not ready",
+			crds: []runtime.Object{
+				newCRD("ingressroutes", true),
+				newCRD("ingressroutetcps", true),
+				newCRD("ingressrouteudps", true),
+				newCRD("middlewares", false),
+				newCRD("tlsoptions", true),
+				newCRD("tlsstores", true),
+				newCRD("traefikservices", true),
+			},
+			denied: map[string]bool{"watch middlewares": true, "list tlsstores": true},
+			opts:   Options{ServiceAccount: "kube-system/traefik", TraefikAPI: server.URL, EntryPoints: []string{"web", "metrics", "internal"}},
+			expected: []Check{
+				{
+					Name:    "Traefik CRDs (traefik.containo.us/v1alpha1)",
+					Status:  StatusFailed,
+					Message: "middlewares.traefik.containo.us doesn't serve the v1alpha1 version, serverstransports.traefik.containo.us is not installed. Install the CRDs of Traefik v2.4: https://doc.traefik.io/traefik/v2.4/reference/dynamic-configuration/kubernetes-crd/",
+				},
+				{
+					Name:    "RBAC of the Traefik service account",
+					Status:  StatusFailed,
+					Message: "system:serviceaccount:kube-system:traefik cannot watch middlewares, list tlsstores in all the namespaces",
+				},
+				{
+					Name:    "Traefik entry points",
+					Status:  StatusFailed,
+					Message: "the entry points internal, metrics are not defined in the static configuration",
+				},
+			},
+		},
+		{
+			desc: "invalid service account",
+			crds: allCRDs,
+			opts: Options{ServiceAccount: "traefik"},
+			expected: []Check{
+				{Name: "Traefik CRDs (traefik.containo.us/v1alpha1)", Status: StatusOK},
+				{Name: "RBAC of the Traefik service account", Status: StatusFailed, Message: `invalid service account "traefik": <namespace>/<name> is expected`},
+				{Name: "Traefik entry points", Status: StatusSkipped, Message: "no Traefik API or no entry point"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), test.crds...)
+
+			report := run(context.Background(), dynamicClient, newKubeClient(test.denied), server.Client(), test.opts)
+
+			assert.Equal(t, test.expected, report.Checks)
+			assert.Equal(t, test.ready, report.Ready())
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Package review asks an operator to review the conversion of each Ingress.
+package review
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/traefik/traefik-migration-tool/ingress"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ErrAborted is returned when the operator quits the review.
+var ErrAborted = errors.New("review aborted")
+
+// Prompt returns a review function showing the conversion of each Ingress on out,
+// and reading the decision of the operator from in: accept, skip, rename the IngressRoute, or quit.
+func Prompt(in io.Reader, out io.Writer) func(ingress.IngressReport) (ingress.ReviewDecision, error) {
+	reader := bufio.NewReader(in)
+
+	return func(report ingress.IngressReport) (ingress.ReviewDecision, error) {
+		show(out, report)
+
+		for {
+			fmt.Fprint(out, "[a]ccept, [s]kip (keep the Ingress), [r]ename the IngressRoute, [q]uit? ")
+
+			answer, err := readLine(reader)
+			if err != nil {
+				return ingress.ReviewDecision{}, err
+			}
+
+			switch answer {
+			case "a", "accept":
+				return ingress.ReviewDecision{}, nil
+
+			case "s", "skip":
+				return ingress.ReviewDecision{Skip: true}, nil
+
+			case "r", "rename":
+				name, err := promptName(reader, out)
+				if err != nil {
+					return ingress.ReviewDecision{}, err
+				}
+
+				if name != "" {
+					return ingress.ReviewDecision{Name: name}, nil
+				}
+
+			case "q", "quit":
+				return ingress.ReviewDecision{}, ErrAborted
+
+			default:
+				fmt.Fprintf(out, "Unknown answer %q.\n", answer)
+			}
+		}
+	}
+}
+
+// promptName reads the new name of the IngressRoute, an empty name goes back to the decision.
+func promptName(reader *bufio.Reader, out io.Writer) (string, error) {
+	for {
+		fmt.Fprint(out, "Name of the IngressRoute (empty to cancel): ")
+
+		name, err := readLine(reader)
+		if err != nil {
+			return "", err
+		}
+
+		if name == "" {
+			return "", nil
+		}
+
+		if problems := validation.IsDNS1123Subdomain(name); len(problems) > 0 {
+			fmt.Fprintf(out, "Invalid name %q: %s.\n", name, strings.Join(problems, ", "))
+			continue
+		}
+
+		return name, nil
+	}
+}
+
+func show(out io.Writer, report ingress.IngressReport) {
+	fmt.Fprintf(out, "\nIngress %s/%s (%s)\n\n", report.Namespace, report.Name, report.File)
+
+	for _, line := range report.Diff {
+		fmt.Fprintf(out, "%s %s\n", line.Op, line.Text)
+	}
+
+	if len(report.Middlewares) > 0 {
+		fmt.Fprintf(out, "\nGenerated middlewares: %s\n", strings.Join(report.Middlewares, ", "))
+	}
+
+	for _, action := range report.ManualActions {
+		fmt.Fprintf(out, "\nManual action: the annotation %s must be converted manually. %s %s\n", action.Annotation, action.Message, action.DocURL)
+	}
+
+	for _, note := range report.Notes {
+		fmt.Fprintf(out, "\nNote: %s\n", note)
+	}
+
+	fmt.Fprintln(out)
+}
+
+// readLine reads a trimmed line, the end of the input aborts the review.
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if errors.Is(err, io.EOF) && line == "" {
+		return "", ErrAborted
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
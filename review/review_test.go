@@ -0,0 +1,79 @@
+package review
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik-migration-tool/ingress"
+)
+
+func TestPrompt(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected ingress.ReviewDecision
+		err      error
+	}{
+		{
+			desc:  "accept",
+			input: "a\n",
+		},
+		{
+			desc:     "skip",
+			input:    "skip\n",
+			expected: ingress.ReviewDecision{Skip: true},
+		},
+		{
+			desc:     "rename",
+			input:    "x\nr\nInvalid_Name\nwhoami\n",
+			expected: ingress.ReviewDecision{Name: "whoami"},
+		},
+		{
+			desc:  "cancel the rename",
+			input: "r\n\na\n",
+		},
+		{
+			desc:  "quit",
+			input: "q\n",
+			err:   ErrAborted,
+		},
+		{
+			desc:  "end of the input",
+			input: "",
+			err:   ErrAborted,
+		},
+	}
+
+	report := ingress.IngressReport{
+		File:        "ingress.yml",
+		Namespace:   "default",
+		Name:        "whoami",
+		Middlewares: []string{"default-whoami-stripprefix"},
+		Diff:        []ingress.DiffLine{{Op: "-", Text: "kind: Ingress"}, {Op: "+", Text: "kind: IngressRoute"}},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+
+			decision, err := Prompt(strings.NewReader(test.input), out)(report)
+			if test.err != nil {
+				assert.Equal(t, test.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, decision)
+
+			assert.Contains(t, out.String(), "Ingress default/whoami (ingress.yml)")
+			assert.Contains(t, out.String(), "+ kind: IngressRoute")
+			assert.Contains(t, out.String(), "Generated middlewares: default-whoami-stripprefix")
+		})
+	}
+}
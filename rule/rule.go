@@ -0,0 +1,205 @@
+// Package rule converts Traefik v1 frontend rules to Traefik v2 router rules.
+package rule
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+const (
+	ruleTypeHost                 = "Host"
+	ruleTypeHostRegexp           = "HostRegexp"
+	ruleTypeMethod               = "Method"
+	ruleTypeHeaders              = "Headers"
+	ruleTypeHeadersRegexp        = "HeadersRegexp"
+	ruleTypeQuery                = "Query"
+	ruleTypePath                 = "Path"
+	ruleTypePathPrefix           = "PathPrefix"
+	ruleTypePathStrip            = "PathStrip"
+	ruleTypePathPrefixStrip      = "PathPrefixStrip"
+	ruleTypePathStripRegex       = "PathStripRegex"
+	ruleTypePathPrefixStripRegex = "PathPrefixStripRegex"
+	ruleTypeAddPrefix            = "AddPrefix"
+	ruleTypeReplacePath          = "ReplacePath"
+	ruleTypeReplacePathRegex     = "ReplacePathRegex"
+)
+
+// Rule holds a v1 frontend rule converted to a v2 router rule and its modifiers.
+// The modifiers are the v1 rule types converted to middlewares, indexed by middleware type (ex: "stripprefix").
+type Rule struct {
+	Match     string
+	Modifiers map[string]*dynamic.Middleware
+}
+
+// Convert converts a v1 frontend rule (ex: "Host:example.com;PathPrefixStrip:/api") to a v2 rule.
+func Convert(v1Rule string) (*Rule, error) {
+	result := &Rule{Modifiers: map[string]*dynamic.Middleware{}}
+
+	var matchers []string
+	for _, part := range splitOutsideBraces(v1Rule, ';') {
+		split := strings.SplitN(part, ":", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("invalid rule %q", part)
+		}
+
+		ruleType := strings.TrimSpace(split[0])
+		values := splitOutsideBraces(split[1], ',')
+		if len(values) == 0 {
+			return nil, fmt.Errorf("missing value for rule %q", part)
+		}
+
+		switch ruleType {
+		case ruleTypeHost, ruleTypeHostRegexp, ruleTypeMethod, ruleTypePath, ruleTypePathPrefix, ruleTypeQuery:
+			matchers = append(matchers, toMatcher(ruleType, values))
+		case ruleTypeHeaders, ruleTypeHeadersRegexp:
+			if len(values) != 2 {
+				return nil, fmt.Errorf("%s rule requires a key and a value: %q", ruleType, part)
+			}
+			matchers = append(matchers, toMatcher(ruleType, values))
+		case ruleTypePathStrip:
+			matchers = append(matchers, toMatcher(ruleTypePath, values))
+			result.Modifiers["stripprefix"] = &dynamic.Middleware{StripPrefix: &dynamic.StripPrefix{Prefixes: values}}
+		case ruleTypePathPrefixStrip:
+			matchers = append(matchers, toMatcher(ruleTypePathPrefix, values))
+			result.Modifiers["stripprefix"] = &dynamic.Middleware{StripPrefix: &dynamic.StripPrefix{Prefixes: values}}
+		case ruleTypePathStripRegex, ruleTypePathPrefixStripRegex:
+			regexes, err := templatesToRegexps(values)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s rule %q: %w", ruleType, part, err)
+			}
+
+			matcherType := ruleTypePath
+			if ruleType == ruleTypePathPrefixStripRegex {
+				matcherType = ruleTypePathPrefix
+			}
+			matchers = append(matchers, toMatcher(matcherType, values))
+			result.Modifiers["stripprefixregex"] = &dynamic.Middleware{StripPrefixRegex: &dynamic.StripPrefixRegex{Regex: regexes}}
+		case ruleTypeAddPrefix:
+			result.Modifiers["addprefix"] = &dynamic.Middleware{AddPrefix: &dynamic.AddPrefix{Prefix: values[0]}}
+		case ruleTypeReplacePath:
+			result.Modifiers["replacepath"] = &dynamic.Middleware{ReplacePath: &dynamic.ReplacePath{Path: values[0]}}
+		case ruleTypeReplacePathRegex:
+			regex := strings.Fields(strings.TrimSpace(split[1]))
+			if len(regex) != 2 {
+				return nil, fmt.Errorf("invalid %s syntax: %q", ruleType, part)
+			}
+			result.Modifiers["replacepathregex"] = &dynamic.Middleware{ReplacePathRegex: &dynamic.ReplacePathRegex{Regex: regex[0], Replacement: regex[1]}}
+		default:
+			return nil, fmt.Errorf("unsupported rule type %q", ruleType)
+		}
+	}
+
+	result.Match = strings.Join(matchers, " && ")
+
+	return result, nil
+}
+
+// splitOutsideBraces splits a value on the separators outside the braces of the path templates (ex: "/api/{id:[0-9]{1,3}}"),
+// the parts are trimmed and the empty parts are dropped.
+func splitOutsideBraces(value string, sep rune) []string {
+	var parts []string
+
+	var level, start int
+	for i, c := range value {
+		switch c {
+		case '{':
+			level++
+		case '}':
+			if level > 0 {
+				level--
+			}
+		case sep:
+			if level == 0 {
+				parts = appendTrimmed(parts, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return appendTrimmed(parts, value[start:])
+}
+
+func appendTrimmed(parts []string, part string) []string {
+	part = strings.TrimSpace(part)
+	if part == "" {
+		return parts
+	}
+
+	return append(parts, part)
+}
+
+// templatesToRegexps converts the v1 path templates (ex: "/api/{id:[0-9]+}") to regular expressions.
+func templatesToRegexps(templates []string) ([]string, error) {
+	var regexes []string
+	for _, template := range templates {
+		regex, err := templateToRegexp(template)
+		if err != nil {
+			return nil, err
+		}
+		regexes = append(regexes, regex)
+	}
+
+	return regexes, nil
+}
+
+// templateToRegexp converts a path template to a regular expression:
+// the literal parts are quoted, and a variable without pattern matches a path segment.
+func templateToRegexp(template string) (string, error) {
+	var expr strings.Builder
+
+	for i := 0; i < len(template); {
+		start := strings.IndexByte(template[i:], '{')
+		if start < 0 {
+			expr.WriteString(regexp.QuoteMeta(template[i:]))
+			break
+		}
+
+		expr.WriteString(regexp.QuoteMeta(template[i : i+start]))
+		i += start
+
+		// The pattern can hold braces (ex: [0-9]{2}).
+		level, end := 0, -1
+		for j := i; j < len(template); j++ {
+			if template[j] == '{' {
+				level++
+			} else if template[j] == '}' {
+				level--
+				if level == 0 {
+					end = j
+					break
+				}
+			}
+		}
+
+		if end < 0 {
+			return "", fmt.Errorf("unbalanced braces in %q", template)
+		}
+
+		variable := template[i+1 : end]
+		pattern := "[^/]+"
+		if sep := strings.IndexByte(variable, ':'); sep >= 0 {
+			pattern = variable[sep+1:]
+		}
+
+		if _, err := regexp.Compile(pattern); err != nil {
+			return "", err
+		}
+
+		expr.WriteString(pattern)
+		i = end + 1
+	}
+
+	return expr.String(), nil
+}
+
+func toMatcher(ruleType string, values []string) string {
+	var quoted []string
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("`%s`", value))
+	}
+
+	return fmt.Sprintf("%s(%s)", ruleType, strings.Join(quoted, ", "))
+}
@@ -0,0 +1,107 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		rule      string
+		expected  string
+		modifiers []string
+		regexes   []string
+		expectErr bool
+	}{
+		{
+			desc:     "host",
+			rule:     "Host:example.com",
+			expected: "Host(`example.com`)",
+		},
+		{
+			desc:     "multiple hosts and path",
+			rule:     "Host:a.example.com,b.example.com;Path:/foo",
+			expected: "Host(`a.example.com`, `b.example.com`) && Path(`/foo`)",
+		},
+		{
+			desc:      "path prefix strip",
+			rule:      "Host:example.com;PathPrefixStrip:/api",
+			expected:  "Host(`example.com`) && PathPrefix(`/api`)",
+			modifiers: []string{"stripprefix"},
+		},
+		{
+			desc:      "modifiers",
+			rule:      "Host:example.com;AddPrefix:/foo;ReplacePathRegex: ^/a/(.*) /b/$1",
+			expected:  "Host(`example.com`)",
+			modifiers: []string{"addprefix", "replacepathregex"},
+		},
+		{
+			desc:     "headers",
+			rule:     "Headers:Content-Type,application/json",
+			expected: "Headers(`Content-Type`, `application/json`)",
+		},
+		{
+			desc:     "path prefix with a quantifier",
+			rule:     "Host:example.com;PathPrefix:/api/{id:[0-9]{1,3}},/v2/{id:[0-9]{1,3}}",
+			expected: "Host(`example.com`) && PathPrefix(`/api/{id:[0-9]{1,3}}`, `/v2/{id:[0-9]{1,3}}`)",
+		},
+		{
+			desc:      "path prefix strip regex with a quantifier",
+			rule:      "PathPrefixStripRegex:/api/{id:[0-9]{1,3}};Host:example.com",
+			expected:  "PathPrefix(`/api/{id:[0-9]{1,3}}`) && Host(`example.com`)",
+			modifiers: []string{"stripprefixregex"},
+			regexes:   []string{"/api/[0-9]{1,3}"},
+		},
+		{
+			desc:      "path strip regex with variables",
+			rule:      "PathStripRegex:/api/{version}/{id:[a-z]{2,}[0-9]*},/v1.0/{name}",
+			expected:  "Path(`/api/{version}/{id:[a-z]{2,}[0-9]*}`, `/v1.0/{name}`)",
+			modifiers: []string{"stripprefixregex"},
+			regexes:   []string{"/api/[^/]+/[a-z]{2,}[0-9]*", `/v1\.0/[^/]+`},
+		},
+		{
+			desc:      "invalid path strip regex",
+			rule:      "PathStripRegex:/api/{id:[0-9}",
+			expectErr: true,
+		},
+		{
+			desc:      "unknown rule type",
+			rule:      "Foo:bar",
+			expectErr: true,
+		},
+		{
+			desc:      "missing value",
+			rule:      "Host:",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := Convert(test.rule)
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, result.Match)
+
+			var modifiers []string
+			for key := range result.Modifiers {
+				modifiers = append(modifiers, key)
+			}
+			assert.ElementsMatch(t, test.modifiers, modifiers)
+
+			if test.regexes != nil {
+				assert.Equal(t, test.regexes, result.Modifiers["stripprefixregex"].StripPrefixRegex.Regex)
+			}
+		})
+	}
+}
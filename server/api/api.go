@@ -0,0 +1,4 @@
+// Package api holds the gRPC API of the conversions.
+package api
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. converter.proto
@@ -0,0 +1,601 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        (unknown)
+// source: converter.proto
+
+package api
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+// IngressRequest is a document of Kubernetes manifests to convert.
+type IngressRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Document is a YAML document of Kubernetes manifests.
+	Document string `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`
+	// Audit records the transformations on the converted objects, read from the first message of the stream.
+	Audit bool `protobuf:"varint,2,opt,name=audit,proto3" json:"audit,omitempty"`
+	// Explain comments the origin of the converted fields, read from the first message of the stream.
+	Explain bool `protobuf:"varint,3,opt,name=explain,proto3" json:"explain,omitempty"`
+}
+
+func (x *IngressRequest) Reset() {
+	*x = IngressRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_converter_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IngressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngressRequest) ProtoMessage() {}
+
+func (x *IngressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_converter_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngressRequest.ProtoReflect.Descriptor instead.
+func (*IngressRequest) Descriptor() ([]byte, []int) {
+	return file_converter_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IngressRequest) GetDocument() string {
+	if x != nil {
+		return x.Document
+	}
+	return ""
+}
+
+func (x *IngressRequest) GetAudit() bool {
+	if x != nil {
+		return x.Audit
+	}
+	return false
+}
+
+func (x *IngressRequest) GetExplain() bool {
+	if x != nil {
+		return x.Explain
+	}
+	return false
+}
+
+// IngressResponse holds the conversion of a document of Kubernetes manifests.
+type IngressResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Document holds the converted objects of the request document.
+	Document string `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`
+	// Report is the report of the conversion of the document, as JSON.
+	Report string `protobuf:"bytes,2,opt,name=report,proto3" json:"report,omitempty"`
+}
+
+func (x *IngressResponse) Reset() {
+	*x = IngressResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_converter_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IngressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngressResponse) ProtoMessage() {}
+
+func (x *IngressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_converter_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngressResponse.ProtoReflect.Descriptor instead.
+func (*IngressResponse) Descriptor() ([]byte, []int) {
+	return file_converter_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IngressResponse) GetDocument() string {
+	if x != nil {
+		return x.Document
+	}
+	return ""
+}
+
+func (x *IngressResponse) GetReport() string {
+	if x != nil {
+		return x.Report
+	}
+	return ""
+}
+
+// ConvertRequest is a payload to convert.
+type ConvertRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Kind is the kind of conversion: ingress, acme, static or dynamic.
+	Kind string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Payload is the content of the source file.
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	// Options are the options of the conversion, as the query parameters of the HTTP endpoints.
+	Options map[string]string `protobuf:"bytes,3,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *ConvertRequest) Reset() {
+	*x = ConvertRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_converter_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConvertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertRequest) ProtoMessage() {}
+
+func (x *ConvertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_converter_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertRequest.ProtoReflect.Descriptor instead.
+func (*ConvertRequest) Descriptor() ([]byte, []int) {
+	return file_converter_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ConvertRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ConvertRequest) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+// ConvertResponse holds the converted files.
+type ConvertResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Files are the converted files, by path relative to the output directory.
+	Files map[string]string `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Report is the report of the conversion, as JSON.
+	Report string `protobuf:"bytes,2,opt,name=report,proto3" json:"report,omitempty"`
+}
+
+func (x *ConvertResponse) Reset() {
+	*x = ConvertResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_converter_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConvertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertResponse) ProtoMessage() {}
+
+func (x *ConvertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_converter_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertResponse.ProtoReflect.Descriptor instead.
+func (*ConvertResponse) Descriptor() ([]byte, []int) {
+	return file_converter_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ConvertResponse) GetFiles() map[string]string {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ConvertResponse) GetReport() string {
+	if x != nil {
+		return x.Report
+	}
+	return ""
+}
+
+var File_converter_proto protoreflect.FileDescriptor
+
+var file_converter_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x14, 0x74, 0x72, 0x61, 0x65, 0x66, 0x69, 0x6b, 0x2e, 0x6d, 0x69, 0x67, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x22, 0x5c, 0x0a, 0x0e, 0x49, 0x6e, 0x67, 0x72, 0x65,
+	0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x6f, 0x63,
+	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x6f, 0x63,
+	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x75, 0x64, 0x69, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x61, 0x75, 0x64, 0x69, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x65,
+	0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x78,
+	0x70, 0x6c, 0x61, 0x69, 0x6e, 0x22, 0x45, 0x0a, 0x0f, 0x49, 0x6e, 0x67, 0x72, 0x65, 0x73, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x6f, 0x63, 0x75,
+	0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x6f, 0x63, 0x75,
+	0x6d, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x22, 0xc7, 0x01, 0x0a,
+	0x0e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b,
+	0x69, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x4b, 0x0a,
+	0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x31,
+	0x2e, 0x74, 0x72, 0x61, 0x65, 0x66, 0x69, 0x6b, 0x2e, 0x6d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x2e, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xab, 0x01, 0x0a, 0x0f, 0x43, 0x6f, 0x6e, 0x76, 0x65,
+	0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x46, 0x0a, 0x05, 0x66, 0x69,
+	0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x74, 0x72, 0x61, 0x65,
+	0x66, 0x69, 0x6b, 0x2e, 0x6d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x2e, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x66, 0x69, 0x6c,
+	0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x1a, 0x38, 0x0a, 0x0a, 0x46, 0x69,
+	0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x32, 0xc6, 0x01, 0x0a, 0x09, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74,
+	0x65, 0x72, 0x12, 0x61, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x49, 0x6e, 0x67,
+	0x72, 0x65, 0x73, 0x73, 0x12, 0x24, 0x2e, 0x74, 0x72, 0x61, 0x65, 0x66, 0x69, 0x6b, 0x2e, 0x6d,
+	0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x67, 0x72,
+	0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x74, 0x72, 0x61,
+	0x65, 0x66, 0x69, 0x6b, 0x2e, 0x6d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x49, 0x6e, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x28, 0x01, 0x30, 0x01, 0x12, 0x56, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74,
+	0x12, 0x24, 0x2e, 0x74, 0x72, 0x61, 0x65, 0x66, 0x69, 0x6b, 0x2e, 0x6d, 0x69, 0x67, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x74, 0x72, 0x61, 0x65, 0x66, 0x69, 0x6b,
+	0x2e, 0x6d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f,
+	0x6e, 0x76, 0x65, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x36, 0x5a,
+	0x34, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x72, 0x61, 0x65,
+	0x66, 0x69, 0x6b, 0x2f, 0x74, 0x72, 0x61, 0x65, 0x66, 0x69, 0x6b, 0x2d, 0x6d, 0x69, 0x67, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2d, 0x74, 0x6f, 0x6f, 0x6c, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x2f, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_converter_proto_rawDescOnce sync.Once
+	file_converter_proto_rawDescData = file_converter_proto_rawDesc
+)
+
+func file_converter_proto_rawDescGZIP() []byte {
+	file_converter_proto_rawDescOnce.Do(func() {
+		file_converter_proto_rawDescData = protoimpl.X.CompressGZIP(file_converter_proto_rawDescData)
+	})
+	return file_converter_proto_rawDescData
+}
+
+var file_converter_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_converter_proto_goTypes = []interface{}{
+	(*IngressRequest)(nil),  // 0: traefik.migration.v1.IngressRequest
+	(*IngressResponse)(nil), // 1: traefik.migration.v1.IngressResponse
+	(*ConvertRequest)(nil),  // 2: traefik.migration.v1.ConvertRequest
+	(*ConvertResponse)(nil), // 3: traefik.migration.v1.ConvertResponse
+	nil,                     // 4: traefik.migration.v1.ConvertRequest.OptionsEntry
+	nil,                     // 5: traefik.migration.v1.ConvertResponse.FilesEntry
+}
+var file_converter_proto_depIdxs = []int32{
+	4, // 0: traefik.migration.v1.ConvertRequest.options:type_name -> traefik.migration.v1.ConvertRequest.OptionsEntry
+	5, // 1: traefik.migration.v1.ConvertResponse.files:type_name -> traefik.migration.v1.ConvertResponse.FilesEntry
+	0, // 2: traefik.migration.v1.Converter.ConvertIngress:input_type -> traefik.migration.v1.IngressRequest
+	2, // 3: traefik.migration.v1.Converter.Convert:input_type -> traefik.migration.v1.ConvertRequest
+	1, // 4: traefik.migration.v1.Converter.ConvertIngress:output_type -> traefik.migration.v1.IngressResponse
+	3, // 5: traefik.migration.v1.Converter.Convert:output_type -> traefik.migration.v1.ConvertResponse
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_converter_proto_init() }
+func file_converter_proto_init() {
+	if File_converter_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_converter_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IngressRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_converter_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IngressResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_converter_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConvertRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_converter_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConvertResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_converter_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_converter_proto_goTypes,
+		DependencyIndexes: file_converter_proto_depIdxs,
+		MessageInfos:      file_converter_proto_msgTypes,
+	}.Build()
+	File_converter_proto = out.File
+	file_converter_proto_rawDesc = nil
+	file_converter_proto_goTypes = nil
+	file_converter_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// ConverterClient is the client API for Converter service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ConverterClient interface {
+	// ConvertIngress converts a stream of Kubernetes manifests, one YAML document per message, and streams the converted documents.
+	ConvertIngress(ctx context.Context, opts ...grpc.CallOption) (Converter_ConvertIngressClient, error)
+	// Convert converts a single payload.
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error)
+}
+
+type converterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewConverterClient(cc grpc.ClientConnInterface) ConverterClient {
+	return &converterClient{cc}
+}
+
+func (c *converterClient) ConvertIngress(ctx context.Context, opts ...grpc.CallOption) (Converter_ConvertIngressClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Converter_serviceDesc.Streams[0], "/traefik.migration.v1.Converter/ConvertIngress", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &converterConvertIngressClient{stream}
+	return x, nil
+}
+
+type Converter_ConvertIngressClient interface {
+	Send(*IngressRequest) error
+	Recv() (*IngressResponse, error)
+	grpc.ClientStream
+}
+
+type converterConvertIngressClient struct {
+	grpc.ClientStream
+}
+
+func (x *converterConvertIngressClient) Send(m *IngressRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *converterConvertIngressClient) Recv() (*IngressResponse, error) {
+	m := new(IngressResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *converterClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error) {
+	out := new(ConvertResponse)
+	err := c.cc.Invoke(ctx, "/traefik.migration.v1.Converter/Convert", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ConverterServer is the server API for Converter service.
+type ConverterServer interface {
+	// ConvertIngress converts a stream of Kubernetes manifests, one YAML document per message, and streams the converted documents.
+	ConvertIngress(Converter_ConvertIngressServer) error
+	// Convert converts a single payload.
+	Convert(context.Context, *ConvertRequest) (*ConvertResponse, error)
+}
+
+// UnimplementedConverterServer can be embedded to have forward compatible implementations.
+type UnimplementedConverterServer struct {
+}
+
+func (*UnimplementedConverterServer) ConvertIngress(Converter_ConvertIngressServer) error {
+	return status.Errorf(codes.Unimplemented, "method ConvertIngress not implemented")
+}
+func (*UnimplementedConverterServer) Convert(context.Context, *ConvertRequest) (*ConvertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Convert not implemented")
+}
+
+func RegisterConverterServer(s *grpc.Server, srv ConverterServer) {
+	s.RegisterService(&_Converter_serviceDesc, srv)
+}
+
+func _Converter_ConvertIngress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ConverterServer).ConvertIngress(&converterConvertIngressServer{stream})
+}
+
+type Converter_ConvertIngressServer interface {
+	Send(*IngressResponse) error
+	Recv() (*IngressRequest, error)
+	grpc.ServerStream
+}
+
+type converterConvertIngressServer struct {
+	grpc.ServerStream
+}
+
+func (x *converterConvertIngressServer) Send(m *IngressResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *converterConvertIngressServer) Recv() (*IngressRequest, error) {
+	m := new(IngressRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Converter_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConverterServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/traefik.migration.v1.Converter/Convert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConverterServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Converter_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "traefik.migration.v1.Converter",
+	HandlerType: (*ConverterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Convert",
+			Handler:    _Converter_Convert_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ConvertIngress",
+			Handler:       _Converter_ConvertIngress_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "converter.proto",
+}
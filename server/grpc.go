@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/traefik/traefik-migration-tool/server/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewGRPC returns a gRPC server exposing the conversions.
+func NewGRPC() *grpc.Server {
+	srv := grpc.NewServer(grpc.MaxRecvMsgSize(maxPayloadSize))
+	api.RegisterConverterServer(srv, &converterServer{})
+
+	return srv
+}
+
+// converterServer implements the gRPC API with the conversions of the HTTP endpoints.
+type converterServer struct {
+	api.UnimplementedConverterServer
+}
+
+func (s *converterServer) Convert(_ context.Context, req *api.ConvertRequest) (*api.ConvertResponse, error) {
+	e, ok := endpoints[req.GetKind()]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown kind of conversion %q", req.GetKind())
+	}
+
+	params := url.Values{}
+	for key, value := range req.GetOptions() {
+		params.Set(key, value)
+	}
+
+	resp, err := e.run(req.GetPayload(), params)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	report, err := encodeReport(resp.Report)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &api.ConvertResponse{Files: resp.Files, Report: report}, nil
+}
+
+// ConvertIngress converts each document of the stream as a single file, the options are read from the first message.
+func (s *converterServer) ConvertIngress(stream api.Converter_ConvertIngressServer) error {
+	e := endpoints["ingress"]
+
+	var params url.Values
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if params == nil {
+			params = url.Values{
+				"audit":   {strconv.FormatBool(req.GetAudit())},
+				"explain": {strconv.FormatBool(req.GetExplain())},
+			}
+		}
+
+		resp, err := e.run([]byte(req.GetDocument()), params)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		report, err := encodeReport(resp.Report)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		err = stream.Send(&api.IngressResponse{Document: resp.Files[e.inputFile(params)], Report: report})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func encodeReport(report interface{}) (string, error) {
+	if report == nil {
+		return "", nil
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
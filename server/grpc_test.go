@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik-migration-tool/server/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newGRPCClient(t *testing.T) api.ConverterClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+
+	srv := NewGRPC()
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithInsecure(),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return api.NewConverterClient(conn)
+}
+
+func TestConverterServer_Convert(t *testing.T) {
+	client := newGRPCClient(t)
+
+	payload, err := os.ReadFile(filepath.Join("..", "acme", "fixtures", "acme.json"))
+	require.NoError(t, err)
+
+	resp, err := client.Convert(context.Background(), &api.ConvertRequest{
+		Kind:    "acme",
+		Payload: payload,
+		Options: map[string]string{"resolver": "myresolver"},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, resp.GetFiles()["acme.json"], "myresolver")
+
+	_, err = client.Convert(context.Background(), &api.ConvertRequest{Kind: "unknown", Payload: payload})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestConverterServer_ConvertIngress(t *testing.T) {
+	client := newGRPCClient(t)
+
+	stream, err := client.ConvertIngress(context.Background())
+	require.NoError(t, err)
+
+	for i, file := range []string{"ingress_with_request_modifier.yml", "ingress_redirect_regex.yml"} {
+		document, err := os.ReadFile(filepath.Join("..", "ingress", "fixtures", "input", file))
+		require.NoError(t, err)
+
+		// The options are read from the first message only.
+		require.NoError(t, stream.Send(&api.IngressRequest{Document: string(document), Explain: i == 0}))
+
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+
+		assert.Contains(t, resp.GetDocument(), "kind: IngressRoute")
+		assert.Contains(t, resp.GetDocument(), "# migrated from Ingress testing/test")
+		assert.Contains(t, resp.GetReport(), `"namespace":"testing"`)
+	}
+
+	require.NoError(t, stream.CloseSend())
+}
@@ -0,0 +1,358 @@
+// Package server exposes the conversions over HTTP.
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/traefik/traefik-migration-tool/acme"
+	"github.com/traefik/traefik-migration-tool/ingress"
+	"github.com/traefik/traefik-migration-tool/metrics"
+	"github.com/traefik/traefik-migration-tool/static"
+	"github.com/traefik/traefik-migration-tool/v2tov3"
+	"google.golang.org/grpc"
+)
+
+// maxPayloadSize is the maximum size of a source payload.
+const maxPayloadSize = 10 << 20
+
+// Response is the result of a conversion.
+type Response struct {
+	// Files are the converted artifacts, by path relative to the output directory.
+	Files  map[string]string `json:"files"`
+	Report interface{}       `json:"report,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// endpoint is a conversion exposed by the server.
+type endpoint struct {
+	kind string
+	// inputFile returns the name of the file holding the source payload.
+	inputFile func(params url.Values) string
+	// convert converts the input file into the output directory, and returns the report of the conversion.
+	convert func(params url.Values, input, outputDir string) (interface{}, error)
+	// observe counts the converted objects, for the conversions not counting them already.
+	observe bool
+}
+
+// endpoints are the conversions, by kind.
+var endpoints = map[string]endpoint{
+	"ingress": {
+		kind:      "ingress",
+		inputFile: fixedName("ingress.yml"),
+		convert:   convertIngress,
+	},
+	"acme": {
+		kind:      "acme",
+		inputFile: fixedName("acme.json"),
+		convert:   convertACME,
+		observe:   true,
+	},
+	"static": {
+		kind:      "static",
+		inputFile: fixedName("traefik.toml"),
+		convert:   convertStatic,
+		observe:   true,
+	},
+	"dynamic": {
+		kind:      "dynamic",
+		inputFile: dynamicFileName,
+		convert:   convertDynamic,
+		observe:   true,
+	},
+}
+
+// ListenAndServe serves the conversion endpoints on addr, and the gRPC API on grpcAddr when not empty, until ctx is done.
+func ListenAndServe(ctx context.Context, addr, grpcAddr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           New(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	var grpcSrv *grpc.Server
+	if grpcAddr != "" {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			_ = srv.Close()
+			return err
+		}
+
+		grpcSrv = NewGRPC()
+		go func() {
+			errCh <- grpcSrv.Serve(listener)
+		}()
+	}
+
+	select {
+	case err := <-errCh:
+		_ = srv.Close()
+		if grpcSrv != nil {
+			grpcSrv.Stop()
+		}
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if grpcSrv != nil {
+			grpcSrv.GracefulStop()
+		}
+
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// New returns the handler of the conversion endpoints and of the metrics endpoint.
+func New() http.Handler {
+	mux := http.NewServeMux()
+
+	for kind, e := range endpoints {
+		mux.Handle("/convert/"+kind, e)
+	}
+
+	mux.Handle("/metrics", metrics.Handler())
+
+	return mux
+}
+
+func (e endpoint) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		writeJSON(rw, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(rw, req.Body, maxPayloadSize))
+	if err != nil {
+		writeJSON(rw, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	resp, err := e.run(payload, req.URL.Query())
+	if err != nil {
+		writeJSON(rw, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, resp)
+}
+
+// run converts a payload in a temporary working directory.
+func (e endpoint) run(payload []byte, params url.Values) (*Response, error) {
+	defer metrics.ObserveConversion(e.kind, time.Now())
+
+	resp, err := e.convertPayload(payload, params)
+
+	if e.observe {
+		if err != nil {
+			metrics.ObserveObject(e.kind, metrics.StatusFailed)
+		} else {
+			metrics.ObserveObject(e.kind, metrics.StatusConverted)
+		}
+	}
+
+	return resp, err
+}
+
+func (e endpoint) convertPayload(payload []byte, params url.Values) (*Response, error) {
+	if len(payload) == 0 {
+		return nil, errors.New("empty payload")
+	}
+
+	workDir, err := os.MkdirTemp("", "traefik-migration-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+
+	inputDir := filepath.Join(workDir, "input")
+	outputDir := filepath.Join(workDir, "output")
+
+	for _, dir := range []string{inputDir, outputDir} {
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	input := filepath.Join(inputDir, e.inputFile(params))
+
+	err = os.WriteFile(input, payload, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := e.convert(params, input, outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := readFiles(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{Files: files, Report: report}, nil
+}
+
+func convertIngress(params url.Values, input, outputDir string) (interface{}, error) {
+	// The notes are returned with the report of each Ingress.
+	opts := ingress.Options{
+		Audit:   paramBool(params, "audit"),
+		Explain: paramBool(params, "explain"),
+		Notes:   io.Discard,
+	}
+
+	// The ingresses whose conversion failed are kept unchanged in the files, and listed in the report.
+	report, err := ingress.Convert(input, outputDir, opts)
+	var failed *ingress.FailedError
+	if err != nil && !errors.As(err, &failed) {
+		return nil, err
+	}
+
+	// The paths of the working directory are meaningless for the client.
+	for i := range report.Ingresses {
+		report.Ingresses[i].File = filepath.Base(report.Ingresses[i].File)
+	}
+
+	result := &ingressReport{Report: report}
+	if failed != nil {
+		result.Failures = failed.Ingresses
+	}
+
+	return result, nil
+}
+
+// ingressReport is the report of the ingress conversions, with the errors of the ingresses which are not converted.
+type ingressReport struct {
+	*ingress.Report
+	Failures []string `json:"failures,omitempty"`
+}
+
+func convertACME(params url.Values, input, outputDir string) (interface{}, error) {
+	resolverName := params.Get("resolver")
+	if resolverName == "" {
+		resolverName = "default"
+	}
+
+	return nil, acme.Convert(input, filepath.Join(outputDir, "acme.json"), resolverName)
+}
+
+func convertStatic(_ url.Values, input, outputDir string) (interface{}, error) {
+	notes := &bytes.Buffer{}
+
+	err := static.Convert(input, outputDir, notes)
+	if err != nil {
+		return nil, err
+	}
+
+	return newWarningsReport(notes.String(), filepath.Dir(input)), nil
+}
+
+func convertDynamic(_ url.Values, input, outputDir string) (interface{}, error) {
+	notes := &bytes.Buffer{}
+
+	err := v2tov3.ConvertFile(input, outputDir, notes)
+	if err != nil {
+		return nil, err
+	}
+
+	return newWarningsReport(notes.String(), filepath.Dir(input)), nil
+}
+
+// warningsReport is the report of the conversions reporting the settings to convert manually.
+type warningsReport struct {
+	Warnings []string `json:"warnings"`
+}
+
+// newWarningsReport returns the report of the notes written by a conversion, one per line.
+// The paths of the working directory are meaningless for the client, they are removed.
+func newWarningsReport(notes, inputDir string) *warningsReport {
+	report := &warningsReport{Warnings: []string{}}
+	for _, line := range strings.Split(notes, "\n") {
+		line = strings.ReplaceAll(line, inputDir+string(filepath.Separator), "")
+		if line != "" {
+			report.Warnings = append(report.Warnings, line)
+		}
+	}
+
+	return report
+}
+
+func fixedName(name string) func(url.Values) string {
+	return func(url.Values) string { return name }
+}
+
+// dynamicFileName returns the name of the dynamic configuration file, from the format parameter (yaml or toml).
+func dynamicFileName(params url.Values) string {
+	if params.Get("format") == "toml" {
+		return "dynamic.toml"
+	}
+
+	return "dynamic.yml"
+}
+
+func paramBool(params url.Values, name string) bool {
+	value, err := strconv.ParseBool(params.Get(name))
+	return err == nil && value
+}
+
+func readFiles(dir string) (map[string]string, error) {
+	files := map[string]string{}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading the converted files: %w", err)
+	}
+
+	return files, nil
+}
+
+func writeJSON(rw http.ResponseWriter, status int, value interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+
+	_ = json.NewEncoder(rw).Encode(value)
+}
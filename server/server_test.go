@@ -0,0 +1,228 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik-migration-tool/ingress"
+)
+
+func TestServer(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		path             string
+		payloadFile      string
+		expectedFiles    []string
+		expectReport     bool
+		expectedWarnings []string
+	}{
+		{
+			desc:          "ingress",
+			path:          "/convert/ingress",
+			payloadFile:   filepath.Join("..", "ingress", "fixtures", "input", "ingress_with_request_modifier.yml"),
+			expectedFiles: []string{"ingress.yml"},
+			expectReport:  true,
+		},
+		{
+			desc:          "acme",
+			path:          "/convert/acme?resolver=myresolver",
+			payloadFile:   filepath.Join("..", "acme", "fixtures", "acme.json"),
+			expectedFiles: []string{"acme.json"},
+		},
+		{
+			desc:          "static",
+			path:          "/convert/static",
+			payloadFile:   filepath.Join("..", "static", "fixtures", "sample01.toml"),
+			expectedFiles: []string{"new-traefik.toml", "new-traefik.yml"},
+			expectReport:  true,
+			expectedWarnings: []string{
+				`Redirect on entry point "http" must be converted manually. See https://docs.traefik.io/middlewares/redirectscheme/`,
+				`TLS on entry point "https" must be converted manually. See https://docs.traefik.io/routing/routers/#tls`,
+				"All the elements related to dynamic configuration (backends, frontends, ...) must be converted manually. See https://docs.traefik.io/routing/overview/",
+			},
+		},
+		{
+			desc:          "dynamic",
+			path:          "/convert/dynamic",
+			payloadFile:   filepath.Join("..", "v2tov3", "fixtures", "input", "file", "dynamic.yml"),
+			expectedFiles: []string{"dynamic.yml"},
+			expectReport:  true,
+			expectedWarnings: []string{
+				"Middleware allowlist: ipWhiteList renamed to ipAllowList.",
+				"Middleware secure: the headers option sslRedirect has been removed in Traefik v3, it must be converted manually (ex: with a redirectScheme middleware). See https://doc.traefik.io/traefik/migration/v2-to-v3/",
+				"MiddlewareTCP allowlist: ipWhiteList renamed to ipAllowList.",
+				"TLSOption modern: the option preferServerCipherSuites has been removed in Traefik v3, the server cipher suites are always preferred with TLS 1.3. See https://doc.traefik.io/traefik/migration/v2-to-v3/",
+				`Router broken: the rule "Host(` + "`broken.example.com`" + `" must be converted manually: unterminated matcher Host`,
+				"dynamic.yml: http router broken: invalid Traefik v3 rule: unterminated matcher Host",
+			},
+		},
+	}
+
+	server := httptest.NewServer(New())
+	defer server.Close()
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			payload, err := os.Open(test.payloadFile)
+			require.NoError(t, err)
+			defer func() { _ = payload.Close() }()
+
+			resp, err := server.Client().Post(server.URL+test.path, "application/octet-stream", payload)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var result Response
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+			var files []string
+			for name, content := range result.Files {
+				files = append(files, name)
+				assert.NotEmpty(t, content)
+			}
+			assert.ElementsMatch(t, test.expectedFiles, files)
+
+			if test.expectReport {
+				assert.NotNil(t, result.Report)
+			} else {
+				assert.Nil(t, result.Report)
+			}
+
+			if test.expectedWarnings != nil {
+				report, ok := result.Report.(map[string]interface{})
+				require.True(t, ok)
+
+				var warnings []string
+				for _, warning := range report["warnings"].([]interface{}) {
+					warnings = append(warnings, warning.(string))
+				}
+				assert.ElementsMatch(t, test.expectedWarnings, warnings)
+			}
+		})
+	}
+}
+
+func TestServer_ingressNotes(t *testing.T) {
+	server := httptest.NewServer(New())
+	defer server.Close()
+
+	payload, err := os.Open(filepath.Join("..", "ingress", "fixtures", "input", "ingress_with_ratelimit.yml"))
+	require.NoError(t, err)
+	defer func() { _ = payload.Close() }()
+
+	resp, err := server.Client().Post(server.URL+"/convert/ingress", "application/octet-stream", payload)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Report ingress.Report `json:"report"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	require.Len(t, result.Report.Ingresses, 1)
+	assert.Equal(t, []string{"The rate sets bar, foo of the annotation ingress.kubernetes.io/rate-limit are converted to chained rateLimit middlewares: " +
+		"unlike Traefik v1, a request rejected by one of them is still counted by the others."}, result.Report.Ingresses[0].Notes)
+}
+
+func TestServer_ingressFailures(t *testing.T) {
+	server := httptest.NewServer(New())
+	defer server.Close()
+
+	payload := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: whoami
+  namespace: testing
+spec:
+  rules:
+  - host: whoami
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: weights
+  namespace: testing
+  annotations:
+    ingress.kubernetes.io/service-weights: |
+      whoami: 120%
+spec:
+  rules:
+  - host: weights
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: whoami
+          servicePort: 80
+`
+
+	resp, err := server.Client().Post(server.URL+"/convert/ingress", "application/octet-stream", strings.NewReader(payload))
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Files  map[string]string `json:"files"`
+		Report struct {
+			Ingresses []ingress.IngressReport `json:"ingresses"`
+			Failures  []string                `json:"failures"`
+		} `json:"report"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	assert.Contains(t, result.Files["ingress.yml"], "kind: IngressRoute")
+	assert.Contains(t, result.Files["ingress.yml"], "whoami: 120%")
+
+	require.Len(t, result.Report.Ingresses, 1)
+	assert.Equal(t, "whoami", result.Report.Ingresses[0].Name)
+	assert.Equal(t, []string{`testing/weights: invalid ingress.kubernetes.io/service-weights: the weight "120%" of the service whoami is not a percentage`}, result.Report.Failures)
+}
+
+func TestServer_errors(t *testing.T) {
+	server := httptest.NewServer(New())
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/convert/ingress")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = server.Client().Post(server.URL+"/convert/acme", "application/json", strings.NewReader("{"))
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var result errorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.NotEmpty(t, result.Error)
+}
+
+func TestServer_metrics(t *testing.T) {
+	server := httptest.NewServer(New())
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -2,18 +2,19 @@ package static
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/traefik/traefik/v2/pkg/config/static"
 	"github.com/traefik/traefik/v2/pkg/provider/acme"
 )
 
-func migrateACME(oldCfg Configuration) map[string]static.CertificateResolver {
+func migrateACME(oldCfg Configuration, w io.Writer) map[string]static.CertificateResolver {
 	if oldCfg.ACME == nil {
 		return nil
 	}
 
 	if oldCfg.ACME.EntryPoint != "" {
-		fmt.Printf("The entry point (%s) defined in the ACME configuration must be converted manually. See https://docs.traefik.io/routing/routers/#certresolver\n", oldCfg.ACME.EntryPoint)
+		fmt.Fprintf(w, "The entry point (%s) defined in the ACME configuration must be converted manually. See https://docs.traefik.io/routing/routers/#certresolver\n", oldCfg.ACME.EntryPoint)
 	}
 
 	return map[string]static.CertificateResolver{
@@ -2,6 +2,7 @@ package static
 
 import (
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/containous/flaeg/parse"
@@ -12,17 +13,17 @@ import (
 	"github.com/traefik/traefik/v2/pkg/types"
 )
 
-func migrateConfiguration(oldCfg Configuration) static.Configuration {
+func migrateConfiguration(oldCfg Configuration, w io.Writer) static.Configuration {
 	if oldCfg.Retry != nil {
-		fmt.Println("Retry must be converted manually. See https://docs.traefik.io/middlewares/retry/")
+		fmt.Fprintln(w, "Retry must be converted manually. See https://docs.traefik.io/middlewares/retry/")
 	}
 
 	if oldCfg.Constraints != nil {
-		fmt.Println("Global Constraints must be converted manually to provider constraints. See https://docs.traefik.io/providers/docker/#constraints")
+		fmt.Fprintln(w, "Global Constraints must be converted manually to provider constraints. See https://docs.traefik.io/providers/docker/#constraints")
 	}
 
 	if oldCfg.Web != nil {
-		fmt.Println("Web must be converted manually. See https://docs.traefik.io/operations/api/")
+		fmt.Fprintln(w, "Web must be converted manually. See https://docs.traefik.io/operations/api/")
 	}
 
 	return static.Configuration{
@@ -31,16 +32,16 @@ func migrateConfiguration(oldCfg Configuration) static.Configuration {
 			SendAnonymousUsage: oldCfg.SendAnonymousUsage,
 		},
 		ServersTransport:      migrateServersTransport(oldCfg),
-		EntryPoints:           migrateEntryPoints(oldCfg),
-		Providers:             migrateProviders(oldCfg),
-		API:                   migrateAPI(oldCfg),
+		EntryPoints:           migrateEntryPoints(oldCfg, w),
+		Providers:             migrateProviders(oldCfg, w),
+		API:                   migrateAPI(oldCfg, w),
 		Metrics:               migrateMetrics(oldCfg),
 		Ping:                  migratePing(oldCfg),
 		Log:                   migrateTraefikLog(oldCfg),
 		AccessLog:             migrateAccessLog(oldCfg),
 		Tracing:               migrateTracing(oldCfg),
 		HostResolver:          migrateHostResolver(oldCfg),
-		CertificatesResolvers: migrateACME(oldCfg),
+		CertificatesResolvers: migrateACME(oldCfg, w),
 	}
 }
 
@@ -54,13 +55,13 @@ func migratePing(oldCfg Configuration) *ping.Handler {
 	}
 }
 
-func migrateAPI(oldCfg Configuration) *static.API {
+func migrateAPI(oldCfg Configuration, w io.Writer) *static.API {
 	if oldCfg.API == nil {
 		return nil
 	}
 
 	if oldCfg.API.EntryPoint != "" {
-		fmt.Printf("The entry point (%s) defined in API must be converted manually. See https://docs.traefik.io/operations/api/\n", oldCfg.API.EntryPoint)
+		fmt.Fprintf(w, "The entry point (%s) defined in API must be converted manually. See https://docs.traefik.io/operations/api/\n", oldCfg.API.EntryPoint)
 	}
 
 	return &static.API{
@@ -70,7 +71,7 @@ func migrateAPI(oldCfg Configuration) *static.API {
 	}
 }
 
-func migrateEntryPoints(oldCfg Configuration) static.EntryPoints {
+func migrateEntryPoints(oldCfg Configuration, w io.Writer) static.EntryPoints {
 	if oldCfg.EntryPoints == nil {
 		return nil
 	}
@@ -78,19 +79,19 @@ func migrateEntryPoints(oldCfg Configuration) static.EntryPoints {
 	eps := static.EntryPoints{}
 	for name, entryPoint := range *oldCfg.EntryPoints {
 		if entryPoint.Compress {
-			fmt.Printf("Compress on entry point %q must be converted manually. See https://docs.traefik.io/middlewares/compress/\n", name)
+			fmt.Fprintf(w, "Compress on entry point %q must be converted manually. See https://docs.traefik.io/middlewares/compress/\n", name)
 		}
 		if entryPoint.TLS != nil {
-			fmt.Printf("TLS on entry point %q must be converted manually. See https://docs.traefik.io/routing/routers/#tls\n", name)
+			fmt.Fprintf(w, "TLS on entry point %q must be converted manually. See https://docs.traefik.io/routing/routers/#tls\n", name)
 		}
 		if entryPoint.Redirect != nil {
-			fmt.Printf("Redirect on entry point %q must be converted manually. See https://docs.traefik.io/middlewares/redirectscheme/\n", name)
+			fmt.Fprintf(w, "Redirect on entry point %q must be converted manually. See https://docs.traefik.io/middlewares/redirectscheme/\n", name)
 		}
 		if entryPoint.WhiteList != nil {
-			fmt.Printf("WhiteList on entry point %q must be converted manually. See https://docs.traefik.io/middlewares/ipwhitelist/\n", name)
+			fmt.Fprintf(w, "WhiteList on entry point %q must be converted manually. See https://docs.traefik.io/middlewares/ipwhitelist/\n", name)
 		}
 		if len(entryPoint.WhitelistSourceRange) != 0 {
-			fmt.Printf("WhitelistSourceRange on entry point %q must be converted manually. See https://docs.traefik.io/middlewares/ipwhitelist/\n", name)
+			fmt.Fprintf(w, "WhitelistSourceRange on entry point %q must be converted manually. See https://docs.traefik.io/middlewares/ipwhitelist/\n", name)
 		}
 
 		eps[name] = &static.EntryPoint{
@@ -15,7 +15,12 @@ type encoder interface {
 }
 
 // Convert old static configuration file to the Traefik v2 static configuration files.
-func Convert(oldFilename, outputDir string) error {
+// The settings to convert manually are reported to notes, default to the standard output.
+func Convert(oldFilename, outputDir string, notes io.Writer) error {
+	if notes == nil {
+		notes = os.Stdout
+	}
+
 	err := os.MkdirAll(outputDir, 0755)
 	if err != nil {
 		return err
@@ -28,7 +33,7 @@ func Convert(oldFilename, outputDir string) error {
 		return err
 	}
 
-	newCfg := migrateConfiguration(oldCfg)
+	newCfg := migrateConfiguration(oldCfg, notes)
 
 	err = writeFile(filepath.Join(outputDir, "new-traefik.yml"), func(w io.Writer) encoder {
 		return yaml.NewEncoder(w)
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/traefik/traefik/v2/pkg/config/static"
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
 	"gopkg.in/yaml.v2"
 )
 
@@ -25,7 +26,7 @@ func TestConvert(t *testing.T) {
 
 			dir := t.TempDir()
 
-			err := Convert(test, dir)
+			err := Convert(test, dir, nil)
 			require.NoError(t, err)
 
 			cfgToml := static.Configuration{}
@@ -43,3 +44,22 @@ func TestConvert(t *testing.T) {
 		})
 	}
 }
+
+func TestTLSOptions(t *testing.T) {
+	options, err := TLSOptions("./fixtures/sample02.toml")
+	require.NoError(t, err)
+
+	expected := map[string]v1alpha1.TLSOptionSpec{
+		"EntryPoint0": {
+			MinVersion:   "foobar",
+			CipherSuites: []string{"foobar", "foobar"},
+			SniStrict:    true,
+			ClientAuth:   v1alpha1.ClientAuth{ClientAuthType: "NoClientCert"},
+		},
+	}
+	assert.Equal(t, expected, options)
+
+	options, err = TLSOptions("./fixtures/sample01.toml")
+	require.NoError(t, err)
+	assert.Empty(t, options)
+}
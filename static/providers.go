@@ -2,6 +2,7 @@ package static
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -16,57 +17,57 @@ import (
 	"github.com/traefik/traefik/v2/pkg/types"
 )
 
-func migrateProviders(oldCfg Configuration) *static.Providers {
+func migrateProviders(oldCfg Configuration, w io.Writer) *static.Providers {
 	if oldCfg.ECS != nil {
-		fmt.Printf("The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.ECS), "*static."))
+		fmt.Fprintf(w, "The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.ECS), "*static."))
 	}
 	if oldCfg.Consul != nil {
-		fmt.Printf("The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.Consul), "*static."))
+		fmt.Fprintf(w, "The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.Consul), "*static."))
 	}
 	if oldCfg.ConsulCatalog != nil {
-		fmt.Printf("The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.ConsulCatalog), "*static."))
+		fmt.Fprintf(w, "The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.ConsulCatalog), "*static."))
 	}
 	if oldCfg.Etcd != nil {
-		fmt.Printf("The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.Etcd), "*static."))
+		fmt.Fprintf(w, "The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.Etcd), "*static."))
 	}
 	if oldCfg.Zookeeper != nil {
-		fmt.Printf("The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.Zookeeper), "*static."))
+		fmt.Fprintf(w, "The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.Zookeeper), "*static."))
 	}
 	if oldCfg.Boltdb != nil {
-		fmt.Printf("The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.Boltdb), "*static."))
+		fmt.Fprintf(w, "The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.Boltdb), "*static."))
 	}
 	if oldCfg.Mesos != nil {
-		fmt.Printf("The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.Mesos), "*static."))
+		fmt.Fprintf(w, "The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.Mesos), "*static."))
 	}
 	if oldCfg.Eureka != nil {
-		fmt.Printf("The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.Eureka), "*static."))
+		fmt.Fprintf(w, "The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.Eureka), "*static."))
 	}
 	if oldCfg.DynamoDB != nil {
-		fmt.Printf("The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.DynamoDB), "*static."))
+		fmt.Fprintf(w, "The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.DynamoDB), "*static."))
 	}
 	if oldCfg.ServiceFabric != nil {
-		fmt.Printf("The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.ServiceFabric), "*static."))
+		fmt.Fprintf(w, "The %s provider is currently not supported by Traefik v2.\n", strings.TrimPrefix(fmt.Sprintf("%T", oldCfg.ServiceFabric), "*static."))
 	}
 
 	return &static.Providers{
 		ProvidersThrottleDuration: convertDuration(oldCfg.ProvidersThrottleDuration, 2*time.Second),
-		Docker:                    migrateDocker(oldCfg),
-		File:                      migrateFile(oldCfg),
-		Marathon:                  migrateMarathon(oldCfg),
+		Docker:                    migrateDocker(oldCfg, w),
+		File:                      migrateFile(oldCfg, w),
+		Marathon:                  migrateMarathon(oldCfg, w),
 		KubernetesIngress:         migrateKubernetes(oldCfg),
 		KubernetesCRD:             nil, // SKIP
-		Rest:                      migrateRest(oldCfg),
-		Rancher:                   migrateRancher(oldCfg),
+		Rest:                      migrateRest(oldCfg, w),
+		Rancher:                   migrateRancher(oldCfg, w),
 	}
 }
 
-func migrateRancher(oldCfg Configuration) *rancher.Provider {
+func migrateRancher(oldCfg Configuration, w io.Writer) *rancher.Provider {
 	if oldCfg.Rancher == nil {
 		return nil
 	}
 
 	if len(oldCfg.Rancher.Constraints) != 0 {
-		fmt.Println("The constraints on the Rancher provider must be converted manually. https://docs.traefik.io/providers/rancher/#constraints")
+		fmt.Fprintln(w, "The constraints on the Rancher provider must be converted manually. https://docs.traefik.io/providers/rancher/#constraints")
 	}
 
 	rancherCfg := &rancher.Provider{
@@ -115,17 +116,17 @@ func migrateIngressEndpoint(oldCfg Configuration) *ingress.EndpointIngress {
 	}
 }
 
-func migrateMarathon(oldCfg Configuration) *marathon.Provider {
+func migrateMarathon(oldCfg Configuration, w io.Writer) *marathon.Provider {
 	if oldCfg.Marathon == nil {
 		return nil
 	}
 
 	if len(oldCfg.Marathon.Constraints) != 0 {
-		fmt.Println("The constraints on the Marathon provider must be converted manually. https://docs.traefik.io/providers/marathon/#constraints")
+		fmt.Fprintln(w, "The constraints on the Marathon provider must be converted manually. https://docs.traefik.io/providers/marathon/#constraints")
 	}
 
 	if oldCfg.Marathon.Domain != "" {
-		fmt.Printf("The domain (%s) defined the Marathon provider must be converted manually. See https://docs.traefik.io/providers/marathon/#defaultrule\n", oldCfg.Marathon.Domain)
+		fmt.Fprintf(w, "The domain (%s) defined the Marathon provider must be converted manually. See https://docs.traefik.io/providers/marathon/#defaultrule\n", oldCfg.Marathon.Domain)
 	}
 
 	return &marathon.Provider{
@@ -158,13 +159,13 @@ func migrateMarathonBasic(oldCfg Configuration) *marathon.Basic {
 	}
 }
 
-func migrateFile(oldCfg Configuration) *file.Provider {
+func migrateFile(oldCfg Configuration, w io.Writer) *file.Provider {
 	if oldCfg.File == nil {
 		return nil
 	}
 
 	if oldCfg.File.Directory == "" && oldCfg.File.Filename == "" {
-		fmt.Println("All the elements related to dynamic configuration (backends, frontends, ...) must be converted manually. See https://docs.traefik.io/routing/overview/")
+		fmt.Fprintln(w, "All the elements related to dynamic configuration (backends, frontends, ...) must be converted manually. See https://docs.traefik.io/routing/overview/")
 	}
 
 	return &file.Provider{
@@ -175,17 +176,17 @@ func migrateFile(oldCfg Configuration) *file.Provider {
 	}
 }
 
-func migrateDocker(oldCfg Configuration) *docker.Provider {
+func migrateDocker(oldCfg Configuration, w io.Writer) *docker.Provider {
 	if oldCfg.Docker == nil {
 		return nil
 	}
 
 	if len(oldCfg.Docker.Constraints) != 0 {
-		fmt.Println("The constraints defined in the Docker provider must be converted manually. See https://docs.traefik.io/providers/docker/#constraints")
+		fmt.Fprintln(w, "The constraints defined in the Docker provider must be converted manually. See https://docs.traefik.io/providers/docker/#constraints")
 	}
 
 	if oldCfg.Docker.Domain != "" {
-		fmt.Printf("The domain (%s) defined in the Docker provider must be converted manually. See https://docs.traefik.io/providers/docker/#defaultrule\n", oldCfg.Docker.Domain)
+		fmt.Fprintf(w, "The domain (%s) defined in the Docker provider must be converted manually. See https://docs.traefik.io/providers/docker/#defaultrule\n", oldCfg.Docker.Domain)
 	}
 
 	swarmModeRefreshSeconds := ptypes.Duration(15 * time.Second)
@@ -207,13 +208,13 @@ func migrateDocker(oldCfg Configuration) *docker.Provider {
 	}
 }
 
-func migrateRest(oldCfg Configuration) *rest.Provider {
+func migrateRest(oldCfg Configuration, w io.Writer) *rest.Provider {
 	if oldCfg.Rest == nil {
 		return nil
 	}
 
 	if oldCfg.Rest.EntryPoint != "" {
-		fmt.Printf("The entry point (%s) defined in the REST provider must be converted manually. See https://docs.traefik.io/operations/api/\n", oldCfg.Rest.EntryPoint)
+		fmt.Fprintf(w, "The entry point (%s) defined in the REST provider must be converted manually. See https://docs.traefik.io/operations/api/\n", oldCfg.Rest.EntryPoint)
 	}
 	return &rest.Provider{
 		Insecure: true,
@@ -0,0 +1,38 @@
+package static
+
+import (
+	"github.com/BurntSushi/toml"
+	"github.com/traefik/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+)
+
+// TLSOptions reads the TLS settings (minimal version, cipher suites, strict SNI) of the entry points of a Traefik v1 static configuration file,
+// and converts them to TLSOption specs, by entry point name. The entry points without these settings are skipped.
+func TLSOptions(oldFilename string) (map[string]v1alpha1.TLSOptionSpec, error) {
+	oldCfg := Configuration{}
+
+	_, err := toml.DecodeFile(oldFilename, &oldCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if oldCfg.EntryPoints == nil {
+		return nil, nil
+	}
+
+	options := map[string]v1alpha1.TLSOptionSpec{}
+	for name, entryPoint := range *oldCfg.EntryPoints {
+		if entryPoint.TLS == nil || entryPoint.TLS.MinVersion == "" && len(entryPoint.TLS.CipherSuites) == 0 && !entryPoint.TLS.SniStrict {
+			continue
+		}
+
+		// The client authentication is always encoded, its type is set as the CRD rejects an empty type.
+		options[name] = v1alpha1.TLSOptionSpec{
+			MinVersion:   entryPoint.TLS.MinVersion,
+			CipherSuites: entryPoint.TLS.CipherSuites,
+			SniStrict:    entryPoint.TLS.SniStrict,
+			ClientAuth:   v1alpha1.ClientAuth{ClientAuthType: "NoClientCert"},
+		}
+	}
+
+	return options, nil
+}
@@ -0,0 +1,234 @@
+//go:build !js
+// +build !js
+
+// The scan of a cluster needs the Kubernetes clients, which don't build for WebAssembly.
+
+package v2tov3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/traefik/traefik-migration-tool/cluster"
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// ClusterOptions holds the options of a cluster scan.
+type ClusterOptions struct {
+	// Kubeconfig is the path to the kubeconfig file, the default loading rules are used when empty.
+	Kubeconfig string
+	// Context is the kubeconfig context, the current context is used when empty.
+	Context string
+	// OutputDir is the directory where the converted manifests are written, one file per namespace.
+	// The manifests are not written when empty.
+	OutputDir string
+}
+
+// Plan holds the Traefik v2 custom resources of a cluster to migrate.
+type Plan struct {
+	Resources []PlanResource `json:"resources"`
+	// MissingCRDs are the kinds which are not yet served in the traefik.io API group by the cluster.
+	MissingCRDs []string `json:"missingCRDs,omitempty"`
+}
+
+// PlanResource is a Traefik v2 custom resource found in a cluster.
+type PlanResource struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+}
+
+// Save writes the plan as JSON.
+func (p *Plan) Save(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(p)
+}
+
+func (p *Plan) String() string {
+	namespaces := map[string]map[string]int{}
+	for _, resource := range p.Resources {
+		if _, ok := namespaces[resource.Namespace]; !ok {
+			namespaces[resource.Namespace] = map[string]int{}
+		}
+		namespaces[resource.Namespace][resource.Kind]++
+	}
+
+	var b strings.Builder
+
+	_, _ = fmt.Fprintf(&b, "Resources in the %s API group: %d, namespaces: %d\n", groupV2, len(p.Resources), len(namespaces))
+
+	var names []string
+	for name := range namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var kinds []string
+		for kind, count := range namespaces[name] {
+			kinds = append(kinds, fmt.Sprintf("%s: %d", kind, count))
+		}
+		sort.Strings(kinds)
+
+		_, _ = fmt.Fprintf(&b, "  %s: %s\n", name, strings.Join(kinds, ", "))
+	}
+
+	if len(p.MissingCRDs) > 0 {
+		_, _ = fmt.Fprintf(&b, "The CRDs of the %s API group must be installed before the migration: %s\n", groupV3, strings.Join(p.MissingCRDs, ", "))
+	}
+
+	return b.String()
+}
+
+// ScanCluster lists the Traefik custom resources of the traefik.containo.us API group in all the namespaces of a cluster.
+func ScanCluster(opts ClusterOptions) (*Plan, error) {
+	config, err := cluster.Config(opts.Kubeconfig, opts.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanCluster(context.Background(), discoveryClient, client, opts.OutputDir)
+}
+
+func scanCluster(ctx context.Context, discoveryClient discovery.DiscoveryInterface, client dynamic.Interface, outputDir string) (*Plan, error) {
+	resourcesV2, err := discoveryClient.ServerResourcesForGroupVersion(groupV2 + versionV1)
+	if err != nil {
+		return nil, fmt.Errorf("%s%s: %w", groupV2, versionV1, err)
+	}
+
+	servedV3 := map[string]struct{}{}
+	if resourcesV3, err := discoveryClient.ServerResourcesForGroupVersion(groupV3 + versionV1); err == nil {
+		for _, resource := range resourcesV3.APIResources {
+			servedV3[resource.Kind] = struct{}{}
+		}
+	}
+
+	plan := &Plan{}
+	manifests := map[string][]*yaml.Node{}
+
+	for _, resource := range resourcesV2.APIResources {
+		// Skip the sub-resources (ex: status).
+		if strings.Contains(resource.Name, "/") {
+			continue
+		}
+
+		gvr := schema.GroupVersionResource{Group: groupV2, Version: strings.TrimPrefix(versionV1, "/"), Resource: resource.Name}
+
+		list, err := client.Resource(gvr).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", resource.Name, err)
+		}
+
+		if len(list.Items) == 0 {
+			continue
+		}
+
+		if _, ok := servedV3[resource.Kind]; !ok {
+			plan.MissingCRDs = append(plan.MissingCRDs, resource.Kind)
+		}
+
+		for _, item := range list.Items {
+			item := item
+
+			plan.Resources = append(plan.Resources, PlanResource{
+				Namespace: item.GetNamespace(),
+				Kind:      resource.Kind,
+				Name:      item.GetName(),
+			})
+
+			if outputDir == "" {
+				continue
+			}
+
+			cleanObject(&item)
+
+			document := &yaml.Node{}
+			if err := document.Encode(item.Object); err != nil {
+				return nil, err
+			}
+
+			convertResource(document, os.Stdout)
+
+			manifests[item.GetNamespace()] = append(manifests[item.GetNamespace()], document)
+		}
+	}
+
+	sort.Slice(plan.Resources, func(i, j int) bool {
+		a, b := plan.Resources[i], plan.Resources[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	sort.Strings(plan.MissingCRDs)
+
+	for namespace, documents := range manifests {
+		err = writeNamespaceManifests(outputDir, namespace, documents)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// cleanObject removes the fields set by the API server, the converted manifests are applied as new resources.
+func cleanObject(item *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(item.Object, "status")
+
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink"} {
+		unstructured.RemoveNestedField(item.Object, "metadata", field)
+	}
+
+	annotations := item.GetAnnotations()
+	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	item.SetAnnotations(annotations)
+}
+
+func writeNamespaceManifests(outputDir, namespace string, documents []*yaml.Node) error {
+	output, err := encodeDocuments(documents)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(outputDir, namespace+".yml"), output, 0666)
+}
@@ -0,0 +1,101 @@
+package v2tov3
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newResource(kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": groupV2 + versionV1,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":            name,
+			"namespace":       namespace,
+			"uid":             "0a3ba2f4",
+			"resourceVersion": "42",
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+			},
+		},
+		"spec": spec,
+	}}
+}
+
+func Test_scanCluster(t *testing.T) {
+	client := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(),
+		newResource("IngressRoute", "default", "whoami", map[string]interface{}{
+			"routes": []interface{}{
+				map[string]interface{}{"match": "Host(`a.example.com`, `b.example.com`)", "kind": "Rule"},
+			},
+		}),
+		newResource("Middleware", "default", "allowlist", map[string]interface{}{
+			"ipWhiteList": map[string]interface{}{"sourceRange": []interface{}{"10.0.0.0/8"}},
+		}),
+		newResource("Middleware", "monitoring", "auth", map[string]interface{}{
+			"basicAuth": map[string]interface{}{"secret": "users"},
+		}),
+	)
+
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	discoveryClient.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: groupV2 + versionV1,
+			APIResources: []metav1.APIResource{
+				{Name: "ingressroutes", Kind: "IngressRoute", Namespaced: true},
+				{Name: "middlewares", Kind: "Middleware", Namespaced: true},
+				{Name: "middlewares/status", Kind: "Middleware", Namespaced: true},
+				{Name: "tlsoptions", Kind: "TLSOption", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: groupV3 + versionV1,
+			APIResources: []metav1.APIResource{
+				{Name: "middlewares", Kind: "Middleware", Namespaced: true},
+			},
+		},
+	}
+
+	outputDir := t.TempDir()
+
+	plan, err := scanCluster(context.Background(), discoveryClient, client, outputDir)
+	require.NoError(t, err)
+
+	expected := &Plan{
+		Resources: []PlanResource{
+			{Namespace: "default", Kind: "IngressRoute", Name: "whoami"},
+			{Namespace: "default", Kind: "Middleware", Name: "allowlist"},
+			{Namespace: "monitoring", Kind: "Middleware", Name: "auth"},
+		},
+		MissingCRDs: []string{"IngressRoute"},
+	}
+	assert.Equal(t, expected, plan)
+
+	for _, namespace := range []string{"default", "monitoring"} {
+		output, err := os.ReadFile(filepath.Join(outputDir, namespace+".yml"))
+		require.NoError(t, err)
+
+		fixtureFile := filepath.Join("fixtures", "output", "cluster", namespace+".yml")
+
+		if *updateExpected {
+			require.NoError(t, os.MkdirAll(filepath.Dir(fixtureFile), 0755))
+			require.NoError(t, os.WriteFile(fixtureFile, output, 0666))
+		}
+
+		fixture, err := os.ReadFile(fixtureFile)
+		require.NoError(t, err)
+
+		assert.Equal(t, string(fixture), string(output))
+	}
+}
@@ -0,0 +1,132 @@
+// Package v2tov3 migrates Traefik v2 configurations to Traefik v3.
+package v2tov3
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	groupV2   = "traefik.containo.us"
+	groupV3   = "traefik.io"
+	versionV1 = "/v1alpha1"
+)
+
+// crdKinds are the Traefik CRD kinds moved from the traefik.containo.us group to the traefik.io group.
+var crdKinds = map[string]struct{}{
+	"IngressRoute":     {},
+	"IngressRouteTCP":  {},
+	"IngressRouteUDP":  {},
+	"Middleware":       {},
+	"MiddlewareTCP":    {},
+	"TraefikService":   {},
+	"TLSOption":        {},
+	"TLSStore":         {},
+	"ServersTransport": {},
+}
+
+// ConvertCRD converts the Traefik v2 custom resources of the manifests in src (a file or a directory) into dstDir.
+func ConvertCRD(src, dstDir string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return convertCRDFile(src, filepath.Join(dstDir, info.Name()))
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = ConvertCRD(filepath.Join(src, entry.Name()), filepath.Join(dstDir, info.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func convertCRDFile(src, dstFile string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	output, err := ConvertResources(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(dstFile), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(dstFile, output, 0666)
+}
+
+// ConvertResources converts the Traefik v2 custom resources of a YAML stream, the other documents are kept.
+func ConvertResources(content []byte) ([]byte, error) {
+	documents, err := decodeDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, document := range documents {
+		convertResource(root(document), os.Stdout)
+	}
+
+	return encodeDocuments(documents)
+}
+
+// convertResource converts a Traefik v2 resource, or the items of a List.
+func convertResource(node *yaml.Node, w io.Writer) {
+	if items := mappingValue(node, "items"); items != nil && items.Kind == yaml.SequenceNode {
+		for _, item := range items.Content {
+			convertResource(item, w)
+		}
+		return
+	}
+
+	apiVersion := mappingValue(node, "apiVersion")
+	if apiVersion == nil || apiVersion.Value != groupV2+versionV1 {
+		return
+	}
+
+	kind := scalarValue(node, "kind")
+	if _, ok := crdKinds[kind]; !ok {
+		fmt.Fprintf(w, "%s %s: unknown kind, the resource must be converted manually.\n", kind, scalarValue(mappingValue(node, "metadata"), "name"))
+		return
+	}
+
+	apiVersion.Value = groupV3 + versionV1
+
+	name := scalarValue(mappingValue(node, "metadata"), "name")
+
+	switch kind {
+	case "IngressRoute", "IngressRouteTCP":
+		routes := mappingValue(mappingValue(node, "spec"), "routes")
+		if routes == nil || routes.Kind != yaml.SequenceNode {
+			return
+		}
+
+		for _, route := range routes.Content {
+			convertRouterRule(kind+" "+name, mappingValue(route, "match"), w)
+		}
+	case "Middleware":
+		convertMiddleware(name, mappingValue(node, "spec"), w)
+	case "MiddlewareTCP":
+		convertMiddlewareTCP(name, mappingValue(node, "spec"), w)
+	case "TLSOption":
+		convertTLSOption(name, mappingValue(node, "spec"), w)
+	}
+}
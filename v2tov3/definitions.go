@@ -0,0 +1,78 @@
+package v2tov3
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// definitionsURL is the location of the Traefik CRD definitions, by Traefik branch (ex: v3.1).
+var definitionsURL = "https://raw.githubusercontent.com/traefik/traefik/%s/docs/content/reference/dynamic-configuration/kubernetes-crd-definition-v1.yml"
+
+var minorVersionRegexp = regexp.MustCompile(`^v?(3\.\d+)$`)
+
+// WriteDefinitions writes the traefik.io CRD definitions of a Traefik v3 minor version (ex: v3.1) into outputDir.
+func WriteDefinitions(version, outputDir string) error {
+	match := minorVersionRegexp.FindStringSubmatch(version)
+	if match == nil {
+		return fmt.Errorf("invalid Traefik version %q: a Traefik v3 minor version is expected (ex: v3.1)", version)
+	}
+	version = "v" + match[1]
+
+	content, err := fetchDefinitions(fmt.Sprintf(definitionsURL, version))
+	if err != nil {
+		return fmt.Errorf("CRD definitions %s: %w", version, err)
+	}
+
+	documents, err := decodeDocuments(content)
+	if err != nil {
+		return fmt.Errorf("CRD definitions %s: %w", version, err)
+	}
+
+	for _, document := range documents {
+		node := root(document)
+		if scalarValue(node, "kind") != "CustomResourceDefinition" || scalarValue(mappingValue(node, "spec"), "group") != groupV3 {
+			return fmt.Errorf("CRD definitions %s: unexpected resource %s %s", version, scalarValue(node, "kind"), scalarValue(mappingValue(node, "metadata"), "name"))
+		}
+	}
+
+	if len(documents) == 0 {
+		return fmt.Errorf("CRD definitions %s: no definition found", version)
+	}
+
+	err = os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	filename := filepath.Join(outputDir, "traefik-crds-"+version+".yml")
+
+	err = os.WriteFile(filename, content, 0666)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("The %s CRD definitions of Traefik %s are written to %s, they must be applied before the converted resources.\n", groupV3, version, filename)
+
+	return nil
+}
+
+func fetchDefinitions(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,77 @@
+package v2tov3
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDefinitions = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: ingressroutes.traefik.io
+spec:
+  group: traefik.io
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: middlewares.traefik.io
+spec:
+  group: traefik.io
+`
+
+func TestWriteDefinitions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v3.1":
+			_, _ = fmt.Fprint(rw, testDefinitions)
+		case "/v3.2":
+			_, _ = fmt.Fprint(rw, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n")
+		default:
+			http.NotFound(rw, req)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	previousURL := definitionsURL
+	definitionsURL = server.URL + "/%s"
+	t.Cleanup(func() { definitionsURL = previousURL })
+
+	testCases := []struct {
+		version string
+		err     string
+	}{
+		{version: "v3.1"},
+		{version: "3.1"},
+		{version: "v2.10", err: `invalid Traefik version "v2.10": a Traefik v3 minor version is expected (ex: v3.1)`},
+		{version: "v3.1.2", err: `invalid Traefik version "v3.1.2": a Traefik v3 minor version is expected (ex: v3.1)`},
+		{version: "v3.2", err: "CRD definitions v3.2: unexpected resource ConfigMap foo"},
+		{version: "v3.9", err: "CRD definitions v3.9: unexpected status 404 Not Found from " + server.URL + "/v3.9"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.version, func(t *testing.T) {
+			outputDir := t.TempDir()
+
+			err := WriteDefinitions(test.version, outputDir)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+
+			content, err := os.ReadFile(filepath.Join(outputDir, "traefik-crds-v3.1.yml"))
+			require.NoError(t, err)
+
+			assert.Equal(t, testDefinitions, string(content))
+		})
+	}
+}
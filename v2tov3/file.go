@@ -0,0 +1,209 @@
+package v2tov3
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// middlewares are the Traefik v3 HTTP middlewares.
+var middlewares = []string{
+	"addPrefix", "basicAuth", "buffering", "chain", "circuitBreaker", "compress", "contentType", "digestAuth", "errors",
+	"forwardAuth", "grpcWeb", "headers", "ipAllowList", "inFlightReq", "passTLSClientCert", "plugin", "rateLimit",
+	"redirectRegex", "redirectScheme", "replacePath", "replacePathRegex", "retry", "stripPrefix", "stripPrefixRegex",
+}
+
+// middlewaresTCP are the Traefik v3 TCP middlewares.
+var middlewaresTCP = []string{"inFlightConn", "ipAllowList"}
+
+// ConvertFile converts the Traefik v2 file provider dynamic configurations in src (a file or a directory) into dstDir.
+// The rules and the options to convert manually, and the problems of the converted configurations, are reported to notes, default to the standard output.
+func ConvertFile(src, dstDir string, notes io.Writer) error {
+	if notes == nil {
+		notes = os.Stdout
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return convertDynamicFile(src, filepath.Join(dstDir, info.Name()), notes)
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = ConvertFile(filepath.Join(src, entry.Name()), filepath.Join(dstDir, info.Name()), notes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func convertDynamicFile(src, dstFile string, w io.Writer) error {
+	ext := strings.ToLower(filepath.Ext(src))
+	if ext != ".toml" && ext != ".yml" && ext != ".yaml" {
+		return nil
+	}
+
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	documents, err := decodeDynamic(content, ext)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+
+	for _, document := range documents {
+		convertDynamic(root(document), w)
+
+		for _, problem := range validateDynamic(root(document)) {
+			fmt.Fprintf(w, "%s: %s\n", src, problem)
+		}
+	}
+
+	output, err := encodeDynamic(documents, ext)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dstFile), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(dstFile, output, 0666)
+}
+
+// decodeDynamic decodes a dynamic configuration to YAML nodes, a TOML configuration is converted to a YAML document.
+func decodeDynamic(content []byte, ext string) ([]*yaml.Node, error) {
+	if ext != ".toml" {
+		return decodeDocuments(content)
+	}
+
+	cfg := map[string]interface{}{}
+	if _, err := toml.Decode(string(content), &cfg); err != nil {
+		return nil, err
+	}
+
+	document := &yaml.Node{}
+	if err := document.Encode(cfg); err != nil {
+		return nil, err
+	}
+
+	return []*yaml.Node{document}, nil
+}
+
+func encodeDynamic(documents []*yaml.Node, ext string) ([]byte, error) {
+	if ext != ".toml" {
+		return encodeDocuments(documents)
+	}
+
+	cfg := map[string]interface{}{}
+	if err := documents[0].Decode(&cfg); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := toml.NewEncoder(buf).Encode(cfg); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// convertDynamic converts a Traefik v2 dynamic configuration: the routers rules and the middlewares.
+func convertDynamic(node *yaml.Node, w io.Writer) {
+	forEach(mappingValueFold(mappingValueFold(node, "http"), "routers"), func(name string, router *yaml.Node) {
+		convertRouterRule("Router "+name, mappingValueFold(router, "rule"), w)
+	})
+
+	forEach(mappingValueFold(mappingValueFold(node, "tcp"), "routers"), func(name string, router *yaml.Node) {
+		convertRouterRule("TCP router "+name, mappingValueFold(router, "rule"), w)
+	})
+
+	forEach(mappingValueFold(mappingValueFold(node, "http"), "middlewares"), func(name string, middleware *yaml.Node) {
+		convertMiddleware(name, middleware, w)
+	})
+
+	forEach(mappingValueFold(mappingValueFold(node, "tcp"), "middlewares"), func(name string, middleware *yaml.Node) {
+		convertMiddlewareTCP(name, middleware, w)
+	})
+
+	forEach(mappingValueFold(mappingValueFold(node, "tls"), "options"), func(name string, option *yaml.Node) {
+		convertTLSOption(name, option, w)
+	})
+}
+
+// convertRouterRule converts a rule node, the rules which cannot be converted are kept and reported.
+func convertRouterRule(desc string, rule *yaml.Node, w io.Writer) {
+	if rule == nil || rule.Kind != yaml.ScalarNode {
+		return
+	}
+
+	converted, err := convertRule(rule.Value)
+	if err != nil {
+		fmt.Fprintf(w, "%s: the rule %q must be converted manually: %v\n", desc, rule.Value, err)
+		return
+	}
+
+	rule.Value = converted
+}
+
+// validateDynamic checks a converted dynamic configuration against the Traefik v3 rules matchers and middlewares.
+func validateDynamic(node *yaml.Node) []string {
+	var problems []string
+
+	for _, protocol := range []string{"http", "tcp"} {
+		forEach(mappingValueFold(mappingValueFold(node, protocol), "routers"), func(name string, router *yaml.Node) {
+			rule := mappingValueFold(router, "rule")
+			if rule == nil {
+				return
+			}
+
+			if err := validateRule(rule.Value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s router %s: invalid Traefik v3 rule: %v", protocol, name, err))
+			}
+		})
+	}
+
+	validateMiddlewares := func(protocol string, known []string) {
+		forEach(mappingValueFold(mappingValueFold(node, protocol), "middlewares"), func(name string, middleware *yaml.Node) {
+			forEach(middleware, func(kind string, _ *yaml.Node) {
+				if !containsFold(known, kind) {
+					problems = append(problems, fmt.Sprintf("%s middleware %s: unknown Traefik v3 middleware type %s", protocol, name, kind))
+				}
+			})
+		})
+	}
+
+	validateMiddlewares("http", middlewares)
+	validateMiddlewares("tcp", middlewaresTCP)
+
+	return problems
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,171 @@
+package v2tov3
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/traefik/traefik-migration-tool/label"
+	"github.com/traefik/traefik-migration-tool/labels"
+	"gopkg.in/yaml.v3"
+)
+
+// ConvertLabels rewrites the Traefik v2 labels of a compose file, or of all the compose files of a directory tree, for Traefik v3.
+// The compose files are written to dst, the other keys and the comments are kept.
+func ConvertLabels(src, dst string) error {
+	return labels.WalkComposeFiles(src, dst, convertComposeFile)
+}
+
+func convertComposeFile(src, dstFile string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	documents, err := decodeDocuments(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+
+	for _, document := range documents {
+		forEach(mappingValue(root(document), "services"), func(name string, service *yaml.Node) {
+			convertServiceLabels(name, mappingValue(service, "labels"), false)
+			convertServiceLabels(name, mappingValue(mappingValue(service, "deploy"), "labels"), true)
+		})
+	}
+
+	output, err := encodeDocuments(documents)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dstFile), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(dstFile, output, 0666)
+}
+
+// convertServiceLabels converts the labels of a service, in the list ("key=value") or the map syntax.
+// The swarm labels are the deploy labels, read by the Swarm provider in Traefik v3.
+func convertServiceLabels(service string, node *yaml.Node, swarm bool) {
+	if node == nil {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var content []*yaml.Node
+		for _, item := range node.Content {
+			parts := strings.SplitN(item.Value, "=", 2)
+			if len(parts) != 2 {
+				content = append(content, item)
+				continue
+			}
+
+			key, value, keep := convertLabel(service, strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), swarm)
+			if !keep {
+				continue
+			}
+
+			item.Value = key + "=" + value
+			content = append(content, item)
+		}
+		node.Content = content
+
+	case yaml.MappingNode:
+		var content []*yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+
+			key, value, keep := convertLabel(service, keyNode.Value, valueNode.Value, swarm)
+			if !keep {
+				continue
+			}
+
+			keyNode.Value = key
+			valueNode.Value = value
+			content = append(content, keyNode, valueNode)
+		}
+		node.Content = content
+	}
+}
+
+// convertLabel converts a Traefik v2 label, keep is false when the label has been removed in Traefik v3.
+// The values hold the compose escaping of "$" ("$$").
+func convertLabel(service, key, value string, swarm bool) (string, string, bool) {
+	if !strings.HasPrefix(strings.ToLower(key), label.Prefix) {
+		return key, value, true
+	}
+
+	parts := strings.Split(key, ".")
+
+	if swarm && strings.EqualFold(parts[1], "docker") {
+		parts[1] = "swarm"
+		fmt.Printf("%s: The label %s is read by the Swarm provider in Traefik v3, renamed to %s.\n", service, key, strings.Join(parts, "."))
+	}
+
+	if len(parts) == 5 && strings.EqualFold(parts[2], "routers") && strings.EqualFold(parts[4], "rule") {
+		rule, err := convertRule(strings.ReplaceAll(value, "$$", "$"))
+		if err != nil {
+			fmt.Printf("%s: The rule %s must be converted manually: %v\n", service, key, err)
+		} else {
+			value = strings.ReplaceAll(rule, "$", "$$")
+		}
+	}
+
+	if len(parts) >= 5 && strings.EqualFold(parts[2], "middlewares") {
+		keep := convertMiddlewareLabel(service, key, parts, value)
+		if !keep {
+			return key, value, false
+		}
+	}
+
+	return strings.Join(parts, "."), value, true
+}
+
+// convertMiddlewareLabel converts the parts of a middleware label (traefik.<protocol>.middlewares.<name>.<type>...).
+func convertMiddlewareLabel(service, key string, parts []string, value string) bool {
+	if strings.EqualFold(parts[4], "ipWhiteList") {
+		parts[4] = matchCase(parts[4], "ipAllowList")
+		fmt.Printf("%s: The label %s renamed to %s.\n", service, key, strings.Join(parts, "."))
+		return true
+	}
+
+	if len(parts) < 6 || !strings.EqualFold(parts[1], "http") {
+		return true
+	}
+
+	switch {
+	case strings.EqualFold(parts[4], "headers") && containsFold(removedHeadersOptions, parts[5]):
+		fmt.Printf("%s: The label %s has been removed in Traefik v3, it must be converted manually (ex: with a redirectScheme middleware). See %s\n", service, key, migrationDoc)
+		return false
+
+	case strings.EqualFold(parts[4], "headers") && strings.EqualFold(parts[5], "featurePolicy"):
+		parts[5] = matchCase(parts[5], "permissionsPolicy")
+		fmt.Printf("%s: The label %s renamed to %s, check the syntax of the value.\n", service, key, strings.Join(parts, "."))
+
+	case strings.EqualFold(parts[4], "contentType") && strings.EqualFold(parts[5], "autoDetect"):
+		if value != "true" {
+			fmt.Printf("%s: The content type auto detection is disabled by default in Traefik v3, the contentType middleware enables it and must be removed.\n", service)
+		}
+		return false
+
+	case strings.EqualFold(parts[4], "stripPrefix") && strings.EqualFold(parts[5], "forceSlash"):
+		fmt.Printf("%s: The label %s has been removed in Traefik v3, it must be converted manually. See %s\n", service, key, migrationDoc)
+		return false
+	}
+
+	return true
+}
+
+// matchCase returns name in lowercase if the original name is in lowercase, as the labels are usually written.
+func matchCase(original, name string) string {
+	if original == strings.ToLower(original) {
+		return strings.ToLower(name)
+	}
+
+	return name
+}
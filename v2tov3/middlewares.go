@@ -0,0 +1,58 @@
+package v2tov3
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const migrationDoc = "https://doc.traefik.io/traefik/migration/v2-to-v3/"
+
+// removedHeadersOptions are the options of the headers middleware removed in Traefik v3.
+var removedHeadersOptions = []string{
+	"sslRedirect",
+	"sslTemporaryRedirect",
+	"sslHost",
+	"sslForceHost",
+	"sslProxyHeaders",
+}
+
+// convertMiddleware converts the configuration of a HTTP middleware (the mapping holding the middleware type).
+func convertMiddleware(name string, node *yaml.Node, w io.Writer) {
+	if renameKey(node, "ipWhiteList", "ipAllowList") {
+		fmt.Fprintf(w, "Middleware %s: ipWhiteList renamed to ipAllowList.\n", name)
+	}
+
+	if headers := mappingValueFold(node, "headers"); headers != nil {
+		for _, option := range removedHeadersOptions {
+			if removeKey(headers, option) != nil {
+				fmt.Fprintf(w, "Middleware %s: the headers option %s has been removed in Traefik v3, it must be converted manually (ex: with a redirectScheme middleware). See %s\n", name, option, migrationDoc)
+			}
+		}
+
+		if renameKey(headers, "featurePolicy", "permissionsPolicy") {
+			fmt.Fprintf(w, "Middleware %s: the headers option featurePolicy renamed to permissionsPolicy, check the syntax of the value.\n", name)
+		}
+	}
+
+	if contentType := mappingValueFold(node, "contentType"); contentType != nil {
+		autoDetect := removeKey(contentType, "autoDetect")
+		if autoDetect != nil && autoDetect.Value != "true" {
+			fmt.Fprintf(w, "Middleware %s: the content type auto detection is disabled by default in Traefik v3, the contentType middleware enables it and must be removed.\n", name)
+		}
+	}
+
+	if stripPrefix := mappingValueFold(node, "stripPrefix"); stripPrefix != nil {
+		if removeKey(stripPrefix, "forceSlash") != nil {
+			fmt.Fprintf(w, "Middleware %s: the stripPrefix option forceSlash has been removed in Traefik v3, it must be converted manually. See %s\n", name, migrationDoc)
+		}
+	}
+}
+
+// convertMiddlewareTCP converts the configuration of a TCP middleware (the mapping holding the middleware type).
+func convertMiddlewareTCP(name string, node *yaml.Node, w io.Writer) {
+	if renameKey(node, "ipWhiteList", "ipAllowList") {
+		fmt.Fprintf(w, "MiddlewareTCP %s: ipWhiteList renamed to ipAllowList.\n", name)
+	}
+}
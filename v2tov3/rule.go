@@ -0,0 +1,327 @@
+package v2tov3
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// matchers are the Traefik v3 rule matchers, with their number of arguments.
+var matchers = map[string]int{
+	"Host":          1,
+	"HostRegexp":    1,
+	"Path":          1,
+	"PathPrefix":    1,
+	"PathRegexp":    1,
+	"Method":        1,
+	"Header":        2,
+	"HeaderRegexp":  2,
+	"Query":         -1, // key, or key and value.
+	"QueryRegexp":   -1,
+	"ClientIP":      1,
+	"HostSNI":       1,
+	"HostSNIRegexp": 1,
+	"ALPN":          1,
+}
+
+// multiValuesMatchers are the matchers accepting several values in Traefik v2, and only one in Traefik v3.
+var multiValuesMatchers = map[string]struct{}{
+	"Host":          {},
+	"HostHeader":    {},
+	"HostRegexp":    {},
+	"Path":          {},
+	"PathPrefix":    {},
+	"Method":        {},
+	"ClientIP":      {},
+	"HostSNI":       {},
+	"HostSNIRegexp": {},
+	"ALPN":          {},
+}
+
+type matcher struct {
+	name string
+	args []string
+}
+
+// convertRule converts a Traefik v2 router rule to the Traefik v3 syntax.
+// The matchers with several values are split into ORed matchers, the v2 regular expressions templates are converted to Go regular expressions.
+// A rule which converted is not a valid Traefik v3 rule (ex: Headers with a single argument) returns an error.
+func convertRule(rule string) (string, error) {
+	var result strings.Builder
+
+	for i := 0; i < len(rule); {
+		c := rule[i]
+
+		switch {
+		case unicode.IsSpace(rune(c)) || c == '(' || c == ')' || c == '!':
+			result.WriteByte(c)
+			i++
+
+		case strings.HasPrefix(rule[i:], "&&"), strings.HasPrefix(rule[i:], "||"):
+			result.WriteString(rule[i : i+2])
+			i += 2
+
+		case unicode.IsLetter(rune(c)):
+			m, next, err := parseMatcher(rule, i)
+			if err != nil {
+				return "", err
+			}
+
+			converted, err := convertMatcher(m)
+			if err != nil {
+				return "", err
+			}
+
+			result.WriteString(converted)
+			i = next
+
+		default:
+			return "", fmt.Errorf("unexpected character %q at position %d", c, i)
+		}
+	}
+
+	if err := validateRule(result.String()); err != nil {
+		return "", err
+	}
+
+	return result.String(), nil
+}
+
+// parseMatcher parses the matcher starting at the position start, and returns the position following it.
+func parseMatcher(rule string, start int) (matcher, int, error) {
+	i := start
+	for i < len(rule) && (unicode.IsLetter(rune(rule[i])) || unicode.IsDigit(rune(rule[i]))) {
+		i++
+	}
+
+	m := matcher{name: rule[start:i]}
+
+	for i < len(rule) && unicode.IsSpace(rune(rule[i])) {
+		i++
+	}
+
+	if i >= len(rule) || rule[i] != '(' {
+		return m, 0, fmt.Errorf("missing arguments of the matcher %s", m.name)
+	}
+	i++
+
+	for {
+		for i < len(rule) && (unicode.IsSpace(rune(rule[i])) || rule[i] == ',') {
+			i++
+		}
+
+		if i >= len(rule) {
+			return m, 0, fmt.Errorf("unterminated matcher %s", m.name)
+		}
+
+		if rule[i] == ')' {
+			return m, i + 1, nil
+		}
+
+		quote := rule[i]
+		if quote != '`' && quote != '"' {
+			return m, 0, fmt.Errorf("unquoted argument of the matcher %s", m.name)
+		}
+
+		end := strings.IndexByte(rule[i+1:], quote)
+		if end < 0 {
+			return m, 0, fmt.Errorf("unterminated argument of the matcher %s", m.name)
+		}
+
+		m.args = append(m.args, rule[i+1:i+1+end])
+		i += end + 2
+	}
+}
+
+func convertMatcher(m matcher) (string, error) {
+	if len(m.args) == 0 {
+		return "", fmt.Errorf("the matcher %s has no argument", m.name)
+	}
+
+	switch m.name {
+	case "Headers":
+		return formatMatcher("Header", m.args...), nil
+	case "HeadersRegexp":
+		return formatMatcher("HeaderRegexp", m.args...), nil
+	case "Query":
+		return convertQuery(m.args), nil
+	}
+
+	if _, ok := multiValuesMatchers[m.name]; !ok {
+		if _, ok := matchers[m.name]; !ok {
+			return "", fmt.Errorf("unknown matcher %s", m.name)
+		}
+
+		return formatMatcher(m.name, m.args...), nil
+	}
+
+	var parts []string
+	for _, arg := range m.args {
+		name, value, err := convertValue(m.name, arg)
+		if err != nil {
+			return "", err
+		}
+
+		parts = append(parts, formatMatcher(name, value))
+	}
+
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+
+	return "(" + strings.Join(parts, " || ") + ")", nil
+}
+
+// convertValue converts the value of a single value matcher, the matcher can change (ex: Path with a template to PathRegexp).
+func convertValue(name, value string) (string, string, error) {
+	switch name {
+	case "HostHeader":
+		return "Host", value, nil
+
+	case "HostRegexp":
+		expr, err := templateToRegexp(value, "[^.]+")
+		if err != nil {
+			return "", "", err
+		}
+		return name, "^" + expr + "$", nil
+
+	case "Path", "PathPrefix":
+		if !strings.Contains(value, "{") {
+			return name, value, nil
+		}
+
+		expr, err := templateToRegexp(value, "[^/]+")
+		if err != nil {
+			return "", "", err
+		}
+
+		if name == "Path" {
+			expr += "$"
+		}
+		return "PathRegexp", "^" + expr, nil
+	}
+
+	return name, value, nil
+}
+
+func convertQuery(args []string) string {
+	var parts []string
+	for _, arg := range args {
+		key, value := arg, ""
+		if i := strings.Index(arg, "="); i >= 0 {
+			key, value = arg[:i], arg[i+1:]
+		}
+
+		if value == "" {
+			parts = append(parts, formatMatcher("Query", key))
+		} else {
+			parts = append(parts, formatMatcher("Query", key, value))
+		}
+	}
+
+	if len(parts) == 1 {
+		return parts[0]
+	}
+
+	return "(" + strings.Join(parts, " && ") + ")"
+}
+
+// templateToRegexp converts a Traefik v2 template (ex: {subdomain:[a-z]+}.example.com) to a Go regular expression.
+// The variables without pattern match the defaultPattern.
+func templateToRegexp(template, defaultPattern string) (string, error) {
+	var expr strings.Builder
+
+	for i := 0; i < len(template); {
+		start := strings.IndexByte(template[i:], '{')
+		if start < 0 {
+			expr.WriteString(regexp.QuoteMeta(template[i:]))
+			break
+		}
+
+		expr.WriteString(regexp.QuoteMeta(template[i : i+start]))
+		i += start
+
+		// The pattern can hold braces (ex: [0-9]{2}).
+		level, end := 0, -1
+		for j := i; j < len(template); j++ {
+			if template[j] == '{' {
+				level++
+			} else if template[j] == '}' {
+				level--
+				if level == 0 {
+					end = j
+					break
+				}
+			}
+		}
+
+		if end < 0 {
+			return "", fmt.Errorf("unbalanced braces in %q", template)
+		}
+
+		variable := template[i+1 : end]
+		pattern := defaultPattern
+		if sep := strings.IndexByte(variable, ':'); sep >= 0 {
+			pattern = variable[sep+1:]
+		}
+
+		if _, err := regexp.Compile(pattern); err != nil {
+			return "", err
+		}
+
+		expr.WriteString(pattern)
+		i = end + 1
+	}
+
+	return expr.String(), nil
+}
+
+func formatMatcher(name string, args ...string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = "`" + arg + "`"
+	}
+
+	return name + "(" + strings.Join(quoted, ", ") + ")"
+}
+
+// validateRule checks that a rule only uses the Traefik v3 matchers, with their expected arguments.
+func validateRule(rule string) error {
+	for i := 0; i < len(rule); {
+		c := rule[i]
+
+		switch {
+		case strings.HasPrefix(rule[i:], "&&"), strings.HasPrefix(rule[i:], "||"):
+			i += 2
+
+		case unicode.IsLetter(rune(c)):
+			m, next, err := parseMatcher(rule, i)
+			if err != nil {
+				return err
+			}
+
+			count, ok := matchers[m.name]
+			if !ok {
+				return fmt.Errorf("unknown matcher %s", m.name)
+			}
+
+			if count < 0 && (len(m.args) < 1 || len(m.args) > 2) || count >= 0 && len(m.args) != count {
+				return fmt.Errorf("invalid number of arguments of the matcher %s", m.name)
+			}
+
+			if strings.HasSuffix(m.name, "Regexp") {
+				if _, err := regexp.Compile(m.args[len(m.args)-1]); err != nil {
+					return err
+				}
+			}
+
+			i = next
+
+		default:
+			i++
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package v2tov3
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// removedProviders are the providers removed in Traefik v3.
+var removedProviders = []string{"marathon", "rancher"}
+
+// removedTracingBackends are the tracing backends replaced by OpenTelemetry in Traefik v3.
+var removedTracingBackends = []string{"jaeger", "zipkin", "datadog", "instana", "haystack", "elastic"}
+
+// ConvertStatic converts a Traefik v2 static configuration file (TOML or YAML) to the Traefik v3 static configuration files.
+// The options to convert manually are reported to notes, default to the standard output.
+func ConvertStatic(src, outputDir string, notes io.Writer) error {
+	if notes == nil {
+		notes = os.Stdout
+	}
+
+	cfg, err := readConfiguration(src)
+	if err != nil {
+		return err
+	}
+
+	migrateStatic(cfg, notes)
+
+	err = os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	return writeConfiguration(outputDir, cfg)
+}
+
+func readConfiguration(filename string) (map[string]interface{}, error) {
+	cfg := map[string]interface{}{}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".toml":
+		_, err := toml.DecodeFile(filename, &cfg)
+		if err != nil {
+			return nil, err
+		}
+
+	case ".yml", ".yaml":
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		err = yaml.Unmarshal(content, &cfg)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported file extension: %s", filename)
+	}
+
+	return cfg, nil
+}
+
+func writeConfiguration(outputDir string, cfg map[string]interface{}) error {
+	err := writeFile(filepath.Join(outputDir, "new-traefik.yml"), func(w io.Writer) error {
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		return encoder.Encode(cfg)
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeFile(filepath.Join(outputDir, "new-traefik.toml"), func(w io.Writer) error {
+		return toml.NewEncoder(w).Encode(cfg)
+	})
+}
+
+func writeFile(filename string, encode func(w io.Writer) error) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	return encode(file)
+}
+
+func migrateStatic(cfg map[string]interface{}, w io.Writer) {
+	if _, ok := deleteKey(cfg, "pilot"); ok {
+		fmt.Fprintln(w, "The static configuration pilot has been removed: Traefik Pilot is not available anymore.")
+	}
+
+	if _, ok := deleteKey(cfg, "hostResolver"); ok {
+		fmt.Fprintf(w, "The static configuration hostResolver has been removed in Traefik v3. See %s\n", migrationDoc)
+	}
+
+	migrateExperimental(cfg, w)
+	migrateProviders(cfg, w)
+	migrateStaticTracing(cfg, w)
+	migrateStaticMetrics(cfg, w)
+}
+
+func migrateExperimental(cfg map[string]interface{}, w io.Writer) {
+	experimental := getMap(cfg, "experimental")
+	if experimental == nil {
+		return
+	}
+
+	if _, ok := deleteKey(experimental, "http3"); ok {
+		fmt.Fprintln(w, "The static configuration experimental.http3 has been removed: HTTP/3 is enabled on the entry points with the http3 option.")
+	}
+
+	if _, ok := deleteKey(experimental, "kubernetesGateway"); ok {
+		fmt.Fprintln(w, "The static configuration experimental.kubernetesGateway has been removed: the Kubernetes Gateway provider is enabled with providers.kubernetesGateway.")
+	}
+
+	if len(experimental) == 0 {
+		deleteKey(cfg, "experimental")
+	}
+}
+
+func migrateProviders(cfg map[string]interface{}, w io.Writer) {
+	providers := getMap(cfg, "providers")
+	if providers == nil {
+		return
+	}
+
+	for _, name := range removedProviders {
+		if _, ok := deleteKey(providers, name); ok {
+			fmt.Fprintf(w, "The static configuration providers.%s has been removed: the provider is not available in Traefik v3, it must be converted manually. See %s\n", name, migrationDoc)
+		}
+	}
+
+	if docker := getMap(providers, "docker"); docker != nil {
+		swarmMode, _ := deleteKey(docker, "swarmMode")
+		if swarmMode == true {
+			moveKey(docker, "swarmModeRefreshSeconds", "refreshSeconds")
+			moveKey(providers, "docker", "swarm")
+			fmt.Fprintln(w, "The static configuration providers.docker.swarmMode moved to the providers.swarm provider: the labels prefix is unchanged, check the options of the provider.")
+		}
+	}
+
+	if crd := getMap(providers, "kubernetesCRD"); crd != nil {
+		if _, ok := findKey(crd, "allowCrossNamespace"); !ok {
+			crd["allowCrossNamespace"] = true
+			fmt.Fprintln(w, "The static configuration providers.kubernetesCRD.allowCrossNamespace defaults to false in Traefik v3: set to true to keep the Traefik v2 behavior, remove it if the cross namespace references are not needed.")
+		}
+	}
+
+	for _, name := range []string{"consul", "consulCatalog", "nomad"} {
+		provider := getMap(providers, name)
+		if namespace, ok := deleteKey(provider, "namespace"); ok {
+			provider["namespaces"] = []interface{}{namespace}
+			fmt.Fprintf(w, "The static configuration providers.%s.namespace replaced by providers.%s.namespaces.\n", name, name)
+		}
+	}
+
+	for _, name := range sortedNames(providers) {
+		if _, ok := deleteKey(getMap(providers, name, "tls"), "caOptional"); ok {
+			fmt.Fprintf(w, "The static configuration providers.%s.tls.caOptional has been removed in Traefik v3.\n", name)
+		}
+	}
+}
+
+func migrateStaticTracing(cfg map[string]interface{}, w io.Writer) {
+	tracing := getMap(cfg, "tracing")
+	if tracing == nil {
+		return
+	}
+
+	for _, name := range removedTracingBackends {
+		if _, ok := deleteKey(tracing, name); ok {
+			fmt.Fprintf(w, "The static configuration tracing.%s has been removed: Traefik v3 only supports OpenTelemetry (tracing.otlp), the exporter must be configured manually. See %s\n", name, migrationDoc)
+		}
+	}
+
+	if _, ok := deleteKey(tracing, "spanNameLimit"); ok {
+		fmt.Fprintln(w, "The static configuration tracing.spanNameLimit has been removed in Traefik v3.")
+	}
+
+	if openTelemetry := getMap(tracing, "openTelemetry"); openTelemetry != nil {
+		deleteKey(tracing, "openTelemetry")
+		tracing["otlp"] = migrateOpenTelemetry(openTelemetry)
+		fmt.Fprintln(w, "The static configuration tracing.openTelemetry moved to tracing.otlp.")
+	}
+}
+
+func migrateStaticMetrics(cfg map[string]interface{}, w io.Writer) {
+	metrics := getMap(cfg, "metrics")
+	if metrics == nil {
+		return
+	}
+
+	if _, ok := deleteKey(metrics, "influxDB"); ok {
+		fmt.Fprintln(w, "The static configuration metrics.influxDB has been removed: InfluxDB v1 is not supported anymore, use metrics.influxDB2 instead.")
+	}
+
+	if openTelemetry := getMap(metrics, "openTelemetry"); openTelemetry != nil {
+		deleteKey(metrics, "openTelemetry")
+		metrics["otlp"] = migrateOpenTelemetry(openTelemetry)
+		fmt.Fprintln(w, "The static configuration metrics.openTelemetry moved to metrics.otlp.")
+	}
+}
+
+// migrateOpenTelemetry moves the exporter options of an OpenTelemetry configuration to the http or grpc section.
+func migrateOpenTelemetry(openTelemetry map[string]interface{}) map[string]interface{} {
+	exporter := map[string]interface{}{}
+
+	address, _ := deleteKey(openTelemetry, "address")
+	path, ok := deleteKey(openTelemetry, "path")
+	if !ok {
+		path = ""
+	}
+	insecure, _ := deleteKey(openTelemetry, "insecure")
+
+	for _, name := range []string{"headers", "tls"} {
+		if value, ok := deleteKey(openTelemetry, name); ok {
+			exporter[name] = value
+		}
+	}
+
+	if _, grpc := deleteKey(openTelemetry, "grpc"); grpc {
+		if address != nil {
+			exporter["endpoint"] = address
+		}
+		if insecure != nil {
+			exporter["insecure"] = insecure
+		}
+
+		openTelemetry["grpc"] = exporter
+
+		return openTelemetry
+	}
+
+	if address != nil {
+		scheme := "https"
+		if insecure == true {
+			scheme = "http"
+		}
+
+		exporter["endpoint"] = fmt.Sprintf("%s://%v%v", scheme, address, path)
+	}
+
+	openTelemetry["http"] = exporter
+
+	return openTelemetry
+}
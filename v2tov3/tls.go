@@ -0,0 +1,15 @@
+package v2tov3
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// convertTLSOption converts the configuration of a TLS option (the spec of a TLSOption, or an option of the file provider).
+func convertTLSOption(name string, node *yaml.Node, w io.Writer) {
+	if removeKey(node, "preferServerCipherSuites") != nil {
+		fmt.Fprintf(w, "TLSOption %s: the option preferServerCipherSuites has been removed in Traefik v3, the server cipher suites are always preferred with TLS 1.3. See %s\n", name, migrationDoc)
+	}
+}
@@ -0,0 +1,75 @@
+package v2tov3
+
+import (
+	"sort"
+	"strings"
+)
+
+// findKey returns the key of a configuration map matching name, the Traefik options are case-insensitive.
+func findKey(m map[string]interface{}, name string) (string, bool) {
+	for key := range m {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+// getMap returns the configuration map at the given path, or nil.
+func getMap(m map[string]interface{}, path ...string) map[string]interface{} {
+	current := m
+	for _, name := range path {
+		key, ok := findKey(current, name)
+		if !ok {
+			return nil
+		}
+
+		child, ok := current[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = child
+	}
+
+	return current
+}
+
+// deleteKey removes an option of a configuration map and returns its value.
+func deleteKey(m map[string]interface{}, name string) (interface{}, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	key, ok := findKey(m, name)
+	if !ok {
+		return nil, false
+	}
+
+	value := m[key]
+	delete(m, key)
+
+	return value, true
+}
+
+// moveKey renames an option of a configuration map.
+func moveKey(m map[string]interface{}, oldName, newName string) bool {
+	value, ok := deleteKey(m, oldName)
+	if !ok {
+		return false
+	}
+
+	m[newName] = value
+
+	return true
+}
+
+func sortedNames(m map[string]interface{}) []string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
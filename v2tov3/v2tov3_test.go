@@ -0,0 +1,245 @@
+package v2tov3
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var updateExpected = flag.Bool("update_expected", false, "Update expected files in testdata")
+
+func TestConvertCRD(t *testing.T) {
+	testCases := []string{
+		"crd.yml",
+		"middlewares.yml",
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test, func(t *testing.T) {
+			dstDir := t.TempDir()
+
+			err := ConvertCRD(filepath.Join("fixtures", "input", test), dstDir)
+			require.NoError(t, err)
+
+			output, err := os.ReadFile(filepath.Join(dstDir, test))
+			require.NoError(t, err)
+
+			fixtureFile := filepath.Join("fixtures", "output", test)
+
+			if *updateExpected {
+				require.NoError(t, os.WriteFile(fixtureFile, output, 0666))
+			}
+
+			fixture, err := os.ReadFile(fixtureFile)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(fixture), string(output))
+		})
+	}
+}
+
+func TestConvertStatic(t *testing.T) {
+	testCases := []struct {
+		src      string
+		expected string
+	}{
+		{
+			src:      "traefik.toml",
+			expected: "traefik-toml.yml",
+		},
+		{
+			src:      "traefik.yml",
+			expected: "traefik-yml.yml",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.src, func(t *testing.T) {
+			dstDir := t.TempDir()
+
+			err := ConvertStatic(filepath.Join("fixtures", "input", "static", test.src), dstDir, nil)
+			require.NoError(t, err)
+
+			output, err := os.ReadFile(filepath.Join(dstDir, "new-traefik.yml"))
+			require.NoError(t, err)
+
+			fixtureFile := filepath.Join("fixtures", "output", "static", test.expected)
+
+			if *updateExpected {
+				require.NoError(t, os.WriteFile(fixtureFile, output, 0666))
+			}
+
+			fixture, err := os.ReadFile(fixtureFile)
+			require.NoError(t, err)
+
+			assert.YAMLEq(t, string(fixture), string(output))
+
+			cfgToml, err := readConfiguration(filepath.Join(dstDir, "new-traefik.toml"))
+			require.NoError(t, err)
+
+			cfgYaml, err := readConfiguration(filepath.Join(dstDir, "new-traefik.yml"))
+			require.NoError(t, err)
+
+			assert.Equal(t, toYAML(t, cfgYaml), toYAML(t, cfgToml))
+		})
+	}
+}
+
+func toYAML(t *testing.T, cfg map[string]interface{}) string {
+	t.Helper()
+
+	content, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+
+	return string(content)
+}
+
+func TestConvertFile(t *testing.T) {
+	testCases := []string{
+		"dynamic.yml",
+		"dynamic.toml",
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test, func(t *testing.T) {
+			dstDir := t.TempDir()
+
+			err := ConvertFile(filepath.Join("fixtures", "input", "file", test), dstDir, nil)
+			require.NoError(t, err)
+
+			output, err := os.ReadFile(filepath.Join(dstDir, test))
+			require.NoError(t, err)
+
+			fixtureFile := filepath.Join("fixtures", "output", "file", test)
+
+			if *updateExpected {
+				require.NoError(t, os.WriteFile(fixtureFile, output, 0666))
+			}
+
+			fixture, err := os.ReadFile(fixtureFile)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(fixture), string(output))
+		})
+	}
+}
+
+func Test_convertRule(t *testing.T) {
+	testCases := []struct {
+		rule     string
+		expected string
+		err      bool
+	}{
+		{
+			rule:     "Host(`example.com`)",
+			expected: "Host(`example.com`)",
+		},
+		{
+			rule:     "Host(`example.com`, `example.org`) && PathPrefix(`/api`)",
+			expected: "(Host(`example.com`) || Host(`example.org`)) && PathPrefix(`/api`)",
+		},
+		{
+			rule:     "HostHeader(`example.com`)",
+			expected: "Host(`example.com`)",
+		},
+		{
+			rule:     "HostRegexp(`{subdomain:[a-z]+}.example.com`, `{any}.example.org`)",
+			expected: "(HostRegexp(`^[a-z]+\\.example\\.com$`) || HostRegexp(`^[^.]+\\.example\\.org$`))",
+		},
+		{
+			rule:     "Path(`/users/{id:[0-9]{1,3}}`)",
+			expected: "PathRegexp(`^/users/[0-9]{1,3}$`)",
+		},
+		{
+			rule:     "PathPrefix(`/users/{id}`)",
+			expected: "PathRegexp(`^/users/[^/]+`)",
+		},
+		{
+			rule:     "!Method(\"GET\", \"POST\") || (Headers(`X-A`, `a`) && HeadersRegexp(`X-B`, `b.*`))",
+			expected: "!(Method(`GET`) || Method(`POST`)) || (Header(`X-A`, `a`) && HeaderRegexp(`X-B`, `b.*`))",
+		},
+		{
+			rule:     "Query(`a=1`, `b`)",
+			expected: "(Query(`a`, `1`) && Query(`b`))",
+		},
+		{
+			rule:     "HostSNI(`*`)",
+			expected: "HostSNI(`*`)",
+		},
+		{
+			rule: "Foo(`bar`)",
+			err:  true,
+		},
+		{
+			rule: "Host(`example.com`",
+			err:  true,
+		},
+		{
+			rule: "HostRegexp(`{sub:[a-z+}.example.com`)",
+			err:  true,
+		},
+		{
+			rule: "Host(`example.com`) && Headers(`X-Version`)",
+			err:  true,
+		},
+		{
+			rule: "HeadersRegexp(`X-Version`, `[0-9`) || Path(`/`)",
+			err:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.rule, func(t *testing.T) {
+			t.Parallel()
+
+			rule, err := convertRule(test.rule)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, rule)
+			assert.NoError(t, validateRule(rule))
+		})
+	}
+}
+
+func TestConvertLabels(t *testing.T) {
+	testCases := []string{
+		"docker-compose.yml",
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test, func(t *testing.T) {
+			dstFile := filepath.Join(t.TempDir(), test)
+
+			err := ConvertLabels(filepath.Join("fixtures", "input", "labels", test), dstFile)
+			require.NoError(t, err)
+
+			output, err := os.ReadFile(dstFile)
+			require.NoError(t, err)
+
+			fixtureFile := filepath.Join("fixtures", "output", "labels", test)
+
+			if *updateExpected {
+				require.NoError(t, os.WriteFile(fixtureFile, output, 0666))
+			}
+
+			fixture, err := os.ReadFile(fixtureFile)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(fixture), string(output))
+		})
+	}
+}
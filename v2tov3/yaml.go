@@ -0,0 +1,142 @@
+package v2tov3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeDocuments decodes all the documents of a YAML stream, the comments are kept.
+func decodeDocuments(content []byte) ([]*yaml.Node, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+
+	var documents []*yaml.Node
+	for {
+		document := &yaml.Node{}
+		err := decoder.Decode(document)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(document.Content) == 0 {
+			continue
+		}
+
+		documents = append(documents, document)
+	}
+}
+
+func encodeDocuments(documents []*yaml.Node) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// root returns the root node of a document.
+func root(document *yaml.Node) *yaml.Node {
+	if document.Kind == yaml.DocumentNode && len(document.Content) > 0 {
+		return document.Content[0]
+	}
+
+	return document
+}
+
+// mappingValue returns the value of a key of a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// scalarValue returns the value of a scalar key of a mapping node, or an empty string.
+func scalarValue(node *yaml.Node, key string) string {
+	value := mappingValue(node, key)
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return ""
+	}
+
+	return value.Value
+}
+
+// renameKey renames a key of a mapping node ignoring the case, the value and the comments are kept.
+func renameKey(node *yaml.Node, oldKey, newKey string) bool {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return false
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, oldKey) {
+			node.Content[i].Value = newKey
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeKey removes a key of a mapping node ignoring the case and returns its value, or nil.
+func removeKey(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			value := node.Content[i+1]
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return value
+		}
+	}
+
+	return nil
+}
+
+// forEach calls fn for each entry of a mapping node.
+func forEach(node *yaml.Node, fn func(key string, value *yaml.Node)) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fn(node.Content[i].Value, node.Content[i+1])
+	}
+}
+
+// mappingValueFold returns the value of a key of a mapping node ignoring the case, as the file provider does, or nil.
+func mappingValueFold(node *yaml.Node, key string) *yaml.Node {
+	var result *yaml.Node
+	forEach(node, func(k string, value *yaml.Node) {
+		if result == nil && strings.EqualFold(k, key) {
+			result = value
+		}
+	})
+
+	return result
+}
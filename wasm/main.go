@@ -0,0 +1,66 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package main exposes the Ingress conversion to JavaScript when compiled to WebAssembly.
+//
+// It registers a global convertIngress(source, options) function,
+// returning an object with the converted YAML (output), the report of the conversion as JSON (report),
+// the annotations to convert manually (notes), and the error of the conversion (error).
+// The options are an object with the audit, explain, and all booleans, as the flags of the CLI.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"syscall/js"
+
+	"github.com/traefik/traefik-migration-tool/ingress"
+)
+
+func main() {
+	js.Global().Set("convertIngress", js.FuncOf(convertIngress))
+
+	// Keeps the functions available to JavaScript.
+	select {}
+}
+
+func convertIngress(_ js.Value, args []js.Value) interface{} {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return map[string]interface{}{"error": "the source must be a string"}
+	}
+
+	notes := &bytes.Buffer{}
+	opts := ingress.Options{
+		AnnotatedOnly: true,
+		Notes:         notes,
+	}
+
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		opts.Audit = optionBool(args[1], "audit")
+		opts.Explain = optionBool(args[1], "explain")
+		opts.AnnotatedOnly = !optionBool(args[1], "all")
+	}
+
+	output := &bytes.Buffer{}
+	report, err := ingress.ConvertStream(strings.NewReader(args[0].String()), output, opts)
+	if err != nil {
+		return map[string]interface{}{"error": err.Error(), "notes": notes.String()}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return map[string]interface{}{"error": err.Error(), "notes": notes.String()}
+	}
+
+	return map[string]interface{}{
+		"output": output.String(),
+		"report": string(data),
+		"notes":  notes.String(),
+	}
+}
+
+func optionBool(options js.Value, name string) bool {
+	value := options.Get(name)
+	return value.Type() == js.TypeBoolean && value.Bool()
+}